@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type IAdminController interface {
+	GetAllUsers(c echo.Context) error
+	GetUserTasks(c echo.Context) error
+	DisableUser(c echo.Context) error
+	EnableUser(c echo.Context) error
+	SetRole(c echo.Context) error
+	GetAuditLog(c echo.Context) error
+}
+
+type adminController struct {
+	au usecase.IAdminUsecase
+}
+
+func NewAdminController(au usecase.IAdminUsecase) IAdminController {
+	return &adminController{au}
+}
+
+func (ac *adminController) GetAllUsers(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	usersRes, err := ac.au.GetAllUsers(uint(userId.(float64)))
+	if err != nil {
+		return adminErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, usersRes)
+}
+
+func (ac *adminController) GetUserTasks(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	targetId, _ := strconv.Atoi(c.Param("userId"))
+	tasksRes, err := ac.au.GetUserTasks(uint(userId.(float64)), uint(targetId))
+	if err != nil {
+		return adminErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, tasksRes)
+}
+
+func (ac *adminController) DisableUser(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	targetId, _ := strconv.Atoi(c.Param("userId"))
+	if err := ac.au.DisableUser(uint(userId.(float64)), uint(targetId)); err != nil {
+		return adminErrorResponse(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ac *adminController) EnableUser(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	targetId, _ := strconv.Atoi(c.Param("userId"))
+	if err := ac.au.EnableUser(uint(userId.(float64)), uint(targetId)); err != nil {
+		return adminErrorResponse(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ac *adminController) SetRole(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	targetId, _ := strconv.Atoi(c.Param("userId"))
+	req := model.RoleRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := ac.au.SetRole(uint(userId.(float64)), uint(targetId), req); err != nil {
+		return adminErrorResponse(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ac *adminController) GetAuditLog(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	entriesRes, err := ac.au.GetAuditLog(uint(userId.(float64)))
+	if err != nil {
+		return adminErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, entriesRes)
+}
+
+func adminErrorResponse(c echo.Context, err error) error {
+	if _, ok := err.(validation.Errors); ok {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, model.ErrPermissionDenied) || errors.Is(err, model.ErrAccountDisabled) {
+		return c.JSON(http.StatusForbidden, err.Error())
+	}
+	if errors.Is(err, model.ErrOwnAccount) {
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, "user not found")
+	}
+	return c.JSON(http.StatusInternalServerError, err.Error())
+}
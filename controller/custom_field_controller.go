@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ICustomFieldController interface {
+	GetCustomFields(c echo.Context) error
+	CreateCustomField(c echo.Context) error
+	DeleteCustomField(c echo.Context) error
+}
+
+type customFieldController struct {
+	cu usecase.ICustomFieldUsecase
+}
+
+func NewCustomFieldController(cu usecase.ICustomFieldUsecase) ICustomFieldController {
+	return &customFieldController{cu}
+}
+
+func (cc *customFieldController) GetCustomFields(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+	fieldResp, err := cc.cu.GetCustomFields(uint(userId.(float64)), activeWorkspaceId(c), uint(projectId))
+	if err != nil {
+		return projectErrorResponse(c, err, "project not found")
+	}
+	return c.JSON(http.StatusOK, fieldResp)
+}
+
+func (cc *customFieldController) CreateCustomField(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+	field := model.CustomField{}
+	if err := c.Bind(&field); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	fieldResp, err := cc.cu.CreateCustomField(uint(userId.(float64)), activeWorkspaceId(c), uint(projectId), field)
+	if err != nil {
+		return projectErrorResponse(c, err, "project not found")
+	}
+	return c.JSON(http.StatusCreated, fieldResp)
+}
+
+func (cc *customFieldController) DeleteCustomField(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+	fieldId, _ := strconv.Atoi(c.Param("fieldId"))
+	if err := cc.cu.DeleteCustomField(uint(userId.(float64)), activeWorkspaceId(c), uint(projectId), uint(fieldId)); err != nil {
+		return projectErrorResponse(c, err, "project or field not found")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
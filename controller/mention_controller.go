@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"go-rest-api/usecase"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IMentionController interface {
+	GetMentions(c echo.Context) error
+}
+
+type mentionController struct {
+	mu usecase.IMentionUsecase
+}
+
+func NewMentionController(mu usecase.IMentionUsecase) IMentionController {
+	return &mentionController{mu}
+}
+
+func (mc *mentionController) GetMentions(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	mentionResp, err := mc.mu.GetMentions(uint(userId.(float64)), activeWorkspaceId(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, mentionResp)
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type IMilestoneController interface {
+	GetAllMilestones(c echo.Context) error
+	GetMilestoneSummary(c echo.Context) error
+	CreateMilestone(c echo.Context) error
+	AddTask(c echo.Context) error
+	RemoveTask(c echo.Context) error
+	CloseMilestone(c echo.Context) error
+}
+
+type milestoneController struct {
+	mu usecase.IMilestoneUsecase
+}
+
+func NewMilestoneController(mu usecase.IMilestoneUsecase) IMilestoneController {
+	return &milestoneController{mu}
+}
+
+func (mc *milestoneController) GetAllMilestones(c echo.Context) error {
+	milestoneResp, err := mc.mu.GetAllMilestones(activeWorkspaceId(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, milestoneResp)
+}
+
+func (mc *milestoneController) GetMilestoneSummary(c echo.Context) error {
+	id := c.Param("milestoneId")
+	milestoneId, _ := strconv.Atoi(id)
+	summaryResp, err := mc.mu.GetMilestoneSummary(activeWorkspaceId(c), uint(milestoneId))
+	if err != nil {
+		return milestoneErrorResponse(c, err, "milestone not found")
+	}
+	return c.JSON(http.StatusOK, summaryResp)
+}
+
+func (mc *milestoneController) CreateMilestone(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	milestone := model.Milestone{}
+	if err := c.Bind(&milestone); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	milestone.UserId = uint(userId.(float64))
+	milestone.WorkspaceId = activeWorkspaceId(c)
+	milestoneResp, err := mc.mu.CreateMilestone(milestone)
+	if err != nil {
+		return milestoneErrorResponse(c, err, "milestone not found")
+	}
+	return c.JSON(http.StatusCreated, milestoneResp)
+}
+
+func (mc *milestoneController) AddTask(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("milestoneId")
+	milestoneId, _ := strconv.Atoi(id)
+	req := model.MilestoneTaskRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := mc.mu.AddTask(uint(userId.(float64)), activeWorkspaceId(c), uint(milestoneId), req.TaskId); err != nil {
+		return milestoneErrorResponse(c, err, "milestone or task not found")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (mc *milestoneController) RemoveTask(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("milestoneId")
+	milestoneId, _ := strconv.Atoi(id)
+	taskId, _ := strconv.Atoi(c.Param("taskId"))
+	if err := mc.mu.RemoveTask(uint(userId.(float64)), activeWorkspaceId(c), uint(milestoneId), uint(taskId)); err != nil {
+		return milestoneErrorResponse(c, err, "milestone or task not found")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (mc *milestoneController) CloseMilestone(c echo.Context) error {
+	id := c.Param("milestoneId")
+	milestoneId, _ := strconv.Atoi(id)
+	req := model.MilestoneCloseRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	summaryResp, err := mc.mu.CloseMilestone(activeWorkspaceId(c), uint(milestoneId), req)
+	if err != nil {
+		return milestoneErrorResponse(c, err, "milestone not found")
+	}
+	return c.JSON(http.StatusOK, summaryResp)
+}
+
+func milestoneErrorResponse(c echo.Context, err error, notFound string) error {
+	if _, ok := err.(validation.Errors); ok {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, model.ErrMilestoneClosed) {
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, notFound)
+	}
+	return c.JSON(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type IPersonalAccessTokenController interface {
+	GetAllTokens(c echo.Context) error
+	CreateToken(c echo.Context) error
+	RevokeToken(c echo.Context) error
+}
+
+type personalAccessTokenController struct {
+	pu usecase.IPersonalAccessTokenUsecase
+}
+
+func NewPersonalAccessTokenController(pu usecase.IPersonalAccessTokenUsecase) IPersonalAccessTokenController {
+	return &personalAccessTokenController{pu}
+}
+
+func (pc *personalAccessTokenController) GetAllTokens(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	tokensRes, err := pc.pu.GetAllTokens(uint(userId.(float64)))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, tokensRes)
+}
+
+func (pc *personalAccessTokenController) CreateToken(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	req := model.PersonalAccessTokenRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	tokenRes, err := pc.pu.CreateToken(uint(userId.(float64)), req)
+	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, tokenRes)
+}
+
+func (pc *personalAccessTokenController) RevokeToken(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	tokenId, _ := strconv.Atoi(c.Param("tokenId"))
+	if err := pc.pu.RevokeToken(uint(userId.(float64)), uint(tokenId)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, "token not found")
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
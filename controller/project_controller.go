@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type IProjectController interface {
+	GetAllProjects(c echo.Context) error
+	CreateProject(c echo.Context) error
+	AddMember(c echo.Context) error
+	RemoveMember(c echo.Context) error
+}
+
+type projectController struct {
+	pu usecase.IProjectUsecase
+}
+
+func NewProjectController(pu usecase.IProjectUsecase) IProjectController {
+	return &projectController{pu}
+}
+
+func (pc *projectController) GetAllProjects(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	projectResp, err := pc.pu.GetAllProjects(uint(userId.(float64)), activeWorkspaceId(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, projectResp)
+}
+
+func (pc *projectController) CreateProject(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	project := model.Project{}
+	if err := c.Bind(&project); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	project.UserId = uint(userId.(float64))
+	project.WorkspaceId = activeWorkspaceId(c)
+	projectResp, err := pc.pu.CreateProject(project)
+	if err != nil {
+		return projectErrorResponse(c, err, "project not found")
+	}
+	return c.JSON(http.StatusCreated, projectResp)
+}
+
+func (pc *projectController) AddMember(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+	req := model.ProjectMemberRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := pc.pu.AddMember(uint(userId.(float64)), activeWorkspaceId(c), uint(projectId), req); err != nil {
+		return projectErrorResponse(c, err, "project or user not found")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (pc *projectController) RemoveMember(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+	memberId, _ := strconv.Atoi(c.Param("userId"))
+	if err := pc.pu.RemoveMember(uint(userId.(float64)), activeWorkspaceId(c), uint(projectId), uint(memberId)); err != nil {
+		return projectErrorResponse(c, err, "project or user not found")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func projectErrorResponse(c echo.Context, err error, notFound string) error {
+	if _, ok := err.(validation.Errors); ok {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, model.ErrNotProjectOwner) || errors.Is(err, model.ErrNotWorkspaceMember) {
+		return c.JSON(http.StatusForbidden, err.Error())
+	}
+	if errors.Is(err, model.ErrProjectOwnerMember) {
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, notFound)
+	}
+	return c.JSON(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type IQuickAddController interface {
+	QuickAddTask(c echo.Context) error
+}
+
+type quickAddController struct {
+	qu usecase.IQuickAddUsecase
+}
+
+func NewQuickAddController(qu usecase.IQuickAddUsecase) IQuickAddController {
+	return &quickAddController{qu}
+}
+
+func (qc *quickAddController) QuickAddTask(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	req := model.QuickAddRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	quickAddResp, err := qc.qu.QuickAddTask(uint(userId.(float64)), activeWorkspaceId(c), req)
+	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		var quotaErr *model.QuotaError
+		if errors.As(err, &quotaErr) {
+			return quotaErrorResponse(c, quotaErr)
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	if req.Preview {
+		return c.JSON(http.StatusOK, quickAddResp)
+	}
+	return c.JSON(http.StatusCreated, quickAddResp)
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ISmartListController interface {
+	GetAllSmartLists(c echo.Context) error
+	GetSmartListByID(c echo.Context) error
+	GetSmartListTasks(c echo.Context) error
+	CreateSmartList(c echo.Context) error
+	UpdateSmartList(c echo.Context) error
+	DeleteSmartList(c echo.Context) error
+}
+
+type smartListController struct {
+	su usecase.ISmartListUsecase
+}
+
+func NewSmartListController(su usecase.ISmartListUsecase) ISmartListController {
+	return &smartListController{su}
+}
+
+func (sc *smartListController) GetAllSmartLists(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	listResp, err := sc.su.GetAllSmartLists(uint(userId.(float64)))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, listResp)
+}
+
+func (sc *smartListController) GetSmartListByID(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("listId")
+	listId, _ := strconv.Atoi(id)
+	listResp, err := sc.su.GetSmartListByID(uint(userId.(float64)), uint(listId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, listResp)
+}
+
+func (sc *smartListController) GetSmartListTasks(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("listId")
+	listId, _ := strconv.Atoi(id)
+	taskResp, err := sc.su.GetSmartListTasks(uint(userId.(float64)), activeWorkspaceId(c), uint(listId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, taskResp)
+}
+
+func (sc *smartListController) CreateSmartList(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	list := model.SmartList{}
+	if err := c.Bind(&list); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	list.UserId = uint(userId.(float64))
+	listResp, err := sc.su.CreateSmartList(list)
+	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, listResp)
+}
+
+func (sc *smartListController) UpdateSmartList(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("listId")
+	listId, _ := strconv.Atoi(id)
+	list := model.SmartList{}
+	if err := c.Bind(&list); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	listResp, err := sc.su.UpdateSmartList(uint(userId.(float64)), uint(listId), list)
+	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, listResp)
+}
+
+func (sc *smartListController) DeleteSmartList(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("listId")
+	listId, _ := strconv.Atoi(id)
+	if err := sc.su.DeleteSmartList(uint(userId.(float64)), uint(listId)); err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ISnoozeController interface {
+	SnoozeTask(c echo.Context) error
+	UnsnoozeTask(c echo.Context) error
+	GetEvents(c echo.Context) error
+}
+
+type snoozeController struct {
+	su usecase.ISnoozeUsecase
+}
+
+func NewSnoozeController(su usecase.ISnoozeUsecase) ISnoozeController {
+	return &snoozeController{su}
+}
+
+func (sc *snoozeController) SnoozeTask(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return ifMatchErrorResponse(c, err)
+	}
+	req := model.SnoozeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	taskResp, err := sc.su.SnoozeTask(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), version, req)
+	if err != nil {
+		return snoozeErrorResponse(c, err)
+	}
+	setETag(c, taskResp.Version)
+	return c.JSON(http.StatusOK, taskResp)
+}
+
+func (sc *snoozeController) UnsnoozeTask(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return ifMatchErrorResponse(c, err)
+	}
+	taskResp, err := sc.su.UnsnoozeTask(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), version)
+	if err != nil {
+		return snoozeErrorResponse(c, err)
+	}
+	setETag(c, taskResp.Version)
+	return c.JSON(http.StatusOK, taskResp)
+}
+
+func (sc *snoozeController) GetEvents(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	after := uint64(0)
+	if c.QueryParam("after") != "" {
+		var err error
+		if after, err = strconv.ParseUint(c.QueryParam("after"), 10, 32); err != nil {
+			return c.JSON(http.StatusBadRequest, "after must be an event id")
+		}
+	}
+	eventResp, err := sc.su.GetEvents(uint(userId.(float64)), activeWorkspaceId(c), uint(after))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, eventResp)
+}
+
+func snoozeErrorResponse(c echo.Context, err error) error {
+	if _, ok := err.(validation.Errors); ok {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, model.ErrStaleVersion) {
+		return c.JSON(http.StatusPreconditionFailed, err.Error())
+	}
+	var quotaErr *model.QuotaError
+	if errors.As(err, &quotaErr) {
+		return quotaErrorResponse(c, quotaErr)
+	}
+	return c.JSON(http.StatusInternalServerError, err.Error())
+}
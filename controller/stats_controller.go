@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+const statsDateLayout = "2006-01-02"
+
+type IStatsController interface {
+	GetStats(c echo.Context) error
+}
+
+type statsController struct {
+	su usecase.IStatsUsecase
+}
+
+func NewStatsController(su usecase.IStatsUsecase) IStatsController {
+	return &statsController{su}
+}
+
+func (sc *statsController) GetStats(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	// 期間の指定がなければ直近30日 (toは当日を含む)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	query := model.StatsQuery{
+		From:     today.AddDate(0, 0, -29),
+		To:       today.AddDate(0, 0, 1),
+		Interval: model.StatsIntervalDay,
+	}
+	if from := c.QueryParam("from"); from != "" {
+		t, err := time.Parse(statsDateLayout, from)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, "from must be formatted as YYYY-MM-DD")
+		}
+		query.From = t
+	}
+	if to := c.QueryParam("to"); to != "" {
+		t, err := time.Parse(statsDateLayout, to)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, "to must be formatted as YYYY-MM-DD")
+		}
+		query.To = t.AddDate(0, 0, 1)
+	}
+	if interval := c.QueryParam("interval"); interval != "" {
+		query.Interval = interval
+	}
+
+	statsResp, err := sc.su.GetStats(uint(userId.(float64)), activeWorkspaceId(c), query)
+	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, statsResp)
+}
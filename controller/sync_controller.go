@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+const maxSyncPushItems = 100
+
+type ISyncController interface {
+	Sync(c echo.Context) error
+	Push(c echo.Context) error
+}
+
+type syncController struct {
+	su usecase.ISyncUsecase
+}
+
+func NewSyncController(su usecase.ISyncUsecase) ISyncController {
+	return &syncController{su}
+}
+
+func (sc *syncController) Sync(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	syncResp, err := sc.su.Sync(uint(userId.(float64)), activeWorkspaceId(c), c.QueryParam("since"))
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidSyncToken) {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, model.ErrSyncTokenExpired) {
+			return c.JSON(http.StatusGone, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, syncResp)
+}
+
+func (sc *syncController) Push(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	req := model.SyncPushRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if len(req.Changes) > maxSyncPushItems {
+		return c.JSON(http.StatusBadRequest, "changes limited max 100 items")
+	}
+	results := sc.su.Push(uint(userId.(float64)), activeWorkspaceId(c), req.Changes)
+	return c.JSON(http.StatusOK, echo.Map{"results": results})
+}
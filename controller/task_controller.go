@@ -1,21 +1,49 @@
 package controller
 
 import (
+	"encoding/json"
+	"errors"
 	"go-rest-api/model"
 	"go-rest-api/usecase"
+	"io"
+	"math"
+	"mime"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
+const (
+	mimeMergePatchJSON = "application/merge-patch+json"
+	headerETag         = "ETag"
+	headerIfMatch      = "If-Match"
+)
+
+// maxAttachmentRequestSize leaves room for the multipart envelope around a
+// file of model.MaxAttachmentSize.
+const maxAttachmentRequestSize = model.MaxAttachmentSize + 64<<10
+
 type ITaskController interface {
 	GetAllTasks(c echo.Context) error
 	GetTaskByID(c echo.Context) error
 	CreateTask(c echo.Context) error
 	UpdateTask(c echo.Context) error
+	PatchTask(c echo.Context) error
 	DeleteTask(c echo.Context) error
+	GetUsage(c echo.Context) error
+	AddComment(c echo.Context) error
+	GetComments(c echo.Context) error
+	AddAttachment(c echo.Context) error
+	GetAttachments(c echo.Context) error
+	DownloadAttachment(c echo.Context) error
+	DeleteAttachment(c echo.Context) error
 }
 
 type taskController struct {
@@ -31,8 +59,19 @@ func (tc *taskController) GetAllTasks(c echo.Context) error {
 	claims := user.Claims.(jwt.MapClaims)
 	userId := claims["userId"]
 
-	taskResp, err := tc.taskUseCase.GetAllTasks(uint(userId.(float64))) // interface{}で帰ってくるので型アサーションしてからuintに変換
+	query, err := taskQuery(c)
 	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	taskResp, err := tc.taskUseCase.GetAllTasks(uint(userId.(float64)), activeWorkspaceId(c), query) // interface{}で帰ってくるので型アサーションしてからuintに変換
+	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		var quotaErr *model.QuotaError
+		if errors.As(err, &quotaErr) {
+			return quotaErrorResponse(c, quotaErr)
+		}
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
 	return c.JSON(http.StatusOK, taskResp)
@@ -45,10 +84,15 @@ func (tc *taskController) GetTaskByID(c echo.Context) error {
 
 	id := c.Param("taskId")
 	taskId, _ := strconv.Atoi(id)
-	taskResp, err := tc.taskUseCase.GetTaskByID(uint(userId.(float64)), uint(taskId))
+	taskResp, err := tc.taskUseCase.GetTaskByID(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId))
 	if err != nil {
+		var quotaErr *model.QuotaError
+		if errors.As(err, &quotaErr) {
+			return quotaErrorResponse(c, quotaErr)
+		}
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
+	setETag(c, taskResp.Version)
 	return c.JSON(http.StatusOK, taskResp)
 }
 
@@ -62,8 +106,19 @@ func (tc *taskController) CreateTask(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 	task.UserId = uint(userId.(float64)) // ここでuserId入れておく
+	task.WorkspaceId = activeWorkspaceId(c)
 	taskResp, err := tc.taskUseCase.CreateTask(task)
 	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, model.ErrNotProjectMember) || errors.Is(err, model.ErrNotWorkspaceMember) {
+			return c.JSON(http.StatusForbidden, err.Error())
+		}
+		var quotaErr *model.QuotaError
+		if errors.As(err, &quotaErr) {
+			return quotaErrorResponse(c, quotaErr)
+		}
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
 	return c.JSON(http.StatusCreated, taskResp)
@@ -76,14 +131,76 @@ func (tc *taskController) UpdateTask(c echo.Context) error {
 
 	id := c.Param("taskId")
 	taskId, _ := strconv.Atoi(id)
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return ifMatchErrorResponse(c, err)
+	}
 	task := model.Task{}
 	if err := c.Bind(&task); err != nil {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
-	taskResp, err := tc.taskUseCase.UpdateTask(uint(userId.(float64)), uint(taskId), task)
+	taskResp, err := tc.taskUseCase.UpdateTask(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), version, task)
 	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, model.ErrStaleVersion) {
+			return c.JSON(http.StatusPreconditionFailed, err.Error())
+		}
+		if errors.Is(err, model.ErrNotProjectMember) || errors.Is(err, model.ErrNotWorkspaceMember) {
+			return c.JSON(http.StatusForbidden, err.Error())
+		}
+		var quotaErr *model.QuotaError
+		if errors.As(err, &quotaErr) {
+			return quotaErrorResponse(c, quotaErr)
+		}
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
+	setETag(c, taskResp.Version)
+	return c.JSON(http.StatusOK, taskResp)
+}
+
+// PatchTask applies a JSON Merge Patch (RFC 7396) to the task.
+func (tc *taskController) PatchTask(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != mimeMergePatchJSON && mediaType != echo.MIMEApplicationJSON {
+		return c.JSON(http.StatusUnsupportedMediaType, "content type must be "+mimeMergePatchJSON)
+	}
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return ifMatchErrorResponse(c, err)
+	}
+	patch := model.TaskPatch{}
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	taskResp, err := tc.taskUseCase.PatchTask(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), version, patch)
+	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, model.ErrStaleVersion) {
+			return c.JSON(http.StatusPreconditionFailed, err.Error())
+		}
+		if errors.Is(err, model.ErrNotProjectMember) || errors.Is(err, model.ErrNotWorkspaceMember) {
+			return c.JSON(http.StatusForbidden, err.Error())
+		}
+		var quotaErr *model.QuotaError
+		if errors.As(err, &quotaErr) {
+			return quotaErrorResponse(c, quotaErr)
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	setETag(c, taskResp.Version)
 	return c.JSON(http.StatusOK, taskResp)
 }
 
@@ -94,8 +211,284 @@ func (tc *taskController) DeleteTask(c echo.Context) error {
 
 	id := c.Param("taskId")
 	taskId, _ := strconv.Atoi(id)
-	if err := tc.taskUseCase.DeleteTask(uint(userId.(float64)), uint(taskId)); err != nil {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return ifMatchErrorResponse(c, err)
+	}
+	if err := tc.taskUseCase.DeleteTask(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), version); err != nil {
+		if errors.Is(err, model.ErrStaleVersion) {
+			return c.JSON(http.StatusPreconditionFailed, err.Error())
+		}
+		var quotaErr *model.QuotaError
+		if errors.As(err, &quotaErr) {
+			return quotaErrorResponse(c, quotaErr)
+		}
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
 	return c.NoContent(http.StatusOK)
 }
+
+func (tc *taskController) GetUsage(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	usageResp, err := tc.taskUseCase.GetUsage(uint(userId.(float64)))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, usageResp)
+}
+
+func (tc *taskController) AddComment(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	comment := model.Comment{}
+	if err := c.Bind(&comment); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	commentResp, err := tc.taskUseCase.AddComment(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), comment)
+	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, "task not found")
+		}
+		var quotaErr *model.QuotaError
+		if errors.As(err, &quotaErr) {
+			return quotaErrorResponse(c, quotaErr)
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, commentResp)
+}
+
+func (tc *taskController) GetComments(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	commentResp, err := tc.taskUseCase.GetComments(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, "task not found")
+		}
+		var quotaErr *model.QuotaError
+		if errors.As(err, &quotaErr) {
+			return quotaErrorResponse(c, quotaErr)
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, commentResp)
+}
+
+// AddAttachment takes the file from the "file" field of a multipart form. The
+// body is capped before it is parsed, so an oversized upload is refused
+// once it passes the cap instead of being read in full.
+func (tc *taskController) AddAttachment(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxAttachmentRequestSize)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return c.JSON(http.StatusRequestEntityTooLarge, "file is limited to 10 MiB")
+		}
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if file.Size > model.MaxAttachmentSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, "file is limited to 10 MiB")
+	}
+	src, err := file.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	contentType := file.Header.Get(echo.HeaderContentType)
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	attachment := model.Attachment{Name: file.Filename, ContentType: contentType, Size: int64(len(data)), Data: data}
+	attachmentResp, err := tc.taskUseCase.AddAttachment(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), attachment)
+	if err != nil {
+		return attachmentErrorResponse(c, err, "task not found")
+	}
+	return c.JSON(http.StatusCreated, attachmentResp)
+}
+
+func (tc *taskController) GetAttachments(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	attachmentResp, err := tc.taskUseCase.GetAttachments(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId))
+	if err != nil {
+		return attachmentErrorResponse(c, err, "task not found")
+	}
+	return c.JSON(http.StatusOK, attachmentResp)
+}
+
+// DownloadAttachment always sends the file as a download, so that uploaded
+// HTML is never rendered on the API's origin.
+func (tc *taskController) DownloadAttachment(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	attachmentId, _ := strconv.Atoi(c.Param("attachmentId"))
+	attachment, err := tc.taskUseCase.GetAttachment(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), uint(attachmentId))
+	if err != nil {
+		return attachmentErrorResponse(c, err, "task or attachment not found")
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	c.Response().Header().Set("X-Content-Type-Options", "nosniff")
+	return c.Blob(http.StatusOK, attachment.ContentType, attachment.Data)
+}
+
+func (tc *taskController) DeleteAttachment(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	attachmentId, _ := strconv.Atoi(c.Param("attachmentId"))
+	if err := tc.taskUseCase.DeleteAttachment(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), uint(attachmentId)); err != nil {
+		return attachmentErrorResponse(c, err, "task or attachment not found")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func attachmentErrorResponse(c echo.Context, err error, notFound string) error {
+	if _, ok := err.(validation.Errors); ok {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, notFound)
+	}
+	var quotaErr *model.QuotaError
+	if errors.As(err, &quotaErr) {
+		return quotaErrorResponse(c, quotaErr)
+	}
+	return c.JSON(http.StatusInternalServerError, err.Error())
+}
+
+// quotaErrorResponse answers a request the task usecase refused because of a
+// quota. Rate limits are temporary and get 429 with Retry-After, other limits
+// get 403 until the user frees something up.
+func quotaErrorResponse(c echo.Context, quotaErr *model.QuotaError) error {
+	body := echo.Map{
+		"message": quotaErr.Error(),
+		"limit":   quotaErr.Limit,
+		"used":    quotaErr.Used,
+		"max":     quotaErr.Max,
+	}
+	if errors.Is(quotaErr, model.ErrRateLimited) {
+		body["reset_at"] = quotaErr.ResetAt
+		retryAfter := int(math.Ceil(time.Until(*quotaErr.ResetAt).Seconds()))
+		c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		return c.JSON(http.StatusTooManyRequests, body)
+	}
+	body["hint"] = "delete tasks you no longer need or ask for a higher plan, see GET /me/usage"
+	if quotaErr.Limit == model.QuotaLimitAttachmentBytes {
+		body["hint"] = "delete attachments you no longer need or ask for a higher plan, see GET /me/usage"
+	}
+	return c.JSON(http.StatusForbidden, body)
+}
+
+// taskQuery reads the GET /tasks query parameters. cf.<field id>=value
+// filters on a custom field, cf.<field id>.gte and cf.<field id>.lte give a
+// range, and sort=cf.<field id> or sort=-cf.<field id> orders by one.
+func taskQuery(c echo.Context) (model.TaskQuery, error) {
+	query := model.TaskQuery{}
+	for _, include := range strings.Split(c.QueryParam("include"), ",") {
+		if include == "snoozed" {
+			query.IncludeSnoozed = true
+		}
+	}
+	params := c.QueryParams()
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if strings.HasPrefix(name, "cf.") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		id, op, _ := strings.Cut(strings.TrimPrefix(name, "cf."), ".")
+		fieldId, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return model.TaskQuery{}, errors.New(name + " must name a custom field by id")
+		}
+		if op == "" {
+			op = model.CustomFieldOpEq
+		}
+		for _, value := range params[name] {
+			query.Filters = append(query.Filters, model.CustomFieldFilter{FieldId: uint(fieldId), Op: op, Value: value})
+		}
+	}
+	if param := c.QueryParam("sort"); param != "" {
+		name := strings.TrimPrefix(param, "-")
+		fieldId, err := strconv.ParseUint(strings.TrimPrefix(name, "cf."), 10, 32)
+		if err != nil || !strings.HasPrefix(name, "cf.") {
+			return model.TaskQuery{}, errors.New("sort must be cf.<field id> or -cf.<field id>")
+		}
+		query.Sort = &model.CustomFieldSort{FieldId: uint(fieldId), Desc: name != param}
+	}
+	return query, nil
+}
+
+func setETag(c echo.Context, version uint) {
+	c.Response().Header().Set(headerETag, strconv.Quote(strconv.FormatUint(uint64(version), 10)))
+}
+
+// errWeakETag rejects weak validators in If-Match, which RFC 9110 compares
+// strongly.
+var errWeakETag = errors.New("If-Match must be a strong ETag")
+
+// ifMatchVersion reads the task version from the If-Match header. A missing
+// header or "*" yields 0, which skips the version check.
+func ifMatchVersion(c echo.Context) (uint, error) {
+	etag := c.Request().Header.Get(headerIfMatch)
+	if etag == "" || etag == "*" {
+		return 0, nil
+	}
+	if strings.HasPrefix(etag, "W/") {
+		return 0, errWeakETag
+	}
+	version, err := strconv.ParseUint(strings.Trim(etag, `"`), 10, 32)
+	if err != nil || version == 0 {
+		return 0, errors.New("If-Match must be an ETag returned by GET /tasks/:taskId")
+	}
+	return uint(version), nil
+}
+
+// ifMatchErrorResponse answers a weak ETag like a stale one, since a weak
+// validator never matches strongly, and any other unreadable If-Match with
+// 400.
+func ifMatchErrorResponse(c echo.Context, err error) error {
+	if errors.Is(err, errWeakETag) {
+		return c.JSON(http.StatusPreconditionFailed, err.Error())
+	}
+	return c.JSON(http.StatusBadRequest, err.Error())
+}
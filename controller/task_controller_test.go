@@ -0,0 +1,53 @@
+package controller
+
+import (
+	apimiddleware "go-rest-api/middleware"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"go-rest-api/validator"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// customFieldTaskUsecase checks custom field values on create the way the
+// task usecase does, against a project with one number field. The other
+// methods are not used.
+type customFieldTaskUsecase struct {
+	usecase.ITaskUsecase
+}
+
+func (tu customFieldTaskUsecase) CreateTask(task model.Task) (model.TaskResponse, error) {
+	fields := []model.CustomField{{ID: 1, Name: "Estimate", Type: model.CustomFieldNumber, ProjectId: 3}}
+	if err := validator.NewCustomFieldValidator().CustomFieldValuesValidate(fields, task.CustomFields); err != nil {
+		return model.TaskResponse{}, err
+	}
+	return model.TaskResponse{Title: task.Title, ProjectId: task.ProjectId, CustomFields: task.CustomFields}, nil
+}
+
+func doCreateTask(body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"userId": 1.0}})
+	c.Set(apimiddleware.ContextKeyWorkspace, model.WorkspaceMember{WorkspaceId: 7, UserId: 1})
+	NewTaskController(customFieldTaskUsecase{}).CreateTask(c)
+	return rec
+}
+
+func TestCreateTask_Success(t *testing.T) {
+	rec := doCreateTask(`{"title":"test","project_id":3,"custom_fields":{"1":5}}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestCreateTask_InvalidCustomField_Failure(t *testing.T) {
+	rec := doCreateTask(`{"title":"test","project_id":3,"custom_fields":{"1":"ten"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type ITaskRevisionController interface {
+	GetRevisions(c echo.Context) error
+	DiffRevisions(c echo.Context) error
+	RevertToRevision(c echo.Context) error
+}
+
+type taskRevisionController struct {
+	ru usecase.ITaskRevisionUsecase
+}
+
+func NewTaskRevisionController(ru usecase.ITaskRevisionUsecase) ITaskRevisionController {
+	return &taskRevisionController{ru}
+}
+
+func (rc *taskRevisionController) GetRevisions(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	revisionResp, err := rc.ru.GetRevisions(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, revisionResp)
+}
+
+func (rc *taskRevisionController) DiffRevisions(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	from, err := strconv.ParseUint(c.QueryParam("from"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "from must be a revision number")
+	}
+	to, err := strconv.ParseUint(c.QueryParam("to"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "to must be a revision number")
+	}
+	diffResp, err := rc.ru.DiffRevisions(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), uint(from), uint(to))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, diffResp)
+}
+
+func (rc *taskRevisionController) RevertToRevision(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	rev, err := strconv.ParseUint(c.Param("rev"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "rev must be a revision number")
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return ifMatchErrorResponse(c, err)
+	}
+	taskResp, err := rc.ru.RevertToRevision(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId), uint(rev), version)
+	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, model.ErrStaleVersion) {
+			return c.JSON(http.StatusPreconditionFailed, err.Error())
+		}
+		var quotaErr *model.QuotaError
+		if errors.As(err, &quotaErr) {
+			return quotaErrorResponse(c, quotaErr)
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	setETag(c, taskResp.Version)
+	return c.JSON(http.StatusOK, taskResp)
+}
@@ -1,22 +1,52 @@
 package controller
 
 import (
+	"errors"
 	"go-rest-api/model"
 	"go-rest-api/usecase"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 )
 
 type IUserController interface {
 	SignUp(c echo.Context) error
 	LogIn(c echo.Context) error
+	LogInTwoFactor(c echo.Context) error
 	LogOut(c echo.Context) error
+	LogOutEverywhere(c echo.Context) error
+	Refresh(c echo.Context) error
+	VerifyEmail(c echo.Context) error
+	ResendVerification(c echo.Context) error
+	ForgotPassword(c echo.Context) error
+	ResetPassword(c echo.Context) error
+	ChangePassword(c echo.Context) error
+	ChangeEmail(c echo.Context) error
+	EnrollTwoFactor(c echo.Context) error
+	ConfirmTwoFactor(c echo.Context) error
+	DisableTwoFactor(c echo.Context) error
+	OIDCLogin(c echo.Context) error
+	OIDCCallback(c echo.Context) error
 	CsrfToken(c echo.Context) error
 }
 
+const (
+	accessTokenCookie  = "go-rest-api-token"
+	refreshTokenCookie = "go-rest-api-refresh-token"
+	// refreshTokenPath keeps browsers from sending the refresh token anywhere
+	// but the refresh endpoint.
+	refreshTokenPath = "/refresh"
+	oidcStateCookie  = "go-rest-api-oidc"
+	oidcStatePath    = "/oidc"
+)
+
 type userController struct {
 	uu usecase.IUserUsecase
 }
@@ -42,38 +72,311 @@ func (uc *userController) LogIn(c echo.Context) error {
 	if err := c.Bind(&user); err != nil {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
-	token, err := uc.uu.Login(user)
+	res, err := uc.uu.Login(user)
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, err.Error())
 	}
-	cookie := new(http.Cookie)
-	cookie.Name = "go-rest-api-token"
-	cookie.Value = token
-	cookie.Expires = time.Now().Add(12 * time.Hour)
-	cookie.Path = "/"
-	cookie.Domain = os.Getenv("API_DOMAIN")
-	cookie.HttpOnly = true
-	cookie.SameSite = http.SameSiteNoneMode
-	// cookie.Secure = true
-	c.SetCookie(cookie)
+	if res.Challenge != nil {
+		return c.JSON(http.StatusAccepted, res.Challenge)
+	}
+	setAuthCookies(c, res.Tokens)
+	return c.NoContent(http.StatusOK)
+}
+
+// LogInTwoFactor answers a login challenge with a TOTP or recovery code.
+func (uc *userController) LogInTwoFactor(c echo.Context) error {
+	req := model.LoginChallengeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	tokens, err := uc.uu.LoginTwoFactor(req)
+	if err != nil {
+		var lockedErr *model.TwoFactorLockedError
+		if errors.As(err, &lockedErr) {
+			retryAfter := int(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+			c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return c.JSON(http.StatusTooManyRequests, err.Error())
+		}
+		if errors.Is(err, model.ErrInvalidChallenge) || errors.Is(err, model.ErrInvalidTwoFactorCode) {
+			return c.JSON(http.StatusUnauthorized, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	setAuthCookies(c, tokens)
 	return c.NoContent(http.StatusOK)
 }
 
+// LogOut revokes the access token in the cookie and ends its session, so a
+// copy of either token stops working too.
 func (uc *userController) LogOut(c echo.Context) error {
-	cookie := new(http.Cookie)
-	cookie.Name = "go-rest-api-token"
-	cookie.Value = ""
-	cookie.Expires = time.Now()
-	cookie.Path = "/"
-	cookie.Domain = os.Getenv("API_DOMAIN")
-	cookie.HttpOnly = true
-	cookie.SameSite = http.SameSiteNoneMode
-	// cookie.Secure = true
-	c.SetCookie(cookie)
+	accessToken := ""
+	if cookie, err := c.Cookie(accessTokenCookie); err == nil {
+		accessToken = cookie.Value
+	}
+	clearAuthCookies(c)
+	if err := uc.uu.LogOut(accessToken); err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (uc *userController) LogOutEverywhere(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	clearAuthCookies(c)
+	if err := uc.uu.LogOutEverywhere(uint(userId.(float64))); err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// Refresh rotates the refresh token cookie and issues a new access token.
+func (uc *userController) Refresh(c echo.Context) error {
+	refreshToken := ""
+	if cookie, err := c.Cookie(refreshTokenCookie); err == nil {
+		refreshToken = cookie.Value
+	}
+	tokens, err := uc.uu.Refresh(refreshToken)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidRefreshToken) || errors.Is(err, model.ErrRefreshTokenReused) {
+			clearAuthCookies(c)
+			return c.JSON(http.StatusUnauthorized, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	setAuthCookies(c, tokens)
+	return c.NoContent(http.StatusOK)
+}
+
+func (uc *userController) VerifyEmail(c echo.Context) error {
+	req := model.EmailVerificationRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := uc.uu.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, model.ErrInvalidVerification) {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (uc *userController) ResendVerification(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	if err := uc.uu.ResendVerification(uint(userId.(float64))); err != nil {
+		if errors.Is(err, model.ErrEmailAlreadyVerified) {
+			return c.JSON(http.StatusConflict, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+// ForgotPassword answers the same way whether or not the address belongs to
+// a user.
+func (uc *userController) ForgotPassword(c echo.Context) error {
+	req := model.PasswordForgotRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := uc.uu.ForgotPassword(req.Email); err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (uc *userController) ResetPassword(c echo.Context) error {
+	req := model.PasswordResetRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := uc.uu.ResetPassword(req); err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, model.ErrInvalidPasswordReset) {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	clearAuthCookies(c)
 	return c.NoContent(http.StatusOK)
 }
 
+// ChangePassword keeps the caller logged in and ends their other sessions.
+func (uc *userController) ChangePassword(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+	sid, _ := claims["sid"].(string)
+
+	req := model.PasswordChangeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := uc.uu.ChangePassword(uint(userId.(float64)), sid, req); err != nil {
+		return accountErrorResponse(c, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// ChangeEmail answers 202 because the address only changes once the user
+// follows the link mailed to it.
+func (uc *userController) ChangeEmail(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+	sid, _ := claims["sid"].(string)
+
+	req := model.EmailChangeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := uc.uu.ChangeEmail(uint(userId.(float64)), sid, req); err != nil {
+		return accountErrorResponse(c, err)
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (uc *userController) EnrollTwoFactor(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	res, err := uc.uu.EnrollTwoFactor(uint(userId.(float64)))
+	if err != nil {
+		return accountErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) ConfirmTwoFactor(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	req := model.TwoFactorCodeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	res, err := uc.uu.ConfirmTwoFactor(uint(userId.(float64)), req)
+	if err != nil {
+		return accountErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func (uc *userController) DisableTwoFactor(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	req := model.TwoFactorDisableRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := uc.uu.DisableTwoFactor(uint(userId.(float64)), req); err != nil {
+		return accountErrorResponse(c, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// OIDCLogin redirects to the provider's login page. The state cookie ties the
+// callback to this browser.
+func (uc *userController) OIDCLogin(c echo.Context) error {
+	start, err := uc.uu.StartOIDCLogin(c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, model.ErrUnknownProvider) {
+			return c.JSON(http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, model.ErrOIDCLoginFailed) {
+			return c.JSON(http.StatusBadGateway, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	c.SetCookie(newAuthCookie(oidcStateCookie, start.StateToken, start.ExpiresAt, oidcStatePath))
+	return c.Redirect(http.StatusFound, start.AuthURL)
+}
+
+// OIDCCallback finishes the login the provider redirects back from and sends
+// the user on to the frontend, to the second factor page if they have one.
+func (uc *userController) OIDCCallback(c echo.Context) error {
+	stateToken := ""
+	if cookie, err := c.Cookie(oidcStateCookie); err == nil {
+		stateToken = cookie.Value
+	}
+	c.SetCookie(newAuthCookie(oidcStateCookie, "", time.Now(), oidcStatePath))
+	req := model.OIDCCallbackRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	res, err := uc.uu.FinishOIDCLogin(c.Param("provider"), stateToken, req)
+	if err != nil {
+		if errors.Is(err, model.ErrUnknownProvider) {
+			return c.JSON(http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, model.ErrOIDCLoginFailed) {
+			return c.JSON(http.StatusUnauthorized, err.Error())
+		}
+		if errors.Is(err, model.ErrIdentityUnverified) || errors.Is(err, model.ErrAccountUnverified) {
+			return c.JSON(http.StatusForbidden, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	if res.Challenge != nil {
+		// A fragment keeps the challenge out of server logs and referrers.
+		return c.Redirect(http.StatusFound, os.Getenv("FE_URL")+"/login/2fa#challenge_token="+url.QueryEscape(res.Challenge.Token))
+	}
+	setAuthCookies(c, res.Tokens)
+	return c.Redirect(http.StatusFound, os.Getenv("FE_URL"))
+}
+
+func accountErrorResponse(c echo.Context, err error) error {
+	if _, ok := err.(validation.Errors); ok {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, model.ErrWrongPassword) {
+		return c.JSON(http.StatusForbidden, err.Error())
+	}
+	if errors.Is(err, model.ErrInvalidTwoFactorCode) {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, model.ErrEmailTaken) || errors.Is(err, model.ErrTwoFactorEnabled) || errors.Is(err, model.ErrTwoFactorNotEnrolled) {
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+	return c.JSON(http.StatusInternalServerError, err.Error())
+}
+
 func (uc *userController) CsrfToken(c echo.Context) error {
 	token := c.Get("csrf").(string)
 	return c.JSON(http.StatusOK, echo.Map{"csrf": token})
 }
+
+func setAuthCookies(c echo.Context, tokens model.AuthTokens) {
+	c.SetCookie(newAuthCookie(accessTokenCookie, tokens.AccessToken, tokens.AccessExpiresAt, "/"))
+	c.SetCookie(newAuthCookie(refreshTokenCookie, tokens.RefreshToken, tokens.RefreshExpiresAt, refreshTokenPath))
+}
+
+func clearAuthCookies(c echo.Context) {
+	c.SetCookie(newAuthCookie(accessTokenCookie, "", time.Now(), "/"))
+	c.SetCookie(newAuthCookie(refreshTokenCookie, "", time.Now(), refreshTokenPath))
+}
+
+func newAuthCookie(name string, value string, expires time.Time, path string) *http.Cookie {
+	cookie := new(http.Cookie)
+	cookie.Name = name
+	cookie.Value = value
+	cookie.Expires = expires
+	cookie.Path = path
+	cookie.Domain = os.Getenv("API_DOMAIN")
+	cookie.HttpOnly = true
+	cookie.SameSite = http.SameSiteNoneMode
+	// cookie.Secure = true
+	return cookie
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"errors"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type IWatcherController interface {
+	WatchTask(c echo.Context) error
+	UnwatchTask(c echo.Context) error
+	GetWatchedTasks(c echo.Context) error
+}
+
+type watcherController struct {
+	wu usecase.IWatcherUsecase
+}
+
+func NewWatcherController(wu usecase.IWatcherUsecase) IWatcherController {
+	return &watcherController{wu}
+}
+
+func (wc *watcherController) WatchTask(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	if err := wc.wu.WatchTask(uint(userId.(float64)), activeWorkspaceId(c), uint(taskId)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, "task not found")
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (wc *watcherController) UnwatchTask(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	if err := wc.wu.UnwatchTask(uint(userId.(float64)), uint(taskId)); err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (wc *watcherController) GetWatchedTasks(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	taskResp, err := wc.wu.GetWatchedTasks(uint(userId.(float64)), activeWorkspaceId(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, taskResp)
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"errors"
+	apimiddleware "go-rest-api/middleware"
+	"go-rest-api/model"
+	"go-rest-api/usecase"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type IWorkspaceController interface {
+	GetAllWorkspaces(c echo.Context) error
+	CreateWorkspace(c echo.Context) error
+	AddMember(c echo.Context) error
+	RemoveMember(c echo.Context) error
+}
+
+type workspaceController struct {
+	wu usecase.IWorkspaceUsecase
+}
+
+func NewWorkspaceController(wu usecase.IWorkspaceUsecase) IWorkspaceController {
+	return &workspaceController{wu}
+}
+
+func (wc *workspaceController) GetAllWorkspaces(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	workspaceResp, err := wc.wu.GetAllWorkspaces(uint(userId.(float64)))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, workspaceResp)
+}
+
+func (wc *workspaceController) CreateWorkspace(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	workspace := model.Workspace{}
+	if err := c.Bind(&workspace); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	workspaceResp, err := wc.wu.CreateWorkspace(uint(userId.(float64)), workspace)
+	if err != nil {
+		if _, ok := err.(validation.Errors); ok {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, workspaceResp)
+}
+
+func (wc *workspaceController) AddMember(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("workspaceId")
+	workspaceId, _ := strconv.Atoi(id)
+	req := model.WorkspaceMemberRequest{}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := wc.wu.AddMember(uint(userId.(float64)), uint(workspaceId), req); err != nil {
+		return workspaceErrorResponse(c, err, "workspace or user not found")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (wc *workspaceController) RemoveMember(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["userId"]
+
+	id := c.Param("workspaceId")
+	workspaceId, _ := strconv.Atoi(id)
+	memberId, _ := strconv.Atoi(c.Param("userId"))
+	if err := wc.wu.RemoveMember(uint(userId.(float64)), uint(workspaceId), uint(memberId)); err != nil {
+		return workspaceErrorResponse(c, err, "workspace or member not found")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func workspaceErrorResponse(c echo.Context, err error, notFound string) error {
+	if _, ok := err.(validation.Errors); ok {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, model.ErrNotWorkspaceAdmin) {
+		return c.JSON(http.StatusForbidden, err.Error())
+	}
+	if errors.Is(err, model.ErrWorkspaceOwnerMember) {
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, notFound)
+	}
+	return c.JSON(http.StatusInternalServerError, err.Error())
+}
+
+// activeWorkspaceId is the workspace the request was made in, as resolved by
+// the workspace middleware.
+func activeWorkspaceId(c echo.Context) uint {
+	member := c.Get(apimiddleware.ContextKeyWorkspace).(model.WorkspaceMember)
+	return member.WorkspaceId
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryMailer(t *testing.T) {
+	m := NewMemoryMailer()
+	msg := Message{To: "user@test.com", Subject: "Hello", Body: "Hi"}
+
+	assert.NoError(t, m.Send(msg))
+
+	messages := m.Messages()
+	assert.Equal(t, []Message{msg}, messages)
+
+	messages[0].To = "other@test.com"
+	assert.Equal(t, "user@test.com", m.Messages()[0].To)
+}
+
+func TestWriterMailer(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewWriterMailer(&buf)
+
+	err := m.Send(Message{To: "user@test.com", Subject: "Hello", Body: "Hi"})
+	assert.NoError(t, err)
+	assert.Equal(t, "To: user@test.com\nSubject: Hello\n\nHi\n\n", buf.String())
+}
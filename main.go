@@ -3,26 +3,191 @@ package main
 import (
 	"go-rest-api/controller"
 	"go-rest-api/db"
+	"go-rest-api/mailer"
+	"go-rest-api/model"
+	"go-rest-api/notifier"
+	"go-rest-api/oidc"
 	"go-rest-api/repository"
 	"go-rest-api/router"
 	"go-rest-api/usecase"
 	"go-rest-api/validator"
+	"log"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
 )
 
 func main() {
 	conn := db.NewDB()
 
+	accessTokenTTL, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL"))
+	if err != nil {
+		accessTokenTTL = 15 * time.Minute
+	}
+	refreshTokenTTL, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL"))
+	if err != nil {
+		refreshTokenTTL = 30 * 24 * time.Hour
+	}
+	tokenLifetimes := model.TokenLifetimes{Access: accessTokenTTL, Refresh: refreshTokenTTL}
+	accountMailer := newMailer()
 	userValidator := validator.NewUserValidator()
 	userRepository := repository.NewUserRepository(conn)
-	userUseCase := usecase.NewUserUsecase(userRepository, userValidator)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(conn)
+	revocationRepository := repository.NewRevocationRepository(conn)
+	passwordResetRepository := repository.NewPasswordResetRepository(conn)
+	twoFactorRepository := repository.NewTwoFactorRepository(conn)
+	identityRepository := repository.NewIdentityRepository(conn)
+	userUseCase := usecase.NewUserUsecase(userRepository, userValidator, refreshTokenRepository, revocationRepository, passwordResetRepository, twoFactorRepository, identityRepository, accountMailer, tokenLifetimes, newOIDCProviders())
 	userContoller := controller.NewUserController(userUseCase)
 
+	workspaceValidator := validator.NewWorkspaceValidator()
+	workspaceRepository := repository.NewWorkspaceRepository(conn)
+	workspaceUseCase := usecase.NewWorkspaceUsecase(workspaceRepository, userRepository, workspaceValidator)
+	workspaceController := controller.NewWorkspaceController(workspaceUseCase)
+
+	maxTasks, _ := strconv.ParseInt(os.Getenv("QUOTA_MAX_TASKS"), 10, 64)
+	maxRequestsPerDay, _ := strconv.ParseInt(os.Getenv("QUOTA_MAX_REQUESTS_PER_DAY"), 10, 64)
+	maxAttachmentBytes, _ := strconv.ParseInt(os.Getenv("QUOTA_MAX_ATTACHMENT_BYTES"), 10, 64)
+	quotaLimits := model.QuotaLimits{MaxTasks: maxTasks, MaxRequestsPerDay: maxRequestsPerDay, MaxAttachmentBytes: maxAttachmentBytes}
+	quotaRepository := repository.NewQuotaRepository(conn)
+
+	watcherRepository := repository.NewWatcherRepository(conn)
+	commentRepository := repository.NewCommentRepository(conn)
+	attachmentRepository := repository.NewAttachmentRepository(conn)
+	taskNotifier := notifier.NewLogNotifier(log.Default())
+
+	mentionRepository := repository.NewMentionRepository(conn)
+	mentionUseCase := usecase.NewMentionUsecase(userRepository, mentionRepository, taskNotifier)
+	mentionController := controller.NewMentionController(mentionUseCase)
+
+	projectValidator := validator.NewProjectValidator()
+	projectRepository := repository.NewProjectRepository(conn)
+	projectUseCase := usecase.NewProjectUsecase(projectRepository, userRepository, projectValidator)
+	projectController := controller.NewProjectController(projectUseCase)
+
+	customFieldValidator := validator.NewCustomFieldValidator()
+	customFieldRepository := repository.NewCustomFieldRepository(conn)
+	customFieldUseCase := usecase.NewCustomFieldUsecase(projectRepository, customFieldRepository, customFieldValidator)
+	customFieldController := controller.NewCustomFieldController(customFieldUseCase)
+
 	taskValidator := validator.NewTaskValidator()
 	taskRepository := repository.NewTaskRepository(conn)
-	taskUseCase := usecase.NewTaskUseCase(taskRepository, taskValidator)
+	taskUseCase := usecase.NewTaskUseCase(taskRepository, taskValidator, quotaRepository, quotaLimits, watcherRepository, commentRepository, attachmentRepository, taskNotifier, mentionUseCase, customFieldRepository, customFieldValidator)
 	taskController := controller.NewTaskController(taskUseCase)
 
-	e := router.NewRouter(userContoller, taskController)
+	quickAddValidator := validator.NewQuickAddValidator()
+	quickAddUseCase := usecase.NewQuickAddUsecase(userRepository, taskUseCase, quickAddValidator)
+	quickAddController := controller.NewQuickAddController(quickAddUseCase)
+
+	watcherUseCase := usecase.NewWatcherUsecase(taskRepository, watcherRepository)
+	watcherController := controller.NewWatcherController(watcherUseCase)
+
+	taskRevisionRepository := repository.NewTaskRevisionRepository(conn)
+	taskRevisionUseCase := usecase.NewTaskRevisionUsecase(taskRevisionRepository, taskUseCase)
+	taskRevisionController := controller.NewTaskRevisionController(taskRevisionUseCase)
+
+	snoozeValidator := validator.NewSnoozeValidator()
+	taskEventRepository := repository.NewTaskEventRepository(conn)
+	snoozeUseCase := usecase.NewSnoozeUsecase(taskRepository, taskEventRepository, taskUseCase, snoozeValidator)
+	snoozeController := controller.NewSnoozeController(snoozeUseCase)
+
+	wakeInterval, err := time.ParseDuration(os.Getenv("SNOOZE_WAKE_INTERVAL"))
+	if err != nil {
+		wakeInterval = time.Minute
+	}
+	go func() {
+		for range time.Tick(wakeInterval) {
+			if _, err := snoozeUseCase.WakeDueTasks(); err != nil {
+				log.Println("wake snoozed tasks:", err)
+			}
+		}
+	}()
+
+	statsValidator := validator.NewStatsValidator()
+	statsRepository := repository.NewStatsRepository(conn)
+	statsUseCase := usecase.NewStatsUsecase(statsRepository, statsValidator)
+	statsController := controller.NewStatsController(statsUseCase)
+
+	smartListValidator := validator.NewSmartListValidator()
+	smartListRepository := repository.NewSmartListRepository(conn)
+	smartListUseCase := usecase.NewSmartListUsecase(smartListRepository, taskRepository, smartListValidator)
+	smartListController := controller.NewSmartListController(smartListUseCase)
+
+	milestoneValidator := validator.NewMilestoneValidator()
+	milestoneRepository := repository.NewMilestoneRepository(conn)
+	milestoneUseCase := usecase.NewMilestoneUsecase(milestoneRepository, taskRepository, milestoneValidator)
+	milestoneController := controller.NewMilestoneController(milestoneUseCase)
+
+	tombstoneTTL, err := time.ParseDuration(os.Getenv("SYNC_TOMBSTONE_TTL"))
+	if err != nil {
+		tombstoneTTL = 30 * 24 * time.Hour
+	}
+	syncRepository := repository.NewSyncRepository(conn)
+	syncUseCase := usecase.NewSyncUsecase(syncRepository, taskUseCase, tombstoneTTL)
+	syncController := controller.NewSyncController(syncUseCase)
+
+	personalAccessTokenValidator := validator.NewPersonalAccessTokenValidator()
+	personalAccessTokenRepository := repository.NewPersonalAccessTokenRepository(conn)
+	personalAccessTokenUseCase := usecase.NewPersonalAccessTokenUsecase(personalAccessTokenRepository, personalAccessTokenValidator)
+	personalAccessTokenController := controller.NewPersonalAccessTokenController(personalAccessTokenUseCase)
+
+	adminValidator := validator.NewAdminValidator()
+	auditRepository := repository.NewAuditRepository(conn)
+	adminUseCase := usecase.NewAdminUsecase(userRepository, taskRepository, auditRepository, userUseCase, adminValidator)
+	adminController := controller.NewAdminController(adminUseCase)
+
+	idempotencyRepository := repository.NewIdempotencyRepository(conn)
+
+	e := router.NewRouter(userContoller, workspaceController, taskController, quickAddController, taskRevisionController, snoozeController, watcherController, mentionController, projectController, customFieldController, statsController, smartListController, milestoneController, syncController, personalAccessTokenController, adminController, idempotencyRepository, workspaceRepository, revocationRepository, userRepository, personalAccessTokenRepository, taskUseCase)
 
 	e.Logger.Fatal(e.Start(":8080"))
 }
+
+// newMailer picks the mailer from MAILER: "smtp" sends through SMTP_ADDR,
+// "file" appends to MAILER_FILE, and anything else prints to stdout.
+func newMailer() mailer.Mailer {
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		var auth smtp.Auth
+		if username := os.Getenv("SMTP_USERNAME"); username != "" {
+			host, _, _ := strings.Cut(os.Getenv("SMTP_ADDR"), ":")
+			auth = smtp.PlainAuth("", username, os.Getenv("SMTP_PASSWORD"), host)
+		}
+		return mailer.NewSMTPMailer(os.Getenv("SMTP_ADDR"), os.Getenv("SMTP_FROM"), auth)
+	case "file":
+		f, err := os.OpenFile(os.Getenv("MAILER_FILE"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return mailer.NewWriterMailer(f)
+	default:
+		return mailer.NewWriterMailer(os.Stdout)
+	}
+}
+
+// newOIDCProviders configures the providers named in OIDC_PROVIDERS, a comma
+// separated list. Each name reads OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID,
+// OIDC_<NAME>_CLIENT_SECRET and OIDC_<NAME>_REDIRECT_URL, and signs users in
+// at /oidc/<name>/login.
+func newOIDCProviders() map[string]oidc.Provider {
+	client := &http.Client{Timeout: 10 * time.Second}
+	providers := map[string]oidc.Provider{}
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers[name] = oidc.NewProvider(oidc.Config{
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		}, client)
+	}
+	return providers
+}
@@ -0,0 +1,45 @@
+// Package mention finds @email and @handle mentions in free text.
+package mention
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A mention starts with @ at the beginning of the text or after a character
+// that cannot be part of an email address, so that the domain of a plain
+// address such as alice@example.com is not read as a handle.
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9._%+\-@])@([A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}|[A-Za-z0-9_]{3,30})`)
+
+type Mentions struct {
+	Emails  []string
+	Handles []string
+}
+
+// Parse returns the distinct mentions in text in the order they first
+// appear, lower-cased. A handle longer than 30 characters is not a mention.
+func Parse(text string) Mentions {
+	mentions := Mentions{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		end := match[3]
+		if end < len(text) && isHandleChar(text[end]) {
+			continue
+		}
+		name := strings.ToLower(text[match[2]:end])
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if strings.Contains(name, "@") {
+			mentions.Emails = append(mentions.Emails, name)
+		} else {
+			mentions.Handles = append(mentions.Handles, name)
+		}
+	}
+	return mentions
+}
+
+func isHandleChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,57 @@
+package mention
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Mentions
+	}{
+		{
+			name: "no mentions",
+			text: "Nothing to see here",
+			want: Mentions{},
+		},
+		{
+			name: "handle",
+			text: "@alice can you take this?",
+			want: Mentions{Handles: []string{"alice"}},
+		},
+		{
+			name: "email",
+			text: "cc @bob@example.com.",
+			want: Mentions{Emails: []string{"bob@example.com"}},
+		},
+		{
+			name: "mixed, lower-cased and deduplicated in order",
+			text: "@Carol and @dave_01, then @carol again (@Erin@Example.org)",
+			want: Mentions{Emails: []string{"erin@example.org"}, Handles: []string{"carol", "dave_01"}},
+		},
+		{
+			name: "plain email address is not a mention",
+			text: "write to frank@example.com",
+			want: Mentions{},
+		},
+		{
+			name: "too short and too long handles",
+			text: "@ab @abcdefghijabcdefghijabcdefghijX",
+			want: Mentions{},
+		},
+		{
+			name: "punctuation after a handle",
+			text: "thanks @grace!",
+			want: Mentions{Handles: []string{"grace"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Parse(tt.text))
+		})
+	}
+}
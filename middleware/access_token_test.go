@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type memoryPersonalAccessTokenRepository struct {
+	tokens []model.PersonalAccessToken
+}
+
+func (mr *memoryPersonalAccessTokenRepository) Create(token *model.PersonalAccessToken) error {
+	mr.tokens = append(mr.tokens, *token)
+	return nil
+}
+
+func (mr *memoryPersonalAccessTokenRepository) GetAll(tokens *[]model.PersonalAccessToken, userId uint) error {
+	return nil
+}
+
+func (mr *memoryPersonalAccessTokenRepository) GetByHash(token *model.PersonalAccessToken, tokenHash string) error {
+	for _, stored := range mr.tokens {
+		if stored.TokenHash == tokenHash && (stored.ExpiresAt == nil || stored.ExpiresAt.After(time.Now())) {
+			*token = stored
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (mr *memoryPersonalAccessTokenRepository) MarkUsed(tokenId uint, usedAt time.Time) error {
+	for i := range mr.tokens {
+		if mr.tokens[i].ID == tokenId {
+			mr.tokens[i].LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func (mr *memoryPersonalAccessTokenRepository) Delete(userId uint, tokenId uint) error {
+	return nil
+}
+
+// newAccessTokenTestServer serves /tasks, which takes personal access tokens
+// with the tasks scopes, and /me/password, which does not take them.
+func newAccessTokenTestServer(store repository.IPersonalAccessTokenRepository) *echo.Echo {
+	e := echo.New()
+	e.Use(echomiddleware.CSRFWithConfig(echomiddleware.CSRFConfig{Skipper: HasAuthorizationHeader}))
+	ok := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	tokenMiddleware := echojwt.WithConfig(echojwt.Config{
+		TokenLookupFuncs: []echomiddleware.ValuesExtractor{BearerOrCookie("go-rest-api-token")},
+		ParseTokenFunc: ParseToken(TokenConfig{
+			SigningKey:   testSigningKey,
+			Store:        repository.NewMemoryRevocationRepository(),
+			AccessTokens: store,
+		}),
+	})
+	sessionMiddleware := echojwt.WithConfig(echojwt.Config{
+		TokenLookupFuncs: []echomiddleware.ValuesExtractor{BearerOrCookie("go-rest-api-token")},
+		ParseTokenFunc: ParseToken(TokenConfig{
+			SigningKey: testSigningKey,
+			Store:      repository.NewMemoryRevocationRepository(),
+		}),
+	})
+	e.GET("/tasks", ok, tokenMiddleware, Scope("tasks"))
+	e.POST("/tasks", ok, tokenMiddleware, Scope("tasks"))
+	e.PUT("/me/password", ok, sessionMiddleware)
+	return e
+}
+
+func storeTestAccessToken(store *memoryPersonalAccessTokenRepository, token string, scopes ...string) {
+	sum := sha256.Sum256([]byte(token))
+	store.Create(&model.PersonalAccessToken{ID: uint(len(store.tokens) + 1), TokenHash: hex.EncodeToString(sum[:]), Scopes: scopes, UserId: 1})
+}
+
+func doBearerRequest(e *echo.Echo, method string, path string, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestScope_ReadToken(t *testing.T) {
+	store := &memoryPersonalAccessTokenRepository{}
+	storeTestAccessToken(store, "grp_read", model.ScopeTasksRead)
+	e := newAccessTokenTestServer(store)
+
+	rec := doBearerRequest(e, http.MethodGet, "/tasks", "grp_read")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotNil(t, store.tokens[0].LastUsedAt)
+	rec = doBearerRequest(e, http.MethodPost, "/tasks", "grp_read")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), model.ErrInsufficientScope.Error())
+}
+
+func TestScope_WriteTokenReads(t *testing.T) {
+	store := &memoryPersonalAccessTokenRepository{}
+	storeTestAccessToken(store, "grp_write", model.ScopeTasksWrite)
+	e := newAccessTokenTestServer(store)
+
+	rec := doBearerRequest(e, http.MethodPost, "/tasks", "grp_write")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = doBearerRequest(e, http.MethodGet, "/tasks", "grp_write")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestScope_OtherResource(t *testing.T) {
+	store := &memoryPersonalAccessTokenRepository{}
+	storeTestAccessToken(store, "grp_projects", model.ScopeProjectsWrite)
+	e := newAccessTokenTestServer(store)
+
+	rec := doBearerRequest(e, http.MethodGet, "/tasks", "grp_projects")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestParseToken_UnknownAccessToken(t *testing.T) {
+	store := &memoryPersonalAccessTokenRepository{}
+	expiresAt := time.Now().Add(-time.Minute)
+	sum := sha256.Sum256([]byte("grp_expired"))
+	store.Create(&model.PersonalAccessToken{ID: 1, TokenHash: hex.EncodeToString(sum[:]), Scopes: []string{model.ScopeTasksRead}, UserId: 1, ExpiresAt: &expiresAt})
+	e := newAccessTokenTestServer(store)
+
+	rec := doBearerRequest(e, http.MethodGet, "/tasks", "grp_unknown")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = doBearerRequest(e, http.MethodGet, "/tasks", "grp_expired")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestParseToken_AccessTokenForbidden(t *testing.T) {
+	store := &memoryPersonalAccessTokenRepository{}
+	storeTestAccessToken(store, "grp_write", model.ScopeTasksWrite)
+	e := newAccessTokenTestServer(store)
+
+	rec := doBearerRequest(e, http.MethodPut, "/me/password", "grp_write")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestScope_SessionBearer(t *testing.T) {
+	e := newAccessTokenTestServer(&memoryPersonalAccessTokenRepository{})
+	token := signTestToken(jwt.MapClaims{"userId": 1.0, "jti": "a", "iat": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix()})
+
+	rec := doBearerRequest(e, http.MethodPost, "/tasks", token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = doBearerRequest(e, http.MethodPut, "/me/password", token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestBearerOrCookie_HeaderWinsOverCookie(t *testing.T) {
+	e := newAccessTokenTestServer(&memoryPersonalAccessTokenRepository{})
+	token := signTestToken(jwt.MapClaims{"userId": 1.0, "jti": "a", "iat": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix()})
+
+	// Without the header the cookie works, but a write still needs the CSRF
+	// token.
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: "go-rest-api-token", Value: token})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	req = httptest.NewRequest(http.MethodPost, "/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: "go-rest-api-token", Value: token})
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// The header skips the CSRF check, so the cookie must not be used.
+	req = httptest.NewRequest(http.MethodPost, "/tasks", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer grp_unknown")
+	req.AddCookie(&http.Cookie{Name: "go-rest-api-token", Value: token})
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+type IdempotencyConfig struct {
+	// Store keeps the key, user, request hash and response of each request.
+	Store repository.IIdempotencyRepository
+	// TTL is how long a key is remembered. Defaults to 24 hours.
+	TTL time.Duration
+}
+
+// Idempotency replays the stored response when a request is retried with the
+// same Idempotency-Key header, and rejects a key reused with a different
+// request. Keys are scoped per user, so it must run after the JWT middleware.
+// Requests without the header pass through untouched.
+func Idempotency(config IdempotencyConfig) echo.MiddlewareFunc {
+	if config.TTL == 0 {
+		config.TTL = 24 * time.Hour
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest, "Idempotency-Key limited max 255 char")
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, err.Error())
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now()
+			record := model.IdempotencyRecord{
+				Key:         key,
+				UserId:      userIdFromContext(c),
+				RequestHash: requestHash(c.Request(), workspaceIdFromContext(c), body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(config.TTL),
+			}
+			reserved, err := config.Store.Reserve(&record)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, err.Error())
+			}
+			if !reserved {
+				return replay(c, config.Store, record)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			// Responses a retry could change are not remembered, so that the
+			// client can retry with the same key.
+			status := c.Response().Status
+			if !c.Response().Committed || !isFinalResponse(status) {
+				if err := config.Store.Delete(record.UserId, record.Key); err != nil {
+					c.Logger().Error(err)
+				}
+				return nil
+			}
+			record.StatusCode = status
+			record.ContentType = c.Response().Header().Get(echo.HeaderContentType)
+			record.ResponseBody = recorder.body.Bytes()
+			if err := config.Store.Complete(&record); err != nil {
+				c.Logger().Error(err)
+			}
+			return nil
+		}
+	}
+}
+
+func replay(c echo.Context, store repository.IIdempotencyRepository, request model.IdempotencyRecord) error {
+	stored := model.IdempotencyRecord{}
+	if err := store.GetByKey(&stored, request.UserId, request.Key); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The original request failed and released the key in between.
+			return c.JSON(http.StatusConflict, "request with this Idempotency-Key is being retried, try again")
+		}
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	if stored.RequestHash != request.RequestHash {
+		return c.JSON(http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+	}
+	if !stored.Completed() {
+		return c.JSON(http.StatusConflict, "request with this Idempotency-Key is still in progress")
+	}
+	c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+	return c.Blob(stored.StatusCode, stored.ContentType, stored.ResponseBody)
+}
+
+// transientClientErrors are answers that the same request may get past
+// later: a quota or membership that changes (403), a conflicting or stale
+// write (409, 412) and a rate limit (429).
+var transientClientErrors = map[int]bool{
+	http.StatusForbidden:          true,
+	http.StatusConflict:           true,
+	http.StatusPreconditionFailed: true,
+	http.StatusTooManyRequests:    true,
+}
+
+// isFinalResponse reports whether a retry with the same request must get
+// the same answer. Only successes and client errors that no retry can fix
+// qualify.
+func isFinalResponse(status int) bool {
+	if status >= http.StatusOK && status < http.StatusMultipleChoices {
+		return true
+	}
+	return status >= http.StatusBadRequest && status < http.StatusInternalServerError && !transientClientErrors[status]
+}
+
+func userIdFromContext(c echo.Context) uint {
+	user, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return 0
+	}
+	claims, ok := user.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0
+	}
+	userId, _ := claims["userId"].(float64)
+	return uint(userId)
+}
+
+// requestHash covers the workspace so that a key reused in another workspace
+// is not answered with the response from the first one.
+func requestHash(req *http.Request, workspaceId uint, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, req.Method+" "+req.URL.Path+" "+strconv.FormatUint(uint64(workspaceId), 10)+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of everything written to the client.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
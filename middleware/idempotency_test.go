@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"go-rest-api/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type memoryIdempotencyRepository struct {
+	records map[string]model.IdempotencyRecord
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{records: map[string]model.IdempotencyRecord{}}
+}
+
+func (mr *memoryIdempotencyRepository) Reserve(record *model.IdempotencyRecord) (bool, error) {
+	if _, ok := mr.records[record.Key]; ok {
+		return false, nil
+	}
+	mr.records[record.Key] = *record
+	return true, nil
+}
+
+func (mr *memoryIdempotencyRepository) GetByKey(record *model.IdempotencyRecord, userId uint, key string) error {
+	stored, ok := mr.records[key]
+	if !ok || stored.UserId != userId {
+		return gorm.ErrRecordNotFound
+	}
+	*record = stored
+	return nil
+}
+
+func (mr *memoryIdempotencyRepository) Complete(record *model.IdempotencyRecord) error {
+	mr.records[record.Key] = *record
+	return nil
+}
+
+func (mr *memoryIdempotencyRepository) Delete(userId uint, key string) error {
+	delete(mr.records, key)
+	return nil
+}
+
+func newIdempotencyTestServer(store *memoryIdempotencyRepository, calls *int, status int) *echo.Echo {
+	e := echo.New()
+	e.POST("/tasks", func(c echo.Context) error {
+		*calls++
+		return c.JSON(status, echo.Map{"call": *calls})
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"userId": 1.0}})
+			return next(c)
+		}
+	}, Idempotency(IdempotencyConfig{Store: store}))
+	return e
+}
+
+func doIdempotentRequest(e *echo.Echo, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	calls := 0
+	e := newIdempotencyTestServer(newMemoryIdempotencyRepository(), &calls, http.StatusCreated)
+
+	first := doIdempotentRequest(e, "key-1", `{"title":"a"}`)
+	second := doIdempotentRequest(e, "key-1", `{"title":"a"}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(HeaderIdempotentReplayed))
+}
+
+func TestIdempotency_DifferentBody_Failure(t *testing.T) {
+	calls := 0
+	e := newIdempotencyTestServer(newMemoryIdempotencyRepository(), &calls, http.StatusCreated)
+
+	doIdempotentRequest(e, "key-1", `{"title":"a"}`)
+	rec := doIdempotentRequest(e, "key-1", `{"title":"b"}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestIdempotency_InProgress_Failure(t *testing.T) {
+	calls := 0
+	store := newMemoryIdempotencyRepository()
+	e := newIdempotencyTestServer(store, &calls, http.StatusCreated)
+
+	store.records["key-1"] = model.IdempotencyRecord{Key: "key-1", UserId: 1, RequestHash: requestHash(httptest.NewRequest(http.MethodPost, "/tasks", nil), 0, []byte(`{"title":"a"}`))}
+	rec := doIdempotentRequest(e, "key-1", `{"title":"a"}`)
+
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestIdempotency_ServerError_NotStored(t *testing.T) {
+	calls := 0
+	store := newMemoryIdempotencyRepository()
+	e := newIdempotencyTestServer(store, &calls, http.StatusInternalServerError)
+
+	doIdempotentRequest(e, "key-1", `{"title":"a"}`)
+	doIdempotentRequest(e, "key-1", `{"title":"a"}`)
+
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, store.records)
+}
+
+func TestIdempotency_RateLimited_NotStored(t *testing.T) {
+	calls := 0
+	store := newMemoryIdempotencyRepository()
+	e := newIdempotencyTestServer(store, &calls, http.StatusTooManyRequests)
+
+	doIdempotentRequest(e, "key-1", `{"title":"a"}`)
+	rec := doIdempotentRequest(e, "key-1", `{"title":"a"}`)
+
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, rec.Header().Get(HeaderIdempotentReplayed))
+	assert.Empty(t, store.records)
+}
+
+func TestIdempotency_ValidationError_Replay(t *testing.T) {
+	calls := 0
+	e := newIdempotencyTestServer(newMemoryIdempotencyRepository(), &calls, http.StatusBadRequest)
+
+	doIdempotentRequest(e, "key-1", `{"title":""}`)
+	rec := doIdempotentRequest(e, "key-1", `{"title":""}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+}
+
+func TestIdempotency_NoKey(t *testing.T) {
+	calls := 0
+	store := newMemoryIdempotencyRepository()
+	e := newIdempotencyTestServer(store, &calls, http.StatusCreated)
+
+	doIdempotentRequest(e, "", `{"title":"a"}`)
+	doIdempotentRequest(e, "", `{"title":"a"}`)
+
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, store.records)
+}
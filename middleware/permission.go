@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PermissionConfig struct {
+	// Store looks up the role of the user.
+	Store repository.IUserRepository
+}
+
+// Permission returns a factory of middleware that turns away users whose role
+// does not grant a permission, used as Permission(config)(permission). It
+// must run after the JWT middleware. Usecases check the permission again,
+// so a route that forgets the middleware is still guarded.
+func Permission(config PermissionConfig) func(permission string) echo.MiddlewareFunc {
+	return func(permission string) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				user := model.User{}
+				if err := config.Store.GetByID(&user, userIdFromContext(c)); err != nil {
+					return c.JSON(http.StatusInternalServerError, err.Error())
+				}
+				if user.DisabledAt != nil {
+					return c.JSON(http.StatusForbidden, model.ErrAccountDisabled.Error())
+				}
+				if !model.HasPermission(user.Role, permission) {
+					return c.JSON(http.StatusForbidden, model.ErrPermissionDenied.Error())
+				}
+				return next(c)
+			}
+		}
+	}
+}
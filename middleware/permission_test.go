@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"go-rest-api/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func doPermissionRequest(userId uint, permission string) *httptest.ResponseRecorder {
+	disabledAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	store := &memoryUserRepository{users: []model.User{
+		{ID: 1, Email: "user@test.com", Role: model.RoleUser},
+		{ID: 2, Email: "support@test.com", Role: model.RoleSupport},
+		{ID: 3, Email: "admin@test.com", Role: model.RoleAdmin},
+		{ID: 4, Email: "disabled@test.com", Role: model.RoleAdmin, DisabledAt: &disabledAt},
+	}}
+	can := Permission(PermissionConfig{Store: store})
+	e := echo.New()
+	e.GET("/admin/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"userId": float64(userId)}})
+			return next(c)
+		}
+	}, can(permission))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/users", nil))
+	return rec
+}
+
+func TestPermission_Granted(t *testing.T) {
+	rec := doPermissionRequest(2, model.PermissionUsersRead)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = doPermissionRequest(3, model.PermissionUsersManage)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestPermission_Denied(t *testing.T) {
+	rec := doPermissionRequest(1, model.PermissionUsersRead)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), model.ErrPermissionDenied.Error())
+	rec = doPermissionRequest(2, model.PermissionUsersManage)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestPermission_Disabled(t *testing.T) {
+	rec := doPermissionRequest(4, model.PermissionUsersRead)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), model.ErrAccountDisabled.Error())
+}
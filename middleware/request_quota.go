@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestCounter counts a request of the user against their daily limit and
+// fails with a *model.QuotaError once it is used up.
+type RequestCounter interface {
+	UseRequest(userId uint) error
+}
+
+type RequestQuotaConfig struct {
+	// Counter counts the requests of the user.
+	Counter RequestCounter
+}
+
+// RequestQuota counts every request against the daily request limit of the
+// user and answers 429 with Retry-After once it is used up. Routes served by
+// the task usecase count their requests there and must not use it as well.
+// It must run after the JWT middleware.
+func RequestQuota(config RequestQuotaConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := config.Counter.UseRequest(userIdFromContext(c))
+			var quotaErr *model.QuotaError
+			if errors.As(err, &quotaErr) && errors.Is(err, model.ErrRateLimited) {
+				retryAfter := int(math.Ceil(time.Until(*quotaErr.ResetAt).Seconds()))
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+				return c.JSON(http.StatusTooManyRequests, echo.Map{
+					"message":  quotaErr.Error(),
+					"limit":    quotaErr.Limit,
+					"used":     quotaErr.Used,
+					"max":      quotaErr.Max,
+					"reset_at": quotaErr.ResetAt,
+				})
+			}
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, err.Error())
+			}
+			return next(c)
+		}
+	}
+}
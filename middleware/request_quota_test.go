@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"go-rest-api/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryRequestCounter allows max requests per user.
+type memoryRequestCounter struct {
+	max  int64
+	used map[uint]int64
+}
+
+func (mc *memoryRequestCounter) UseRequest(userId uint) error {
+	mc.used[userId]++
+	if mc.used[userId] > mc.max {
+		resetAt := time.Now().Add(time.Hour)
+		return &model.QuotaError{Err: model.ErrRateLimited, Limit: model.QuotaLimitRequestsPerDay, Used: mc.used[userId], Max: mc.max, ResetAt: &resetAt}
+	}
+	return nil
+}
+
+func newRequestQuotaServer(counter *memoryRequestCounter, calls *int) *echo.Echo {
+	e := echo.New()
+	e.GET("/stats", func(c echo.Context) error {
+		*calls++
+		return c.NoContent(http.StatusOK)
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"userId": float64(1)}})
+			return next(c)
+		}
+	}, RequestQuota(RequestQuotaConfig{Counter: counter}))
+	return e
+}
+
+func TestRequestQuota_UnderLimit(t *testing.T) {
+	counter := &memoryRequestCounter{max: 2, used: map[uint]int64{}}
+	calls := 0
+	e := newRequestQuotaServer(counter, &calls)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, int64(1), counter.used[1], "the request should be counted for the user")
+}
+
+func TestRequestQuota_OverLimit(t *testing.T) {
+	counter := &memoryRequestCounter{max: 1, used: map[uint]int64{}}
+	calls := 0
+	e := newRequestQuotaServer(counter, &calls)
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stats", nil))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "3600", rec.Header().Get(echo.HeaderRetryAfter))
+	assert.Contains(t, rec.Body.String(), model.QuotaLimitRequestsPerDay)
+	assert.Equal(t, 1, calls, "the handler should not run once the limit is used up")
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type TokenConfig struct {
+	// SigningKey verifies the HS256 signature of access tokens.
+	SigningKey []byte
+	// Store tells whether a token has been logged out.
+	Store repository.IRevocationRepository
+	// AccessTokens looks up personal access tokens. Without it they are
+	// rejected, which keeps them away from routes that manage the account.
+	AccessTokens repository.IPersonalAccessTokenRepository
+}
+
+// ParseToken is an echojwt ParseTokenFunc that verifies the access token like
+// the default one and then rejects it if it has been revoked, either by its
+// jti, by its session or by a log out everywhere of its user. Personal
+// access tokens are handed to parsePersonalAccessToken.
+func ParseToken(config TokenConfig) func(c echo.Context, auth string) (interface{}, error) {
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}}
+	return func(c echo.Context, auth string) (interface{}, error) {
+		if strings.HasPrefix(auth, model.PersonalAccessTokenPrefix) {
+			if config.AccessTokens == nil {
+				return nil, model.ErrAccessTokenForbidden
+			}
+			return parsePersonalAccessToken(config.AccessTokens, auth)
+		}
+		token, err := parser.Parse(auth, func(*jwt.Token) (interface{}, error) {
+			return config.SigningKey, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		claims := token.Claims.(jwt.MapClaims)
+		jti, _ := claims["jti"].(string)
+		sid, _ := claims["sid"].(string)
+		userId, _ := claims["userId"].(float64)
+		gen, _ := claims["gen"].(float64)
+		revoked, err := config.Store.IsRevoked(jti, sid, uint(userId), uint(gen))
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, model.ErrTokenRevoked
+		}
+		return token, nil
+	}
+}
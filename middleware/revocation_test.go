@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSigningKey = []byte("test-secret")
+
+func newRevocationTestServer(store repository.IRevocationRepository) *echo.Echo {
+	e := echo.New()
+	e.GET("/tasks", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, echojwt.WithConfig(echojwt.Config{
+		TokenLookup:    "cookie:go-rest-api-token",
+		ParseTokenFunc: ParseToken(TokenConfig{SigningKey: testSigningKey, Store: store}),
+	}))
+	return e
+}
+
+func signTestToken(claims jwt.MapClaims) string {
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testSigningKey)
+	return token
+}
+
+func doRevocationRequest(e *echo.Echo, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: "go-rest-api-token", Value: token})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestParseToken_Valid(t *testing.T) {
+	e := newRevocationTestServer(repository.NewMemoryRevocationRepository())
+	now := time.Now()
+	token := signTestToken(jwt.MapClaims{"userId": 1.0, "jti": "a", "iat": now.Unix(), "exp": now.Add(time.Hour).Unix()})
+
+	rec := doRevocationRequest(e, token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestParseToken_RevokedToken(t *testing.T) {
+	store := repository.NewMemoryRevocationRepository()
+	e := newRevocationTestServer(store)
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	revoked := signTestToken(jwt.MapClaims{"userId": 1.0, "jti": "a", "iat": now.Unix(), "exp": expiresAt.Unix()})
+	other := signTestToken(jwt.MapClaims{"userId": 1.0, "jti": "b", "iat": now.Unix(), "exp": expiresAt.Unix()})
+	store.RevokeToken(&model.RevokedToken{Jti: "a", UserId: 1, ExpiresAt: expiresAt})
+
+	rec := doRevocationRequest(e, revoked)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = doRevocationRequest(e, other)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestParseToken_RevokedSession(t *testing.T) {
+	store := repository.NewMemoryRevocationRepository()
+	e := newRevocationTestServer(store)
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	revoked := signTestToken(jwt.MapClaims{"userId": 1.0, "jti": "a", "sid": "laptop", "iat": now.Unix(), "exp": expiresAt.Unix()})
+	other := signTestToken(jwt.MapClaims{"userId": 1.0, "jti": "b", "sid": "phone", "iat": now.Unix(), "exp": expiresAt.Unix()})
+	store.RevokeSessions([]model.RevokedSession{{Sid: "laptop", UserId: 1, ExpiresAt: expiresAt}})
+
+	rec := doRevocationRequest(e, revoked)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = doRevocationRequest(e, other)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestParseToken_RevokedUser(t *testing.T) {
+	store := repository.NewMemoryRevocationRepository()
+	e := newRevocationTestServer(store)
+	now := time.Now()
+	old := signTestToken(jwt.MapClaims{"userId": 1.0, "jti": "a", "gen": 0, "iat": now.Unix(), "exp": now.Add(time.Hour).Unix()})
+	store.RevokeUser(1)
+	fresh := signTestToken(jwt.MapClaims{"userId": 1.0, "jti": "b", "gen": 1, "iat": now.Unix(), "exp": now.Add(time.Hour).Unix()})
+
+	rec := doRevocationRequest(e, old)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = doRevocationRequest(e, fresh)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestParseToken_WrongKey(t *testing.T) {
+	e := newRevocationTestServer(repository.NewMemoryRevocationRepository())
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"userId": 1.0, "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte("other"))
+
+	rec := doRevocationRequest(e, token)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type EmailVerifiedConfig struct {
+	// Store looks up whether the user has verified their email address.
+	Store repository.IUserRepository
+}
+
+// EmailVerified turns away users who have not verified their email address
+// yet. It must run after the JWT middleware.
+func EmailVerified(config EmailVerifiedConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := model.User{}
+			if err := config.Store.GetByID(&user, userIdFromContext(c)); err != nil {
+				return c.JSON(http.StatusInternalServerError, err.Error())
+			}
+			if user.EmailVerifiedAt == nil {
+				return c.JSON(http.StatusForbidden, model.ErrEmailNotVerified.Error())
+			}
+			return next(c)
+		}
+	}
+}
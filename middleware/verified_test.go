@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"go-rest-api/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type memoryUserRepository struct {
+	users []model.User
+}
+
+func (mr *memoryUserRepository) GetByEmail(user *model.User, email string) error {
+	return gorm.ErrRecordNotFound
+}
+
+func (mr *memoryUserRepository) GetByID(user *model.User, userId uint) error {
+	for _, stored := range mr.users {
+		if stored.ID == userId {
+			*user = stored
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (mr *memoryUserRepository) GetByMentions(users *[]model.User, workspaceId uint, emails []string, handles []string) error {
+	return nil
+}
+
+func (mr *memoryUserRepository) Create(user *model.User) error {
+	return nil
+}
+
+func (mr *memoryUserRepository) VerifyEmail(userId uint, email string) error {
+	return nil
+}
+
+func (mr *memoryUserRepository) UpdatePassword(userId uint, password string) error {
+	return nil
+}
+
+func (mr *memoryUserRepository) SetPendingEmail(userId uint, email string) error {
+	return nil
+}
+
+func (mr *memoryUserRepository) GetAll(users *[]model.User) error {
+	*users = mr.users
+	return nil
+}
+
+func (mr *memoryUserRepository) SetDisabled(userId uint, disabledAt *time.Time) error {
+	return nil
+}
+
+func (mr *memoryUserRepository) SetRole(userId uint, role string) error {
+	return nil
+}
+
+func doVerifiedRequest(userId uint) *httptest.ResponseRecorder {
+	verifiedAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	store := &memoryUserRepository{users: []model.User{
+		{ID: 1, Email: "verified@test.com", EmailVerifiedAt: &verifiedAt},
+		{ID: 2, Email: "unverified@test.com"},
+	}}
+	e := echo.New()
+	e.GET("/tasks", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"userId": float64(userId)}})
+			return next(c)
+		}
+	}, EmailVerified(EmailVerifiedConfig{Store: store}))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	return rec
+}
+
+func TestEmailVerified_Verified(t *testing.T) {
+	rec := doVerifiedRequest(1)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestEmailVerified_Unverified(t *testing.T) {
+	rec := doVerifiedRequest(2)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), model.ErrEmailNotVerified.Error())
+}
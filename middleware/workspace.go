@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	HeaderWorkspaceID   = "X-Workspace-ID"
+	ContextKeyWorkspace = "workspace"
+	workspacePathPrefix = "/w/"
+)
+
+type WorkspaceConfig struct {
+	// Store looks up the membership of the user in the workspace.
+	Store repository.IWorkspaceRepository
+}
+
+// Workspace resolves the active workspace of the request from the
+// X-Workspace-ID header, falling back to the user's default workspace, and
+// stores the user's model.WorkspaceMember under ContextKeyWorkspace. Users
+// who are not members of the workspace are turned away. It must run after
+// the JWT middleware.
+func Workspace(config WorkspaceConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userId := userIdFromContext(c)
+			member := model.WorkspaceMember{}
+			var err error
+			if header := c.Request().Header.Get(HeaderWorkspaceID); header != "" {
+				workspaceId, parseErr := strconv.ParseUint(header, 10, 64)
+				if parseErr != nil {
+					return c.JSON(http.StatusBadRequest, HeaderWorkspaceID+" must be a workspace id")
+				}
+				err = config.Store.GetMember(&member, uint(workspaceId), userId)
+			} else {
+				err = config.Store.GetDefault(&member, userId)
+			}
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return c.JSON(http.StatusForbidden, model.ErrNotWorkspaceMember.Error())
+				}
+				return c.JSON(http.StatusInternalServerError, err.Error())
+			}
+			c.Set(ContextKeyWorkspace, member)
+			return next(c)
+		}
+	}
+}
+
+// WorkspacePath lets clients choose the workspace with a /w/:workspaceId path
+// prefix instead of the header, so /w/3/tasks is /tasks in workspace 3. It
+// must be registered with Echo#Pre so that it runs before routing.
+func WorkspacePath() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if rest, ok := strings.CutPrefix(req.URL.Path, workspacePathPrefix); ok {
+				workspaceId, path, _ := strings.Cut(rest, "/")
+				req.Header.Set(HeaderWorkspaceID, workspaceId)
+				req.URL.Path = "/" + path
+				req.URL.RawPath = ""
+			}
+			return next(c)
+		}
+	}
+}
+
+func workspaceIdFromContext(c echo.Context) uint {
+	member, _ := c.Get(ContextKeyWorkspace).(model.WorkspaceMember)
+	return member.WorkspaceId
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"go-rest-api/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type memoryWorkspaceRepository struct {
+	members []model.WorkspaceMember
+}
+
+func (mr *memoryWorkspaceRepository) Create(workspace *model.Workspace, userId uint) error {
+	return nil
+}
+
+func (mr *memoryWorkspaceRepository) GetAll(members *[]model.WorkspaceMember, userId uint) error {
+	return nil
+}
+
+func (mr *memoryWorkspaceRepository) GetMember(member *model.WorkspaceMember, workspaceId uint, userId uint) error {
+	for _, stored := range mr.members {
+		if stored.WorkspaceId == workspaceId && stored.UserId == userId {
+			*member = stored
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (mr *memoryWorkspaceRepository) GetDefault(member *model.WorkspaceMember, userId uint) error {
+	for _, stored := range mr.members {
+		if stored.UserId == userId {
+			*member = stored
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (mr *memoryWorkspaceRepository) AddMember(member *model.WorkspaceMember) error {
+	return nil
+}
+
+func (mr *memoryWorkspaceRepository) RemoveMember(workspaceId uint, userId uint) error {
+	return nil
+}
+
+func newWorkspaceTestServer() *echo.Echo {
+	store := &memoryWorkspaceRepository{members: []model.WorkspaceMember{
+		{WorkspaceId: 1, UserId: 1, Role: model.WorkspaceRoleOwner},
+		{WorkspaceId: 2, UserId: 1, Role: model.WorkspaceRoleMember},
+		{WorkspaceId: 3, UserId: 2, Role: model.WorkspaceRoleOwner},
+	}}
+	e := echo.New()
+	e.Pre(WorkspacePath())
+	e.GET("/tasks", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, echo.Map{"workspace": workspaceIdFromContext(c)})
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"userId": 1.0}})
+			return next(c)
+		}
+	}, Workspace(WorkspaceConfig{Store: store}))
+	return e
+}
+
+func doWorkspaceRequest(e *echo.Echo, path string, header string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if header != "" {
+		req.Header.Set(HeaderWorkspaceID, header)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWorkspace_Default(t *testing.T) {
+	rec := doWorkspaceRequest(newWorkspaceTestServer(), "/tasks", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"workspace":1}`, strings.TrimSpace(rec.Body.String()))
+}
+
+func TestWorkspace_Header(t *testing.T) {
+	rec := doWorkspaceRequest(newWorkspaceTestServer(), "/tasks", "2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"workspace":2}`, strings.TrimSpace(rec.Body.String()))
+}
+
+func TestWorkspace_PathPrefix(t *testing.T) {
+	rec := doWorkspaceRequest(newWorkspaceTestServer(), "/w/2/tasks", "1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"workspace":2}`, strings.TrimSpace(rec.Body.String()))
+}
+
+func TestWorkspace_NotMember(t *testing.T) {
+	e := newWorkspaceTestServer()
+	rec := doWorkspaceRequest(e, "/tasks", "3")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = doWorkspaceRequest(e, "/w/3/tasks", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestWorkspace_InvalidHeader(t *testing.T) {
+	rec := doWorkspaceRequest(newWorkspaceTestServer(), "/tasks", "abc")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
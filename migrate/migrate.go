@@ -19,5 +19,21 @@ func main() {
 	}
 	defer fmt.Println("Successfully migrated")
 	defer db.CloseDB(dbConn)
-	dbConn.AutoMigrate(&model.User{}, &model.Task{})
+	// Accounts created before email verification existed count as verified.
+	grandfatherVerified := !dbConn.Migrator().HasColumn(&model.User{}, "EmailVerifiedAt")
+	// Log outs everywhere used to be recorded as a cutoff time. They become
+	// generation 1, which revokes the older tokens that carry no generation.
+	convertRevocations := dbConn.Migrator().HasColumn(&model.SessionRevocation{}, "revoked_before")
+	dbConn.AutoMigrate(&model.User{}, &model.Workspace{}, &model.WorkspaceMember{}, &model.Project{}, &model.ProjectMember{}, &model.CustomField{}, &model.Label{}, &model.Task{}, &model.SmartList{}, &model.IdempotencyRecord{}, &model.SyncCounter{}, &model.TaskTombstone{}, &model.TaskRevision{}, &model.UserQuota{}, &model.APIUsage{}, &model.TaskEvent{}, &model.Comment{}, &model.Attachment{}, &model.TaskWatcher{}, &model.Mention{}, &model.Milestone{}, &model.MilestoneTask{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.RevokedSession{}, &model.SessionRevocation{}, &model.PasswordResetToken{}, &model.TwoFactor{}, &model.TwoFactorChallenge{}, &model.RecoveryCode{}, &model.Identity{}, &model.PersonalAccessToken{}, &model.AuditEntry{})
+	if grandfatherVerified {
+		dbConn.Exec("UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL")
+	}
+	if convertRevocations {
+		dbConn.Exec("UPDATE session_revocations SET generation = 1")
+		dbConn.Migrator().DropColumn(&model.SessionRevocation{}, "revoked_before")
+	}
+	// Admins can only be made by other admins, so the first one is named here.
+	if email := os.Getenv("ADMIN_EMAIL"); email != "" {
+		dbConn.Model(&model.User{}).Where("email = ?", email).Update("role", model.RoleAdmin)
+	}
 }
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// MaxAttachmentSize caps a single upload. What a user may store in total is
+// limited by QuotaLimits.MaxAttachmentBytes.
+const MaxAttachmentSize = 10 << 20
+
+// Attachment is a file uploaded to a task. Its size counts against the
+// storage quota of the user who uploaded it.
+type Attachment struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null"`
+	ContentType string    `json:"content_type" gorm:"not null"`
+	Size        int64     `json:"size" gorm:"not null"`
+	Data        []byte    `json:"-" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	Task        Task      `json:"-" gorm:"foreignKey:TaskId; constraint:onDelete:CASCADE"`
+	TaskId      uint      `json:"task_id" gorm:"not null; index"`
+	User        User      `json:"-" gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId      uint      `json:"user_id" gorm:"not null; index"`
+}
+
+type AttachmentResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	TaskId      uint      `json:"task_id"`
+	UserId      uint      `json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+const (
+	AuditActionListUsers   = "users.list"
+	AuditActionViewTasks   = "user.view_tasks"
+	AuditActionDisableUser = "user.disable"
+	AuditActionEnableUser  = "user.enable"
+	AuditActionSetRole     = "user.set_role"
+	AuditActionViewAudit   = "audit.view"
+)
+
+const (
+	AuditOutcomePending   = "pending"
+	AuditOutcomeSucceeded = "succeeded"
+	AuditOutcomeFailed    = "failed"
+)
+
+// AuditEntry records an action taken through the admin API. It has no
+// foreign keys so that entries outlive the users they mention. It is written
+// as pending before the action and gets its outcome afterwards, so an entry
+// that stays pending belongs to an action that was cut short. Error holds why
+// a failed action failed.
+type AuditEntry struct {
+	ID           uint   `gorm:"primaryKey"`
+	ActorId      uint   `gorm:"not null; index"`
+	Action       string `gorm:"not null"`
+	TargetUserId *uint  `gorm:"index"`
+	Detail       string
+	Outcome      string `gorm:"not null; default:'pending'"`
+	Error        string
+	CreatedAt    time.Time `gorm:"index"`
+}
+
+type AuditEntryResponse struct {
+	ID           uint      `json:"id"`
+	ActorId      uint      `json:"actor_id"`
+	Action       string    `json:"action"`
+	TargetUserId *uint     `json:"target_user_id"`
+	Detail       string    `json:"detail"`
+	Outcome      string    `json:"outcome"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
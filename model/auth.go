@@ -0,0 +1,85 @@
+package model
+
+import "time"
+
+// TokenLifetimes sets how long access and refresh tokens stay valid.
+type TokenLifetimes struct {
+	Access  time.Duration
+	Refresh time.Duration
+}
+
+// RefreshToken is stored by the SHA-256 hash of the opaque token handed to
+// the client. Every token rotated from one login shares a FamilyId, so that
+// replaying a used token can revoke the whole family.
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenHash string    `gorm:"not null; uniqueIndex"`
+	FamilyId  string    `gorm:"not null; index"`
+	User      User      `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId    uint      `gorm:"not null; index"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// AuthTokens are issued on login and on every refresh.
+type AuthTokens struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// RevokedToken is an access token that was logged out before it expired.
+// Rows are only needed until ExpiresAt, after which the token is rejected
+// anyway.
+type RevokedToken struct {
+	Jti       string    `gorm:"primaryKey"`
+	UserId    uint      `gorm:"not null; index"`
+	ExpiresAt time.Time `gorm:"not null; index"`
+}
+
+// RevokedSession invalidates every access token whose sid claim is Sid, as
+// when a user ends their other sessions. Like RevokedToken it is only needed
+// until the last of those tokens expires.
+type RevokedSession struct {
+	Sid       string    `gorm:"primaryKey"`
+	UserId    uint      `gorm:"not null; index"`
+	ExpiresAt time.Time `gorm:"not null; index"`
+}
+
+// SessionRevocation counts how often the user logged out everywhere. Access
+// tokens carry the generation they were issued in, and those of an older
+// generation are invalid. A user without a row is in generation 0.
+type SessionRevocation struct {
+	UserId     uint `gorm:"primaryKey"`
+	User       User `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	Generation uint `gorm:"not null; default:0"`
+}
+
+// EmailVerificationRequest carries the token from a verification link.
+type EmailVerificationRequest struct {
+	Token string `json:"token"`
+}
+
+// PasswordResetToken is stored by the SHA-256 hash of the token mailed to the
+// user. It can be used once, before ExpiresAt.
+type PasswordResetToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenHash string    `gorm:"not null; uniqueIndex"`
+	User      User      `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId    uint      `gorm:"not null; index"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type PasswordForgotRequest struct {
+	Email string `json:"email"`
+}
+
+type PasswordResetRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type Comment struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Body      string    `json:"body" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	Task      Task      `json:"-" gorm:"foreignKey:TaskId; constraint:onDelete:CASCADE"`
+	TaskId    uint      `json:"task_id" gorm:"not null; index"`
+	User      User      `json:"-" gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId    uint      `json:"user_id" gorm:"not null"`
+}
+
+type CommentResponse struct {
+	ID        uint      `json:"id"`
+	Body      string    `json:"body"`
+	TaskId    uint      `json:"task_id"`
+	UserId    uint      `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
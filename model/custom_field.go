@@ -0,0 +1,111 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	CustomFieldText        = "text"
+	CustomFieldNumber      = "number"
+	CustomFieldDate        = "date"
+	CustomFieldSelect      = "select"
+	CustomFieldMultiSelect = "multi_select"
+)
+
+// CustomFieldDateLayout is the format of date values. Dates sort and compare
+// correctly as text in this format.
+const CustomFieldDateLayout = "2006-01-02"
+
+const (
+	CustomFieldOpEq  = "eq"
+	CustomFieldOpGte = "gte"
+	CustomFieldOpLte = "lte"
+)
+
+// CustomField is a typed task attribute defined by the owner of a project.
+// Min and Max apply to numbers, MaxLength and Pattern to text and Options
+// lists the choices of select and multi_select fields.
+type CustomField struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null; uniqueIndex:idx_custom_fields_project_name"`
+	Type      string    `json:"type" gorm:"not null"`
+	Required  bool      `json:"required" gorm:"not null; default:false"`
+	Options   []string  `json:"options" gorm:"serializer:json; type:jsonb"`
+	Min       *float64  `json:"min"`
+	Max       *float64  `json:"max"`
+	MaxLength *int      `json:"max_length"`
+	Pattern   string    `json:"pattern"`
+	CreatedAt time.Time `json:"created_at"`
+	Project   Project   `json:"-" gorm:"foreignKey:ProjectId; constraint:onDelete:CASCADE"`
+	ProjectId uint      `json:"project_id" gorm:"not null; uniqueIndex:idx_custom_fields_project_name"`
+}
+
+type CustomFieldResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Required  bool      `json:"required"`
+	Options   []string  `json:"options,omitempty"`
+	Min       *float64  `json:"min,omitempty"`
+	Max       *float64  `json:"max,omitempty"`
+	MaxLength *int      `json:"max_length,omitempty"`
+	Pattern   string    `json:"pattern,omitempty"`
+	ProjectId uint      `json:"project_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CustomFieldValues holds the custom field values of a task keyed by the
+// decimal field ID. Numbers are float64, dates are strings in
+// CustomFieldDateLayout and multi_select values are lists of strings.
+type CustomFieldValues map[string]interface{}
+
+func (v CustomFieldValues) Value() (driver.Value, error) {
+	if v == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (v *CustomFieldValues) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(src, v)
+	case string:
+		return json.Unmarshal([]byte(src), v)
+	}
+	return errors.New("custom field values must be json")
+}
+
+// CustomFieldFilter keeps the tasks whose value of the field compares to
+// Value with Op. Type is copied from the field definition before the filter
+// reaches the repository.
+type CustomFieldFilter struct {
+	FieldId uint
+	Op      string
+	Value   string
+	Type    string
+}
+
+type CustomFieldSort struct {
+	FieldId uint
+	Desc    bool
+	Type    string
+}
+
+// TaskQuery narrows and orders GET /tasks. Without a Sort tasks come in the
+// order they were created.
+type TaskQuery struct {
+	IncludeSnoozed bool
+	Filters        []CustomFieldFilter
+	Sort           *CustomFieldSort
+}
@@ -0,0 +1,42 @@
+package model
+
+import "errors"
+
+var (
+	ErrStaleVersion         = errors.New("task has been modified since it was read")
+	ErrInvalidSyncToken     = errors.New("sync token is invalid")
+	ErrSyncTokenExpired     = errors.New("sync token has expired, sync again without a token")
+	ErrQuotaExceeded        = errors.New("quota exceeded")
+	ErrRateLimited          = errors.New("rate limit exceeded")
+	ErrNotProjectMember     = errors.New("user is not a member of the project")
+	ErrNotProjectOwner      = errors.New("only the project owner can do this")
+	ErrProjectOwnerMember   = errors.New("the project owner cannot leave the project")
+	ErrNotWorkspaceMember   = errors.New("user is not a member of the workspace")
+	ErrNotWorkspaceAdmin    = errors.New("only workspace owners and admins can do this")
+	ErrWorkspaceOwnerMember = errors.New("the workspace owner cannot leave the workspace")
+	ErrMilestoneClosed      = errors.New("milestone is closed")
+	ErrInvalidRefreshToken  = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused   = errors.New("refresh token has already been used")
+	ErrTokenRevoked         = errors.New("token has been revoked")
+	ErrInvalidVerification  = errors.New("verification link is invalid or expired")
+	ErrEmailNotVerified     = errors.New("email address has not been verified")
+	ErrEmailAlreadyVerified = errors.New("email address is already verified")
+	ErrInvalidPasswordReset = errors.New("password reset link is invalid or expired")
+	ErrWrongPassword        = errors.New("current password is incorrect")
+	ErrEmailTaken           = errors.New("email address is already in use")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication is not set up")
+	ErrInvalidTwoFactorCode = errors.New("two-factor code is invalid")
+	ErrInvalidChallenge     = errors.New("login challenge is invalid or expired")
+	ErrTwoFactorLocked      = errors.New("too many wrong two-factor codes, try again later")
+	ErrUnknownProvider      = errors.New("identity provider is not configured")
+	ErrOIDCLoginFailed      = errors.New("sign-in with the identity provider failed")
+	ErrIdentityUnverified   = errors.New("the identity provider has not verified the email address")
+	ErrAccountUnverified    = errors.New("verify the email address of your account before signing in with an identity provider")
+	ErrInvalidAccessToken   = errors.New("personal access token is invalid or expired")
+	ErrAccessTokenForbidden = errors.New("personal access tokens cannot be used here")
+	ErrInsufficientScope    = errors.New("personal access token lacks the scope for this request")
+	ErrPermissionDenied     = errors.New("your role does not allow this")
+	ErrAccountDisabled      = errors.New("account has been disabled")
+	ErrOwnAccount           = errors.New("admins cannot disable or change the role of their own account")
+)
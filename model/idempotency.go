@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type IdempotencyRecord struct {
+	ID           uint      `gorm:"primaryKey"`
+	Key          string    `gorm:"not null; uniqueIndex:idx_idempotency_user_key"`
+	UserId       uint      `gorm:"not null; uniqueIndex:idx_idempotency_user_key"`
+	RequestHash  string    `gorm:"not null"`
+	StatusCode   int       `gorm:"not null; default:0"`
+	ContentType  string    `gorm:"not null; default:''"`
+	ResponseBody []byte    `gorm:"type:bytea"`
+	CreatedAt    time.Time `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"not null; index"`
+}
+
+// Completed reports whether the original request has finished and its
+// response can be replayed.
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
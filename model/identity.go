@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Identity links a user to their account at an OpenID Connect provider. The
+// provider is recorded by issuer, so renaming it in the configuration keeps
+// the link.
+type Identity struct {
+	ID        uint   `gorm:"primaryKey"`
+	Issuer    string `gorm:"not null; uniqueIndex:idx_identities_issuer_subject"`
+	Subject   string `gorm:"not null; uniqueIndex:idx_identities_issuer_subject"`
+	User      User   `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId    uint   `gorm:"not null; index"`
+	Email     string
+	CreatedAt time.Time
+}
+
+// OIDCLoginStart sends the user to the provider. StateToken comes back with
+// them in a cookie and ties the callback to this browser.
+type OIDCLoginStart struct {
+	AuthURL    string
+	StateToken string
+	ExpiresAt  time.Time
+}
+
+// OIDCCallbackRequest is what the provider redirects back with.
+type OIDCCallbackRequest struct {
+	Code  string `query:"code"`
+	State string `query:"state"`
+	Error string `query:"error"`
+}
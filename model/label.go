@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type Label struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null; uniqueIndex:idx_labels_workspace_user_name"`
+	CreatedAt   time.Time `json:"created_at"`
+	User        User      `json:"-" gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId      uint      `json:"user_id" gorm:"not null; uniqueIndex:idx_labels_workspace_user_name"`
+	Workspace   Workspace `json:"-" gorm:"foreignKey:WorkspaceId; constraint:onDelete:CASCADE"`
+	WorkspaceId uint      `json:"workspace_id" gorm:"not null; uniqueIndex:idx_labels_workspace_user_name"`
+}
+
+type LabelResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
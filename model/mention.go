@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	MentionSourceComment     = "comment"
+	MentionSourceDescription = "description"
+)
+
+// Mention records that a user was mentioned on a task. SourceId is the
+// comment ID for comments and 0 for the task description, so that a user
+// mentioned in a description is only recorded once.
+type Mention struct {
+	ID        uint   `gorm:"primaryKey"`
+	Task      Task   `gorm:"foreignKey:TaskId; constraint:onDelete:CASCADE"`
+	TaskId    uint   `gorm:"not null; uniqueIndex:idx_mentions_source"`
+	Source    string `gorm:"not null; uniqueIndex:idx_mentions_source"`
+	SourceId  uint   `gorm:"not null; uniqueIndex:idx_mentions_source"`
+	User      User   `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId    uint   `gorm:"not null; uniqueIndex:idx_mentions_source; index"`
+	ActorId   uint   `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+type MentionResponse struct {
+	ID        uint      `json:"id"`
+	TaskId    uint      `json:"task_id"`
+	Source    string    `json:"source"`
+	SourceId  uint      `json:"source_id"`
+	ActorId   uint      `json:"actor_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// Milestone is a time box, such as a sprint, for tasks of a workspace.
+// Closing it records how many tasks were committed and completed, which the
+// velocity of later milestones is computed from.
+type Milestone struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Name        string     `json:"name" gorm:"not null"`
+	StartDate   time.Time  `json:"start_date" gorm:"not null"`
+	EndDate     time.Time  `json:"end_date" gorm:"not null"`
+	ClosedAt    *time.Time `json:"closed_at"`
+	Committed   int        `json:"-" gorm:"not null; default:0"`
+	Completed   int        `json:"-" gorm:"not null; default:0"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Workspace   Workspace  `json:"-" gorm:"foreignKey:WorkspaceId; constraint:onDelete:CASCADE"`
+	WorkspaceId uint       `json:"workspace_id" gorm:"not null; index"`
+	User        User       `json:"-" gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId      uint       `json:"user_id" gorm:"not null"`
+}
+
+// MilestoneTask puts a task in a milestone. CarriedOver marks a task that was
+// moved in unfinished when an earlier milestone closed.
+type MilestoneTask struct {
+	Milestone   Milestone `gorm:"foreignKey:MilestoneId; constraint:onDelete:CASCADE"`
+	MilestoneId uint      `gorm:"primaryKey"`
+	Task        Task      `gorm:"foreignKey:TaskId; constraint:onDelete:CASCADE"`
+	TaskId      uint      `gorm:"primaryKey; index"`
+	CarriedOver bool      `gorm:"not null; default:false"`
+	CreatedAt   time.Time
+}
+
+type MilestoneTaskRequest struct {
+	TaskId uint `json:"task_id"`
+}
+
+// MilestoneCloseRequest optionally names an open milestone that the
+// unfinished tasks carry over to.
+type MilestoneCloseRequest struct {
+	CarryOverTo *uint `json:"carry_over_to"`
+}
+
+// MilestoneCounts are the task counts of a milestone that is still open.
+type MilestoneCounts struct {
+	Committed int
+	Completed int
+	CarriedIn int
+}
+
+type MilestoneResponse struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     time.Time  `json:"end_date"`
+	ClosedAt    *time.Time `json:"closed_at"`
+	UserId      uint       `json:"user_id"`
+	WorkspaceId uint       `json:"workspace_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// MilestoneSummary compares the work committed to a milestone with the work
+// completed. Velocity is the average number of tasks completed in the last
+// VelocityMilestones closed milestones that ended no later than this one.
+type MilestoneSummary struct {
+	Milestone          MilestoneResponse `json:"milestone"`
+	Committed          int               `json:"committed"`
+	Completed          int               `json:"completed"`
+	Remaining          int               `json:"remaining"`
+	CarriedIn          int               `json:"carried_in"`
+	CarriedOut         int               `json:"carried_out"`
+	Velocity           float64           `json:"velocity"`
+	VelocityMilestones int               `json:"velocity_milestones"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "encoding/json"
+
+// PatchField holds one member of a JSON Merge Patch (RFC 7396) document.
+// Set is false when the member was absent, Null is true when it was
+// explicitly null.
+type PatchField[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
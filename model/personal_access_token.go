@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// PersonalAccessTokenPrefix starts every personal access token, which tells
+// them apart from the JWT access tokens of a login session.
+const PersonalAccessTokenPrefix = "grp_"
+
+// Scopes a personal access token can be granted. A write scope includes the
+// read scope of the same resource.
+const (
+	ScopeTasksRead       = "tasks:read"
+	ScopeTasksWrite      = "tasks:write"
+	ScopeProjectsRead    = "projects:read"
+	ScopeProjectsWrite   = "projects:write"
+	ScopeMilestonesRead  = "milestones:read"
+	ScopeMilestonesWrite = "milestones:write"
+	ScopeWorkspacesRead  = "workspaces:read"
+	ScopeWorkspacesWrite = "workspaces:write"
+)
+
+var PersonalAccessTokenScopes = []string{
+	ScopeTasksRead, ScopeTasksWrite,
+	ScopeProjectsRead, ScopeProjectsWrite,
+	ScopeMilestonesRead, ScopeMilestonesWrite,
+	ScopeWorkspacesRead, ScopeWorkspacesWrite,
+}
+
+// PersonalAccessToken lets scripts call the API with an Authorization: Bearer
+// header instead of logging in. It is stored by the SHA-256 hash of the
+// token, which is only shown once, when it is created. A token without
+// ExpiresAt never expires.
+type PersonalAccessToken struct {
+	ID         uint     `gorm:"primaryKey"`
+	Name       string   `gorm:"not null"`
+	TokenHash  string   `gorm:"not null; uniqueIndex"`
+	Scopes     []string `gorm:"serializer:json; type:jsonb; not null"`
+	User       User     `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId     uint     `gorm:"not null; index"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+type PersonalAccessTokenRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// PersonalAccessTokenResponse carries Token only in the response that
+// creates it.
+type PersonalAccessTokenResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
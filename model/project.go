@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+type Project struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	User        User      `json:"-" gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId      uint      `json:"user_id" gorm:"not null"`
+	Workspace   Workspace `json:"-" gorm:"foreignKey:WorkspaceId; constraint:onDelete:CASCADE"`
+	WorkspaceId uint      `json:"workspace_id" gorm:"not null; index"`
+}
+
+// ProjectMember lists the users who share a project, including its owner.
+type ProjectMember struct {
+	Project   Project `gorm:"foreignKey:ProjectId; constraint:onDelete:CASCADE"`
+	ProjectId uint    `gorm:"primaryKey"`
+	User      User    `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId    uint    `gorm:"primaryKey; index"`
+	CreatedAt time.Time
+}
+
+type ProjectMemberRequest struct {
+	Email string `json:"email"`
+}
+
+type ProjectResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	UserId      uint      `json:"user_id"`
+	WorkspaceId uint      `json:"workspace_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
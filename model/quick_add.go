@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+type QuickAddRequest struct {
+	Text string `json:"text"`
+	// Timezone overrides the user's time zone for this request.
+	Timezone string `json:"timezone"`
+	// Preview only parses Text. Nothing is saved.
+	Preview bool `json:"preview"`
+}
+
+type QuickAddParsed struct {
+	Title      string     `json:"title"`
+	DueDate    *time.Time `json:"due_date"`
+	AllDay     bool       `json:"all_day"`
+	Labels     []string   `json:"labels"`
+	Priority   string     `json:"priority"`
+	Recurrence string     `json:"recurrence"`
+	Timezone   string     `json:"timezone"`
+}
+
+type QuickAddResponse struct {
+	Parsed QuickAddParsed `json:"parsed"`
+	Task   *TaskResponse  `json:"task,omitempty"`
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	QuotaLimitTasks           = "tasks"
+	QuotaLimitRequestsPerDay  = "requests_per_day"
+	QuotaLimitAttachmentBytes = "attachment_bytes"
+)
+
+// QuotaLimits caps what a user may do. A zero limit means unlimited.
+type QuotaLimits struct {
+	MaxTasks          int64 `json:"max_tasks"`
+	MaxRequestsPerDay int64 `json:"max_requests_per_day"`
+	// MaxAttachmentBytes caps the total size of the attachments a user has
+	// uploaded.
+	MaxAttachmentBytes int64 `json:"max_attachment_bytes"`
+}
+
+// UserQuota overrides the default limits for one user, for example for a
+// partner plan. Nil columns keep the default.
+type UserQuota struct {
+	UserId             uint   `gorm:"primaryKey"`
+	User               User   `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	Plan               string `gorm:"not null"`
+	MaxTasks           *int64
+	MaxRequestsPerDay  *int64
+	MaxAttachmentBytes *int64
+	UpdatedAt          time.Time
+}
+
+// APIUsage counts the task API requests of a user on one UTC day.
+type APIUsage struct {
+	UserId   uint      `gorm:"primaryKey"`
+	Day      time.Time `gorm:"primaryKey; type:date"`
+	Requests int64     `gorm:"not null; default:0"`
+}
+
+// QuotaError reports which limit was hit. It wraps ErrQuotaExceeded for
+// limits that only go away when the user frees something up, and
+// ErrRateLimited for limits that reset at ResetAt.
+type QuotaError struct {
+	Err     error
+	Limit   string
+	Used    int64
+	Max     int64
+	ResetAt *time.Time
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %s limit of %d reached", e.Err, e.Limit, e.Max)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return e.Err
+}
+
+type UsageCounter struct {
+	Used int64 `json:"used"`
+	// Limit is 0 when unlimited.
+	Limit int64 `json:"limit"`
+}
+
+type UsageResponse struct {
+	Plan            string       `json:"plan"`
+	Tasks           UsageCounter `json:"tasks"`
+	RequestsToday   UsageCounter `json:"requests_today"`
+	RequestsResetAt time.Time    `json:"requests_reset_at"`
+	AttachmentBytes UsageCounter `json:"attachment_bytes"`
+}
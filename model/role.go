@@ -0,0 +1,37 @@
+package model
+
+import "slices"
+
+// Roles of a user across the whole service, unlike the roles of a workspace
+// member. Every user can use their own account; the roles add permissions
+// over other users.
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+const (
+	PermissionUsersRead    = "users:read"
+	PermissionUsersManage  = "users:manage"
+	PermissionTasksReadAll = "tasks:read_all"
+	PermissionAuditRead    = "audit:read"
+)
+
+// rolePermissions grants support read-only access to users and their tasks,
+// and admins everything.
+var rolePermissions = map[string][]string{
+	RoleUser:    {},
+	RoleSupport: {PermissionUsersRead, PermissionTasksReadAll},
+	RoleAdmin:   {PermissionUsersRead, PermissionUsersManage, PermissionTasksReadAll, PermissionAuditRead},
+}
+
+// HasPermission reports whether role grants permission. Unknown roles grant
+// nothing.
+func HasPermission(role string, permission string) bool {
+	return slices.Contains(rolePermissions[role], permission)
+}
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+const (
+	DueOverdue   = "overdue"
+	DueToday     = "today"
+	DueThisWeek  = "this_week"
+	DueNext7Days = "next_7_days"
+	DueNoDueDate = "none"
+)
+
+// TaskFilter is a saved task query. Due is relative to the time the filter is
+// evaluated, DueFrom and DueTo are absolute bounds.
+type TaskFilter struct {
+	Statuses      []string   `json:"statuses,omitempty"`
+	Priorities    []string   `json:"priorities,omitempty"`
+	Labels        []string   `json:"labels,omitempty"`
+	ExcludeLabels []string   `json:"exclude_labels,omitempty"`
+	Due           string     `json:"due,omitempty"`
+	DueFrom       *time.Time `json:"due_from,omitempty"`
+	DueTo         *time.Time `json:"due_to,omitempty"`
+	TitleContains string     `json:"title_contains,omitempty"`
+}
+
+type SmartList struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Name      string     `json:"name" gorm:"not null"`
+	Filter    TaskFilter `json:"filter" gorm:"serializer:json; type:jsonb; not null"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	User      User       `json:"-" gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId    uint       `json:"user_id" gorm:"not null"`
+}
+
+type SmartListResponse struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Filter    TaskFilter `json:"filter"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,7 @@
+package model
+
+import "time"
+
+type SnoozeRequest struct {
+	Until time.Time `json:"until"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+const (
+	StatsIntervalDay  = "day"
+	StatsIntervalWeek = "week"
+)
+
+type StatsQuery struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Interval string    `json:"interval"`
+}
+
+type ThroughputPoint struct {
+	Period    time.Time `json:"period"`
+	Created   int64     `json:"created"`
+	Completed int64     `json:"completed"`
+}
+
+type BurndownPoint struct {
+	Period    time.Time `json:"period"`
+	Remaining int64     `json:"remaining"`
+}
+
+type StatusCount struct {
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+type LabelCount struct {
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+type StatsResponse struct {
+	From                 time.Time         `json:"from"`
+	To                   time.Time         `json:"to"`
+	Interval             string            `json:"interval"`
+	Throughput           []ThroughputPoint `json:"throughput"`
+	AverageLeadTimeHours float64           `json:"average_lead_time_hours"`
+	OpenByStatus         []StatusCount     `json:"open_by_status"`
+	OpenByLabel          []LabelCount      `json:"open_by_label"`
+	Burndown             []BurndownPoint   `json:"burndown"`
+}
@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// SyncCounter is the per-user change sequence. Every task write takes the next
+// Seq. PurgedSeq is the highest sequence whose tombstone has been purged; sync
+// tokens older than that can no longer be served incrementally.
+type SyncCounter struct {
+	UserId    uint  `gorm:"primaryKey; autoIncrement:false"`
+	User      User  `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	Seq       int64 `gorm:"not null; default:0"`
+	PurgedSeq int64 `gorm:"not null; default:0"`
+}
+
+type TaskTombstone struct {
+	ID          uint      `gorm:"primaryKey"`
+	TaskId      uint      `gorm:"not null"`
+	User        User      `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId      uint      `gorm:"not null; index:idx_task_tombstones_user_change_seq,priority:1"`
+	WorkspaceId uint      `gorm:"not null"`
+	ChangeSeq   int64     `gorm:"not null; index:idx_task_tombstones_user_change_seq,priority:2"`
+	DeletedAt   time.Time `gorm:"not null"`
+}
+
+type TombstoneResponse struct {
+	ID        uint      `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type SyncResponse struct {
+	Tasks   []TaskResponse      `json:"tasks"`
+	Deleted []TombstoneResponse `json:"deleted"`
+	Token   string              `json:"token"`
+	// Full is true when Tasks is the complete task list rather than a delta,
+	// so the client should drop anything it has that is not in it.
+	Full bool `json:"full"`
+}
+
+const (
+	SyncPushApplied  = "applied"
+	SyncPushMerged   = "merged"
+	SyncPushConflict = "conflict"
+	SyncPushCreated  = "created"
+	SyncPushDeleted  = "deleted"
+	SyncPushRejected = "rejected"
+)
+
+// SyncPushItem is one offline edit. Base holds the values of the task at
+// BaseVersion for at least every member of Changes, so the server can tell
+// which side changed a field. A TaskId of 0 creates a task.
+type SyncPushItem struct {
+	TaskId      uint      `json:"task_id"`
+	ClientId    string    `json:"client_id"`
+	BaseVersion uint      `json:"base_version"`
+	Base        TaskPatch `json:"base"`
+	Changes     TaskPatch `json:"changes"`
+	Deleted     bool      `json:"deleted"`
+}
+
+type SyncPushRequest struct {
+	Changes []SyncPushItem `json:"changes"`
+}
+
+type SyncConflict struct {
+	Field  string      `json:"field"`
+	Base   interface{} `json:"base"`
+	Yours  interface{} `json:"yours"`
+	Theirs interface{} `json:"theirs"`
+}
+
+type SyncPushResult struct {
+	TaskId    uint           `json:"task_id"`
+	ClientId  string         `json:"client_id,omitempty"`
+	Status    string         `json:"status"`
+	Task      *TaskResponse  `json:"task,omitempty"`
+	Conflicts []SyncConflict `json:"conflicts,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
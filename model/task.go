@@ -2,18 +2,76 @@ package model
 
 import "time"
 
+const (
+	TaskStatusTodo  = "todo"
+	TaskStatusDoing = "doing"
+	TaskStatusDone  = "done"
+)
+
+const (
+	TaskPriorityLow    = "low"
+	TaskPriorityMedium = "medium"
+	TaskPriorityHigh   = "high"
+)
+
 type Task struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Title     string    `json:"title" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	User      User      `json:"user" gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
-	UserId    uint      `json:"user_id" gorm:"not null"`
+	ID           uint              `json:"id" gorm:"primaryKey"`
+	Title        string            `json:"title" gorm:"not null"`
+	Description  string            `json:"description"`
+	Status       string            `json:"status" gorm:"not null; default:todo; index"`
+	Priority     string            `json:"priority" gorm:"not null; default:medium"`
+	DueDate      *time.Time        `json:"due_date"`
+	CompletedAt  *time.Time        `json:"completed_at"`
+	Labels       []Label           `json:"labels" gorm:"many2many:task_labels; constraint:onDelete:CASCADE"`
+	Recurrence   string            `json:"recurrence"`
+	ScheduledAt  *time.Time        `json:"scheduled_at" gorm:"index"`
+	Assignee     *User             `json:"-" gorm:"foreignKey:AssigneeId; constraint:onDelete:SET NULL"`
+	AssigneeId   *uint             `json:"assignee_id" gorm:"index"`
+	Project      *Project          `json:"-" gorm:"foreignKey:ProjectId; constraint:onDelete:SET NULL"`
+	ProjectId    *uint             `json:"project_id" gorm:"index"`
+	CustomFields CustomFieldValues `json:"custom_fields" gorm:"type:jsonb; not null; default:'{}'"`
+	Version      uint              `json:"version" gorm:"not null; default:1"`
+	ChangeSeq    int64             `json:"-" gorm:"not null; default:0; index:idx_tasks_user_change_seq,priority:2"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	User         User              `json:"user" gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId       uint              `json:"user_id" gorm:"not null; index:idx_tasks_user_change_seq,priority:1"`
+	Workspace    Workspace         `json:"-" gorm:"foreignKey:WorkspaceId; constraint:onDelete:CASCADE"`
+	WorkspaceId  uint              `json:"workspace_id" gorm:"not null; index"`
+}
+
+// TaskPatch is a JSON Merge Patch of a task. Labels and custom_fields are
+// replaced as a whole.
+type TaskPatch struct {
+	Title        PatchField[string]            `json:"title"`
+	Description  PatchField[string]            `json:"description"`
+	Status       PatchField[string]            `json:"status"`
+	Priority     PatchField[string]            `json:"priority"`
+	DueDate      PatchField[time.Time]         `json:"due_date"`
+	Labels       PatchField[[]Label]           `json:"labels"`
+	Recurrence   PatchField[string]            `json:"recurrence"`
+	ScheduledAt  PatchField[time.Time]         `json:"scheduled_at"`
+	AssigneeId   PatchField[uint]              `json:"assignee_id"`
+	ProjectId    PatchField[uint]              `json:"project_id"`
+	CustomFields PatchField[CustomFieldValues] `json:"custom_fields"`
 }
 
 type TaskResponse struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Title     string    `json:"title" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           uint              `json:"id" gorm:"primaryKey"`
+	Title        string            `json:"title" gorm:"not null"`
+	Description  string            `json:"description"`
+	Status       string            `json:"status"`
+	Priority     string            `json:"priority"`
+	DueDate      *time.Time        `json:"due_date"`
+	CompletedAt  *time.Time        `json:"completed_at"`
+	Labels       []LabelResponse   `json:"labels"`
+	Recurrence   string            `json:"recurrence"`
+	ScheduledAt  *time.Time        `json:"scheduled_at"`
+	AssigneeId   *uint             `json:"assignee_id"`
+	ProjectId    *uint             `json:"project_id"`
+	WorkspaceId  uint              `json:"workspace_id"`
+	CustomFields CustomFieldValues `json:"custom_fields"`
+	Version      uint              `json:"version"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
 }
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+const TaskEventWokeUp = "woke_up"
+
+// TaskEvent records something that happened to a task without the user
+// doing it, such as a snoozed task coming back.
+type TaskEvent struct {
+	ID        uint   `gorm:"primaryKey"`
+	Type      string `gorm:"not null"`
+	TaskId    uint   `gorm:"not null"`
+	UserId    uint   `gorm:"not null; index"`
+	CreatedAt time.Time
+}
+
+type TaskEventResponse struct {
+	ID        uint      `json:"id"`
+	Type      string    `json:"type"`
+	TaskId    uint      `json:"task_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
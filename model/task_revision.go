@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// TaskRevision is a snapshot of the editable fields of a task, taken every
+// time the task is written. Version matches Task.Version at that point.
+type TaskRevision struct {
+	ID           uint   `gorm:"primaryKey"`
+	Task         Task   `gorm:"foreignKey:TaskId; constraint:onDelete:CASCADE"`
+	TaskId       uint   `gorm:"not null; uniqueIndex:idx_task_revisions_task_version"`
+	Version      uint   `gorm:"not null; uniqueIndex:idx_task_revisions_task_version"`
+	Title        string `gorm:"not null"`
+	Description  string
+	Status       string `gorm:"not null"`
+	Priority     string `gorm:"not null"`
+	DueDate      *time.Time
+	Labels       []string `gorm:"serializer:json; type:jsonb; not null"`
+	Recurrence   string
+	AssigneeId   *uint
+	ProjectId    *uint
+	CustomFields CustomFieldValues `gorm:"type:jsonb; not null; default:'{}'"`
+	CreatedAt    time.Time
+	UserId       uint `gorm:"not null"`
+}
+
+type TaskRevisionResponse struct {
+	Version      uint              `json:"version"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Status       string            `json:"status"`
+	Priority     string            `json:"priority"`
+	DueDate      *time.Time        `json:"due_date"`
+	Labels       []string          `json:"labels"`
+	Recurrence   string            `json:"recurrence"`
+	AssigneeId   *uint             `json:"assignee_id"`
+	ProjectId    *uint             `json:"project_id"`
+	CustomFields CustomFieldValues `json:"custom_fields"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type TaskRevisionDiff struct {
+	From    uint          `json:"from"`
+	To      uint          `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
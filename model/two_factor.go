@@ -0,0 +1,98 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// TwoFactor holds the TOTP secret of a user. It is enrolled with EnabledAt
+// unset and only asked for at login once the user confirms a code from it.
+// LastStep is the time step of the last accepted code, which cannot be used
+// again. FailedAttempts counts the wrong codes entered at login since the
+// last right one, and too many of them lock logins until LockedUntil.
+type TwoFactor struct {
+	User           User   `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId         uint   `gorm:"primaryKey"`
+	Secret         string `gorm:"not null"`
+	EnabledAt      *time.Time
+	LastStep       int64 `gorm:"not null; default:0"`
+	FailedAttempts int   `gorm:"not null; default:0"`
+	LockedUntil    *time.Time
+	CreatedAt      time.Time
+}
+
+// TwoFactorChallenge is the server side of a login challenge, stored by the
+// jti of its token. It is used up by the first right code and gives out after
+// a few wrong ones.
+type TwoFactorChallenge struct {
+	Jti       string `gorm:"primaryKey"`
+	User      User   `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId    uint   `gorm:"not null; index"`
+	Attempts  int    `gorm:"not null; default:0"`
+	UsedAt    *time.Time
+	ExpiresAt time.Time `gorm:"not null; index"`
+}
+
+// TwoFactorLockedError wraps ErrTwoFactorLocked with the time the lockout
+// ends.
+type TwoFactorLockedError struct {
+	Until time.Time
+}
+
+func (e *TwoFactorLockedError) Error() string {
+	return fmt.Sprintf("%s (until %s)", ErrTwoFactorLocked, e.Until.Format(time.RFC3339))
+}
+
+func (e *TwoFactorLockedError) Unwrap() error {
+	return ErrTwoFactorLocked
+}
+
+// RecoveryCode is stored by the SHA-256 hash of a code shown to the user once
+// when they enable two-factor authentication. Each code signs in once in
+// place of a TOTP code.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	User      User   `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId    uint   `gorm:"not null; index"`
+	CodeHash  string `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code or a recovery code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// LoginChallenge stands in for a session when the password was right but a
+// second factor is still needed. Token goes to POST /login/2fa with a code.
+type LoginChallenge struct {
+	Token     string    `json:"challenge_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type LoginChallengeRequest struct {
+	Token string `json:"challenge_token"`
+	Code  string `json:"code"`
+}
+
+// LoginResult is either a session or, for users with two-factor
+// authentication, a challenge.
+type LoginResult struct {
+	Tokens    AuthTokens
+	Challenge *LoginChallenge
+}
@@ -5,12 +5,50 @@ import "time"
 type User struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	Email     string    `json:"email" gorm:"unique"`
+	Handle    *string   `json:"handle" gorm:"uniqueIndex"`
 	Password  string    `json:"password"`
+	Timezone  string    `json:"timezone" gorm:"not null; default:UTC"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// EmailVerifiedAt is set once the user follows the verification link
+	// sent to Email.
+	EmailVerifiedAt *time.Time `json:"-"`
+	// PendingEmail replaces Email once the user verifies it.
+	PendingEmail *string `json:"-"`
+	Role         string  `json:"-" gorm:"not null; default:user"`
+	// DisabledAt is set while an admin has disabled the account, which
+	// keeps the user from logging in or using their tokens.
+	DisabledAt *time.Time `json:"-"`
 }
 
 type UserResponse struct {
-	ID    uint   `json:"id" gorm:"primaryKey"`
-	Email string `json:"email" gorm:"unique"`
+	ID            uint    `json:"id" gorm:"primaryKey"`
+	Email         string  `json:"email" gorm:"unique"`
+	Handle        *string `json:"handle"`
+	Timezone      string  `json:"timezone"`
+	EmailVerified bool    `json:"email_verified"`
+	Role          string  `json:"role"`
+}
+
+// AdminUserResponse is how the admin API shows a user.
+type AdminUserResponse struct {
+	ID            uint       `json:"id"`
+	Email         string     `json:"email"`
+	Handle        *string    `json:"handle"`
+	Role          string     `json:"role"`
+	EmailVerified bool       `json:"email_verified"`
+	DisabledAt    *time.Time `json:"disabled_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// EmailChangeRequest asks to move the account to Email. The current password
+// confirms it is the user asking.
+type EmailChangeRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
 }
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+const (
+	TaskChangeStatus       = "status"
+	TaskChangeDueDate      = "due_date"
+	TaskChangeComment      = "comment"
+	TaskChangeReassignment = "reassignment"
+	TaskChangeMention      = "mention"
+)
+
+type TaskWatcher struct {
+	Task      Task `gorm:"foreignKey:TaskId; constraint:onDelete:CASCADE"`
+	TaskId    uint `gorm:"primaryKey"`
+	User      User `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId    uint `gorm:"primaryKey; index"`
+	CreatedAt time.Time
+}
+
+// TaskChange is what watchers of a task are told about. From and To hold the
+// old and new value of the field; for a comment To is the comment.
+type TaskChange struct {
+	Type    string      `json:"type"`
+	TaskId  uint        `json:"task_id"`
+	ActorId uint        `json:"actor_id"`
+	From    interface{} `json:"from"`
+	To      interface{} `json:"to"`
+	At      time.Time   `json:"at"`
+}
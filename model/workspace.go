@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+)
+
+// Workspace is the tenant boundary. Every task, project and label belongs to
+// exactly one workspace, and nothing in one workspace is visible from another.
+type Workspace struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkspaceMember gives a user access to a workspace. Each workspace has one
+// owner; owners and admins manage the members.
+type WorkspaceMember struct {
+	Workspace   Workspace `gorm:"foreignKey:WorkspaceId; constraint:onDelete:CASCADE"`
+	WorkspaceId uint      `gorm:"primaryKey"`
+	User        User      `gorm:"foreignKey:UserId; constraint:onDelete:CASCADE"`
+	UserId      uint      `gorm:"primaryKey; index"`
+	Role        string    `gorm:"not null; default:member"`
+	CreatedAt   time.Time
+}
+
+type WorkspaceMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type WorkspaceResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,63 @@
+// Package notifier delivers task changes to the users watching the task.
+package notifier
+
+import (
+	"encoding/json"
+	"go-rest-api/model"
+	"log"
+	"sync"
+)
+
+// Notifier delivers one change to one watcher.
+type Notifier interface {
+	Notify(userId uint, change model.TaskChange) error
+}
+
+type Notification struct {
+	UserId uint
+	Change model.TaskChange
+}
+
+// MemorySink keeps every notification in memory. It is meant for tests and
+// local development.
+type MemorySink struct {
+	mu            sync.Mutex
+	notifications []Notification
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Notify(userId uint, change model.TaskChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = append(s.notifications, Notification{UserId: userId, Change: change})
+	return nil
+}
+
+// Notifications returns a copy of what has been delivered so far, oldest
+// first.
+func (s *MemorySink) Notifications() []Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Notification{}, s.notifications...)
+}
+
+type logNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier writes every notification to logger as JSON.
+func NewLogNotifier(logger *log.Logger) Notifier {
+	return &logNotifier{logger}
+}
+
+func (n *logNotifier) Notify(userId uint, change model.TaskChange) error {
+	body, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	n.logger.Printf("notify user %d: %s", userId, body)
+	return nil
+}
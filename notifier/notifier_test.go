@@ -0,0 +1,33 @@
+package notifier
+
+import (
+	"bytes"
+	"go-rest-api/model"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemorySink(t *testing.T) {
+	sink := NewMemorySink()
+	change := model.TaskChange{Type: model.TaskChangeStatus, TaskId: 1, From: "todo", To: "done"}
+
+	assert.NoError(t, sink.Notify(2, change))
+	assert.NoError(t, sink.Notify(3, change))
+
+	notifications := sink.Notifications()
+	assert.Equal(t, []Notification{{UserId: 2, Change: change}, {UserId: 3, Change: change}}, notifications)
+
+	notifications[0].UserId = 99
+	assert.Equal(t, uint(2), sink.Notifications()[0].UserId)
+}
+
+func TestLogNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	n := NewLogNotifier(log.New(&buf, "", 0))
+
+	err := n.Notify(2, model.TaskChange{Type: model.TaskChangeDueDate, TaskId: 1})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `notify user 2: {"type":"due_date","task_id":1,`)
+}
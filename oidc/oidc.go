@@ -0,0 +1,260 @@
+// Package oidc signs users in with an OpenID Connect provider through the
+// authorization code flow with PKCE. The provider is found by discovery from
+// its issuer, and ID tokens are verified against its published RS256 keys.
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrDiscovery      = errors.New("oidc: provider discovery failed")
+	ErrTokenExchange  = errors.New("oidc: code exchange failed")
+	ErrInvalidIDToken = errors.New("oidc: ID token is invalid")
+)
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback registered with the provider.
+	RedirectURL string
+}
+
+// Claims are what an ID token says about the user. Subject identifies them
+// at the issuer for good; the email address may change.
+type Claims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+type Provider interface {
+	// AuthCodeURL is where the user signs in. The provider sends them back
+	// to the redirect URL with a code and state.
+	AuthCodeURL(state string, nonce string, codeChallenge string) (string, error)
+	// Authenticate exchanges the code for tokens and returns the claims of
+	// the verified ID token.
+	Authenticate(code string, codeVerifier string, nonce string) (Claims, error)
+}
+
+// Metadata is the part of the discovery document the flow needs.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type provider struct {
+	config Config
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *Metadata
+	keys     map[string]*rsa.PublicKey
+}
+
+// NewProvider returns a provider for config. Discovery happens on first use,
+// so a provider that is down does not keep the server from starting.
+func NewProvider(config Config, client *http.Client) Provider {
+	return &provider{config: config, client: client}
+}
+
+func (p *provider) AuthCodeURL(state string, nonce string, codeChallenge string) (string, error) {
+	metadata, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", "openid email profile")
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+func (p *provider) Authenticate(code string, codeVerifier string, nonce string) (Claims, error) {
+	metadata, err := p.discover()
+	if err != nil {
+		return Claims{}, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	req, err := http.NewRequest(http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	res, err := p.client.Do(req)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+	defer res.Body.Close()
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+	if res.StatusCode != http.StatusOK || body.IDToken == "" {
+		return Claims{}, fmt.Errorf("%w: %s %s", ErrTokenExchange, body.Error, body.ErrorDescription)
+	}
+	return p.verify(metadata, body.IDToken, nonce)
+}
+
+// verify checks the signature, issuer, audience, expiry and nonce of an ID
+// token as OpenID Connect Core 3.1.3.7 requires.
+func (p *provider) verify(metadata Metadata, idToken string, nonce string) (Claims, error) {
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodRS256.Alg()}}
+	token, err := parser.Parse(idToken, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(metadata, kid)
+	})
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if !claims.VerifyIssuer(metadata.Issuer, true) {
+		return Claims{}, fmt.Errorf("%w: wrong issuer", ErrInvalidIDToken)
+	}
+	if !claims.VerifyAudience(p.config.ClientID, true) {
+		return Claims{}, fmt.Errorf("%w: wrong audience", ErrInvalidIDToken)
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != p.config.ClientID {
+		return Claims{}, fmt.Errorf("%w: wrong authorized party", ErrInvalidIDToken)
+	}
+	if _, ok := claims["exp"]; !ok {
+		return Claims{}, fmt.Errorf("%w: no expiry", ErrInvalidIDToken)
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return Claims{}, fmt.Errorf("%w: wrong nonce", ErrInvalidIDToken)
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return Claims{}, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+	email, _ := claims["email"].(string)
+	// Some providers send email_verified as a string.
+	emailVerified := claims["email_verified"] == true || claims["email_verified"] == "true"
+	return Claims{Issuer: metadata.Issuer, Subject: subject, Email: email, EmailVerified: emailVerified}, nil
+}
+
+func (p *provider) discover() (Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return *p.metadata, nil
+	}
+	metadata := Metadata{}
+	if err := p.getJSON(strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", &metadata); err != nil {
+		return Metadata{}, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	if metadata.Issuer != p.config.Issuer {
+		return Metadata{}, fmt.Errorf("%w: issuer %q does not match %q", ErrDiscovery, metadata.Issuer, p.config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return Metadata{}, fmt.Errorf("%w: missing endpoints", ErrDiscovery)
+	}
+	p.metadata = &metadata
+	return metadata, nil
+}
+
+// key returns the signing key kid. The key set is fetched again when kid is
+// unknown, which is how providers roll their keys.
+func (p *provider) key(metadata Metadata, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(metadata.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	p.keys = keys
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (p *provider) getJSON(url string, v interface{}) error {
+	res, err := p.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// NewCodeVerifier returns a PKCE code verifier of 256 random bits.
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge is the S256 challenge for verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,106 @@
+package oidc
+
+import (
+	"errors"
+	"go-rest-api/oidc/oidctest"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestProvider(t *testing.T) (*oidctest.Provider, Provider) {
+	mock := oidctest.NewProvider("client", "secret")
+	t.Cleanup(mock.Close)
+	mock.SetUser(oidctest.User{Subject: "alice", Email: "alice@test.com", EmailVerified: true})
+	p := NewProvider(Config{
+		Issuer:       mock.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/oidc/test/callback",
+	}, http.DefaultClient)
+	return mock, p
+}
+
+func TestAuthenticate(t *testing.T) {
+	mock, p := newTestProvider(t)
+	verifier, _ := NewCodeVerifier()
+
+	authURL, err := p.AuthCodeURL("state-1", "nonce-1", CodeChallenge(verifier))
+	assert.NoError(t, err)
+	code, state, err := mock.SignIn(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "state-1", state)
+
+	claims, err := p.Authenticate(code, verifier, "nonce-1")
+	assert.NoError(t, err)
+	assert.Equal(t, Claims{Issuer: mock.Issuer(), Subject: "alice", Email: "alice@test.com", EmailVerified: true}, claims)
+}
+
+func TestAuthenticate_WrongVerifier(t *testing.T) {
+	mock, p := newTestProvider(t)
+	verifier, _ := NewCodeVerifier()
+	other, _ := NewCodeVerifier()
+
+	authURL, _ := p.AuthCodeURL("state-1", "nonce-1", CodeChallenge(verifier))
+	code, _, _ := mock.SignIn(authURL)
+
+	_, err := p.Authenticate(code, other, "nonce-1")
+	assert.True(t, errors.Is(err, ErrTokenExchange), "got %v", err)
+}
+
+func TestAuthenticate_WrongNonce(t *testing.T) {
+	mock, p := newTestProvider(t)
+	verifier, _ := NewCodeVerifier()
+
+	authURL, _ := p.AuthCodeURL("state-1", "nonce-1", CodeChallenge(verifier))
+	code, _, _ := mock.SignIn(authURL)
+
+	_, err := p.Authenticate(code, verifier, "nonce-2")
+	assert.True(t, errors.Is(err, ErrInvalidIDToken), "got %v", err)
+}
+
+func TestVerify(t *testing.T) {
+	mock, p := newTestProvider(t)
+	metadata, err := p.(*provider).discover()
+	assert.NoError(t, err)
+	now := time.Now()
+	valid := jwt.MapClaims{"iss": mock.Issuer(), "sub": "alice", "aud": "client", "exp": now.Add(time.Hour).Unix(), "nonce": "n"}
+
+	with := func(key string, value interface{}) jwt.MapClaims {
+		claims := jwt.MapClaims{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[key] = value
+		return claims
+	}
+	tokens := map[string]jwt.MapClaims{
+		"issuer":   with("iss", "https://other.example.com"),
+		"audience": with("aud", "other-client"),
+		"expired":  with("exp", now.Add(-time.Minute).Unix()),
+		"azp":      with("azp", "other-client"),
+		"subject":  with("sub", ""),
+	}
+	_, err = p.(*provider).verify(metadata, mock.SignIDToken(valid), "n")
+	assert.NoError(t, err)
+	for name, claims := range tokens {
+		_, err := p.(*provider).verify(metadata, mock.SignIDToken(claims), "n")
+		assert.True(t, errors.Is(err, ErrInvalidIDToken), "%s: got %v", name, err)
+	}
+
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, valid).SignedString([]byte("client"))
+	_, err = p.(*provider).verify(metadata, unsigned, "n")
+	assert.True(t, errors.Is(err, ErrInvalidIDToken), "HS256: got %v", err)
+}
+
+func TestDiscovery_WrongIssuer(t *testing.T) {
+	mock := oidctest.NewProvider("client", "secret")
+	defer mock.Close()
+	p := NewProvider(Config{Issuer: mock.Issuer() + "/other", ClientID: "client"}, http.DefaultClient)
+
+	_, err := p.AuthCodeURL("s", "n", "c")
+	assert.True(t, errors.Is(err, ErrDiscovery), "got %v", err)
+}
@@ -0,0 +1,368 @@
+// Package quickadd turns a free-text task line such as
+// "Call dentist tomorrow 3pm #personal !high every monday" into task fields.
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+type Result struct {
+	Title   string
+	DueDate *time.Time
+	// AllDay is true when a date was given without a time of day. DueDate is
+	// then midnight at the start of that day.
+	AllDay   bool
+	Labels   []string
+	Priority string
+	// Recurrence is an RFC 5545 RRULE value such as "FREQ=WEEKLY;BYDAY=MO".
+	Recurrence string
+}
+
+var (
+	clock12Pattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	clock24Pattern = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	isoDatePattern = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	dayPattern     = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var rruleDays = map[time.Weekday]string{
+	time.Sunday: "SU", time.Monday: "MO", time.Tuesday: "TU", time.Wednesday: "WE",
+	time.Thursday: "TH", time.Friday: "FR", time.Saturday: "SA",
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var priorities = map[string]string{
+	"!high": PriorityHigh, "!1": PriorityHigh,
+	"!medium": PriorityMedium, "!med": PriorityMedium, "!2": PriorityMedium,
+	"!low": PriorityLow, "!3": PriorityLow,
+}
+
+// connectors are dropped when they introduce a date or time, as in "at 3pm".
+var connectors = map[string]bool{"at": true, "on": true, "by": true, "due": true}
+
+type clock struct {
+	hour, minute int
+}
+
+type parser struct {
+	tokens []string
+	now    time.Time
+	date   *time.Time
+	clock  *clock
+	byDay  []time.Weekday
+	result Result
+	title  []string
+}
+
+// Parse reads text relative to now. Dates and times are interpreted in the
+// location of now. Words that are not recognised make up the title, in order.
+func Parse(text string, now time.Time) Result {
+	p := parser{tokens: strings.Fields(text), now: now}
+	return p.parse()
+}
+
+func (p *parser) parse() Result {
+	for i := 0; i < len(p.tokens); {
+		i += p.consume(i)
+	}
+	p.result.Title = strings.Join(p.title, " ")
+	p.resolveDueDate()
+	return p.result
+}
+
+// consume handles the token at i and reports how many tokens it used.
+func (p *parser) consume(i int) int {
+	token := p.tokens[i]
+	lower := strings.ToLower(token)
+
+	if strings.HasPrefix(token, "#") && len(token) > 1 {
+		p.result.Labels = append(p.result.Labels, token[1:])
+		return 1
+	}
+	if priority, ok := priorities[lower]; ok && p.result.Priority == "" {
+		p.result.Priority = priority
+		return 1
+	}
+	if lower == "every" && p.result.Recurrence == "" {
+		if n := p.recurrence(i + 1); n > 0 {
+			return n + 1
+		}
+	}
+	if connectors[lower] {
+		if n := p.dateOrClock(i + 1); n > 0 {
+			return n + 1
+		}
+	}
+	if n := p.dateOrClock(i); n > 0 {
+		return n
+	}
+	p.title = append(p.title, token)
+	return 1
+}
+
+func (p *parser) dateOrClock(i int) int {
+	if p.date == nil {
+		if date, n := p.parseDate(i); n > 0 {
+			p.date = &date
+			return n
+		}
+	}
+	if p.clock == nil {
+		if c, n := p.parseClock(i); n > 0 {
+			p.clock = &c
+			return n
+		}
+	}
+	return 0
+}
+
+func (p *parser) word(i int) string {
+	if i < 0 || i >= len(p.tokens) {
+		return ""
+	}
+	return strings.TrimRight(strings.ToLower(p.tokens[i]), ",.")
+}
+
+func (p *parser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+// parseDate recognises today, tomorrow, weekday names, "next week|month|<weekday>",
+// "in N days|weeks|months", ISO dates and "may 3" or "3 may".
+func (p *parser) parseDate(i int) (time.Time, int) {
+	today := p.today()
+	word := p.word(i)
+	switch word {
+	case "today":
+		return today, 1
+	case "tomorrow", "tmr", "tmrw":
+		return today.AddDate(0, 0, 1), 1
+	case "next":
+		next := p.word(i + 1)
+		switch next {
+		case "week":
+			return nextWeekday(today, time.Monday), 2
+		case "month":
+			return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), 2
+		}
+		if weekday, ok := weekdays[next]; ok {
+			return nextWeekday(today, weekday), 2
+		}
+	case "in":
+		n, err := strconv.Atoi(p.word(i + 1))
+		if err != nil || n <= 0 {
+			break
+		}
+		switch strings.TrimSuffix(p.word(i+2), "s") {
+		case "day":
+			return today.AddDate(0, 0, n), 3
+		case "week":
+			return today.AddDate(0, 0, 7*n), 3
+		case "month":
+			return today.AddDate(0, n, 0), 3
+		}
+	}
+	if weekday, ok := weekdays[word]; ok {
+		return nextWeekday(today, weekday), 1
+	}
+	if m := isoDatePattern.FindStringSubmatch(word); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, today.Location())
+		if date.Month() == time.Month(month) && date.Day() == day {
+			return date, 1
+		}
+	}
+	if month, ok := months[word]; ok {
+		if day, ok := dayOfMonth(p.word(i + 1)); ok {
+			return p.upcomingDate(month, day), 2
+		}
+	}
+	if day, ok := dayOfMonth(word); ok {
+		if month, ok := months[p.word(i+1)]; ok {
+			return p.upcomingDate(month, day), 2
+		}
+	}
+	return time.Time{}, 0
+}
+
+// parseClock recognises 3pm, 3:30pm, "3 pm", 15:00 and noon.
+func (p *parser) parseClock(i int) (clock, int) {
+	word := p.word(i)
+	if word == "noon" {
+		return clock{12, 0}, 1
+	}
+	n := 1
+	if next := p.word(i + 1); (next == "am" || next == "pm") && dayPattern.MatchString(word) {
+		word += next
+		n = 2
+	}
+	if m := clock12Pattern.FindStringSubmatch(word); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour < 1 || hour > 12 || minute > 59 {
+			return clock{}, 0
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+		return clock{hour, minute}, n
+	}
+	if m := clock24Pattern.FindStringSubmatch(word); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour > 23 || minute > 59 {
+			return clock{}, 0
+		}
+		return clock{hour, minute}, 1
+	}
+	return clock{}, 0
+}
+
+// recurrence recognises what follows "every": day, weekday, week, month,
+// year, "other <unit>", "N <units>" and weekday names joined by "and" or
+// commas.
+func (p *parser) recurrence(i int) int {
+	word := p.word(i)
+	interval := 1
+	n := 0
+	switch {
+	case word == "other":
+		interval, n = 2, 1
+	case word != "":
+		if value, err := strconv.Atoi(word); err == nil && value > 0 {
+			interval, n = value, 1
+		}
+	}
+	unit := strings.TrimSuffix(p.word(i+n), "s")
+	freq := map[string]string{"day": "DAILY", "week": "WEEKLY", "month": "MONTHLY", "year": "YEARLY"}[unit]
+	if freq != "" {
+		p.result.Recurrence = "FREQ=" + freq
+		if interval > 1 {
+			p.result.Recurrence += ";INTERVAL=" + strconv.Itoa(interval)
+		}
+		return n + 1
+	}
+	if n > 0 {
+		return 0
+	}
+	if word == "weekday" || word == "weekdays" {
+		p.byDay = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		p.result.Recurrence = "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+		return 1
+	}
+
+	var days []string
+	for j := i; j < len(p.tokens); j++ {
+		word := p.word(j)
+		if weekday, ok := weekdays[word]; ok {
+			p.byDay = append(p.byDay, weekday)
+			days = append(days, rruleDays[weekday])
+			n = j - i + 1
+			continue
+		}
+		if word != "and" || len(days) == 0 {
+			break
+		}
+	}
+	if len(days) == 0 {
+		return 0
+	}
+	p.result.Recurrence = "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
+	return n
+}
+
+func (p *parser) resolveDueDate() {
+	if p.date == nil && p.clock == nil && len(p.byDay) > 0 {
+		first := nextOrSameWeekday(p.today(), p.byDay[0])
+		for _, weekday := range p.byDay[1:] {
+			if next := nextOrSameWeekday(p.today(), weekday); next.Before(first) {
+				first = next
+			}
+		}
+		p.date = &first
+	}
+	switch {
+	case p.date != nil && p.clock != nil:
+		due := time.Date(p.date.Year(), p.date.Month(), p.date.Day(), p.clock.hour, p.clock.minute, 0, 0, p.date.Location())
+		p.result.DueDate = &due
+	case p.date != nil:
+		p.result.DueDate = p.date
+		p.result.AllDay = true
+	case p.clock != nil:
+		today := p.today()
+		due := time.Date(today.Year(), today.Month(), today.Day(), p.clock.hour, p.clock.minute, 0, 0, today.Location())
+		if due.Before(p.now) {
+			due = due.AddDate(0, 0, 1)
+		}
+		p.result.DueDate = &due
+	}
+}
+
+// upcomingDate is the next month/day on or after today, in this year or the
+// next.
+func (p *parser) upcomingDate(month time.Month, day int) time.Time {
+	today := p.today()
+	date := time.Date(today.Year(), month, day, 0, 0, 0, 0, today.Location())
+	if date.Before(today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return date
+}
+
+func dayOfMonth(word string) (int, bool) {
+	m := dayPattern.FindStringSubmatch(word)
+	if m == nil {
+		return 0, false
+	}
+	day, _ := strconv.Atoi(m[1])
+	return day, day >= 1 && day <= 31
+}
+
+// nextWeekday is the first given weekday strictly after day.
+func nextWeekday(day time.Time, weekday time.Weekday) time.Time {
+	days := (int(weekday) - int(day.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return day.AddDate(0, 0, days)
+}
+
+func nextOrSameWeekday(day time.Time, weekday time.Weekday) time.Time {
+	return day.AddDate(0, 0, (int(weekday)-int(day.Weekday())+7)%7)
+}
@@ -0,0 +1,141 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("JST", 9*60*60)
+	// 2024-05-15 is a Wednesday.
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, loc)
+	at := func(year int, month time.Month, day, hour, minute int) *time.Time {
+		due := time.Date(year, month, day, hour, minute, 0, 0, loc)
+		return &due
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want Result
+	}{
+		{
+			name: "full example",
+			text: "Call dentist tomorrow 3pm #personal !high every monday",
+			want: Result{Title: "Call dentist", DueDate: at(2024, 5, 16, 15, 0), Labels: []string{"personal"}, Priority: PriorityHigh, Recurrence: "FREQ=WEEKLY;BYDAY=MO"},
+		},
+		{
+			name: "title only",
+			text: "  Buy   milk ",
+			want: Result{Title: "Buy milk"},
+		},
+		{
+			name: "today is all day",
+			text: "Pay rent today",
+			want: Result{Title: "Pay rent", DueDate: at(2024, 5, 15, 0, 0), AllDay: true},
+		},
+		{
+			name: "connector before time is dropped",
+			text: "Standup at 9:30am",
+			want: Result{Title: "Standup", DueDate: at(2024, 5, 16, 9, 30)},
+		},
+		{
+			name: "time later today",
+			text: "Lunch noon",
+			want: Result{Title: "Lunch", DueDate: at(2024, 5, 15, 12, 0)},
+		},
+		{
+			name: "24 hour clock with spaced am/pm",
+			text: "Deploy friday 18:00 then 3 pm",
+			want: Result{Title: "Deploy then 3 pm", DueDate: at(2024, 5, 17, 18, 0)},
+		},
+		{
+			name: "spaced pm",
+			text: "Gym 7 pm",
+			want: Result{Title: "Gym", DueDate: at(2024, 5, 15, 19, 0)},
+		},
+		{
+			name: "weekday names mean the next one",
+			text: "Review on wed",
+			want: Result{Title: "Review", DueDate: at(2024, 5, 22, 0, 0), AllDay: true},
+		},
+		{
+			name: "next week",
+			text: "Plan sprint next week",
+			want: Result{Title: "Plan sprint", DueDate: at(2024, 5, 20, 0, 0), AllDay: true},
+		},
+		{
+			name: "in n days",
+			text: "Follow up in 3 days",
+			want: Result{Title: "Follow up", DueDate: at(2024, 5, 18, 0, 0), AllDay: true},
+		},
+		{
+			name: "in without a count stays in the title",
+			text: "Check in with Sam",
+			want: Result{Title: "Check in with Sam"},
+		},
+		{
+			name: "iso date",
+			text: "File taxes 2025-03-15 #finance #home",
+			want: Result{Title: "File taxes", DueDate: at(2025, 3, 15, 0, 0), Labels: []string{"finance", "home"}, AllDay: true},
+		},
+		{
+			name: "month and day rolls over to next year",
+			text: "Renew passport by jan 3rd",
+			want: Result{Title: "Renew passport", DueDate: at(2025, 1, 3, 0, 0), AllDay: true},
+		},
+		{
+			name: "day and month",
+			text: "Birthday 20 May 8pm",
+			want: Result{Title: "Birthday", DueDate: at(2024, 5, 20, 20, 0)},
+		},
+		{
+			name: "numeric priority and only the first is used",
+			text: "Fix bug !1 !low",
+			want: Result{Title: "Fix bug !low", Priority: PriorityHigh},
+		},
+		{
+			name: "recurrence sets the first due date",
+			text: "Water plants every tue and fri",
+			want: Result{Title: "Water plants", DueDate: at(2024, 5, 17, 0, 0), AllDay: true, Recurrence: "FREQ=WEEKLY;BYDAY=TU,FR"},
+		},
+		{
+			name: "recurrence includes today",
+			text: "Team sync every wednesday",
+			want: Result{Title: "Team sync", DueDate: at(2024, 5, 15, 0, 0), AllDay: true, Recurrence: "FREQ=WEEKLY;BYDAY=WE"},
+		},
+		{
+			name: "recurrence interval",
+			text: "Backup every 2 weeks",
+			want: Result{Title: "Backup", Recurrence: "FREQ=WEEKLY;INTERVAL=2"},
+		},
+		{
+			name: "recurrence every other month",
+			text: "Haircut every other month !low",
+			want: Result{Title: "Haircut", Priority: PriorityLow, Recurrence: "FREQ=MONTHLY;INTERVAL=2"},
+		},
+		{
+			name: "recurrence on weekdays at a time",
+			text: "Stretch every weekday 8am",
+			want: Result{Title: "Stretch", DueDate: at(2024, 5, 16, 8, 0), Recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		},
+		{
+			name: "every without a unit stays in the title",
+			text: "Read every article",
+			want: Result{Title: "Read every article"},
+		},
+		{
+			name: "invalid values stay in the title",
+			text: "Meet 13pm 2024-02-30 #",
+			want: Result{Title: "Meet 13pm 2024-02-30 #"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Parse(tt.text, now))
+		})
+	}
+}
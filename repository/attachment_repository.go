@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+)
+
+// IAttachmentRepository does not check access to the task. Callers load it
+// with ITaskRepository.GetAccessible first.
+type IAttachmentRepository interface {
+	Create(attachment *model.Attachment, maxBytes int64) error
+	GetAll(attachments *[]model.Attachment, taskId uint) error
+	GetByID(attachment *model.Attachment, taskId uint, attachmentId uint) error
+	Delete(userId uint, taskId uint, attachmentId uint) error
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) IAttachmentRepository {
+	return &attachmentRepository{db}
+}
+
+// Create fails with a *model.QuotaError if the attachment would take the
+// uploader past maxBytes. A maxBytes of 0 means unlimited.
+func (ar *attachmentRepository) Create(attachment *model.Attachment, maxBytes int64) error {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkAttachmentQuota(tx, attachment.UserId, attachment.Size, maxBytes); err != nil {
+			return err
+		}
+		return tx.Create(attachment).Error
+	})
+}
+
+// GetAll leaves out the file contents.
+func (ar *attachmentRepository) GetAll(attachments *[]model.Attachment, taskId uint) error {
+	if err := ar.db.Omit("data").Where("task_id = ?", taskId).Order("created_at, id").Find(attachments).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ar *attachmentRepository) GetByID(attachment *model.Attachment, taskId uint, attachmentId uint) error {
+	if err := ar.db.Where("task_id = ?", taskId).First(attachment, attachmentId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete only removes attachments the user uploaded.
+func (ar *attachmentRepository) Delete(userId uint, taskId uint, attachmentId uint) error {
+	result := ar.db.Where("id = ? AND task_id = ? AND user_id = ?", attachmentId, taskId, userId).Delete(&model.Attachment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupAttachmentTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testattachment.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	seedWorkspace(db, USER_ID)
+	return db
+}
+
+func TestAttachments(t *testing.T) {
+	db := setupAttachmentTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupAttachmentTable(db)
+	defer util.CleanupTaskTable(db)
+
+	ar := NewAttachmentRepository(db)
+	qr := NewQuotaRepository(db)
+
+	task := model.Task{Title: "Attached", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	db.Create(&task)
+
+	first := model.Attachment{Name: "a.txt", ContentType: "text/plain", Size: 6, Data: []byte("first!"), TaskId: task.ID, UserId: uint(USER_ID)}
+	if err := ar.Create(&first, 10); err != nil {
+		t.Fatalf("Create attachment failed: %v", err)
+	}
+	second := model.Attachment{Name: "b.txt", ContentType: "text/plain", Size: 6, Data: []byte("second"), TaskId: task.ID, UserId: uint(USER_ID)}
+	if err := ar.Create(&second, 10); !errors.Is(err, model.ErrQuotaExceeded) {
+		t.Errorf("Expected the storage quota to be exceeded, got %v", err)
+	}
+
+	var total int64
+	if err := qr.SumAttachmentSizes(&total, uint(USER_ID)); err != nil {
+		t.Fatalf("SumAttachmentSizes failed: %v", err)
+	}
+	if total != 6 {
+		t.Errorf("Expected 6 bytes, got %d", total)
+	}
+
+	var attachments []model.Attachment
+	if err := ar.GetAll(&attachments, task.ID); err != nil {
+		t.Fatalf("GetAll attachments failed: %v", err)
+	}
+	if len(attachments) != 1 || attachments[0].Data != nil {
+		t.Errorf("Expected one attachment without contents, got %v", attachments)
+	}
+	var stored model.Attachment
+	if err := ar.GetByID(&stored, task.ID, first.ID); err != nil {
+		t.Fatalf("GetByID attachment failed: %v", err)
+	}
+	if string(stored.Data) != "first!" {
+		t.Errorf("Expected the contents, got %q", stored.Data)
+	}
+
+	if err := ar.Delete(uint(USER_ID)+1, task.ID, first.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected only the uploader to delete, got %v", err)
+	}
+	if err := ar.Delete(uint(USER_ID), task.ID, first.ID); err != nil {
+		t.Fatalf("Delete attachment failed: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+)
+
+type IAuditRepository interface {
+	Create(entry *model.AuditEntry) error
+	SetOutcome(entry *model.AuditEntry) error
+	GetAll(entries *[]model.AuditEntry, limit int) error
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) IAuditRepository {
+	return &auditRepository{db}
+}
+
+func (ar *auditRepository) Create(entry *model.AuditEntry) error {
+	if err := ar.db.Create(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetOutcome stores the Outcome and Error of entry.
+func (ar *auditRepository) SetOutcome(entry *model.AuditEntry) error {
+	if err := ar.db.Model(entry).Select("outcome", "error").Updates(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetAll loads the latest limit entries, newest first.
+func (ar *auditRepository) GetAll(entries *[]model.AuditEntry, limit int) error {
+	if err := ar.db.Order("created_at DESC, id DESC").Limit(limit).Find(entries).Error; err != nil {
+		return err
+	}
+	return nil
+}
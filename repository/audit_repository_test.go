@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+)
+
+func TestGetAllAuditEntries(t *testing.T) {
+	db := util.NewTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupAuditTable(db)
+
+	ar := NewAuditRepository(db)
+
+	target := uint(2)
+	ar.Create(&model.AuditEntry{ActorId: 1, Action: model.AuditActionListUsers})
+	ar.Create(&model.AuditEntry{ActorId: 1, Action: model.AuditActionDisableUser, TargetUserId: &target})
+	ar.Create(&model.AuditEntry{ActorId: 1, Action: model.AuditActionEnableUser, TargetUserId: &target})
+
+	var entries []model.AuditEntry
+	if err := ar.GetAll(&entries, 2); err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Action != model.AuditActionEnableUser {
+		t.Errorf("Expected the newest entry first, got %s", entries[0].Action)
+	}
+}
+
+func TestSetAuditOutcome(t *testing.T) {
+	db := util.NewTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupAuditTable(db)
+
+	ar := NewAuditRepository(db)
+
+	entry := model.AuditEntry{ActorId: 1, Action: model.AuditActionListUsers}
+	if err := ar.Create(&entry); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if entry.Outcome != model.AuditOutcomePending {
+		t.Errorf("Expected a new entry to be pending, got %s", entry.Outcome)
+	}
+	entry.Outcome = model.AuditOutcomeFailed
+	entry.Error = "database is down"
+	if err := ar.SetOutcome(&entry); err != nil {
+		t.Fatalf("SetOutcome failed: %v", err)
+	}
+
+	var entries []model.AuditEntry
+	ar.GetAll(&entries, 1)
+	if len(entries) != 1 || entries[0].Outcome != model.AuditOutcomeFailed || entries[0].Error != "database is down" {
+		t.Errorf("Expected the failed outcome to be stored, got %+v", entries)
+	}
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+)
+
+type ICommentRepository interface {
+	Create(comment *model.Comment) error
+	GetAll(comments *[]model.Comment, taskId uint) error
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) ICommentRepository {
+	return &commentRepository{db}
+}
+
+func (cr *commentRepository) Create(comment *model.Comment) error {
+	if err := cr.db.Create(comment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetAll does not check access. Callers load the task with
+// ITaskRepository.GetAccessible first.
+func (cr *commentRepository) GetAll(comments *[]model.Comment, taskId uint) error {
+	if err := cr.db.Where("task_id = ?", taskId).Order("created_at, id").Find(comments).Error; err != nil {
+		return err
+	}
+	return nil
+}
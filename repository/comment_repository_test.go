@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupCommentTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testcomment.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	seedWorkspace(db, USER_ID)
+	return db
+}
+
+func TestComments(t *testing.T) {
+	db := setupCommentTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupCommentTable(db)
+	defer util.CleanupTaskTable(db)
+
+	cr := NewCommentRepository(db)
+
+	task := model.Task{Title: "Commented", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	db.Create(&task)
+
+	first := model.Comment{Body: "first", TaskId: task.ID, UserId: uint(USER_ID)}
+	if err := cr.Create(&first); err != nil {
+		t.Fatalf("Create comment failed: %v", err)
+	}
+	cr.Create(&model.Comment{Body: "second", TaskId: task.ID, UserId: uint(USER_ID)})
+
+	var comments []model.Comment
+	if err := cr.GetAll(&comments, task.ID); err != nil {
+		t.Fatalf("GetAll comments failed: %v", err)
+	}
+	if len(comments) != 2 || comments[0].Body != "first" {
+		t.Errorf("Expected both comments oldest first, got %v", comments)
+	}
+}
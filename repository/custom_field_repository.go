@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type ICustomFieldRepository interface {
+	Create(field *model.CustomField) error
+	GetByProject(fields *[]model.CustomField, projectId uint) error
+	GetByIDs(fields *[]model.CustomField, userId uint, workspaceId uint, fieldIds []uint) error
+	Delete(projectId uint, fieldId uint) error
+}
+
+type customFieldRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomFieldRepository(db *gorm.DB) ICustomFieldRepository {
+	return &customFieldRepository{db}
+}
+
+func (cr *customFieldRepository) Create(field *model.CustomField) error {
+	if err := cr.db.Create(field).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByProject does not check access. Callers check that the user is a
+// member of the project first.
+func (cr *customFieldRepository) GetByProject(fields *[]model.CustomField, projectId uint) error {
+	if err := cr.db.Where("project_id = ?", projectId).Order("id").Find(fields).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByIDs loads the fields among fieldIds that belong to projects of the
+// workspace the user is a member of.
+func (cr *customFieldRepository) GetByIDs(fields *[]model.CustomField, userId uint, workspaceId uint, fieldIds []uint) error {
+	err := cr.db.Joins("JOIN project_members ON project_members.project_id = custom_fields.project_id").
+		Joins("JOIN projects ON projects.id = custom_fields.project_id").
+		Where("project_members.user_id = ? AND projects.workspace_id = ? AND custom_fields.id IN ?", userId, workspaceId, fieldIds).
+		Order("custom_fields.id").
+		Find(fields).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete removes the field and its values from the tasks of the project.
+func (cr *customFieldRepository) Delete(projectId uint, fieldId uint) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("project_id = ? AND id = ?", projectId, fieldId).Delete(&model.CustomField{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&model.Task{}).
+			Where("project_id = ?", projectId).
+			Update("custom_fields", gorm.Expr("custom_fields - ?", strconv.FormatUint(uint64(fieldId), 10))).Error
+	})
+}
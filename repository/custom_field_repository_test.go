@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"strconv"
+	"testing"
+)
+
+func TestCustomFieldQuery(t *testing.T) {
+	db := setupProjectTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+	defer util.CleanupProjectTables(db)
+
+	pr := NewProjectRepository(db)
+	fr := NewCustomFieldRepository(db)
+	tr := NewTaskRepository(db)
+
+	project := model.Project{Name: "Launch", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	pr.Create(&project)
+	estimate := model.CustomField{Name: "Estimate", Type: model.CustomFieldNumber, ProjectId: project.ID}
+	platforms := model.CustomField{Name: "Platforms", Type: model.CustomFieldMultiSelect, Options: []string{"ios", "web"}, ProjectId: project.ID}
+	fr.Create(&estimate)
+	fr.Create(&platforms)
+	estimateKey := strconv.FormatUint(uint64(estimate.ID), 10)
+	platformsKey := strconv.FormatUint(uint64(platforms.ID), 10)
+
+	for _, values := range []model.CustomFieldValues{
+		{estimateKey: float64(8), platformsKey: []interface{}{"ios"}},
+		{estimateKey: float64(10), platformsKey: []interface{}{"ios", "web"}},
+		{platformsKey: []interface{}{"web"}},
+	} {
+		tr.Create(&model.Task{Title: "Task", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), ProjectId: &project.ID, CustomFields: values}, 0)
+	}
+
+	var fields []model.CustomField
+	if err := fr.GetByIDs(&fields, uint(OTHER_USER_ID), uint(WORKSPACE_ID), []uint{estimate.ID}); err != nil || len(fields) != 0 {
+		t.Fatalf("Expected no fields for a non-member, got %v, %v", fields, err)
+	}
+
+	var tasks []model.Task
+	query := model.TaskQuery{
+		Filters: []model.CustomFieldFilter{{FieldId: platforms.ID, Op: model.CustomFieldOpEq, Value: "ios", Type: model.CustomFieldMultiSelect}},
+		Sort:    &model.CustomFieldSort{FieldId: estimate.ID, Desc: true, Type: model.CustomFieldNumber},
+	}
+	if err := tr.GetAll(&tasks, uint(USER_ID), uint(WORKSPACE_ID), query); err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].CustomFields[estimateKey] != float64(10) {
+		t.Fatalf("Expected 2 ios tasks largest estimate first, got %v", tasks)
+	}
+
+	tasks = nil
+	query = model.TaskQuery{
+		Filters: []model.CustomFieldFilter{{FieldId: estimate.ID, Op: model.CustomFieldOpGte, Value: "9", Type: model.CustomFieldNumber}},
+	}
+	tr.GetAll(&tasks, uint(USER_ID), uint(WORKSPACE_ID), query)
+	if len(tasks) != 1 {
+		t.Fatalf("Expected 1 task with an estimate of at least 9, got %v", tasks)
+	}
+
+	if err := fr.Delete(project.ID, estimate.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	var task model.Task
+	tr.GetByID(&task, uint(USER_ID), uint(WORKSPACE_ID), tasks[0].ID)
+	if _, ok := task.CustomFields[estimateKey]; ok {
+		t.Errorf("Expected the values of the deleted field to be removed, got %v", task.CustomFields)
+	}
+}
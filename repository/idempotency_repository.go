@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IIdempotencyRepository interface {
+	Reserve(record *model.IdempotencyRecord) (bool, error)
+	GetByKey(record *model.IdempotencyRecord, userId uint, key string) error
+	Complete(record *model.IdempotencyRecord) error
+	Delete(userId uint, key string) error
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IIdempotencyRepository {
+	return &idempotencyRepository{db}
+}
+
+// Reserve stores record unless a live record with the same user and key
+// exists, and reports whether it did. Expired records are replaced.
+func (ir *idempotencyRepository) Reserve(record *model.IdempotencyRecord) (bool, error) {
+	var reserved bool
+	err := ir.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND key = ? AND expires_at <= ?", record.UserId, record.Key, time.Now()).Delete(&model.IdempotencyRecord{}).Error; err != nil {
+			return err
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return result.Error
+		}
+		reserved = result.RowsAffected == 1
+		return nil
+	})
+	return reserved, err
+}
+
+func (ir *idempotencyRepository) GetByKey(record *model.IdempotencyRecord, userId uint, key string) error {
+	if err := ir.db.Where("user_id = ? AND key = ?", userId, key).First(record).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ir *idempotencyRepository) Complete(record *model.IdempotencyRecord) error {
+	if err := ir.db.Model(record).Select("status_code", "content_type", "response_body").Updates(record).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ir *idempotencyRepository) Delete(userId uint, key string) error {
+	if err := ir.db.Where("user_id = ? AND key = ?", userId, key).Delete(&model.IdempotencyRecord{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
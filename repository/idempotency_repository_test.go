@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+	"time"
+)
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	db := util.NewTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupIdempotencyTable(db)
+
+	ir := NewIdempotencyRepository(db)
+
+	now := time.Now()
+	first := model.IdempotencyRecord{Key: "key-1", UserId: uint(USER_ID), RequestHash: "a", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	reserved, err := ir.Reserve(&first)
+	if err != nil || !reserved {
+		t.Fatalf("Expected first reservation, got %v %v", reserved, err)
+	}
+
+	second := model.IdempotencyRecord{Key: "key-1", UserId: uint(USER_ID), RequestHash: "b", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	reserved, err = ir.Reserve(&second)
+	if err != nil || reserved {
+		t.Fatalf("Expected second reservation to be rejected, got %v %v", reserved, err)
+	}
+}
+
+func TestReserveExpiredIdempotencyKey(t *testing.T) {
+	db := util.NewTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupIdempotencyTable(db)
+
+	ir := NewIdempotencyRepository(db)
+
+	now := time.Now()
+	db.Create(&model.IdempotencyRecord{Key: "key-1", UserId: uint(USER_ID), RequestHash: "a", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)})
+
+	record := model.IdempotencyRecord{Key: "key-1", UserId: uint(USER_ID), RequestHash: "b", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	reserved, err := ir.Reserve(&record)
+	if err != nil || !reserved {
+		t.Fatalf("Expected expired key to be reserved again, got %v %v", reserved, err)
+	}
+
+	var stored model.IdempotencyRecord
+	if err := ir.GetByKey(&stored, uint(USER_ID), "key-1"); err != nil {
+		t.Fatalf("GetByKey failed: %v", err)
+	}
+	if stored.RequestHash != "b" {
+		t.Errorf("Expected request hash b, got %s", stored.RequestHash)
+	}
+}
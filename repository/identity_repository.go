@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+)
+
+type IIdentityRepository interface {
+	GetBySubject(identity *model.Identity, issuer string, subject string) error
+	Create(identity *model.Identity) error
+	CreateUser(user *model.User, identity *model.Identity) error
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IIdentityRepository {
+	return &identityRepository{db}
+}
+
+func (ir *identityRepository) GetBySubject(identity *model.Identity, issuer string, subject string) error {
+	if err := ir.db.Where("issuer = ? AND subject = ?", issuer, subject).First(identity).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ir *identityRepository) Create(identity *model.Identity) error {
+	if err := ir.db.Create(identity).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateUser signs up a user who arrives through an identity provider, with
+// a personal workspace like any other new user, and links the identity.
+func (ir *identityRepository) CreateUser(user *model.User, identity *model.Identity) error {
+	return ir.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if err := createWorkspace(tx, &model.Workspace{Name: personalWorkspaceName}, user.ID); err != nil {
+			return err
+		}
+		identity.UserId = user.ID
+		return tx.Create(identity).Error
+	})
+}
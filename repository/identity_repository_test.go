@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+)
+
+func TestIdentityCreateUser(t *testing.T) {
+	db := util.NewTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupUserTabls(db)
+	defer util.CleanupIdentityTable(db)
+
+	ir := NewIdentityRepository(db)
+
+	user := model.User{Email: "user1@testidentity.com", Timezone: "UTC"}
+	identity := model.Identity{Issuer: "https://idp.test", Subject: "alice", Email: user.Email}
+	if err := ir.CreateUser(&user, &identity); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+
+	var linked model.Identity
+	if err := ir.GetBySubject(&linked, "https://idp.test", "alice"); err != nil {
+		t.Fatalf("GetBySubject failed: %v", err)
+	}
+	if linked.UserId != user.ID {
+		t.Errorf("Expected UserId %d, got %d", user.ID, linked.UserId)
+	}
+	var count int64
+	db.Model(&model.WorkspaceMember{}).Where("user_id = ?", user.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected a personal workspace, got %d memberships", count)
+	}
+	if err := ir.GetBySubject(&model.Identity{}, "https://other.test", "alice"); err == nil {
+		t.Errorf("Expected no identity for another issuer")
+	}
+}
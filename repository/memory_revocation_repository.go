@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"sync"
+	"time"
+)
+
+type memoryRevocationRepository struct {
+	mu       sync.Mutex
+	tokens   map[string]time.Time
+	sessions map[string]time.Time
+	users    map[uint]uint
+}
+
+// NewMemoryRevocationRepository keeps revocations in memory. It is meant for
+// tests and single-instance development servers; revocations are lost on
+// restart and not shared between instances.
+func NewMemoryRevocationRepository() IRevocationRepository {
+	return &memoryRevocationRepository{tokens: map[string]time.Time{}, sessions: map[string]time.Time{}, users: map[uint]uint{}}
+}
+
+func (vr *memoryRevocationRepository) RevokeToken(token *model.RevokedToken) error {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	dropExpired(vr.tokens)
+	vr.tokens[token.Jti] = token.ExpiresAt
+	return nil
+}
+
+func (vr *memoryRevocationRepository) RevokeSessions(sessions []model.RevokedSession) error {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	dropExpired(vr.sessions)
+	for _, session := range sessions {
+		if session.ExpiresAt.After(vr.sessions[session.Sid]) {
+			vr.sessions[session.Sid] = session.ExpiresAt
+		}
+	}
+	return nil
+}
+
+func (vr *memoryRevocationRepository) RevokeUser(userId uint) error {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	vr.users[userId]++
+	return nil
+}
+
+func (vr *memoryRevocationRepository) GetGeneration(generation *uint, userId uint) error {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	*generation = vr.users[userId]
+	return nil
+}
+
+func (vr *memoryRevocationRepository) IsRevoked(jti string, sid string, userId uint, generation uint) (bool, error) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	if _, ok := vr.tokens[jti]; ok {
+		return true, nil
+	}
+	if _, ok := vr.sessions[sid]; ok {
+		return true, nil
+	}
+	return generation < vr.users[userId], nil
+}
+
+func dropExpired(expiries map[string]time.Time) {
+	now := time.Now()
+	for key, expiresAt := range expiries {
+		if !expiresAt.After(now) {
+			delete(expiries, key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IMentionRepository interface {
+	Add(mention *model.Mention) (bool, error)
+	GetInbox(mentions *[]model.Mention, userId uint, workspaceId uint, limit int) error
+}
+
+type mentionRepository struct {
+	db *gorm.DB
+}
+
+func NewMentionRepository(db *gorm.DB) IMentionRepository {
+	return &mentionRepository{db}
+}
+
+// Add stores the mention unless the user was already mentioned in the same
+// comment or description, and reports whether it was new.
+func (mr *mentionRepository) Add(mention *model.Mention) (bool, error) {
+	result := mr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(mention)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetInbox loads the latest mentions of the user on tasks of the workspace,
+// newest first.
+func (mr *mentionRepository) GetInbox(mentions *[]model.Mention, userId uint, workspaceId uint, limit int) error {
+	err := mr.db.Joins("JOIN tasks ON tasks.id = mentions.task_id").
+		Where("mentions.user_id = ? AND tasks.workspace_id = ?", userId, workspaceId).
+		Order("mentions.created_at DESC, mentions.id DESC").
+		Limit(limit).
+		Find(mentions).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
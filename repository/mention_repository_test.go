@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupMentionTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testmention.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	db.Exec("INSERT INTO users (id, email, handle, password) VALUES (?, 'user2@testmention.com', 'Mentioned', 'password') ON CONFLICT (id) DO NOTHING", OTHER_USER_ID)
+	seedWorkspace(db, USER_ID, OTHER_USER_ID)
+	return db
+}
+
+func TestMentions(t *testing.T) {
+	db := setupMentionTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupMentionTable(db)
+	defer util.CleanupTaskTable(db)
+
+	mr := NewMentionRepository(db)
+	ur := NewUserRepository(db)
+
+	task := model.Task{Title: "Mentioned", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	db.Create(&task)
+
+	var users []model.User
+	if err := ur.GetByMentions(&users, uint(WORKSPACE_ID), []string{"user1@testmention.com"}, []string{"mentioned"}); err != nil {
+		t.Fatalf("GetByMentions failed: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("Expected both users, got %v", users)
+	}
+
+	mention := model.Mention{TaskId: task.ID, Source: model.MentionSourceComment, SourceId: 1, UserId: uint(OTHER_USER_ID), ActorId: uint(USER_ID)}
+	if added, err := mr.Add(&mention); err != nil || !added {
+		t.Fatalf("Add failed: %v", err)
+	}
+	again := mention
+	again.ID = 0
+	if added, err := mr.Add(&again); err != nil || added {
+		t.Fatalf("Expected the same mention not to be added twice, got %v, %v", added, err)
+	}
+	mr.Add(&model.Mention{TaskId: task.ID, Source: model.MentionSourceComment, SourceId: 2, UserId: uint(OTHER_USER_ID), ActorId: uint(USER_ID)})
+
+	var mentions []model.Mention
+	if err := mr.GetInbox(&mentions, uint(OTHER_USER_ID), uint(WORKSPACE_ID), 10); err != nil {
+		t.Fatalf("GetInbox failed: %v", err)
+	}
+	if len(mentions) != 2 || mentions[0].SourceId != 2 {
+		t.Errorf("Expected 2 mentions newest first, got %v", mentions)
+	}
+}
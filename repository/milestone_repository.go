@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IMilestoneRepository interface {
+	Create(milestone *model.Milestone) error
+	GetAll(milestones *[]model.Milestone, workspaceId uint) error
+	GetByID(milestone *model.Milestone, workspaceId uint, milestoneId uint) error
+	AddTask(milestoneTask *model.MilestoneTask) error
+	RemoveTask(milestoneId uint, taskId uint) error
+	CountTasks(counts *model.MilestoneCounts, milestoneId uint) error
+	GetVelocity(completed *[]int, milestone model.Milestone, limit int) error
+	Close(milestone *model.Milestone, carryOverTo *uint) error
+}
+
+type milestoneRepository struct {
+	db *gorm.DB
+}
+
+func NewMilestoneRepository(db *gorm.DB) IMilestoneRepository {
+	return &milestoneRepository{db}
+}
+
+func (mr *milestoneRepository) Create(milestone *model.Milestone) error {
+	if err := mr.db.Create(milestone).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mr *milestoneRepository) GetAll(milestones *[]model.Milestone, workspaceId uint) error {
+	if err := mr.db.Where("workspace_id = ?", workspaceId).Order("start_date, id").Find(milestones).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mr *milestoneRepository) GetByID(milestone *model.Milestone, workspaceId uint, milestoneId uint) error {
+	if err := mr.db.Where("workspace_id = ?", workspaceId).First(milestone, milestoneId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddTask is idempotent. A task already in the milestone keeps its
+// carried over mark.
+func (mr *milestoneRepository) AddTask(milestoneTask *model.MilestoneTask) error {
+	if err := mr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(milestoneTask).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mr *milestoneRepository) RemoveTask(milestoneId uint, taskId uint) error {
+	result := mr.db.Where("milestone_id = ? AND task_id = ?", milestoneId, taskId).Delete(&model.MilestoneTask{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (mr *milestoneRepository) CountTasks(counts *model.MilestoneCounts, milestoneId uint) error {
+	return countMilestoneTasks(mr.db, counts, milestoneId)
+}
+
+// GetVelocity loads the completed counts of the last limit closed milestones
+// of the workspace that ended no later than milestone, newest first.
+func (mr *milestoneRepository) GetVelocity(completed *[]int, milestone model.Milestone, limit int) error {
+	if err := mr.db.Model(&model.Milestone{}).
+		Where("workspace_id = ? AND id <> ? AND closed_at IS NOT NULL AND end_date <= ?", milestone.WorkspaceId, milestone.ID, milestone.EndDate).
+		Order("end_date DESC, id DESC").Limit(limit).
+		Pluck("completed", completed).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close records the committed and completed counts of the milestone and, if
+// carryOverTo is set, adds its unfinished tasks to that milestone. Callers
+// check that carryOverTo is an open milestone of the same workspace.
+func (mr *milestoneRepository) Close(milestone *model.Milestone, carryOverTo *uint) error {
+	return mr.db.Transaction(func(tx *gorm.DB) error {
+		var counts model.MilestoneCounts
+		if err := countMilestoneTasks(tx, &counts, milestone.ID); err != nil {
+			return err
+		}
+		now := time.Now()
+		result := tx.Model(milestone).Clauses(clause.Returning{}).Where("closed_at IS NULL").
+			Updates(map[string]interface{}{"closed_at": now, "committed": counts.Committed, "completed": counts.Completed})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrMilestoneClosed
+		}
+		if carryOverTo == nil {
+			return nil
+		}
+		return tx.Exec(`INSERT INTO milestone_tasks (milestone_id, task_id, carried_over, created_at)
+SELECT ?, milestone_tasks.task_id, true, ? FROM milestone_tasks JOIN tasks ON tasks.id = milestone_tasks.task_id
+WHERE milestone_tasks.milestone_id = ? AND tasks.status <> ?
+ON CONFLICT DO NOTHING`, *carryOverTo, now, milestone.ID, model.TaskStatusDone).Error
+	})
+}
+
+func countMilestoneTasks(tx *gorm.DB, counts *model.MilestoneCounts, milestoneId uint) error {
+	if err := tx.Table("milestone_tasks").
+		Select("COUNT(*) AS committed, COUNT(*) FILTER (WHERE tasks.status = ?) AS completed, COUNT(*) FILTER (WHERE milestone_tasks.carried_over) AS carried_in", model.TaskStatusDone).
+		Joins("JOIN tasks ON tasks.id = milestone_tasks.task_id").
+		Where("milestone_tasks.milestone_id = ?", milestoneId).
+		Scan(counts).Error; err != nil {
+		return err
+	}
+	return nil
+}
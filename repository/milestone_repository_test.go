@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func setupMilestoneTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testmilestone.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	seedWorkspace(db, USER_ID)
+	return db
+}
+
+func TestCloseMilestone(t *testing.T) {
+	db := setupMilestoneTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+	defer util.CleanupMilestoneTables(db)
+
+	mr := NewMilestoneRepository(db)
+
+	start := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	sprint := model.Milestone{Name: "Sprint 1", StartDate: start, EndDate: start.AddDate(0, 0, 14), UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	next := model.Milestone{Name: "Sprint 2", StartDate: start.AddDate(0, 0, 14), EndDate: start.AddDate(0, 0, 28), UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	mr.Create(&sprint)
+	mr.Create(&next)
+
+	done := model.Task{Title: "Done", Status: model.TaskStatusDone, UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	open := model.Task{Title: "Open", Status: model.TaskStatusDoing, UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	db.Create(&done)
+	db.Create(&open)
+	mr.AddTask(&model.MilestoneTask{MilestoneId: sprint.ID, TaskId: done.ID})
+	if err := mr.AddTask(&model.MilestoneTask{MilestoneId: sprint.ID, TaskId: open.ID}); err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+
+	var counts model.MilestoneCounts
+	if err := mr.CountTasks(&counts, sprint.ID); err != nil {
+		t.Fatalf("CountTasks failed: %v", err)
+	}
+	if counts.Committed != 2 || counts.Completed != 1 {
+		t.Errorf("Expected 2 committed and 1 completed, got %+v", counts)
+	}
+
+	if err := mr.Close(&sprint, &next.ID); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if sprint.ClosedAt == nil || sprint.Committed != 2 || sprint.Completed != 1 {
+		t.Errorf("Expected the counts to be recorded on close, got %+v", sprint)
+	}
+	if err := mr.Close(&sprint, nil); !errors.Is(err, model.ErrMilestoneClosed) {
+		t.Errorf("Expected ErrMilestoneClosed, got %v", err)
+	}
+
+	counts = model.MilestoneCounts{}
+	mr.CountTasks(&counts, next.ID)
+	if counts.Committed != 1 || counts.CarriedIn != 1 {
+		t.Errorf("Expected the open task to carry over, got %+v", counts)
+	}
+
+	var completed []int
+	if err := mr.GetVelocity(&completed, next, 3); err != nil {
+		t.Fatalf("GetVelocity failed: %v", err)
+	}
+	if len(completed) != 1 || completed[0] != 1 {
+		t.Errorf("Expected the velocity of Sprint 1, got %v", completed)
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IPasswordResetRepository interface {
+	Create(token *model.PasswordResetToken) error
+	Consume(token *model.PasswordResetToken, tokenHash string) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) IPasswordResetRepository {
+	return &passwordResetRepository{db}
+}
+
+func (pr *passwordResetRepository) Create(token *model.PasswordResetToken) error {
+	if err := pr.db.Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Consume marks the unused, unexpired token with tokenHash as used and loads
+// it into token. Every other outstanding token of the user is used up too,
+// so only one reset link works. Any other token fails with
+// model.ErrInvalidPasswordReset.
+func (pr *passwordResetRepository) Consume(token *model.PasswordResetToken, tokenHash string) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(token).Clauses(clause.Returning{}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrInvalidPasswordReset
+		}
+		return tx.Model(&model.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserId).
+			Update("used_at", now).Error
+	})
+}
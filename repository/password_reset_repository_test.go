@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func setupPasswordResetTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testpasswordreset.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	return db
+}
+
+func TestConsumePasswordReset(t *testing.T) {
+	db := setupPasswordResetTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupPasswordResetTable(db)
+
+	pr := NewPasswordResetRepository(db)
+
+	expiresAt := time.Now().Add(time.Hour)
+	pr.Create(&model.PasswordResetToken{TokenHash: "first", UserId: uint(USER_ID), ExpiresAt: expiresAt})
+	pr.Create(&model.PasswordResetToken{TokenHash: "second", UserId: uint(USER_ID), ExpiresAt: expiresAt})
+	pr.Create(&model.PasswordResetToken{TokenHash: "expired", UserId: uint(USER_ID), ExpiresAt: time.Now().Add(-time.Minute)})
+
+	if err := pr.Consume(&model.PasswordResetToken{}, "expired"); !errors.Is(err, model.ErrInvalidPasswordReset) {
+		t.Errorf("Expected ErrInvalidPasswordReset for an expired token, got %v", err)
+	}
+
+	token := model.PasswordResetToken{}
+	if err := pr.Consume(&token, "second"); err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	if token.UserId != uint(USER_ID) {
+		t.Errorf("Expected UserId %d, got %d", USER_ID, token.UserId)
+	}
+	if err := pr.Consume(&model.PasswordResetToken{}, "second"); !errors.Is(err, model.ErrInvalidPasswordReset) {
+		t.Errorf("Expected ErrInvalidPasswordReset for a used token, got %v", err)
+	}
+	if err := pr.Consume(&model.PasswordResetToken{}, "first"); !errors.Is(err, model.ErrInvalidPasswordReset) {
+		t.Errorf("Expected ErrInvalidPasswordReset for an older token of the user, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lastUsedPrecision is how stale LastUsedAt may get, so that a busy token
+// does not write on every request.
+const lastUsedPrecision = time.Minute
+
+type IPersonalAccessTokenRepository interface {
+	Create(token *model.PersonalAccessToken) error
+	GetAll(tokens *[]model.PersonalAccessToken, userId uint) error
+	GetByHash(token *model.PersonalAccessToken, tokenHash string) error
+	MarkUsed(tokenId uint, usedAt time.Time) error
+	Delete(userId uint, tokenId uint) error
+}
+
+type personalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) IPersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{db}
+}
+
+func (pr *personalAccessTokenRepository) Create(token *model.PersonalAccessToken) error {
+	if err := pr.db.Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pr *personalAccessTokenRepository) GetAll(tokens *[]model.PersonalAccessToken, userId uint) error {
+	if err := pr.db.Where("user_id = ?", userId).Order("created_at, id").Find(tokens).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByHash loads the token with tokenHash unless it has expired or its user
+// has been disabled.
+func (pr *personalAccessTokenRepository) GetByHash(token *model.PersonalAccessToken, tokenHash string) error {
+	if err := pr.db.Joins("JOIN users ON users.id = personal_access_tokens.user_id AND users.disabled_at IS NULL").
+		Where("token_hash = ? AND (expires_at IS NULL OR expires_at > ?)", tokenHash, time.Now()).
+		First(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarkUsed records usedAt as the last use of the token, unless the recorded
+// one is less than lastUsedPrecision older.
+func (pr *personalAccessTokenRepository) MarkUsed(tokenId uint, usedAt time.Time) error {
+	if err := pr.db.Model(&model.PersonalAccessToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", tokenId, usedAt.Add(-lastUsedPrecision)).
+		Update("last_used_at", usedAt).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pr *personalAccessTokenRepository) Delete(userId uint, tokenId uint) error {
+	result := pr.db.Where("id = ? AND user_id = ?", tokenId, userId).Delete(&model.PersonalAccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func setupPersonalAccessTokenTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testaccesstoken.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	return db
+}
+
+func TestGetPersonalAccessTokenByHash(t *testing.T) {
+	db := setupPersonalAccessTokenTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupPersonalAccessTokenTable(db)
+
+	pr := NewPersonalAccessTokenRepository(db)
+
+	expired := time.Now().Add(-time.Minute)
+	pr.Create(&model.PersonalAccessToken{Name: "ci", TokenHash: "live", Scopes: []string{model.ScopeTasksRead}, UserId: uint(USER_ID)})
+	pr.Create(&model.PersonalAccessToken{Name: "old", TokenHash: "expired", Scopes: []string{model.ScopeTasksRead}, UserId: uint(USER_ID), ExpiresAt: &expired})
+
+	token := model.PersonalAccessToken{}
+	if err := pr.GetByHash(&token, "live"); err != nil {
+		t.Fatalf("GetByHash failed: %v", err)
+	}
+	if len(token.Scopes) != 1 || token.Scopes[0] != model.ScopeTasksRead {
+		t.Errorf("Expected scopes [%s], got %v", model.ScopeTasksRead, token.Scopes)
+	}
+	if err := pr.GetByHash(&model.PersonalAccessToken{}, "expired"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound for an expired token, got %v", err)
+	}
+
+	db.Model(&model.User{}).Where("id = ?", USER_ID).Update("disabled_at", time.Now())
+	defer db.Model(&model.User{}).Where("id = ?", USER_ID).Update("disabled_at", nil)
+	if err := pr.GetByHash(&model.PersonalAccessToken{}, "live"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound for a disabled user, got %v", err)
+	}
+}
+
+func TestMarkPersonalAccessTokenUsed(t *testing.T) {
+	db := setupPersonalAccessTokenTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupPersonalAccessTokenTable(db)
+
+	pr := NewPersonalAccessTokenRepository(db)
+
+	token := model.PersonalAccessToken{Name: "ci", TokenHash: "live", Scopes: []string{model.ScopeTasksRead}, UserId: uint(USER_ID)}
+	pr.Create(&token)
+	first := time.Now().Truncate(time.Second)
+	pr.MarkUsed(token.ID, first)
+	pr.MarkUsed(token.ID, first.Add(10*time.Second))
+
+	stored := model.PersonalAccessToken{}
+	pr.GetByHash(&stored, "live")
+	if stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(first) {
+		t.Errorf("Expected LastUsedAt %v, got %v", first, stored.LastUsedAt)
+	}
+
+	later := first.Add(2 * time.Minute)
+	pr.MarkUsed(token.ID, later)
+	pr.GetByHash(&stored, "live")
+	if stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(later) {
+		t.Errorf("Expected LastUsedAt %v, got %v", later, stored.LastUsedAt)
+	}
+}
+
+func TestDeletePersonalAccessToken(t *testing.T) {
+	db := setupPersonalAccessTokenTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupPersonalAccessTokenTable(db)
+
+	pr := NewPersonalAccessTokenRepository(db)
+
+	token := model.PersonalAccessToken{Name: "ci", TokenHash: "live", Scopes: []string{model.ScopeTasksRead}, UserId: uint(USER_ID)}
+	pr.Create(&token)
+
+	if err := pr.Delete(uint(USER_ID)+1, token.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound for another user's token, got %v", err)
+	}
+	if err := pr.Delete(uint(USER_ID), token.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	var tokens []model.PersonalAccessToken
+	pr.GetAll(&tokens, uint(USER_ID))
+	if len(tokens) != 0 {
+		t.Errorf("Expected no tokens, got %d", len(tokens))
+	}
+}
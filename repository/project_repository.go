@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IProjectRepository interface {
+	Create(project *model.Project) error
+	GetAll(projects *[]model.Project, userId uint, workspaceId uint) error
+	GetByID(project *model.Project, userId uint, workspaceId uint, projectId uint) error
+	AddMember(projectId uint, userId uint) error
+	RemoveMember(projectId uint, userId uint) error
+}
+
+type projectRepository struct {
+	db *gorm.DB
+}
+
+func NewProjectRepository(db *gorm.DB) IProjectRepository {
+	return &projectRepository{db}
+}
+
+// Create makes the owner the first member of the project. The owner must be
+// a member of the project's workspace.
+func (pr *projectRepository) Create(project *model.Project) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkWorkspaceMember(tx, project.WorkspaceId, &project.UserId); err != nil {
+			return err
+		}
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.ProjectMember{ProjectId: project.ID, UserId: project.UserId}).Error
+	})
+}
+
+// GetAll lists the projects of the workspace the user is a member of.
+func (pr *projectRepository) GetAll(projects *[]model.Project, userId uint, workspaceId uint) error {
+	err := pr.db.Joins("JOIN project_members ON project_members.project_id = projects.id").
+		Where("project_members.user_id = ? AND projects.workspace_id = ?", userId, workspaceId).
+		Order("projects.created_at").
+		Find(projects).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByID loads a project of the workspace the user is a member of.
+func (pr *projectRepository) GetByID(project *model.Project, userId uint, workspaceId uint, projectId uint) error {
+	err := pr.db.Joins("JOIN project_members ON project_members.project_id = projects.id").
+		Where("project_members.user_id = ? AND projects.workspace_id = ?", userId, workspaceId).
+		First(project, projectId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddMember is idempotent. It fails with model.ErrNotWorkspaceMember unless
+// the user is a member of the project's workspace.
+func (pr *projectRepository) AddMember(projectId uint, userId uint) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		project := model.Project{}
+		if err := tx.First(&project, projectId).Error; err != nil {
+			return err
+		}
+		if err := checkWorkspaceMember(tx, project.WorkspaceId, &userId); err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.ProjectMember{ProjectId: projectId, UserId: userId}).Error
+	})
+}
+
+func (pr *projectRepository) RemoveMember(projectId uint, userId uint) error {
+	if err := pr.db.Where("project_id = ? AND user_id = ?", projectId, userId).Delete(&model.ProjectMember{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
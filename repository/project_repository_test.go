@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupProjectTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testproject.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user2@testproject.com', 'password') ON CONFLICT (id) DO NOTHING", OTHER_USER_ID)
+	seedWorkspace(db, USER_ID, OTHER_USER_ID)
+	return db
+}
+
+func TestProjectMembers(t *testing.T) {
+	db := setupProjectTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupProjectTables(db)
+
+	pr := NewProjectRepository(db)
+
+	project := model.Project{Name: "Launch", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	if err := pr.Create(&project); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	var found model.Project
+	if err := pr.GetByID(&found, uint(OTHER_USER_ID), uint(WORKSPACE_ID), project.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Expected a non-member not to find the project, got %v", err)
+	}
+
+	pr.AddMember(project.ID, uint(OTHER_USER_ID))
+	if err := pr.AddMember(project.ID, uint(OTHER_USER_ID)); err != nil {
+		t.Fatalf("Adding a member twice failed: %v", err)
+	}
+	var projects []model.Project
+	if err := pr.GetAll(&projects, uint(OTHER_USER_ID), uint(WORKSPACE_ID)); err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ID != project.ID {
+		t.Fatalf("Expected the member to see the project, got %v", projects)
+	}
+
+	pr.RemoveMember(project.ID, uint(OTHER_USER_ID))
+	projects = nil
+	pr.GetAll(&projects, uint(OTHER_USER_ID), uint(WORKSPACE_ID))
+	if len(projects) != 0 {
+		t.Errorf("Expected no projects after leaving, got %v", projects)
+	}
+}
+
+func TestProjectTaskAccess(t *testing.T) {
+	db := setupProjectTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupMentionTable(db)
+	defer util.CleanupTaskTable(db)
+	defer util.CleanupProjectTables(db)
+
+	pr := NewProjectRepository(db)
+	tr := NewTaskRepository(db)
+	mr := NewMentionRepository(db)
+
+	project := model.Project{Name: "Launch", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	pr.Create(&project)
+
+	foreign := model.Task{Title: "Foreign", UserId: uint(OTHER_USER_ID), WorkspaceId: uint(WORKSPACE_ID), ProjectId: &project.ID}
+	if err := tr.Create(&foreign, 0); !errors.Is(err, model.ErrNotProjectMember) {
+		t.Fatalf("Expected a non-member not to file tasks under the project, got %v", err)
+	}
+
+	task := model.Task{Title: "Shared", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), ProjectId: &project.ID}
+	if err := tr.Create(&task, 0); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	added, err := mr.Add(&model.Mention{TaskId: task.ID, Source: model.MentionSourceDescription, UserId: uint(OTHER_USER_ID), ActorId: uint(USER_ID)})
+	if err != nil || !added {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	var accessible model.Task
+	if err := tr.GetAccessible(&accessible, uint(OTHER_USER_ID), uint(WORKSPACE_ID), task.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Expected a mention outside the project not to grant access, got %v", err)
+	}
+
+	pr.AddMember(project.ID, uint(OTHER_USER_ID))
+	if err := tr.GetAccessible(&accessible, uint(OTHER_USER_ID), uint(WORKSPACE_ID), task.ID); err != nil {
+		t.Errorf("Expected a mentioned member to have access: %v", err)
+	}
+}
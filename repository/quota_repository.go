@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IQuotaRepository interface {
+	GetUserQuota(quota *model.UserQuota, userId uint) error
+	CountTasks(count *int64, userId uint) error
+	SumAttachmentSizes(total *int64, userId uint) error
+	AddRequest(usage *model.APIUsage, userId uint, day time.Time) error
+	GetUsage(usage *model.APIUsage, userId uint, day time.Time) error
+}
+
+type quotaRepository struct {
+	db *gorm.DB
+}
+
+func NewQuotaRepository(db *gorm.DB) IQuotaRepository {
+	return &quotaRepository{db}
+}
+
+// GetUserQuota loads the overrides of the user. A user without overrides
+// gets an empty quota.
+func (qr *quotaRepository) GetUserQuota(quota *model.UserQuota, userId uint) error {
+	if err := qr.db.Where(model.UserQuota{UserId: userId}).FirstOrInit(quota).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (qr *quotaRepository) CountTasks(count *int64, userId uint) error {
+	if err := qr.db.Model(&model.Task{}).Where("user_id = ?", userId).Count(count).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (qr *quotaRepository) SumAttachmentSizes(total *int64, userId uint) error {
+	return sumAttachmentSizes(qr.db, total, userId)
+}
+
+// AddRequest counts one request on day and loads the new total into usage.
+func (qr *quotaRepository) AddRequest(usage *model.APIUsage, userId uint, day time.Time) error {
+	err := qr.db.Raw(`INSERT INTO api_usages (user_id, day, requests) VALUES (?, ?, 1)
+ON CONFLICT (user_id, day) DO UPDATE SET requests = api_usages.requests + 1
+RETURNING user_id, day, requests`, userId, day).Scan(usage).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetUsage loads the request count of day. A day without requests gets a
+// zero count.
+func (qr *quotaRepository) GetUsage(usage *model.APIUsage, userId uint, day time.Time) error {
+	if err := qr.db.Where(model.APIUsage{UserId: userId, Day: day}).FirstOrInit(usage).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// lockQuota locks the user until tx ends, so that concurrent transactions
+// checking the same quota count one after another and cannot pass the limit
+// together.
+func lockQuota(tx *gorm.DB, userId uint) error {
+	if err := tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).Select("id").First(&model.User{}, userId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkTaskQuota fails with a *model.QuotaError if the user owns maxTasks
+// tasks or more.
+func checkTaskQuota(tx *gorm.DB, userId uint, maxTasks int64) error {
+	if maxTasks <= 0 {
+		return nil
+	}
+	if err := lockQuota(tx, userId); err != nil {
+		return err
+	}
+	var count int64
+	if err := tx.Model(&model.Task{}).Where("user_id = ?", userId).Count(&count).Error; err != nil {
+		return err
+	}
+	if count >= maxTasks {
+		return &model.QuotaError{Err: model.ErrQuotaExceeded, Limit: model.QuotaLimitTasks, Used: count, Max: maxTasks}
+	}
+	return nil
+}
+
+// checkAttachmentQuota fails with a *model.QuotaError if size more bytes
+// would take the attachments the user uploaded past maxBytes.
+func checkAttachmentQuota(tx *gorm.DB, userId uint, size int64, maxBytes int64) error {
+	if maxBytes <= 0 {
+		return nil
+	}
+	if err := lockQuota(tx, userId); err != nil {
+		return err
+	}
+	var total int64
+	if err := sumAttachmentSizes(tx, &total, userId); err != nil {
+		return err
+	}
+	if total+size > maxBytes {
+		return &model.QuotaError{Err: model.ErrQuotaExceeded, Limit: model.QuotaLimitAttachmentBytes, Used: total, Max: maxBytes}
+	}
+	return nil
+}
+
+func sumAttachmentSizes(tx *gorm.DB, total *int64, userId uint) error {
+	if err := tx.Model(&model.Attachment{}).Where("user_id = ?", userId).Select("COALESCE(SUM(size), 0)").Scan(total).Error; err != nil {
+		return err
+	}
+	return nil
+}
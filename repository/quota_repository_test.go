@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func setupQuotaTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testquota.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	seedWorkspace(db, USER_ID)
+	return db
+}
+
+func TestQuotaRequestsAndTasks(t *testing.T) {
+	db := setupQuotaTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupQuotaTables(db)
+	defer util.CleanupTaskTable(db)
+
+	qr := NewQuotaRepository(db)
+	day := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+
+	var usage model.APIUsage
+	if err := qr.GetUsage(&usage, uint(USER_ID), day); err != nil {
+		t.Fatalf("GetUsage failed: %v", err)
+	}
+	if usage.Requests != 0 {
+		t.Errorf("Expected no requests, got %d", usage.Requests)
+	}
+
+	qr.AddRequest(&usage, uint(USER_ID), day)
+	if err := qr.AddRequest(&usage, uint(USER_ID), day); err != nil {
+		t.Fatalf("AddRequest failed: %v", err)
+	}
+	if usage.Requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", usage.Requests)
+	}
+	qr.AddRequest(&usage, uint(USER_ID), day.AddDate(0, 0, 1))
+	if usage.Requests != 1 {
+		t.Errorf("Expected the next day to start at 1, got %d", usage.Requests)
+	}
+
+	db.Create(&model.Task{Title: "Counted", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)})
+	var count int64
+	if err := qr.CountTasks(&count, uint(USER_ID)); err != nil {
+		t.Fatalf("CountTasks failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 task, got %d", count)
+	}
+}
+
+func TestGetUserQuota(t *testing.T) {
+	db := setupQuotaTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupQuotaTables(db)
+
+	qr := NewQuotaRepository(db)
+
+	var quota model.UserQuota
+	if err := qr.GetUserQuota(&quota, uint(USER_ID)); err != nil {
+		t.Fatalf("GetUserQuota failed: %v", err)
+	}
+	if quota.MaxTasks != nil {
+		t.Errorf("Expected no override, got %d", *quota.MaxTasks)
+	}
+
+	maxTasks := int64(500)
+	db.Create(&model.UserQuota{UserId: uint(USER_ID), Plan: "partner", MaxTasks: &maxTasks})
+	quota = model.UserQuota{}
+	if err := qr.GetUserQuota(&quota, uint(USER_ID)); err != nil {
+		t.Fatalf("GetUserQuota failed: %v", err)
+	}
+	if quota.Plan != "partner" || quota.MaxTasks == nil || *quota.MaxTasks != maxTasks {
+		t.Errorf("Expected partner plan with %d tasks, got %v", maxTasks, quota)
+	}
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IRefreshTokenRepository interface {
+	Create(token *model.RefreshToken) error
+	GetByHash(token *model.RefreshToken, tokenHash string) error
+	Rotate(next *model.RefreshToken, tokenHash string) error
+	RevokeFamily(familyId string) error
+	RevokeUser(userId uint) error
+	RevokeOtherFamilies(familyIds *[]string, userId uint, keepFamilyId string) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) IRefreshTokenRepository {
+	return &refreshTokenRepository{db}
+}
+
+func (rr *refreshTokenRepository) Create(token *model.RefreshToken) error {
+	if err := rr.db.Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *refreshTokenRepository) GetByHash(token *model.RefreshToken, tokenHash string) error {
+	if err := rr.db.Where("token_hash = ?", tokenHash).First(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Rotate marks the token with tokenHash as used and stores next in its family
+// and for its user. An unknown, expired or revoked token fails with
+// model.ErrInvalidRefreshToken. A token that was used before fails with
+// model.ErrRefreshTokenReused after its whole family has been revoked.
+func (rr *refreshTokenRepository) Rotate(next *model.RefreshToken, tokenHash string) error {
+	reused := false
+	err := rr.db.Transaction(func(tx *gorm.DB) error {
+		current := model.RefreshToken{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", tokenHash).First(&current).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if current.RevokedAt != nil || !current.ExpiresAt.After(now) {
+			return model.ErrInvalidRefreshToken
+		}
+		if current.UsedAt != nil {
+			reused = true
+			return tx.Model(&model.RefreshToken{}).
+				Where("family_id = ? AND revoked_at IS NULL", current.FamilyId).
+				Update("revoked_at", now).Error
+		}
+		if err := tx.Model(&current).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		next.FamilyId = current.FamilyId
+		next.UserId = current.UserId
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		return err
+	}
+	if reused {
+		return model.ErrRefreshTokenReused
+	}
+	return nil
+}
+
+// RevokeFamily ends one session, the tokens rotated from a single login.
+func (rr *refreshTokenRepository) RevokeFamily(familyId string) error {
+	if err := rr.db.Model(&model.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", familyId).Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// RevokeUser ends every session of the user.
+func (rr *refreshTokenRepository) RevokeUser(userId uint) error {
+	if err := rr.db.Model(&model.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userId).Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// RevokeOtherFamilies ends every session of the user but keepFamilyId and
+// loads the ids of the sessions it ended.
+func (rr *refreshTokenRepository) RevokeOtherFamilies(familyIds *[]string, userId uint, keepFamilyId string) error {
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.RefreshToken{}).
+			Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userId, keepFamilyId).
+			Distinct().Pluck("family_id", familyIds).Error; err != nil {
+			return err
+		}
+		if len(*familyIds) == 0 {
+			return nil
+		}
+		return tx.Model(&model.RefreshToken{}).
+			Where("family_id IN ? AND revoked_at IS NULL", *familyIds).
+			Update("revoked_at", time.Now()).Error
+	})
+}
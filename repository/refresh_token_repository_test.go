@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func setupRefreshTokenTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testrefresh.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	return db
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	db := setupRefreshTokenTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupRefreshTokenTable(db)
+
+	rr := NewRefreshTokenRepository(db)
+
+	expiresAt := time.Now().Add(time.Hour)
+	first := model.RefreshToken{TokenHash: "first", FamilyId: "family", UserId: uint(USER_ID), ExpiresAt: expiresAt}
+	if err := rr.Create(&first); err != nil {
+		t.Fatalf("Create refresh token failed: %v", err)
+	}
+	stored := model.RefreshToken{}
+	if err := rr.GetByHash(&stored, "first"); err != nil || stored.UserId != uint(USER_ID) {
+		t.Errorf("Expected GetByHash to load the token of user %d, got %+v, %v", USER_ID, stored, err)
+	}
+
+	second := model.RefreshToken{TokenHash: "second", ExpiresAt: expiresAt}
+	if err := rr.Rotate(&second, "first"); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if second.FamilyId != "family" || second.UserId != uint(USER_ID) {
+		t.Errorf("Expected the rotated token to join the family, got %+v", second)
+	}
+
+	replayed := model.RefreshToken{TokenHash: "third", ExpiresAt: expiresAt}
+	if err := rr.Rotate(&replayed, "first"); !errors.Is(err, model.ErrRefreshTokenReused) {
+		t.Errorf("Expected ErrRefreshTokenReused, got %v", err)
+	}
+
+	third := model.RefreshToken{TokenHash: "third", ExpiresAt: expiresAt}
+	if err := rr.Rotate(&third, "second"); !errors.Is(err, model.ErrInvalidRefreshToken) {
+		t.Errorf("Expected the family to be revoked after reuse, got %v", err)
+	}
+}
+
+func TestRotateRefreshToken_Expired(t *testing.T) {
+	db := setupRefreshTokenTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupRefreshTokenTable(db)
+
+	rr := NewRefreshTokenRepository(db)
+
+	expired := model.RefreshToken{TokenHash: "expired", FamilyId: "family", UserId: uint(USER_ID), ExpiresAt: time.Now().Add(-time.Minute)}
+	rr.Create(&expired)
+
+	next := model.RefreshToken{TokenHash: "next", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := rr.Rotate(&next, "expired"); !errors.Is(err, model.ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken, got %v", err)
+	}
+}
+
+func TestRevokeRefreshTokens(t *testing.T) {
+	db := setupRefreshTokenTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupRefreshTokenTable(db)
+
+	rr := NewRefreshTokenRepository(db)
+
+	expiresAt := time.Now().Add(time.Hour)
+	rr.Create(&model.RefreshToken{TokenHash: "laptop", FamilyId: "laptop", UserId: uint(USER_ID), ExpiresAt: expiresAt})
+	rr.Create(&model.RefreshToken{TokenHash: "phone", FamilyId: "phone", UserId: uint(USER_ID), ExpiresAt: expiresAt})
+
+	if err := rr.RevokeFamily("laptop"); err != nil {
+		t.Fatalf("RevokeFamily failed: %v", err)
+	}
+	if err := rr.Rotate(&model.RefreshToken{TokenHash: "laptop-2", ExpiresAt: expiresAt}, "laptop"); !errors.Is(err, model.ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken for a revoked family, got %v", err)
+	}
+	if err := rr.RevokeUser(uint(USER_ID)); err != nil {
+		t.Fatalf("RevokeUser failed: %v", err)
+	}
+	if err := rr.Rotate(&model.RefreshToken{TokenHash: "phone-2", ExpiresAt: expiresAt}, "phone"); !errors.Is(err, model.ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken after revoking every session, got %v", err)
+	}
+}
+
+func TestRevokeOtherFamilies(t *testing.T) {
+	db := setupRefreshTokenTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupRefreshTokenTable(db)
+
+	rr := NewRefreshTokenRepository(db)
+
+	expiresAt := time.Now().Add(time.Hour)
+	rr.Create(&model.RefreshToken{TokenHash: "laptop", FamilyId: "laptop", UserId: uint(USER_ID), ExpiresAt: expiresAt})
+	rr.Create(&model.RefreshToken{TokenHash: "phone", FamilyId: "phone", UserId: uint(USER_ID), ExpiresAt: expiresAt})
+
+	var familyIds []string
+	if err := rr.RevokeOtherFamilies(&familyIds, uint(USER_ID), "laptop"); err != nil {
+		t.Fatalf("RevokeOtherFamilies failed: %v", err)
+	}
+	if len(familyIds) != 1 || familyIds[0] != "phone" {
+		t.Errorf("Expected only phone to be revoked, got %v", familyIds)
+	}
+	if err := rr.Rotate(&model.RefreshToken{TokenHash: "phone-2", ExpiresAt: expiresAt}, "phone"); !errors.Is(err, model.ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken for another session, got %v", err)
+	}
+	if err := rr.Rotate(&model.RefreshToken{TokenHash: "laptop-2", ExpiresAt: expiresAt}, "laptop"); err != nil {
+		t.Errorf("Expected the kept session to rotate, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IRevocationRepository records access tokens that were logged out before
+// they expired, one at a time by jti, a session at a time by sid, or all of
+// a user's at once.
+type IRevocationRepository interface {
+	RevokeToken(token *model.RevokedToken) error
+	RevokeSessions(sessions []model.RevokedSession) error
+	RevokeUser(userId uint) error
+	GetGeneration(generation *uint, userId uint) error
+	IsRevoked(jti string, sid string, userId uint, generation uint) (bool, error)
+}
+
+type revocationRepository struct {
+	db *gorm.DB
+}
+
+func NewRevocationRepository(db *gorm.DB) IRevocationRepository {
+	return &revocationRepository{db}
+}
+
+// RevokeToken also drops the tokens that have expired since they were
+// revoked.
+func (vr *revocationRepository) RevokeToken(token *model.RevokedToken) error {
+	return vr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", time.Now()).Delete(&model.RevokedToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+	})
+}
+
+// RevokeSessions also drops the sessions whose tokens have all expired.
+func (vr *revocationRepository) RevokeSessions(sessions []model.RevokedSession) error {
+	return vr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", time.Now()).Delete(&model.RevokedSession{}).Error; err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "sid"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"expires_at": gorm.Expr("GREATEST(revoked_sessions.expires_at, excluded.expires_at)")}),
+		}).Create(&sessions).Error
+	})
+}
+
+// RevokeUser starts a new generation for the user, which invalidates every
+// access token issued so far.
+func (vr *revocationRepository) RevokeUser(userId uint) error {
+	revocation := model.SessionRevocation{UserId: userId, Generation: 1}
+	err := vr.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"generation": gorm.Expr("session_revocations.generation + 1")}),
+	}).Create(&revocation).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (vr *revocationRepository) GetGeneration(generation *uint, userId uint) error {
+	revocation := model.SessionRevocation{}
+	if err := vr.db.Where("user_id = ?", userId).Limit(1).Find(&revocation).Error; err != nil {
+		return err
+	}
+	*generation = revocation.Generation
+	return nil
+}
+
+func (vr *revocationRepository) IsRevoked(jti string, sid string, userId uint, generation uint) (bool, error) {
+	var revoked bool
+	err := vr.db.Raw(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = ?)
+OR EXISTS (SELECT 1 FROM revoked_sessions WHERE sid = ?)
+OR EXISTS (SELECT 1 FROM session_revocations WHERE user_id = ? AND generation > ?)`, jti, sid, userId, generation).
+		Scan(&revoked).Error
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
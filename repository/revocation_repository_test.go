@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func setupRevocationTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testrevocation.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	return db
+}
+
+func TestRevocation(t *testing.T) {
+	db := setupRevocationTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupRevocationTables(db)
+
+	vr := NewRevocationRepository(db)
+
+	now := time.Now()
+	if err := vr.RevokeToken(&model.RevokedToken{Jti: "a", UserId: uint(USER_ID), ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	if revoked, err := vr.IsRevoked("a", "", uint(USER_ID), 0); err != nil || !revoked {
+		t.Errorf("Expected token a to be revoked, got %v, %v", revoked, err)
+	}
+	if revoked, _ := vr.IsRevoked("b", "", uint(USER_ID), 0); revoked {
+		t.Errorf("Expected token b not to be revoked")
+	}
+
+	if err := vr.RevokeSessions([]model.RevokedSession{{Sid: "laptop", UserId: uint(USER_ID), ExpiresAt: now.Add(time.Hour)}}); err != nil {
+		t.Fatalf("RevokeSessions failed: %v", err)
+	}
+	if revoked, _ := vr.IsRevoked("b", "laptop", uint(USER_ID), 0); !revoked {
+		t.Errorf("Expected tokens of session laptop to be revoked")
+	}
+	if revoked, _ := vr.IsRevoked("b", "phone", uint(USER_ID), 0); revoked {
+		t.Errorf("Expected tokens of session phone not to be revoked")
+	}
+
+	var generation uint
+	if err := vr.GetGeneration(&generation, uint(USER_ID)); err != nil || generation != 0 {
+		t.Errorf("Expected generation 0 before any revocation, got %d, %v", generation, err)
+	}
+	if err := vr.RevokeUser(uint(USER_ID)); err != nil {
+		t.Fatalf("RevokeUser failed: %v", err)
+	}
+	if err := vr.RevokeUser(uint(USER_ID)); err != nil {
+		t.Fatalf("RevokeUser failed: %v", err)
+	}
+	if err := vr.GetGeneration(&generation, uint(USER_ID)); err != nil || generation != 2 {
+		t.Errorf("Expected generation 2 after two revocations, got %d, %v", generation, err)
+	}
+	if revoked, _ := vr.IsRevoked("b", "", uint(USER_ID), 1); !revoked {
+		t.Errorf("Expected tokens of an older generation to be revoked")
+	}
+	if revoked, _ := vr.IsRevoked("b", "", uint(USER_ID), 2); revoked {
+		t.Errorf("Expected tokens of the current generation to be valid")
+	}
+}
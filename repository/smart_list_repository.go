@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ISmartListRepository interface {
+	Create(list *model.SmartList) error
+	GetAll(lists *[]model.SmartList, userId uint) error
+	GetByID(list *model.SmartList, userId uint, listId uint) error
+	Update(list *model.SmartList, userId uint, listId uint) error
+	Delete(userId uint, listId uint) error
+}
+
+type smartListRepository struct {
+	db *gorm.DB
+}
+
+func NewSmartListRepository(db *gorm.DB) ISmartListRepository {
+	return &smartListRepository{db}
+}
+
+func (sr *smartListRepository) Create(list *model.SmartList) error {
+	if err := sr.db.Create(list).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sr *smartListRepository) GetAll(lists *[]model.SmartList, userId uint) error {
+	if err := sr.db.Where("user_id = ?", userId).Order("created_at").Find(lists).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sr *smartListRepository) GetByID(list *model.SmartList, userId uint, listId uint) error {
+	if err := sr.db.Where("user_id = ?", userId).First(list, listId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sr *smartListRepository) Update(list *model.SmartList, userId uint, listId uint) error {
+	result := sr.db.Model(list).Clauses(clause.Returning{}).Where("user_id = ? AND id = ?", userId, listId).Select("name", "filter").Updates(list)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (sr *smartListRepository) Delete(userId uint, listId uint) error {
+	if err := sr.db.Where("user_id = ? AND id = ?", userId, listId).Delete(&model.SmartList{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
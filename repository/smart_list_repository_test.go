@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupSmartListTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testsmartlist.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	seedWorkspace(db, USER_ID)
+	return db
+}
+
+func TestCreateSmartList(t *testing.T) {
+	db := setupSmartListTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupSmartListTable(db)
+
+	sr := NewSmartListRepository(db)
+
+	list := model.SmartList{Name: "high", Filter: model.TaskFilter{Priorities: []string{model.TaskPriorityHigh}}, UserId: uint(USER_ID)}
+	if err := sr.Create(&list); err != nil {
+		t.Fatalf("Create smart list failed: %v", err)
+	}
+
+	var rec model.SmartList
+	db.First(&rec, list.ID)
+
+	if len(rec.Filter.Priorities) != 1 || rec.Filter.Priorities[0] != model.TaskPriorityHigh {
+		t.Errorf("Expected filter priorities [high], got %v", rec.Filter.Priorities)
+	}
+}
+
+func TestUpdateSmartList(t *testing.T) {
+	db := setupSmartListTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupSmartListTable(db)
+
+	sr := NewSmartListRepository(db)
+
+	list := model.SmartList{Name: "high", UserId: uint(USER_ID)}
+	db.Create(&list)
+
+	updated := model.SmartList{Name: "today", Filter: model.TaskFilter{Due: model.DueToday}}
+	if err := sr.Update(&updated, uint(USER_ID), list.ID); err != nil {
+		t.Fatalf("Update smart list failed: %v", err)
+	}
+
+	var rec model.SmartList
+	db.First(&rec, list.ID)
+
+	if rec.Name != "today" || rec.Filter.Due != model.DueToday {
+		t.Errorf("Expected today filter, got %s %v", rec.Name, rec.Filter)
+	}
+}
+
+func TestGetFilteredTasks(t *testing.T) {
+	db := setupSmartListTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupLabelTable(db)
+	defer util.CleanupTaskTable(db)
+
+	tr := NewTaskRepository(db)
+
+	tr.Create(&model.Task{Title: "High", Priority: model.TaskPriorityHigh, Status: model.TaskStatusTodo, UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}, 0)
+	tr.Create(&model.Task{Title: "High waiting", Priority: model.TaskPriorityHigh, Status: model.TaskStatusTodo, UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), Labels: []model.Label{{Name: "waiting"}}}, 0)
+	tr.Create(&model.Task{Title: "Low", Priority: model.TaskPriorityLow, Status: model.TaskStatusTodo, UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}, 0)
+
+	var tasks []model.Task
+	filter := model.TaskFilter{Priorities: []string{model.TaskPriorityHigh}, ExcludeLabels: []string{"waiting"}}
+	if err := tr.GetFiltered(&tasks, uint(USER_ID), uint(WORKSPACE_ID), filter); err != nil {
+		t.Fatalf("GetFiltered failed: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "High" {
+		t.Errorf("Expected only task High, got %v", tasks)
+	}
+}
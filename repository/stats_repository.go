@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+)
+
+type IStatsRepository interface {
+	GetThroughput(points *[]model.ThroughputPoint, userId uint, workspaceId uint, query model.StatsQuery) error
+	GetBurndown(points *[]model.BurndownPoint, userId uint, workspaceId uint, query model.StatsQuery) error
+	GetAverageLeadTime(hours *float64, userId uint, workspaceId uint, query model.StatsQuery) error
+	CountOpenByStatus(counts *[]model.StatusCount, userId uint, workspaceId uint) error
+	CountOpenByLabel(counts *[]model.LabelCount, userId uint, workspaceId uint) error
+}
+
+type statsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) IStatsRepository {
+	return &statsRepository{db}
+}
+
+const (
+	// periodStep is the length of one bucket, "1 day" or "1 week".
+	periodStep = `CAST('1 ' || CAST(@unit AS text) AS interval)`
+	// periodSeries yields one row per bucket between query.From (inclusive)
+	// and query.To (exclusive). Buckets follow calendar days or weeks, but the
+	// first one starts at query.From rather than the start of its period, so
+	// it never counts anything from before query.From.
+	periodSeries = `(SELECT GREATEST(g, CAST(@from AS timestamptz)) AS period FROM generate_series(date_trunc(@unit, CAST(@from AS timestamptz)), CAST(@to AS timestamptz) - interval '1 second', ` + periodStep + `) AS g) AS s`
+	periodEnd    = `LEAST(date_trunc(@unit, s.period) + ` + periodStep + `, CAST(@to AS timestamptz))`
+)
+
+func (sr *statsRepository) GetThroughput(points *[]model.ThroughputPoint, userId uint, workspaceId uint, query model.StatsQuery) error {
+	sql := `SELECT s.period AS period,
+(SELECT COUNT(*) FROM tasks t WHERE t.user_id = @user AND t.workspace_id = @workspace AND t.created_at >= s.period AND t.created_at < ` + periodEnd + `) AS created,
+(SELECT COUNT(*) FROM tasks t WHERE t.user_id = @user AND t.workspace_id = @workspace AND t.completed_at >= s.period AND t.completed_at < ` + periodEnd + `) AS completed
+FROM ` + periodSeries + ` ORDER BY s.period`
+	if err := sr.db.Raw(sql, statsArgs(userId, workspaceId, query)).Scan(points).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sr *statsRepository) GetBurndown(points *[]model.BurndownPoint, userId uint, workspaceId uint, query model.StatsQuery) error {
+	sql := `SELECT s.period AS period,
+(SELECT COUNT(*) FROM tasks t WHERE t.user_id = @user AND t.workspace_id = @workspace AND t.created_at < ` + periodEnd + ` AND (t.completed_at IS NULL OR t.completed_at >= ` + periodEnd + `)) AS remaining
+FROM ` + periodSeries + ` ORDER BY s.period`
+	if err := sr.db.Raw(sql, statsArgs(userId, workspaceId, query)).Scan(points).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sr *statsRepository) GetAverageLeadTime(hours *float64, userId uint, workspaceId uint, query model.StatsQuery) error {
+	if err := sr.db.Model(&model.Task{}).
+		Select("COALESCE(AVG(EXTRACT(EPOCH FROM (completed_at - created_at))) / 3600, 0)").
+		Where("user_id = ? AND workspace_id = ? AND completed_at >= ? AND completed_at < ?", userId, workspaceId, query.From, query.To).
+		Scan(hours).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sr *statsRepository) CountOpenByStatus(counts *[]model.StatusCount, userId uint, workspaceId uint) error {
+	if err := sr.db.Model(&model.Task{}).
+		Select("status, COUNT(*) AS count").
+		Where("user_id = ? AND workspace_id = ? AND status <> ?", userId, workspaceId, model.TaskStatusDone).
+		Group("status").Order("status").
+		Scan(counts).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sr *statsRepository) CountOpenByLabel(counts *[]model.LabelCount, userId uint, workspaceId uint) error {
+	if err := sr.db.Table("tasks").
+		Select("labels.name AS label, COUNT(*) AS count").
+		Joins("JOIN task_labels ON task_labels.task_id = tasks.id").
+		Joins("JOIN labels ON labels.id = task_labels.label_id").
+		Where("tasks.user_id = ? AND tasks.workspace_id = ? AND tasks.status <> ?", userId, workspaceId, model.TaskStatusDone).
+		Group("labels.name").Order("labels.name").
+		Scan(counts).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func statsArgs(userId uint, workspaceId uint, query model.StatsQuery) map[string]interface{} {
+	return map[string]interface{}{
+		"user":      userId,
+		"workspace": workspaceId,
+		"unit":      query.Interval,
+		"from":      query.From,
+		"to":        query.To,
+	}
+}
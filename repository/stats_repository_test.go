@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func setupStatsTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@teststats.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	seedWorkspace(db, USER_ID)
+	return db
+}
+
+func TestGetThroughput(t *testing.T) {
+	db := setupStatsTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+
+	sr := NewStatsRepository(db)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	completedAt := from.Add(26 * time.Hour)
+	db.Create(&model.Task{Title: "Task1", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), CreatedAt: from.Add(time.Hour), Status: model.TaskStatusDone, CompletedAt: &completedAt})
+	db.Create(&model.Task{Title: "Task2", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), CreatedAt: from.Add(2 * time.Hour)})
+
+	var points []model.ThroughputPoint
+	query := model.StatsQuery{From: from, To: from.AddDate(0, 0, 3), Interval: model.StatsIntervalDay}
+	if err := sr.GetThroughput(&points, uint(USER_ID), uint(WORKSPACE_ID), query); err != nil {
+		t.Fatalf("GetThroughput failed: %v", err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("Expected 3 points, got %d", len(points))
+	}
+	if points[0].Created != 2 {
+		t.Errorf("Expected 2 created on first day, got %d", points[0].Created)
+	}
+	if points[1].Completed != 1 {
+		t.Errorf("Expected 1 completed on second day, got %d", points[1].Completed)
+	}
+}
+
+func TestGetThroughput_FromMidWeek(t *testing.T) {
+	db := setupStatsTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+
+	sr := NewStatsRepository(db)
+
+	// 2024-01-03 is a Wednesday, so its week started on Monday 2024-01-01.
+	from := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	db.Create(&model.Task{Title: "Before", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), CreatedAt: from.Add(-24 * time.Hour)})
+	db.Create(&model.Task{Title: "Inside", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), CreatedAt: from.Add(time.Hour)})
+	db.Create(&model.Task{Title: "NextWeek", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), CreatedAt: from.AddDate(0, 0, 5)})
+
+	var points []model.ThroughputPoint
+	query := model.StatsQuery{From: from, To: from.AddDate(0, 0, 7), Interval: model.StatsIntervalWeek}
+	if err := sr.GetThroughput(&points, uint(USER_ID), uint(WORKSPACE_ID), query); err != nil {
+		t.Fatalf("GetThroughput failed: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(points))
+	}
+	if !points[0].Period.Equal(from) {
+		t.Errorf("Expected the first week to start at %v, got %v", from, points[0].Period)
+	}
+	if points[0].Created != 1 {
+		t.Errorf("Expected 1 created in the first week, got %d", points[0].Created)
+	}
+	if points[1].Created != 1 {
+		t.Errorf("Expected 1 created in the second week, got %d", points[1].Created)
+	}
+}
+
+func TestGetBurndown(t *testing.T) {
+	db := setupStatsTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+
+	sr := NewStatsRepository(db)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	completedAt := from.Add(26 * time.Hour)
+	db.Create(&model.Task{Title: "Task1", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), CreatedAt: from.Add(time.Hour), Status: model.TaskStatusDone, CompletedAt: &completedAt})
+	db.Create(&model.Task{Title: "Task2", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), CreatedAt: from.Add(2 * time.Hour)})
+
+	var points []model.BurndownPoint
+	query := model.StatsQuery{From: from, To: from.AddDate(0, 0, 2), Interval: model.StatsIntervalDay}
+	if err := sr.GetBurndown(&points, uint(USER_ID), uint(WORKSPACE_ID), query); err != nil {
+		t.Fatalf("GetBurndown failed: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(points))
+	}
+	if points[0].Remaining != 2 || points[1].Remaining != 1 {
+		t.Errorf("Expected remaining 2 then 1, got %d then %d", points[0].Remaining, points[1].Remaining)
+	}
+}
+
+func TestCountOpenByStatus(t *testing.T) {
+	db := setupStatsTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+
+	sr := NewStatsRepository(db)
+
+	db.Create(&model.Task{Title: "Task1", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), Status: model.TaskStatusTodo})
+	db.Create(&model.Task{Title: "Task2", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), Status: model.TaskStatusDoing})
+	db.Create(&model.Task{Title: "Task3", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), Status: model.TaskStatusDone})
+
+	var counts []model.StatusCount
+	if err := sr.CountOpenByStatus(&counts, uint(USER_ID), uint(WORKSPACE_ID)); err != nil {
+		t.Fatalf("CountOpenByStatus failed: %v", err)
+	}
+	if len(counts) != 2 {
+		t.Errorf("Expected 2 open statuses, got %d", len(counts))
+	}
+}
+
+func TestCountOpenByLabel(t *testing.T) {
+	db := setupStatsTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupLabelTable(db)
+	defer util.CleanupTaskTable(db)
+
+	sr := NewStatsRepository(db)
+	tr := NewTaskRepository(db)
+
+	tr.Create(&model.Task{Title: "Task1", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), Status: model.TaskStatusTodo, Labels: []model.Label{{Name: "work"}}}, 0)
+	tr.Create(&model.Task{Title: "Task2", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), Status: model.TaskStatusTodo, Labels: []model.Label{{Name: "work"}, {Name: "home"}}}, 0)
+
+	var counts []model.LabelCount
+	if err := sr.CountOpenByLabel(&counts, uint(USER_ID), uint(WORKSPACE_ID)); err != nil {
+		t.Fatalf("CountOpenByLabel failed: %v", err)
+	}
+	if len(counts) != 2 {
+		t.Fatalf("Expected 2 labels, got %d", len(counts))
+	}
+	if counts[1].Label != "work" || counts[1].Count != 2 {
+		t.Errorf("Expected work with 2 tasks, got %s with %d", counts[1].Label, counts[1].Count)
+	}
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ISyncRepository interface {
+	GetCounter(counter *model.SyncCounter, userId uint) error
+	GetChangedTasks(tasks *[]model.Task, userId uint, workspaceId uint, since int64, until int64) error
+	GetTombstones(tombstones *[]model.TaskTombstone, userId uint, workspaceId uint, since int64, until int64) error
+	PurgeTombstones(userId uint, before time.Time) error
+}
+
+type syncRepository struct {
+	db *gorm.DB
+}
+
+func NewSyncRepository(db *gorm.DB) ISyncRepository {
+	return &syncRepository{db}
+}
+
+// GetCounter loads the change sequence of the user. A user who has never
+// written a task gets a zero counter.
+func (sr *syncRepository) GetCounter(counter *model.SyncCounter, userId uint) error {
+	if err := sr.db.Where(model.SyncCounter{UserId: userId}).FirstOrInit(counter).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetChangedTasks and GetTombstones only see the workspace. The change
+// sequence is shared by all workspaces of the user, so a delta of one
+// workspace skips the sequences of the others.
+func (sr *syncRepository) GetChangedTasks(tasks *[]model.Task, userId uint, workspaceId uint, since int64, until int64) error {
+	if err := sr.db.Preload("Labels").Where("user_id = ? AND workspace_id = ? AND change_seq > ? AND change_seq <= ?", userId, workspaceId, since, until).Order("change_seq").Find(tasks).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sr *syncRepository) GetTombstones(tombstones *[]model.TaskTombstone, userId uint, workspaceId uint, since int64, until int64) error {
+	if err := sr.db.Where("user_id = ? AND workspace_id = ? AND change_seq > ? AND change_seq <= ?", userId, workspaceId, since, until).Order("change_seq").Find(tombstones).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// PurgeTombstones deletes the tombstones of tasks deleted before the given
+// time and records how far the purge reached.
+func (sr *syncRepository) PurgeTombstones(userId uint, before time.Time) error {
+	return sr.db.Transaction(func(tx *gorm.DB) error {
+		var purged []model.TaskTombstone
+		result := tx.Raw("DELETE FROM task_tombstones WHERE user_id = ? AND deleted_at < ? RETURNING change_seq", userId, before).Scan(&purged)
+		if result.Error != nil {
+			return result.Error
+		}
+		var maxSeq int64
+		for _, tombstone := range purged {
+			if tombstone.ChangeSeq > maxSeq {
+				maxSeq = tombstone.ChangeSeq
+			}
+		}
+		if maxSeq == 0 {
+			return nil
+		}
+		return tx.Model(&model.SyncCounter{}).Where("user_id = ? AND purged_seq < ?", userId, maxSeq).Update("purged_seq", maxSeq).Error
+	})
+}
+
+// nextChangeSeq takes the next change sequence of the user. It must run in
+// the transaction that writes the change so that sequences become visible in
+// order.
+func nextChangeSeq(tx *gorm.DB, userId uint) (int64, error) {
+	var seq int64
+	err := tx.Raw(`INSERT INTO sync_counters (user_id, seq, purged_seq) VALUES (?, 1, 0)
+ON CONFLICT (user_id) DO UPDATE SET seq = sync_counters.seq + 1
+RETURNING seq`, userId).Scan(&seq).Error
+	return seq, err
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func setupSyncTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testsync.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	seedWorkspace(db, USER_ID)
+	return db
+}
+
+func TestSyncChangesAndTombstones(t *testing.T) {
+	db := setupSyncTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupSyncTables(db)
+	defer util.CleanupTaskTable(db)
+
+	tr := NewTaskRepository(db)
+	sr := NewSyncRepository(db)
+
+	first := model.Task{Title: "First", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	second := model.Task{Title: "Second", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	tr.Create(&first, 0)
+	tr.Create(&second, 0)
+
+	var counter model.SyncCounter
+	sr.GetCounter(&counter, uint(USER_ID))
+	since := counter.Seq
+
+	tr.Update(&model.Task{Title: "First updated"}, uint(USER_ID), uint(WORKSPACE_ID), first.ID, 0)
+	tr.Delete(uint(USER_ID), uint(WORKSPACE_ID), second.ID, 0)
+
+	if err := sr.GetCounter(&counter, uint(USER_ID)); err != nil {
+		t.Fatalf("GetCounter failed: %v", err)
+	}
+	if counter.Seq != since+2 {
+		t.Fatalf("Expected seq %d, got %d", since+2, counter.Seq)
+	}
+
+	var tasks []model.Task
+	if err := sr.GetChangedTasks(&tasks, uint(USER_ID), uint(WORKSPACE_ID), since, counter.Seq); err != nil {
+		t.Fatalf("GetChangedTasks failed: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "First updated" {
+		t.Errorf("Expected only the updated task, got %v", tasks)
+	}
+
+	var tombstones []model.TaskTombstone
+	if err := sr.GetTombstones(&tombstones, uint(USER_ID), uint(WORKSPACE_ID), since, counter.Seq); err != nil {
+		t.Fatalf("GetTombstones failed: %v", err)
+	}
+	if len(tombstones) != 1 || tombstones[0].TaskId != second.ID {
+		t.Errorf("Expected tombstone of task %d, got %v", second.ID, tombstones)
+	}
+}
+
+func TestPurgeTombstones(t *testing.T) {
+	db := setupSyncTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupSyncTables(db)
+	defer util.CleanupTaskTable(db)
+
+	tr := NewTaskRepository(db)
+	sr := NewSyncRepository(db)
+
+	task := model.Task{Title: "Task", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	tr.Create(&task, 0)
+	tr.Delete(uint(USER_ID), uint(WORKSPACE_ID), task.ID, 0)
+
+	if err := sr.PurgeTombstones(uint(USER_ID), time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("PurgeTombstones failed: %v", err)
+	}
+
+	var counter model.SyncCounter
+	sr.GetCounter(&counter, uint(USER_ID))
+	if counter.PurgedSeq != counter.Seq {
+		t.Errorf("Expected purged seq %d, got %d", counter.Seq, counter.PurgedSeq)
+	}
+}
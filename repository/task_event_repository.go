@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+)
+
+type ITaskEventRepository interface {
+	GetAfter(events *[]model.TaskEvent, userId uint, workspaceId uint, after uint, limit int) error
+}
+
+type taskEventRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskEventRepository(db *gorm.DB) ITaskEventRepository {
+	return &taskEventRepository{db}
+}
+
+// GetAfter loads the oldest limit events of the user on tasks of the
+// workspace with an ID above after.
+func (er *taskEventRepository) GetAfter(events *[]model.TaskEvent, userId uint, workspaceId uint, after uint, limit int) error {
+	err := er.db.Joins("JOIN tasks ON tasks.id = task_events.task_id").
+		Where("task_events.user_id = ? AND tasks.workspace_id = ? AND task_events.id > ?", userId, workspaceId, after).
+		Order("task_events.id").
+		Limit(limit).
+		Find(events).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -1,18 +1,28 @@
 package repository
 
 import (
+	"database/sql"
+	"fmt"
 	"go-rest-api/model"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type ITaskRepository interface {
-	Create(task *model.Task) error
-	GetAll(tasks *[]model.Task, userId uint) error
-	GetByID(task *model.Task, userId uint, taskId uint) error
-	Update(task *model.Task, userId uint, taskId uint) error
-	Delete(userId uint, taskId uint) error
+	Create(task *model.Task, maxTasks int64) error
+	GetAll(tasks *[]model.Task, userId uint, workspaceId uint, query model.TaskQuery) error
+	GetFiltered(tasks *[]model.Task, userId uint, workspaceId uint, filter model.TaskFilter) error
+	GetByID(task *model.Task, userId uint, workspaceId uint, taskId uint) error
+	GetAccessible(task *model.Task, userId uint, workspaceId uint, taskId uint) error
+	Update(task *model.Task, userId uint, workspaceId uint, taskId uint, version uint) error
+	Patch(task *model.Task, userId uint, workspaceId uint, taskId uint, version uint, patch model.TaskPatch) error
+	Delete(userId uint, workspaceId uint, taskId uint, version uint) error
+	WakeSnoozed(tasks *[]model.Task, before time.Time, limit int) error
+	GetAllByOwner(tasks *[]model.Task, userId uint) error
 }
 
 type taskRepository struct {
@@ -23,42 +33,457 @@ func NewTaskRepository(db *gorm.DB) ITaskRepository {
 	return &taskRepository{db}
 }
 
-func (tr *taskRepository) Create(task *model.Task) error {
-	if err := tr.db.Create(task).Error; err != nil {
+// Create, Update and Patch fail with model.ErrNotWorkspaceMember when the
+// owner or assignee is not a member of the task's workspace.
+// Create fails with a *model.QuotaError if the owner already has maxTasks
+// tasks. A maxTasks of 0 means unlimited.
+func (tr *taskRepository) Create(task *model.Task, maxTasks int64) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkTaskQuota(tx, task.UserId, maxTasks); err != nil {
+			return err
+		}
+		if err := checkProjectMember(tx, task.WorkspaceId, task.ProjectId, task.UserId); err != nil {
+			return err
+		}
+		if err := checkWorkspaceMember(tx, task.WorkspaceId, &task.UserId); err != nil {
+			return err
+		}
+		if err := checkWorkspaceMember(tx, task.WorkspaceId, task.AssigneeId); err != nil {
+			return err
+		}
+		if err := resolveLabels(tx, task.Labels, task.UserId, task.WorkspaceId); err != nil {
+			return err
+		}
+		seq, err := nextChangeSeq(tx, task.UserId)
+		if err != nil {
+			return err
+		}
+		task.ChangeSeq = seq
+		task.Version = 1
+		if err := tx.Omit("Labels.*").Create(task).Error; err != nil {
+			return err
+		}
+		return saveRevision(tx, task)
+	})
+}
+
+// GetAll applies the custom field filters and sort of query, whose Type
+// members must already be filled in. Tasks without a value for the sort field
+// come last. Tasks snoozed until later are left out unless
+// query.IncludeSnoozed is set; a task shows up again as soon as its
+// scheduled_at has passed, even before WakeSnoozed has run.
+func (tr *taskRepository) GetAll(tasks *[]model.Task, userId uint, workspaceId uint, query model.TaskQuery) error {
+	db := tr.db.Joins("User").Preload("Labels").Where("user_id = ? AND tasks.workspace_id = ?", userId, workspaceId)
+	if !query.IncludeSnoozed {
+		db = whereAwake(db)
+	}
+	for _, filter := range query.Filters {
+		db = whereCustomField(db, filter)
+	}
+	if query.Sort != nil {
+		direction := "ASC"
+		if query.Sort.Desc {
+			direction = "DESC"
+		}
+		db = db.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  customFieldValue(query.Sort.Type) + " " + direction + " NULLS LAST",
+			Vars: []interface{}{customFieldKey(query.Sort.FieldId)},
+		}})
+	}
+	if err := db.Order("tasks.created_at").Find(tasks).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetFiltered applies the absolute parts of filter. Relative due windows must
+// already be resolved into DueFrom and DueTo by the caller. Snoozed tasks are
+// left out.
+func (tr *taskRepository) GetFiltered(tasks *[]model.Task, userId uint, workspaceId uint, filter model.TaskFilter) error {
+	query := whereAwake(tr.db.Joins("User").Preload("Labels").Where("user_id = ? AND tasks.workspace_id = ?", userId, workspaceId))
+	if len(filter.Statuses) > 0 {
+		query = query.Where("tasks.status IN ?", filter.Statuses)
+	}
+	if len(filter.Priorities) > 0 {
+		query = query.Where("tasks.priority IN ?", filter.Priorities)
+	}
+	if len(filter.Labels) > 0 {
+		query = query.Where("tasks.id IN (?)", tr.db.Table("task_labels").
+			Select("task_labels.task_id").
+			Joins("JOIN labels ON labels.id = task_labels.label_id").
+			Where("labels.user_id = ? AND labels.workspace_id = ? AND labels.name IN ?", userId, workspaceId, filter.Labels).
+			Group("task_labels.task_id").
+			Having("COUNT(DISTINCT labels.name) = ?", len(filter.Labels)))
+	}
+	if len(filter.ExcludeLabels) > 0 {
+		query = query.Where("tasks.id NOT IN (?)", tr.db.Table("task_labels").
+			Select("task_labels.task_id").
+			Joins("JOIN labels ON labels.id = task_labels.label_id").
+			Where("labels.user_id = ? AND labels.workspace_id = ? AND labels.name IN ?", userId, workspaceId, filter.ExcludeLabels))
+	}
+	if filter.Due == model.DueNoDueDate {
+		query = query.Where("tasks.due_date IS NULL")
+	}
+	if filter.DueFrom != nil {
+		query = query.Where("tasks.due_date >= ?", *filter.DueFrom)
+	}
+	if filter.DueTo != nil {
+		query = query.Where("tasks.due_date < ?", *filter.DueTo)
+	}
+	if filter.TitleContains != "" {
+		query = query.Where("tasks.title ILIKE ?", "%"+escapeLike(filter.TitleContains)+"%")
+	}
+	if err := query.Order("tasks.due_date NULLS LAST, tasks.created_at").Find(tasks).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (tr *taskRepository) GetAll(tasks *[]model.Task, userId uint) error {
-	if err := tr.db.Joins("User").Where("user_id = ?", userId).Order("created_at").Find(tasks).Error; err != nil {
+func (tr *taskRepository) GetByID(task *model.Task, userId uint, workspaceId uint, taskId uint) error {
+	if err := tr.db.Joins("User").Preload("Labels").Where("user_id = ? AND tasks.workspace_id = ?", userId, workspaceId).First(task, taskId).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (tr *taskRepository) GetByID(task *model.Task, userId uint, taskId uint) error {
-	if err := tr.db.Joins("User").Where("user_id = ?", userId).First(task, taskId).Error; err != nil {
+// GetAccessible loads a task of the workspace the user may read, see
+// taskAccess.
+func (tr *taskRepository) GetAccessible(task *model.Task, userId uint, workspaceId uint, taskId uint) error {
+	if err := tr.db.Preload("Labels").Where("tasks.workspace_id = ?", workspaceId).Where(taskAccess("@user"), sql.Named("user", userId)).First(task, taskId).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (tr *taskRepository) Update(task *model.Task, userId uint, taskId uint) error {
-	result := tr.db.Model(task).Clauses(clause.Returning{}).Where("user_id = ? AND id = ?", userId, taskId).Updates(map[string]interface{}{
-		"title": task.Title,
+// Update, Patch and Delete only touch the task while it is still at version.
+// A version of 0 skips the check. model.ErrStaleVersion is returned when the
+// task exists but has moved on.
+func (tr *taskRepository) Update(task *model.Task, userId uint, workspaceId uint, taskId uint, version uint) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectMember(tx, workspaceId, task.ProjectId, userId); err != nil {
+			return err
+		}
+		if err := checkWorkspaceMember(tx, workspaceId, task.AssigneeId); err != nil {
+			return err
+		}
+		seq, err := nextChangeSeq(tx, userId)
+		if err != nil {
+			return err
+		}
+		values := map[string]interface{}{
+			"title":         task.Title,
+			"description":   task.Description,
+			"project_id":    task.ProjectId,
+			"custom_fields": task.CustomFields,
+			"due_date":      task.DueDate,
+			"recurrence":    task.Recurrence,
+			"assignee_id":   task.AssigneeId,
+			"version":       gorm.Expr("version + 1"),
+			"change_seq":    seq,
+		}
+		if task.Priority != "" {
+			values["priority"] = task.Priority
+		}
+		if task.Status != "" {
+			setStatus(values, task.Status)
+		}
+		result := whereVersion(tx.Model(task).Clauses(clause.Returning{}).Where("user_id = ? AND workspace_id = ? AND id = ?", userId, workspaceId, taskId), version).Updates(values)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return notUpdatedError(tx, userId, workspaceId, taskId)
+		}
+		if task.Labels == nil {
+			if err := tx.Model(task).Association("Labels").Find(&task.Labels); err != nil {
+				return err
+			}
+		} else if err := replaceLabels(tx, task, task.Labels); err != nil {
+			return err
+		}
+		return saveRevision(tx, task)
+	})
+}
+
+// Patch writes only the members present in patch and loads the resulting task
+// into task. Null members clear the field.
+func (tr *taskRepository) Patch(task *model.Task, userId uint, workspaceId uint, taskId uint, version uint, patch model.TaskPatch) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx, userId)
+		if err != nil {
+			return err
+		}
+		values := map[string]interface{}{
+			"updated_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+			"change_seq": seq,
+		}
+		if patch.Title.Set {
+			values["title"] = patch.Title.Value
+		}
+		if patch.Description.Set {
+			values["description"] = patch.Description.Value
+		}
+		if patch.CustomFields.Set {
+			values["custom_fields"] = patch.CustomFields.Value
+		}
+		if patch.ProjectId.Set {
+			values["project_id"] = nil
+			if !patch.ProjectId.Null {
+				if err := checkProjectMember(tx, workspaceId, &patch.ProjectId.Value, userId); err != nil {
+					return err
+				}
+				values["project_id"] = patch.ProjectId.Value
+			}
+		}
+		if patch.Status.Set {
+			setStatus(values, patch.Status.Value)
+		}
+		if patch.Priority.Set {
+			values["priority"] = patch.Priority.Value
+		}
+		if patch.DueDate.Set {
+			values["due_date"] = nil
+			if !patch.DueDate.Null {
+				values["due_date"] = patch.DueDate.Value
+			}
+		}
+		if patch.Recurrence.Set {
+			values["recurrence"] = patch.Recurrence.Value
+		}
+		if patch.AssigneeId.Set {
+			values["assignee_id"] = nil
+			if !patch.AssigneeId.Null {
+				if err := checkWorkspaceMember(tx, workspaceId, &patch.AssigneeId.Value); err != nil {
+					return err
+				}
+				values["assignee_id"] = patch.AssigneeId.Value
+			}
+		}
+		if patch.ScheduledAt.Set {
+			values["scheduled_at"] = nil
+			if !patch.ScheduledAt.Null {
+				values["scheduled_at"] = patch.ScheduledAt.Value
+			}
+		}
+		result := whereVersion(tx.Model(&model.Task{}).Where("user_id = ? AND workspace_id = ? AND id = ?", userId, workspaceId, taskId), version).Updates(values)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return notUpdatedError(tx, userId, workspaceId, taskId)
+		}
+		if err := tx.Where("user_id = ? AND workspace_id = ?", userId, workspaceId).First(task, taskId).Error; err != nil {
+			return err
+		}
+		if !patch.Labels.Set {
+			if err := tx.Model(task).Association("Labels").Find(&task.Labels); err != nil {
+				return err
+			}
+		} else if err := replaceLabels(tx, task, patch.Labels.Value); err != nil {
+			return err
+		}
+		return saveRevision(tx, task)
 	})
-	if result.Error != nil {
-		return result.Error
+}
+
+// Delete leaves a tombstone behind so that sync clients learn about the
+// deletion.
+func (tr *taskRepository) Delete(userId uint, workspaceId uint, taskId uint, version uint) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		result := whereVersion(tx.Where("user_id = ? AND workspace_id = ? AND id = ?", userId, workspaceId, taskId), version).Delete(&model.Task{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			if version != 0 {
+				return notUpdatedError(tx, userId, workspaceId, taskId)
+			}
+			return nil
+		}
+		seq, err := nextChangeSeq(tx, userId)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&model.TaskTombstone{TaskId: taskId, UserId: userId, WorkspaceId: workspaceId, ChangeSeq: seq, DeletedAt: time.Now()}).Error
+	})
+}
+
+// WakeSnoozed ends the snooze of up to limit tasks of any user whose
+// scheduled_at is not after before. Each woken task gets a new version, so
+// that sync clients pick it up, and a woke_up event. Rows locked by another
+// waker are skipped.
+func (tr *taskRepository) WakeSnoozed(tasks *[]model.Task, before time.Time, limit int) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("scheduled_at <= ?", before).Order("scheduled_at").Limit(limit).Find(tasks).Error
+		if err != nil {
+			return err
+		}
+		for i := range *tasks {
+			task := &(*tasks)[i]
+			seq, err := nextChangeSeq(tx, task.UserId)
+			if err != nil {
+				return err
+			}
+			values := map[string]interface{}{
+				"scheduled_at": nil,
+				"version":      gorm.Expr("version + 1"),
+				"change_seq":   seq,
+			}
+			if err := tx.Model(task).Clauses(clause.Returning{}).Updates(values).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(task).Association("Labels").Find(&task.Labels); err != nil {
+				return err
+			}
+			if err := saveRevision(tx, task); err != nil {
+				return err
+			}
+			if err := tx.Create(&model.TaskEvent{Type: model.TaskEventWokeUp, TaskId: task.ID, UserId: task.UserId}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// customFieldValue is the SQL for the value of a custom field of the given
+// type, with the field key as its only placeholder. Numbers are compared as
+// numbers and everything else as text, which orders dates correctly.
+func customFieldValue(fieldType string) string {
+	if fieldType == model.CustomFieldNumber {
+		return "CAST(tasks.custom_fields->>? AS numeric)"
+	}
+	return "tasks.custom_fields->>?"
+}
+
+func customFieldKey(fieldId uint) string {
+	return strconv.FormatUint(uint64(fieldId), 10)
+}
+
+// whereCustomField applies a filter. An eq filter on a multi_select field
+// keeps the tasks that have the option among their values.
+func whereCustomField(db *gorm.DB, filter model.CustomFieldFilter) *gorm.DB {
+	key := customFieldKey(filter.FieldId)
+	if filter.Type == model.CustomFieldMultiSelect {
+		return db.Where("tasks.custom_fields->? @> to_jsonb(CAST(? AS text))", key, filter.Value)
+	}
+	value := customFieldValue(filter.Type)
+	operand := "?"
+	if filter.Type == model.CustomFieldNumber {
+		operand = "CAST(? AS numeric)"
+	}
+	operator := "="
+	switch filter.Op {
+	case model.CustomFieldOpGte:
+		operator = ">="
+	case model.CustomFieldOpLte:
+		operator = "<="
+	}
+	return db.Where(value+" "+operator+" "+operand, key, filter.Value)
+}
+
+// taskAccess is a condition on the tasks table that holds when the user in
+// userExpr may read the task: they are a member of its workspace and own it,
+// are assigned to it, or were mentioned on it and are a member of its project.
+func taskAccess(userExpr string) string {
+	return fmt.Sprintf(`(EXISTS (
+SELECT 1 FROM workspace_members WHERE workspace_members.workspace_id = tasks.workspace_id AND workspace_members.user_id = %[1]s
+) AND (tasks.user_id = %[1]s OR tasks.assignee_id = %[1]s OR EXISTS (
+SELECT 1 FROM mentions JOIN project_members ON project_members.user_id = mentions.user_id
+WHERE mentions.task_id = tasks.id AND mentions.user_id = %[1]s AND project_members.project_id = tasks.project_id)))`, userExpr)
+}
+
+// checkProjectMember fails with model.ErrNotProjectMember unless projectId is
+// nil or the user is a member of the project and the project belongs to the
+// workspace.
+func checkProjectMember(tx *gorm.DB, workspaceId uint, projectId *uint, userId uint) error {
+	if projectId == nil {
+		return nil
+	}
+	var count int64
+	err := tx.Model(&model.ProjectMember{}).Joins("JOIN projects ON projects.id = project_members.project_id").
+		Where("project_members.project_id = ? AND project_members.user_id = ? AND projects.workspace_id = ?", *projectId, userId, workspaceId).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return model.ErrNotProjectMember
+	}
+	return nil
+}
+
+// whereAwake leaves out tasks that are snoozed until a time still to come.
+func whereAwake(tx *gorm.DB) *gorm.DB {
+	return tx.Where("tasks.scheduled_at IS NULL OR tasks.scheduled_at <= ?", time.Now())
+}
+
+func whereVersion(tx *gorm.DB, version uint) *gorm.DB {
+	if version == 0 {
+		return tx
 	}
-	if result.RowsAffected == 0 {
+	return tx.Where("version = ?", version)
+}
+
+// notUpdatedError tells a missing task apart from one whose version check
+// failed.
+func notUpdatedError(tx *gorm.DB, userId uint, workspaceId uint, taskId uint) error {
+	var count int64
+	if err := tx.Model(&model.Task{}).Where("user_id = ? AND workspace_id = ? AND id = ?", userId, workspaceId, taskId).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
 		return gorm.ErrRecordNotFound
 	}
+	return model.ErrStaleVersion
+}
+
+// setStatus adds status to values. completed_at is only stamped on the
+// transition into done so that repeated updates of a finished task keep its
+// original lead time.
+func setStatus(values map[string]interface{}, status string) {
+	values["status"] = status
+	values["completed_at"] = nil
+	if status == model.TaskStatusDone {
+		values["completed_at"] = gorm.Expr("COALESCE(completed_at, NOW())")
+	}
+}
+
+// replaceLabels makes labels the complete label set of task.
+func replaceLabels(tx *gorm.DB, task *model.Task, labels []model.Label) error {
+	task.Labels = labels
+	if len(labels) == 0 {
+		return tx.Model(task).Association("Labels").Clear()
+	}
+	if err := resolveLabels(tx, labels, task.UserId, task.WorkspaceId); err != nil {
+		return err
+	}
+	return tx.Model(task).Omit("Labels.*").Association("Labels").Replace(labels)
+}
+
+// resolveLabels fills in the ID of every label by name, creating the labels
+// the user does not have in the workspace yet.
+func resolveLabels(tx *gorm.DB, labels []model.Label, userId uint, workspaceId uint) error {
+	for i := range labels {
+		label := model.Label{Name: labels[i].Name, UserId: userId, WorkspaceId: workspaceId}
+		if err := tx.Where(label).FirstOrCreate(&label).Error; err != nil {
+			return err
+		}
+		labels[i] = label
+	}
 	return nil
 }
 
-func (tr *taskRepository) Delete(userId uint, taskId uint) error {
-	if err := tr.db.Where("user_id = ? AND id = ?", userId, taskId).Delete(&model.Task{}).Error; err != nil {
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// GetAllByOwner loads every task the user owns, in all of their workspaces
+// and snoozed or not, for the admin API.
+func (tr *taskRepository) GetAllByOwner(tasks *[]model.Task, userId uint) error {
+	if err := tr.db.Preload("Labels").Where("user_id = ?", userId).Order("workspace_id, created_at").Find(tasks).Error; err != nil {
 		return err
 	}
 	return nil
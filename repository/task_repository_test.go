@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"go-rest-api/model"
 	"go-rest-api/util"
+	"sync"
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -20,14 +23,15 @@ func TestCreateTask(t *testing.T) {
 	db := setupTaskTestDB()
 	query := fmt.Sprintf("INSERT INTO users (id, email, password) VALUES (%d, 'user1@testtask.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
 	db.Exec(query)
+	seedWorkspace(db, USER_ID)
 	defer util.CloseTestDB(db)
 	defer util.CleanupTaskTable(db)
 
 	tr := NewTaskRepository(db)
 
-	task := model.Task{Title: "Test Task", UserId: uint(USER_ID)}
+	task := model.Task{Title: "Test Task", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
 
-	if err := tr.Create(&task); err != nil {
+	if err := tr.Create(&task, 0); err != nil {
 		t.Fatalf("Create task failed: %v", err)
 	}
 
@@ -42,6 +46,43 @@ func TestCreateTask(t *testing.T) {
 	}
 }
 
+func TestCreateTask_Quota(t *testing.T) {
+	db := setupTaskTestDB()
+	query := fmt.Sprintf("INSERT INTO users (id, email, password) VALUES (%d, 'user1@testtask.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	db.Exec(query)
+	seedWorkspace(db, USER_ID)
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+
+	tr := NewTaskRepository(db)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- tr.Create(&model.Task{Title: "Concurrent", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}, 3)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	rejected := 0
+	for err := range errs {
+		if errors.Is(err, model.ErrQuotaExceeded) {
+			rejected++
+		} else if err != nil {
+			t.Fatalf("Create task failed: %v", err)
+		}
+	}
+	var count int64
+	db.Model(&model.Task{}).Where("user_id = ?", USER_ID).Count(&count)
+	if count != 3 || rejected != 2 {
+		t.Errorf("Expected 3 tasks and 2 rejected creates, got %d and %d", count, rejected)
+	}
+}
+
 func TestUpdateTask(t *testing.T) {
 	db := setupTaskTestDB()
 	defer util.CloseTestDB(db)
@@ -49,11 +90,11 @@ func TestUpdateTask(t *testing.T) {
 
 	tr := NewTaskRepository(db)
 
-	task := model.Task{Title: "Test Task", UserId: uint(USER_ID)}
+	task := model.Task{Title: "Test Task", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
 	db.Create(&task)
 
-	updatedTask := model.Task{Title: "Updated Title", UserId: uint(USER_ID)}
-	if err := tr.Update(&updatedTask, uint(USER_ID), task.ID); err != nil {
+	updatedTask := model.Task{Title: "Updated Title", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	if err := tr.Update(&updatedTask, uint(USER_ID), uint(WORKSPACE_ID), task.ID, 0); err != nil {
 		t.Fatalf("Update task failed: %v", err)
 	}
 
@@ -72,11 +113,11 @@ func TestGetAllTasks(t *testing.T) {
 
 	tr := NewTaskRepository(db)
 
-	db.Create(&model.Task{Title: "Test Title1", UserId: uint(USER_ID)})
-	db.Create(&model.Task{Title: "Test Title2", UserId: uint(USER_ID)})
+	db.Create(&model.Task{Title: "Test Title1", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)})
+	db.Create(&model.Task{Title: "Test Title2", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)})
 
 	var tasks []model.Task
-	if err := tr.GetAll(&tasks, uint(USER_ID)); err != nil {
+	if err := tr.GetAll(&tasks, uint(USER_ID), uint(WORKSPACE_ID), model.TaskQuery{}); err != nil {
 		t.Fatalf("GetAll task failed: %v", err)
 	}
 	if len(tasks) != 2 {
@@ -84,6 +125,71 @@ func TestGetAllTasks(t *testing.T) {
 	}
 }
 
+func TestGetAllTasks_Snoozed(t *testing.T) {
+	db := setupTaskTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+
+	tr := NewTaskRepository(db)
+
+	later := time.Now().Add(time.Hour)
+	earlier := time.Now().Add(-time.Hour)
+	db.Create(&model.Task{Title: "Awake", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)})
+	db.Create(&model.Task{Title: "Snoozed", ScheduledAt: &later, UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)})
+	db.Create(&model.Task{Title: "Woken", ScheduledAt: &earlier, UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)})
+
+	var tasks []model.Task
+	if err := tr.GetAll(&tasks, uint(USER_ID), uint(WORKSPACE_ID), model.TaskQuery{}); err != nil {
+		t.Fatalf("GetAll task failed: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("Expected 2 tasks without the snoozed one, got %d", len(tasks))
+	}
+
+	tasks = nil
+	if err := tr.GetAll(&tasks, uint(USER_ID), uint(WORKSPACE_ID), model.TaskQuery{IncludeSnoozed: true}); err != nil {
+		t.Fatalf("GetAll task failed: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Errorf("Expected 3 tasks with the snoozed one, got %d", len(tasks))
+	}
+}
+
+func TestWakeSnoozed(t *testing.T) {
+	db := setupTaskTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskEventTable(db)
+	defer util.CleanupTaskTable(db)
+
+	tr := NewTaskRepository(db)
+
+	snoozed := model.Task{Title: "Snoozed", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	tr.Create(&snoozed, 0)
+	until := time.Now().Add(time.Minute)
+	tr.Patch(&snoozed, uint(USER_ID), uint(WORKSPACE_ID), snoozed.ID, 0, model.TaskPatch{ScheduledAt: model.PatchField[time.Time]{Set: true, Value: until}})
+
+	var woken []model.Task
+	if err := tr.WakeSnoozed(&woken, time.Now(), 10); err != nil {
+		t.Fatalf("WakeSnoozed failed: %v", err)
+	}
+	if len(woken) != 0 {
+		t.Fatalf("Expected no task to wake yet, got %d", len(woken))
+	}
+
+	if err := tr.WakeSnoozed(&woken, until.Add(time.Second), 10); err != nil {
+		t.Fatalf("WakeSnoozed failed: %v", err)
+	}
+	if len(woken) != 1 || woken[0].ScheduledAt != nil || woken[0].Version != 3 {
+		t.Fatalf("Expected the task to wake at version 3, got %v", woken)
+	}
+
+	var events []model.TaskEvent
+	db.Where("task_id = ?", snoozed.ID).Find(&events)
+	if len(events) != 1 || events[0].Type != model.TaskEventWokeUp {
+		t.Errorf("Expected a woke_up event, got %v", events)
+	}
+}
+
 func TestGetTaskById(t *testing.T) {
 	db := setupTaskTestDB()
 	defer util.CloseTestDB(db)
@@ -91,11 +197,11 @@ func TestGetTaskById(t *testing.T) {
 
 	tr := NewTaskRepository(db)
 
-	expected := model.Task{ID: 1, Title: "Test Title1", UserId: uint(USER_ID)}
+	expected := model.Task{ID: 1, Title: "Test Title1", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
 	db.Create(&expected)
 
 	var actual model.Task
-	if err := tr.GetByID(&actual, uint(USER_ID), expected.ID); err != nil {
+	if err := tr.GetByID(&actual, uint(USER_ID), uint(WORKSPACE_ID), expected.ID); err != nil {
 		t.Fatalf("GetById task failed: %v", err)
 	}
 	if actual.ID != expected.ID {
@@ -105,3 +211,84 @@ func TestGetTaskById(t *testing.T) {
 		t.Fatalf("Expected title %s got %s", expected.Title, actual.Title)
 	}
 }
+
+func TestPatchTask(t *testing.T) {
+	db := setupTaskTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+
+	tr := NewTaskRepository(db)
+
+	dueDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	task := model.Task{Title: "Test Task", Priority: model.TaskPriorityHigh, DueDate: &dueDate, UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	db.Create(&task)
+
+	patch := model.TaskPatch{
+		Status:  model.PatchField[string]{Set: true, Value: model.TaskStatusDone},
+		DueDate: model.PatchField[time.Time]{Set: true, Null: true},
+	}
+	var patched model.Task
+	if err := tr.Patch(&patched, uint(USER_ID), uint(WORKSPACE_ID), task.ID, 0, patch); err != nil {
+		t.Fatalf("Patch task failed: %v", err)
+	}
+
+	if patched.Title != task.Title || patched.Priority != model.TaskPriorityHigh {
+		t.Errorf("Expected absent fields unchanged, got %s %s", patched.Title, patched.Priority)
+	}
+	if patched.DueDate != nil {
+		t.Errorf("Expected due date cleared, got %v", patched.DueDate)
+	}
+	if patched.Status != model.TaskStatusDone || patched.CompletedAt == nil {
+		t.Errorf("Expected task completed, got %s %v", patched.Status, patched.CompletedAt)
+	}
+}
+
+func TestUpdateTask_StaleVersion(t *testing.T) {
+	db := setupTaskTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+
+	tr := NewTaskRepository(db)
+
+	task := model.Task{Title: "Test Task", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	db.Create(&task)
+
+	first := model.Task{Title: "First"}
+	if err := tr.Update(&first, uint(USER_ID), uint(WORKSPACE_ID), task.ID, 1); err != nil {
+		t.Fatalf("Update task failed: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Expected version 2, got %d", first.Version)
+	}
+
+	second := model.Task{Title: "Second"}
+	if err := tr.Update(&second, uint(USER_ID), uint(WORKSPACE_ID), task.ID, 1); !errors.Is(err, model.ErrStaleVersion) {
+		t.Errorf("Expected ErrStaleVersion, got %v", err)
+	}
+	if err := tr.Delete(uint(USER_ID), uint(WORKSPACE_ID), task.ID, 1); !errors.Is(err, model.ErrStaleVersion) {
+		t.Errorf("Expected ErrStaleVersion, got %v", err)
+	}
+}
+
+func TestGetAllTasksByOwner(t *testing.T) {
+	db := setupTaskTestDB()
+	query := fmt.Sprintf("INSERT INTO users (id, email, password) VALUES (%d, 'user1@testtask.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	db.Exec(query)
+	seedWorkspace(db, USER_ID)
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+
+	tr := NewTaskRepository(db)
+
+	later := time.Now().Add(time.Hour)
+	tr.Create(&model.Task{Title: "Task 1", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}, 0)
+	tr.Create(&model.Task{Title: "Task 2", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), ScheduledAt: &later}, 0)
+
+	var tasks []model.Task
+	if err := tr.GetAllByOwner(&tasks, uint(USER_ID)); err != nil {
+		t.Fatalf("GetAllByOwner failed: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("Expected 2 tasks including the snoozed one, got %d", len(tasks))
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+)
+
+type ITaskRevisionRepository interface {
+	GetAll(revisions *[]model.TaskRevision, userId uint, workspaceId uint, taskId uint) error
+	GetByVersion(revision *model.TaskRevision, userId uint, workspaceId uint, taskId uint, version uint) error
+}
+
+type taskRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskRevisionRepository(db *gorm.DB) ITaskRevisionRepository {
+	return &taskRevisionRepository{db}
+}
+
+func (rr *taskRevisionRepository) GetAll(revisions *[]model.TaskRevision, userId uint, workspaceId uint, taskId uint) error {
+	if err := inWorkspaceTask(rr.db, workspaceId).Where("task_revisions.user_id = ? AND task_revisions.task_id = ?", userId, taskId).Order("task_revisions.version").Find(revisions).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *taskRevisionRepository) GetByVersion(revision *model.TaskRevision, userId uint, workspaceId uint, taskId uint, version uint) error {
+	if err := inWorkspaceTask(rr.db, workspaceId).Where("task_revisions.user_id = ? AND task_revisions.task_id = ? AND task_revisions.version = ?", userId, taskId, version).First(revision).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// inWorkspaceTask keeps the revisions of tasks of the workspace.
+func inWorkspaceTask(db *gorm.DB, workspaceId uint) *gorm.DB {
+	return db.Joins("JOIN tasks ON tasks.id = task_revisions.task_id").Where("tasks.workspace_id = ?", workspaceId)
+}
+
+// saveRevision snapshots task as it is after a write in tx.
+func saveRevision(tx *gorm.DB, task *model.Task) error {
+	labels := make([]string, 0, len(task.Labels))
+	for _, label := range task.Labels {
+		labels = append(labels, label.Name)
+	}
+	return tx.Create(&model.TaskRevision{
+		TaskId:       task.ID,
+		Version:      task.Version,
+		Title:        task.Title,
+		Description:  task.Description,
+		Status:       task.Status,
+		Priority:     task.Priority,
+		DueDate:      task.DueDate,
+		Labels:       labels,
+		Recurrence:   task.Recurrence,
+		AssigneeId:   task.AssigneeId,
+		ProjectId:    task.ProjectId,
+		CustomFields: task.CustomFields,
+		UserId:       task.UserId,
+	}).Error
+}
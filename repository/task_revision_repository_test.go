@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+)
+
+func TestTaskRevisions(t *testing.T) {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testrevision.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	seedWorkspace(db, USER_ID)
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+
+	tr := NewTaskRepository(db)
+	rr := NewTaskRevisionRepository(db)
+
+	task := model.Task{Title: "First", Status: model.TaskStatusTodo, Priority: model.TaskPriorityLow, UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), Labels: []model.Label{{Name: "work"}}}
+	tr.Create(&task, 0)
+	tr.Update(&model.Task{Title: "Second"}, uint(USER_ID), uint(WORKSPACE_ID), task.ID, 0)
+
+	var revisions []model.TaskRevision
+	if err := rr.GetAll(&revisions, uint(USER_ID), uint(WORKSPACE_ID), task.ID); err != nil {
+		t.Fatalf("GetAll revisions failed: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(revisions))
+	}
+
+	var first model.TaskRevision
+	if err := rr.GetByVersion(&first, uint(USER_ID), uint(WORKSPACE_ID), task.ID, 1); err != nil {
+		t.Fatalf("GetByVersion failed: %v", err)
+	}
+	if first.Title != "First" || len(first.Labels) != 1 || first.Labels[0] != "work" {
+		t.Errorf("Expected first revision with label work, got %v", first)
+	}
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ITwoFactorRepository interface {
+	Get(twoFactor *model.TwoFactor, userId uint) error
+	Enroll(twoFactor *model.TwoFactor) error
+	Enable(userId uint, step int64, codeHashes []string) error
+	Disable(userId uint) error
+	UseStep(userId uint, step int64) error
+	UseRecoveryCode(userId uint, codeHash string) error
+	CreateChallenge(challenge *model.TwoFactorChallenge) error
+	StartAttempt(jti string, userId uint, maxAttempts int) error
+	UseChallenge(jti string, userId uint) error
+	RecordFailure(failures *int, userId uint) error
+	Lock(userId uint, until time.Time) error
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) ITwoFactorRepository {
+	return &twoFactorRepository{db}
+}
+
+func (tr *twoFactorRepository) Get(twoFactor *model.TwoFactor, userId uint) error {
+	if err := tr.db.First(twoFactor, "user_id = ?", userId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Enroll replaces an enrollment that was never confirmed. It fails with
+// model.ErrTwoFactorEnabled if the user has two-factor authentication
+// enabled already.
+func (tr *twoFactorRepository) Enroll(twoFactor *model.TwoFactor) error {
+	result := tr.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "last_step", "created_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "two_factors.enabled_at IS NULL"}}},
+	}).Create(twoFactor)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+// Enable turns on the enrolled secret of the user, records step as used and
+// replaces the recovery codes with codeHashes.
+func (tr *twoFactorRepository) Enable(userId uint, step int64, codeHashes []string) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.TwoFactor{}).
+			Where("user_id = ? AND enabled_at IS NULL", userId).
+			Updates(map[string]interface{}{"enabled_at": time.Now(), "last_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrTwoFactorEnabled
+		}
+		if err := tx.Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := []model.RecoveryCode{}
+		for _, codeHash := range codeHashes {
+			codes = append(codes, model.RecoveryCode{UserId: userId, CodeHash: codeHash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Disable removes the secret and the recovery codes of the user.
+func (tr *twoFactorRepository) Disable(userId uint) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userId).Delete(&model.TwoFactor{}).Error
+	})
+}
+
+// UseStep records that a code of step was accepted. A code of that step or
+// an earlier one has been used already, and it fails with
+// model.ErrInvalidTwoFactorCode.
+func (tr *twoFactorRepository) UseStep(userId uint, step int64) error {
+	result := tr.db.Model(&model.TwoFactor{}).
+		Where("user_id = ? AND last_step < ?", userId, step).
+		Update("last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// UseRecoveryCode uses up the unused recovery code with codeHash. Any other
+// code fails with model.ErrInvalidTwoFactorCode.
+func (tr *twoFactorRepository) UseRecoveryCode(userId uint, codeHash string) error {
+	result := tr.db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// CreateChallenge also drops the challenges that have expired.
+func (tr *twoFactorRepository) CreateChallenge(challenge *model.TwoFactorChallenge) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", time.Now()).Delete(&model.TwoFactorChallenge{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(challenge).Error
+	})
+}
+
+// StartAttempt counts an attempt against the challenge with jti before its
+// code is checked, so that concurrent requests cannot exceed maxAttempts.
+// A challenge of another user, or one that is used up, expired or out of
+// attempts, fails with model.ErrInvalidChallenge.
+func (tr *twoFactorRepository) StartAttempt(jti string, userId uint, maxAttempts int) error {
+	result := tr.db.Model(&model.TwoFactorChallenge{}).
+		Where("jti = ? AND user_id = ? AND used_at IS NULL AND expires_at > ? AND attempts < ?", jti, userId, time.Now(), maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrInvalidChallenge
+	}
+	return nil
+}
+
+// UseChallenge uses up the challenge with jti after a right code and clears
+// the failed attempts of the user. A challenge that was used already fails
+// with model.ErrInvalidChallenge.
+func (tr *twoFactorRepository) UseChallenge(jti string, userId uint) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.TwoFactorChallenge{}).
+			Where("jti = ? AND user_id = ? AND used_at IS NULL", jti, userId).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrInvalidChallenge
+		}
+		return tx.Model(&model.TwoFactor{}).Where("user_id = ?", userId).
+			Updates(map[string]interface{}{"failed_attempts": 0, "locked_until": nil}).Error
+	})
+}
+
+// RecordFailure counts a wrong code of the user and loads how many there
+// have been since the last right one.
+func (tr *twoFactorRepository) RecordFailure(failures *int, userId uint) error {
+	twoFactor := model.TwoFactor{}
+	result := tr.db.Model(&twoFactor).Clauses(clause.Returning{}).
+		Where("user_id = ?", userId).
+		Update("failed_attempts", gorm.Expr("failed_attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	*failures = twoFactor.FailedAttempts
+	return nil
+}
+
+// Lock turns away two-factor logins of the user until until.
+func (tr *twoFactorRepository) Lock(userId uint, until time.Time) error {
+	if err := tr.db.Model(&model.TwoFactor{}).Where("user_id = ?", userId).Update("locked_until", until).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func setupTwoFactorTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testtwofactor.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	return db
+}
+
+func TestTwoFactor(t *testing.T) {
+	db := setupTwoFactorTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTwoFactorTables(db)
+
+	tfr := NewTwoFactorRepository(db)
+
+	if err := tfr.Enroll(&model.TwoFactor{UserId: uint(USER_ID), Secret: "FIRST"}); err != nil {
+		t.Fatalf("Enroll failed: %v", err)
+	}
+	if err := tfr.Enroll(&model.TwoFactor{UserId: uint(USER_ID), Secret: "SECOND"}); err != nil {
+		t.Fatalf("Enroll again before enabling failed: %v", err)
+	}
+	if err := tfr.Enable(uint(USER_ID), 100, []string{"code-a", "code-b"}); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	if err := tfr.Enroll(&model.TwoFactor{UserId: uint(USER_ID), Secret: "THIRD"}); !errors.Is(err, model.ErrTwoFactorEnabled) {
+		t.Errorf("Expected ErrTwoFactorEnabled, got %v", err)
+	}
+
+	twoFactor := model.TwoFactor{}
+	if err := tfr.Get(&twoFactor, uint(USER_ID)); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if twoFactor.Secret != "SECOND" || twoFactor.EnabledAt == nil {
+		t.Errorf("Expected SECOND to be enabled, got %s, %v", twoFactor.Secret, twoFactor.EnabledAt)
+	}
+
+	if err := tfr.UseStep(uint(USER_ID), 100); !errors.Is(err, model.ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected ErrInvalidTwoFactorCode for the step used to enable, got %v", err)
+	}
+	if err := tfr.UseStep(uint(USER_ID), 101); err != nil {
+		t.Errorf("UseStep failed: %v", err)
+	}
+	if err := tfr.UseRecoveryCode(uint(USER_ID), "code-a"); err != nil {
+		t.Errorf("UseRecoveryCode failed: %v", err)
+	}
+	if err := tfr.UseRecoveryCode(uint(USER_ID), "code-a"); !errors.Is(err, model.ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected ErrInvalidTwoFactorCode for a used recovery code, got %v", err)
+	}
+
+	if err := tfr.Disable(uint(USER_ID)); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+	if err := tfr.Get(&model.TwoFactor{}, uint(USER_ID)); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound after Disable, got %v", err)
+	}
+}
+
+func TestTwoFactorChallenge(t *testing.T) {
+	db := setupTwoFactorTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupTwoFactorTables(db)
+
+	tfr := NewTwoFactorRepository(db)
+	tfr.Enroll(&model.TwoFactor{UserId: uint(USER_ID), Secret: "SECRET"})
+
+	expiresAt := time.Now().Add(time.Minute)
+	if err := tfr.CreateChallenge(&model.TwoFactorChallenge{Jti: "first", UserId: uint(USER_ID), ExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("CreateChallenge failed: %v", err)
+	}
+	if err := tfr.StartAttempt("first", uint(USER_ID)+1, 2); !errors.Is(err, model.ErrInvalidChallenge) {
+		t.Errorf("Expected ErrInvalidChallenge for another user, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := tfr.StartAttempt("first", uint(USER_ID), 2); err != nil {
+			t.Fatalf("StartAttempt %d failed: %v", i+1, err)
+		}
+	}
+	if err := tfr.StartAttempt("first", uint(USER_ID), 2); !errors.Is(err, model.ErrInvalidChallenge) {
+		t.Errorf("Expected ErrInvalidChallenge once the attempts are used up, got %v", err)
+	}
+
+	var failures int
+	for i := 0; i < 3; i++ {
+		if err := tfr.RecordFailure(&failures, uint(USER_ID)); err != nil {
+			t.Fatalf("RecordFailure failed: %v", err)
+		}
+	}
+	if failures != 3 {
+		t.Errorf("Expected 3 failures, got %d", failures)
+	}
+	if err := tfr.Lock(uint(USER_ID), expiresAt); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	tfr.CreateChallenge(&model.TwoFactorChallenge{Jti: "second", UserId: uint(USER_ID), ExpiresAt: expiresAt})
+	if err := tfr.UseChallenge("second", uint(USER_ID)); err != nil {
+		t.Fatalf("UseChallenge failed: %v", err)
+	}
+	if err := tfr.UseChallenge("second", uint(USER_ID)); !errors.Is(err, model.ErrInvalidChallenge) {
+		t.Errorf("Expected ErrInvalidChallenge for a used challenge, got %v", err)
+	}
+	if err := tfr.StartAttempt("second", uint(USER_ID), 2); !errors.Is(err, model.ErrInvalidChallenge) {
+		t.Errorf("Expected ErrInvalidChallenge for an attempt on a used challenge, got %v", err)
+	}
+	twoFactor := model.TwoFactor{}
+	tfr.Get(&twoFactor, uint(USER_ID))
+	if twoFactor.FailedAttempts != 0 || twoFactor.LockedUntil != nil {
+		t.Errorf("Expected a used challenge to clear the failures and the lockout, got %d, %v", twoFactor.FailedAttempts, twoFactor.LockedUntil)
+	}
+}
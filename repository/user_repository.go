@@ -2,13 +2,24 @@ package repository
 
 import (
 	"go-rest-api/model"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+const personalWorkspaceName = "Personal"
+
 type IUserRepository interface {
 	GetByEmail(user *model.User, email string) error
+	GetByID(user *model.User, userId uint) error
+	GetByMentions(users *[]model.User, workspaceId uint, emails []string, handles []string) error
 	Create(user *model.User) error
+	VerifyEmail(userId uint, email string) error
+	UpdatePassword(userId uint, password string) error
+	SetPendingEmail(userId uint, email string) error
+	GetAll(users *[]model.User) error
+	SetDisabled(userId uint, disabledAt *time.Time) error
+	SetRole(userId uint, role string) error
 }
 
 type userRepository struct {
@@ -26,9 +37,115 @@ func (ur *userRepository) GetByEmail(user *model.User, email string) error {
 	return nil
 }
 
+func (ur *userRepository) GetByID(user *model.User, userId uint) error {
+	if err := ur.db.First(user, userId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByMentions loads the members of the workspace with any of the emails or
+// handles, ignoring case.
+func (ur *userRepository) GetByMentions(users *[]model.User, workspaceId uint, emails []string, handles []string) error {
+	err := ur.db.Joins("JOIN workspace_members ON workspace_members.user_id = users.id").
+		Where("workspace_members.workspace_id = ?", workspaceId).
+		Where("lower(email) IN ? OR lower(handle) IN ?", emails, handles).
+		Order("users.id").
+		Find(users).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Create gives the new user a personal workspace of their own.
 func (ur *userRepository) Create(user *model.User) error {
-	if err := ur.db.Create(user).Error; err != nil {
+	return ur.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return createWorkspace(tx, &model.Workspace{Name: personalWorkspaceName}, user.ID)
+	})
+}
+
+// VerifyEmail moves the user to email if it is their pending address, or
+// marks email as verified if it is still their unverified address. Otherwise
+// the link was used already or was sent to an old address, and it fails with
+// model.ErrInvalidVerification.
+func (ur *userRepository) VerifyEmail(userId uint, email string) error {
+	now := time.Now()
+	result := ur.db.Model(&model.User{}).
+		Where("id = ? AND pending_email = ?", userId, email).
+		Updates(map[string]interface{}{"email": email, "pending_email": nil, "email_verified_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	result = ur.db.Model(&model.User{}).
+		Where("id = ? AND email = ? AND email_verified_at IS NULL", userId, email).
+		Update("email_verified_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrInvalidVerification
+	}
+	return nil
+}
+
+// UpdatePassword stores password, which must already be hashed.
+func (ur *userRepository) UpdatePassword(userId uint, password string) error {
+	result := ur.db.Model(&model.User{}).Where("id = ?", userId).Update("password", password)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetPendingEmail replaces any earlier pending address of the user.
+func (ur *userRepository) SetPendingEmail(userId uint, email string) error {
+	result := ur.db.Model(&model.User{}).Where("id = ?", userId).Update("pending_email", email)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (ur *userRepository) GetAll(users *[]model.User) error {
+	if err := ur.db.Order("id").Find(users).Error; err != nil {
 		return err
 	}
 	return nil
 }
+
+// SetDisabled disables the user as of disabledAt, or enables them again if
+// it is nil.
+func (ur *userRepository) SetDisabled(userId uint, disabledAt *time.Time) error {
+	result := ur.db.Model(&model.User{}).Where("id = ?", userId).Update("disabled_at", disabledAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (ur *userRepository) SetRole(userId uint, role string) error {
+	result := ur.db.Model(&model.User{}).Where("id = ?", userId).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
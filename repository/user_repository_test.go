@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"errors"
 	"go-rest-api/model"
 	"go-rest-api/util"
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -55,3 +57,115 @@ func TestGetUserByEmail(t *testing.T) {
 		t.Fatalf("Expected Email %s got %s", expected.Email, actual.Email)
 	}
 }
+
+func TestGetUserByID(t *testing.T) {
+	db := setupUserTestDB()
+	defer util.CleanupTaskTable(db)
+	defer util.CleanupUserTabls(db)
+
+	ur := NewUserRepository(db)
+
+	expected := model.User{ID: 101, Email: "user2@testemail.com", Password: "testpass"}
+	db.Create(&expected)
+
+	var actual model.User
+	if err := ur.GetByID(&actual, expected.ID); err != nil {
+		t.Fatalf("GetByID user failed: %v", err)
+	}
+	if actual.Email != expected.Email {
+		t.Fatalf("Expected Email %s got %s", expected.Email, actual.Email)
+	}
+	if actual.Timezone != "UTC" {
+		t.Fatalf("Expected Timezone UTC got %s", actual.Timezone)
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	db := setupUserTestDB()
+	defer util.CleanupTaskTable(db)
+	defer util.CleanupUserTabls(db)
+
+	ur := NewUserRepository(db)
+
+	user := model.User{ID: 102, Email: "user3@testemail.com", Password: "testpass"}
+	db.Create(&user)
+
+	if err := ur.VerifyEmail(user.ID, "old@testemail.com"); !errors.Is(err, model.ErrInvalidVerification) {
+		t.Errorf("Expected ErrInvalidVerification for another address, got %v", err)
+	}
+	if err := ur.VerifyEmail(user.ID, user.Email); err != nil {
+		t.Fatalf("VerifyEmail failed: %v", err)
+	}
+	if err := ur.VerifyEmail(user.ID, user.Email); !errors.Is(err, model.ErrInvalidVerification) {
+		t.Errorf("Expected ErrInvalidVerification for a used link, got %v", err)
+	}
+
+	var actual model.User
+	ur.GetByID(&actual, user.ID)
+	if actual.EmailVerifiedAt == nil {
+		t.Errorf("Expected EmailVerifiedAt to be set")
+	}
+}
+
+func TestVerifyPendingEmail(t *testing.T) {
+	db := setupUserTestDB()
+	defer util.CleanupTaskTable(db)
+	defer util.CleanupUserTabls(db)
+
+	ur := NewUserRepository(db)
+
+	user := model.User{ID: 103, Email: "user4@testemail.com", Password: "testpass"}
+	db.Create(&user)
+
+	if err := ur.SetPendingEmail(user.ID, "user5@testemail.com"); err != nil {
+		t.Fatalf("SetPendingEmail failed: %v", err)
+	}
+	if err := ur.VerifyEmail(user.ID, "user5@testemail.com"); err != nil {
+		t.Fatalf("VerifyEmail failed: %v", err)
+	}
+
+	var actual model.User
+	ur.GetByID(&actual, user.ID)
+	if actual.Email != "user5@testemail.com" {
+		t.Errorf("Expected Email user5@testemail.com, got %s", actual.Email)
+	}
+	if actual.PendingEmail != nil || actual.EmailVerifiedAt == nil {
+		t.Errorf("Expected the pending address to be verified and cleared")
+	}
+}
+
+func TestSetUserDisabled(t *testing.T) {
+	db := setupUserTestDB()
+	defer util.CleanupTaskTable(db)
+	defer util.CleanupUserTabls(db)
+
+	ur := NewUserRepository(db)
+
+	user := model.User{ID: 104, Email: "user6@testemail.com", Password: "testpass"}
+	db.Create(&user)
+
+	disabledAt := time.Now()
+	if err := ur.SetDisabled(user.ID, &disabledAt); err != nil {
+		t.Fatalf("SetDisabled failed: %v", err)
+	}
+	var actual model.User
+	ur.GetByID(&actual, user.ID)
+	if actual.DisabledAt == nil {
+		t.Errorf("Expected DisabledAt to be set")
+	}
+	if actual.Role != model.RoleUser {
+		t.Errorf("Expected Role %s, got %s", model.RoleUser, actual.Role)
+	}
+
+	if err := ur.SetDisabled(user.ID, nil); err != nil {
+		t.Fatalf("SetDisabled failed: %v", err)
+	}
+	actual = model.User{}
+	ur.GetByID(&actual, user.ID)
+	if actual.DisabledAt != nil {
+		t.Errorf("Expected DisabledAt to be cleared")
+	}
+	if err := ur.SetDisabled(user.ID+1000, nil); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound for an unknown user, got %v", err)
+	}
+}
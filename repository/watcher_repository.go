@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"database/sql"
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IWatcherRepository interface {
+	Watch(userId uint, taskId uint) error
+	Unwatch(userId uint, taskId uint) error
+	GetWatchers(userIds *[]uint, taskId uint) error
+	GetWatchedTasks(tasks *[]model.Task, userId uint, workspaceId uint) error
+}
+
+type watcherRepository struct {
+	db *gorm.DB
+}
+
+func NewWatcherRepository(db *gorm.DB) IWatcherRepository {
+	return &watcherRepository{db}
+}
+
+// Watch is idempotent.
+func (wr *watcherRepository) Watch(userId uint, taskId uint) error {
+	if err := wr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.TaskWatcher{TaskId: taskId, UserId: userId}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *watcherRepository) Unwatch(userId uint, taskId uint) error {
+	if err := wr.db.Where("user_id = ? AND task_id = ?", userId, taskId).Delete(&model.TaskWatcher{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetWatchers lists the watchers of the task that still have access to it.
+func (wr *watcherRepository) GetWatchers(userIds *[]uint, taskId uint) error {
+	err := wr.db.Model(&model.TaskWatcher{}).
+		Joins("JOIN tasks ON tasks.id = task_watchers.task_id").
+		Where("task_watchers.task_id = ?", taskId).
+		Where(taskAccess("task_watchers.user_id")).
+		Order("task_watchers.user_id").
+		Pluck("task_watchers.user_id", userIds).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *watcherRepository) GetWatchedTasks(tasks *[]model.Task, userId uint, workspaceId uint) error {
+	err := wr.db.Preload("Labels").
+		Joins("JOIN task_watchers ON task_watchers.task_id = tasks.id").
+		Where("task_watchers.user_id = ? AND tasks.workspace_id = ?", userId, workspaceId).
+		Where(taskAccess("@user"), sql.Named("user", userId)).
+		Order("task_watchers.created_at").
+		Find(tasks).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+const OTHER_USER_ID = 998
+
+func setupWatcherTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testwatcher.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user2@testwatcher.com', 'password') ON CONFLICT (id) DO NOTHING", OTHER_USER_ID)
+	seedWorkspace(db, USER_ID, OTHER_USER_ID)
+	return db
+}
+
+func TestWatchers(t *testing.T) {
+	db := setupWatcherTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupWatcherTable(db)
+	defer util.CleanupTaskTable(db)
+
+	wr := NewWatcherRepository(db)
+	tr := NewTaskRepository(db)
+
+	assignee := uint(OTHER_USER_ID)
+	task := model.Task{Title: "Watched", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), AssigneeId: &assignee}
+	db.Create(&task)
+
+	wr.Watch(uint(USER_ID), task.ID)
+	if err := wr.Watch(uint(USER_ID), task.ID); err != nil {
+		t.Fatalf("Watching twice failed: %v", err)
+	}
+	wr.Watch(uint(OTHER_USER_ID), task.ID)
+
+	var watchers []uint
+	if err := wr.GetWatchers(&watchers, task.ID); err != nil {
+		t.Fatalf("GetWatchers failed: %v", err)
+	}
+	if len(watchers) != 2 {
+		t.Fatalf("Expected 2 watchers, got %v", watchers)
+	}
+
+	var accessible model.Task
+	if err := tr.GetAccessible(&accessible, uint(OTHER_USER_ID), uint(WORKSPACE_ID), task.ID); err != nil {
+		t.Fatalf("Expected the assignee to have access: %v", err)
+	}
+
+	tr.Patch(&task, uint(USER_ID), uint(WORKSPACE_ID), task.ID, 0, model.TaskPatch{AssigneeId: model.PatchField[uint]{Set: true, Null: true}})
+	watchers = nil
+	wr.GetWatchers(&watchers, task.ID)
+	if len(watchers) != 1 || watchers[0] != uint(USER_ID) {
+		t.Errorf("Expected only the owner to keep watching after unassignment, got %v", watchers)
+	}
+	var tasks []model.Task
+	wr.GetWatchedTasks(&tasks, uint(OTHER_USER_ID), uint(WORKSPACE_ID))
+	if len(tasks) != 0 {
+		t.Errorf("Expected no watched tasks without access, got %d", len(tasks))
+	}
+
+	if err := wr.Unwatch(uint(USER_ID), task.ID); err != nil {
+		t.Fatalf("Unwatch failed: %v", err)
+	}
+	tasks = nil
+	wr.GetWatchedTasks(&tasks, uint(USER_ID), uint(WORKSPACE_ID))
+	if len(tasks) != 0 {
+		t.Errorf("Expected no watched tasks after unwatching, got %d", len(tasks))
+	}
+}
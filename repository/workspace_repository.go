@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"go-rest-api/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IWorkspaceRepository interface {
+	Create(workspace *model.Workspace, userId uint) error
+	GetAll(members *[]model.WorkspaceMember, userId uint) error
+	GetMember(member *model.WorkspaceMember, workspaceId uint, userId uint) error
+	GetDefault(member *model.WorkspaceMember, userId uint) error
+	AddMember(member *model.WorkspaceMember) error
+	RemoveMember(workspaceId uint, userId uint) error
+}
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) IWorkspaceRepository {
+	return &workspaceRepository{db}
+}
+
+// Create makes the user the owner of the new workspace.
+func (wr *workspaceRepository) Create(workspace *model.Workspace, userId uint) error {
+	return wr.db.Transaction(func(tx *gorm.DB) error {
+		return createWorkspace(tx, workspace, userId)
+	})
+}
+
+// GetAll lists the memberships of the user together with their workspaces.
+func (wr *workspaceRepository) GetAll(members *[]model.WorkspaceMember, userId uint) error {
+	if err := wr.db.Joins("Workspace").Where("workspace_members.user_id = ?", userId).Order("workspace_members.workspace_id").Find(members).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *workspaceRepository) GetMember(member *model.WorkspaceMember, workspaceId uint, userId uint) error {
+	if err := wr.db.Where("workspace_id = ? AND user_id = ?", workspaceId, userId).First(member).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetDefault loads the oldest membership of the user, which is the personal
+// workspace made at sign up unless the user has left it.
+func (wr *workspaceRepository) GetDefault(member *model.WorkspaceMember, userId uint) error {
+	if err := wr.db.Where("user_id = ?", userId).Order("created_at, workspace_id").First(member).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddMember is idempotent. An existing member keeps their role.
+func (wr *workspaceRepository) AddMember(member *model.WorkspaceMember) error {
+	if err := wr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(member).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// RemoveMember also takes the user out of every project of the workspace.
+func (wr *workspaceRepository) RemoveMember(workspaceId uint, userId uint) error {
+	return wr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND project_id IN (?)", userId, tx.Model(&model.Project{}).Select("id").Where("workspace_id = ?", workspaceId)).
+			Delete(&model.ProjectMember{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("workspace_id = ? AND user_id = ?", workspaceId, userId).Delete(&model.WorkspaceMember{}).Error
+	})
+}
+
+func createWorkspace(tx *gorm.DB, workspace *model.Workspace, userId uint) error {
+	if err := tx.Create(workspace).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.WorkspaceMember{WorkspaceId: workspace.ID, UserId: userId, Role: model.WorkspaceRoleOwner}).Error
+}
+
+// checkWorkspaceMember fails with model.ErrNotWorkspaceMember unless userId is
+// nil or the user is a member of the workspace.
+func checkWorkspaceMember(tx *gorm.DB, workspaceId uint, userId *uint) error {
+	if userId == nil {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&model.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspaceId, *userId).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return model.ErrNotWorkspaceMember
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/util"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+const WORKSPACE_ID = 999
+
+// seedWorkspace makes the users members of WORKSPACE_ID, which the other
+// repository tests put their tasks, projects and labels in.
+func seedWorkspace(db *gorm.DB, userIds ...int) {
+	db.Exec("INSERT INTO workspaces (id, name) VALUES (?, 'Test') ON CONFLICT (id) DO NOTHING", WORKSPACE_ID)
+	for _, userId := range userIds {
+		db.Exec("INSERT INTO workspace_members (workspace_id, user_id, role) VALUES (?, ?, 'member') ON CONFLICT DO NOTHING", WORKSPACE_ID, userId)
+	}
+}
+
+func setupWorkspaceTestDB() *gorm.DB {
+	db := util.NewTestDB()
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user1@testworkspace.com', 'password') ON CONFLICT (id) DO NOTHING", USER_ID)
+	db.Exec("INSERT INTO users (id, email, password) VALUES (?, 'user2@testworkspace.com', 'password') ON CONFLICT (id) DO NOTHING", OTHER_USER_ID)
+	return db
+}
+
+func TestWorkspaceMembers(t *testing.T) {
+	db := setupWorkspaceTestDB()
+	defer util.CloseTestDB(db)
+	defer util.CleanupWorkspaceTables(db)
+
+	wr := NewWorkspaceRepository(db)
+
+	workspace := model.Workspace{Name: "Team"}
+	if err := wr.Create(&workspace, uint(USER_ID)); err != nil {
+		t.Fatalf("Create workspace failed: %v", err)
+	}
+
+	var owner model.WorkspaceMember
+	if err := wr.GetMember(&owner, workspace.ID, uint(USER_ID)); err != nil {
+		t.Fatalf("GetMember failed: %v", err)
+	}
+	if owner.Role != model.WorkspaceRoleOwner {
+		t.Errorf("Expected the creator to be the owner, got %s", owner.Role)
+	}
+
+	wr.AddMember(&model.WorkspaceMember{WorkspaceId: workspace.ID, UserId: uint(OTHER_USER_ID), Role: model.WorkspaceRoleAdmin})
+	if err := wr.AddMember(&model.WorkspaceMember{WorkspaceId: workspace.ID, UserId: uint(OTHER_USER_ID), Role: model.WorkspaceRoleMember}); err != nil {
+		t.Fatalf("Adding a member twice failed: %v", err)
+	}
+	var members []model.WorkspaceMember
+	if err := wr.GetAll(&members, uint(OTHER_USER_ID)); err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(members) != 1 || members[0].Workspace.Name != "Team" || members[0].Role != model.WorkspaceRoleAdmin {
+		t.Errorf("Expected an admin membership of Team, got %+v", members)
+	}
+
+	if err := wr.RemoveMember(workspace.ID, uint(OTHER_USER_ID)); err != nil {
+		t.Fatalf("RemoveMember failed: %v", err)
+	}
+	var removed model.WorkspaceMember
+	if err := wr.GetMember(&removed, workspace.ID, uint(OTHER_USER_ID)); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound after removal, got %v", err)
+	}
+}
+
+func TestTaskIsolatedByWorkspace(t *testing.T) {
+	db := setupWorkspaceTestDB()
+	seedWorkspace(db, USER_ID)
+	defer util.CloseTestDB(db)
+	defer util.CleanupTaskTable(db)
+	defer util.CleanupWorkspaceTables(db)
+
+	wr := NewWorkspaceRepository(db)
+	tr := NewTaskRepository(db)
+
+	other := model.Workspace{Name: "Other"}
+	wr.Create(&other, uint(USER_ID))
+
+	task := model.Task{Title: "Isolated", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID)}
+	if err := tr.Create(&task, 0); err != nil {
+		t.Fatalf("Create task failed: %v", err)
+	}
+
+	var found model.Task
+	if err := tr.GetByID(&found, uint(USER_ID), other.ID, task.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound from another workspace, got %v", err)
+	}
+	var tasks []model.Task
+	tr.GetAll(&tasks, uint(USER_ID), other.ID, model.TaskQuery{})
+	if len(tasks) != 0 {
+		t.Errorf("Expected no tasks in another workspace, got %d", len(tasks))
+	}
+
+	assignee := uint(OTHER_USER_ID)
+	outsider := model.Task{Title: "Outsider", UserId: uint(USER_ID), WorkspaceId: uint(WORKSPACE_ID), AssigneeId: &assignee}
+	if err := tr.Create(&outsider, 0); !errors.Is(err, model.ErrNotWorkspaceMember) {
+		t.Errorf("Expected ErrNotWorkspaceMember for a non-member assignee, got %v", err)
+	}
+}
@@ -2,25 +2,32 @@ package router
 
 import (
 	"go-rest-api/controller"
+	apimiddleware "go-rest-api/middleware"
+	"go-rest-api/model"
+	"go-rest-api/repository"
 	"net/http"
 	"os"
+	"time"
 
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-func NewRouter(uc controller.IUserController, tc controller.ITaskController) *echo.Echo {
+func NewRouter(uc controller.IUserController, wsc controller.IWorkspaceController, tc controller.ITaskController, qc controller.IQuickAddController, trc controller.ITaskRevisionController, snc controller.ISnoozeController, wc controller.IWatcherController, mc controller.IMentionController, pc controller.IProjectController, cfc controller.ICustomFieldController, sc controller.IStatsController, slc controller.ISmartListController, msc controller.IMilestoneController, syc controller.ISyncController, atc controller.IPersonalAccessTokenController, ac controller.IAdminController, ir repository.IIdempotencyRepository, wr repository.IWorkspaceRepository, vr repository.IRevocationRepository, ur repository.IUserRepository, atr repository.IPersonalAccessTokenRepository, rc apimiddleware.RequestCounter) *echo.Echo {
 	e := echo.New()
+	e.Pre(apimiddleware.WorkspacePath())
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"http://localhost:3000", os.Getenv("FE_URL")},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAccessControlAllowHeaders, echo.HeaderXCSRFToken},
-		AllowMethods:     []string{echo.GET, echo.PUT, echo.POST, echo.DELETE},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAccessControlAllowHeaders, echo.HeaderXCSRFToken, "If-Match", apimiddleware.HeaderIdempotencyKey, apimiddleware.HeaderWorkspaceID},
+		ExposeHeaders:    []string{"ETag", echo.HeaderRetryAfter, apimiddleware.HeaderIdempotentReplayed},
+		AllowMethods:     []string{echo.GET, echo.PUT, echo.PATCH, echo.POST, echo.DELETE},
 		AllowCredentials: true,
 	}))
 
 	e.Use(middleware.CSRFWithConfig(middleware.CSRFConfig{
+		Skipper:        apimiddleware.HasAuthorizationHeader,
 		CookiePath:     "/",
 		CookieDomain:   os.Getenv("API_DOMAIN"),
 		CookieHTTPOnly: true,
@@ -31,19 +38,133 @@ func NewRouter(uc controller.IUserController, tc controller.ITaskController) *ec
 
 	e.POST("/signup", uc.SignUp)
 	e.POST("/login", uc.LogIn)
+	e.POST("/login/2fa", uc.LogInTwoFactor)
 	e.POST("/logout", uc.LogOut)
+	e.POST("/refresh", uc.Refresh)
+	e.POST("/verify-email", uc.VerifyEmail)
+	e.POST("/password/forgot", uc.ForgotPassword)
+	e.POST("/password/reset", uc.ResetPassword)
 	e.GET("/csrf", uc.CsrfToken)
+	e.GET("/oidc/:provider/login", uc.OIDCLogin)
+	e.GET("/oidc/:provider/callback", uc.OIDCCallback)
+
+	tokenLookup := []middleware.ValuesExtractor{apimiddleware.BearerOrCookie("go-rest-api-token")}
+	// jwtMiddleware only takes login sessions and guards the account itself.
+	jwtMiddleware := echojwt.WithConfig(echojwt.Config{
+		TokenLookupFuncs: tokenLookup,
+		ParseTokenFunc: apimiddleware.ParseToken(apimiddleware.TokenConfig{
+			SigningKey: []byte(os.Getenv("SECRET")),
+			Store:      vr,
+		}),
+	})
+	// apiMiddleware also takes personal access tokens, so it must be followed
+	// by an apimiddleware.Scope.
+	apiMiddleware := echojwt.WithConfig(echojwt.Config{
+		TokenLookupFuncs: tokenLookup,
+		ParseTokenFunc: apimiddleware.ParseToken(apimiddleware.TokenConfig{
+			SigningKey:   []byte(os.Getenv("SECRET")),
+			Store:        vr,
+			AccessTokens: atr,
+		}),
+	})
+	tasksScope := apimiddleware.Scope("tasks")
+	e.POST("/logout/all", uc.LogOutEverywhere, jwtMiddleware)
+	e.POST("/verify-email/resend", uc.ResendVerification, jwtMiddleware)
+	workspace := apimiddleware.Workspace(apimiddleware.WorkspaceConfig{Store: wr})
+	emailVerified := apimiddleware.EmailVerified(apimiddleware.EmailVerifiedConfig{Store: ur})
+	// requestQuota counts requests to task data that the task usecase does
+	// not see, so it is left off routes that go through it.
+	requestQuota := apimiddleware.RequestQuota(apimiddleware.RequestQuotaConfig{Counter: rc})
+
+	ws := e.Group("/workspaces")
+	ws.Use(apiMiddleware, apimiddleware.Scope("workspaces"))
+	ws.GET("", wsc.GetAllWorkspaces)
+	ws.POST("", wsc.CreateWorkspace)
+	ws.POST("/:workspaceId/members", wsc.AddMember)
+	ws.DELETE("/:workspaceId/members/:userId", wsc.RemoveMember)
 
 	t := e.Group("/tasks")
-	t.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  []byte(os.Getenv("SECRET")),
-		TokenLookup: "cookie:go-rest-api-token",
-	}))
+	t.Use(apiMiddleware, tasksScope, emailVerified, workspace)
 	t.GET("", tc.GetAllTasks)
+	t.GET("/events", snc.GetEvents, requestQuota)
+	t.GET("/watched", wc.GetWatchedTasks, requestQuota)
 	t.GET("/:taskId", tc.GetTaskByID)
-	t.POST("", tc.CreateTask)
+	idempotencyTTL, _ := time.ParseDuration(os.Getenv("IDEMPOTENCY_TTL"))
+	idempotency := apimiddleware.Idempotency(apimiddleware.IdempotencyConfig{
+		Store: ir,
+		TTL:   idempotencyTTL,
+	})
+	t.POST("", tc.CreateTask, idempotency)
+	t.POST("/quick", qc.QuickAddTask, idempotency)
 	t.PUT("/:taskId", tc.UpdateTask)
+	t.PATCH("/:taskId", tc.PatchTask)
 	t.DELETE("/:taskId", tc.DeleteTask)
+	t.GET("/:taskId/revisions", trc.GetRevisions, requestQuota)
+	t.GET("/:taskId/revisions/diff", trc.DiffRevisions, requestQuota)
+	t.POST("/:taskId/revisions/:rev/revert", trc.RevertToRevision)
+	t.POST("/:taskId/snooze", snc.SnoozeTask)
+	t.DELETE("/:taskId/snooze", snc.UnsnoozeTask)
+	t.POST("/:taskId/watch", wc.WatchTask, requestQuota)
+	t.DELETE("/:taskId/watch", wc.UnwatchTask, requestQuota)
+	t.GET("/:taskId/comments", tc.GetComments)
+	t.POST("/:taskId/comments", tc.AddComment)
+	t.GET("/:taskId/attachments", tc.GetAttachments)
+	t.POST("/:taskId/attachments", tc.AddAttachment)
+	t.GET("/:taskId/attachments/:attachmentId", tc.DownloadAttachment)
+	t.DELETE("/:taskId/attachments/:attachmentId", tc.DeleteAttachment)
+
+	e.GET("/me/usage", tc.GetUsage, apiMiddleware, tasksScope, emailVerified)
+	e.PUT("/me/password", uc.ChangePassword, jwtMiddleware)
+	e.PUT("/me/email", uc.ChangeEmail, jwtMiddleware)
+	e.POST("/me/2fa/enroll", uc.EnrollTwoFactor, jwtMiddleware)
+	e.POST("/me/2fa/confirm", uc.ConfirmTwoFactor, jwtMiddleware)
+	e.POST("/me/2fa/disable", uc.DisableTwoFactor, jwtMiddleware)
+	e.GET("/me/tokens", atc.GetAllTokens, jwtMiddleware)
+	e.POST("/me/tokens", atc.CreateToken, jwtMiddleware)
+	e.DELETE("/me/tokens/:tokenId", atc.RevokeToken, jwtMiddleware)
+	e.GET("/me/mentions", mc.GetMentions, apiMiddleware, tasksScope, emailVerified, workspace, requestQuota)
+	e.GET("/stats", sc.GetStats, apiMiddleware, tasksScope, emailVerified, workspace, requestQuota)
+	e.GET("/sync", syc.Sync, apiMiddleware, tasksScope, emailVerified, workspace, requestQuota)
+	e.POST("/sync", syc.Push, apiMiddleware, tasksScope, emailVerified, workspace)
+
+	p := e.Group("/projects")
+	p.Use(apiMiddleware, apimiddleware.Scope("projects"), emailVerified, workspace)
+	p.GET("", pc.GetAllProjects)
+	p.POST("", pc.CreateProject)
+	p.POST("/:projectId/members", pc.AddMember)
+	p.DELETE("/:projectId/members/:userId", pc.RemoveMember)
+	p.GET("/:projectId/fields", cfc.GetCustomFields)
+	p.POST("/:projectId/fields", cfc.CreateCustomField)
+	p.DELETE("/:projectId/fields/:fieldId", cfc.DeleteCustomField)
+
+	ms := e.Group("/milestones")
+	ms.Use(apiMiddleware, apimiddleware.Scope("milestones"), emailVerified, workspace, requestQuota)
+	ms.GET("", msc.GetAllMilestones)
+	ms.POST("", msc.CreateMilestone)
+	ms.GET("/:milestoneId", msc.GetMilestoneSummary)
+	ms.POST("/:milestoneId/tasks", msc.AddTask)
+	ms.DELETE("/:milestoneId/tasks/:taskId", msc.RemoveTask)
+	ms.POST("/:milestoneId/close", msc.CloseMilestone)
+
+	sl := e.Group("/smart-lists")
+	sl.Use(apiMiddleware, tasksScope, emailVerified, workspace, requestQuota)
+	sl.GET("", slc.GetAllSmartLists)
+	sl.GET("/:listId", slc.GetSmartListByID)
+	sl.GET("/:listId/tasks", slc.GetSmartListTasks)
+	sl.POST("", slc.CreateSmartList)
+	sl.PUT("/:listId", slc.UpdateSmartList)
+	sl.DELETE("/:listId", slc.DeleteSmartList)
+
+	// Admin routes are for people, so personal access tokens are not taken.
+	can := apimiddleware.Permission(apimiddleware.PermissionConfig{Store: ur})
+	a := e.Group("/admin")
+	a.Use(jwtMiddleware)
+	a.GET("/users", ac.GetAllUsers, can(model.PermissionUsersRead))
+	a.GET("/users/:userId/tasks", ac.GetUserTasks, can(model.PermissionTasksReadAll))
+	a.POST("/users/:userId/disable", ac.DisableUser, can(model.PermissionUsersManage))
+	a.POST("/users/:userId/enable", ac.EnableUser, can(model.PermissionUsersManage))
+	a.PUT("/users/:userId/role", ac.SetRole, can(model.PermissionUsersManage))
+	a.GET("/audit", ac.GetAuditLog, can(model.PermissionAuditRead))
 
 	return e
 }
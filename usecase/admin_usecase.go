@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"time"
+)
+
+// auditLogLimit is how many of the latest audit entries GetAuditLog returns.
+const auditLogLimit = 200
+
+// IAdminUsecase lets admins and support staff act on other users. Every
+// method checks the permission of the actor and records an audit entry
+// before it does anything, so that nothing happens unaudited, and then the
+// outcome of the action on that entry.
+type IAdminUsecase interface {
+	GetAllUsers(actorId uint) ([]model.AdminUserResponse, error)
+	GetUserTasks(actorId uint, userId uint) ([]model.TaskResponse, error)
+	DisableUser(actorId uint, userId uint) error
+	EnableUser(actorId uint, userId uint) error
+	SetRole(actorId uint, userId uint, req model.RoleRequest) error
+	GetAuditLog(actorId uint) ([]model.AuditEntryResponse, error)
+}
+
+type adminUsecase struct {
+	ur repository.IUserRepository
+	tr repository.ITaskRepository
+	ar repository.IAuditRepository
+	uu IUserUsecase
+	av validator.IAdminValidator
+}
+
+func NewAdminUsecase(ur repository.IUserRepository, tr repository.ITaskRepository, ar repository.IAuditRepository, uu IUserUsecase, av validator.IAdminValidator) IAdminUsecase {
+	return &adminUsecase{ur, tr, ar, uu, av}
+}
+
+func (au *adminUsecase) GetAllUsers(actorId uint) ([]model.AdminUserResponse, error) {
+	entry, err := au.authorize(actorId, model.PermissionUsersRead, model.AuditActionListUsers, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var users []model.User
+	if err := au.finish(entry, au.ur.GetAll(&users)); err != nil {
+		return nil, err
+	}
+
+	userResponses := []model.AdminUserResponse{}
+	for _, user := range users {
+		userResponses = append(userResponses, newAdminUserResponse(user))
+	}
+	return userResponses, nil
+}
+
+func (au *adminUsecase) GetUserTasks(actorId uint, userId uint) ([]model.TaskResponse, error) {
+	entry, err := au.authorize(actorId, model.PermissionTasksReadAll, model.AuditActionViewTasks, &userId, "")
+	if err != nil {
+		return nil, err
+	}
+	var tasks []model.Task
+	if err := au.finish(entry, au.tr.GetAllByOwner(&tasks, userId)); err != nil {
+		return nil, err
+	}
+
+	taskResponses := []model.TaskResponse{}
+	for _, task := range tasks {
+		taskResponses = append(taskResponses, newTaskResponse(task))
+	}
+	return taskResponses, nil
+}
+
+// DisableUser keeps the user from logging in and using their personal access
+// tokens, and ends their sessions.
+func (au *adminUsecase) DisableUser(actorId uint, userId uint) error {
+	if actorId == userId {
+		return model.ErrOwnAccount
+	}
+	entry, err := au.authorize(actorId, model.PermissionUsersManage, model.AuditActionDisableUser, &userId, "")
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	err = au.ur.SetDisabled(userId, &now)
+	if err == nil {
+		err = au.uu.LogOutEverywhere(userId)
+	}
+	return au.finish(entry, err)
+}
+
+func (au *adminUsecase) EnableUser(actorId uint, userId uint) error {
+	entry, err := au.authorize(actorId, model.PermissionUsersManage, model.AuditActionEnableUser, &userId, "")
+	if err != nil {
+		return err
+	}
+	return au.finish(entry, au.ur.SetDisabled(userId, nil))
+}
+
+// SetRole cannot change the actor's own role, so the last admin cannot lock
+// everyone out by accident.
+func (au *adminUsecase) SetRole(actorId uint, userId uint, req model.RoleRequest) error {
+	if err := au.av.RoleValidate(req); err != nil {
+		return err
+	}
+	if actorId == userId {
+		return model.ErrOwnAccount
+	}
+	entry, err := au.authorize(actorId, model.PermissionUsersManage, model.AuditActionSetRole, &userId, req.Role)
+	if err != nil {
+		return err
+	}
+	return au.finish(entry, au.ur.SetRole(userId, req.Role))
+}
+
+func (au *adminUsecase) GetAuditLog(actorId uint) ([]model.AuditEntryResponse, error) {
+	entry, err := au.authorize(actorId, model.PermissionAuditRead, model.AuditActionViewAudit, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var entries []model.AuditEntry
+	if err := au.finish(entry, au.ar.GetAll(&entries, auditLogLimit)); err != nil {
+		return nil, err
+	}
+
+	entryResponses := []model.AuditEntryResponse{}
+	for _, entry := range entries {
+		entryResponses = append(entryResponses, model.AuditEntryResponse{
+			ID:           entry.ID,
+			ActorId:      entry.ActorId,
+			Action:       entry.Action,
+			TargetUserId: entry.TargetUserId,
+			Detail:       entry.Detail,
+			Outcome:      entry.Outcome,
+			Error:        entry.Error,
+			CreatedAt:    entry.CreatedAt,
+		})
+	}
+	return entryResponses, nil
+}
+
+// authorize fails with model.ErrPermissionDenied unless the role of the actor
+// grants permission, and otherwise records the action in the audit log as
+// pending. The caller passes the entry to finish once the action is done.
+func (au *adminUsecase) authorize(actorId uint, permission string, action string, targetUserId *uint, detail string) (*model.AuditEntry, error) {
+	actor := model.User{}
+	if err := au.ur.GetByID(&actor, actorId); err != nil {
+		return nil, err
+	}
+	if actor.DisabledAt != nil {
+		return nil, model.ErrAccountDisabled
+	}
+	if !model.HasPermission(actor.Role, permission) {
+		return nil, model.ErrPermissionDenied
+	}
+	entry := &model.AuditEntry{ActorId: actorId, Action: action, TargetUserId: targetUserId, Detail: detail, Outcome: model.AuditOutcomePending}
+	if err := au.ar.Create(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// finish records on entry whether the action succeeded, and returns the
+// error of the action, if any, ahead of one from recording it.
+func (au *adminUsecase) finish(entry *model.AuditEntry, actionErr error) error {
+	entry.Outcome = model.AuditOutcomeSucceeded
+	if actionErr != nil {
+		entry.Outcome = model.AuditOutcomeFailed
+		entry.Error = actionErr.Error()
+	}
+	if err := au.ar.SetOutcome(entry); err != nil && actionErr == nil {
+		return err
+	}
+	return actionErr
+}
+
+func newAdminUserResponse(user model.User) model.AdminUserResponse {
+	return model.AdminUserResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		Handle:        user.Handle,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		DisabledAt:    user.DisabledAt,
+		CreatedAt:     user.CreatedAt,
+	}
+}
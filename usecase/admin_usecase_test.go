@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/mailer"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func newMockAuditRepository() *MockAuditRepository {
+	ar := &MockAuditRepository{}
+	ar.On("Create", mock.Anything).Return(nil).Maybe()
+	ar.On("SetOutcome", mock.Anything).Return(nil).Maybe()
+	return ar
+}
+
+func (ar *MockAuditRepository) Create(entry *model.AuditEntry) error {
+	args := ar.Called(entry)
+	return args.Error(0)
+}
+
+func (ar *MockAuditRepository) SetOutcome(entry *model.AuditEntry) error {
+	args := ar.Called(entry)
+	return args.Error(0)
+}
+
+func (ar *MockAuditRepository) GetAll(entries *[]model.AuditEntry, limit int) error {
+	args := ar.Called(entries, limit)
+	return args.Error(0)
+}
+
+type MockAdminValidator struct {
+	mock.Mock
+}
+
+func newMockAdminValidator() *MockAdminValidator {
+	return &MockAdminValidator{}
+}
+
+func (av *MockAdminValidator) RoleValidate(req model.RoleRequest) error {
+	args := av.Called(req)
+	return args.Error(0)
+}
+
+// mockActors makes user 1 a regular user, 2 support staff and 3 an admin.
+func mockActors(mr *MockUserRepository) {
+	for _, actor := range []model.User{
+		{ID: 1, Email: "user@test.com", Role: model.RoleUser},
+		{ID: 2, Email: "support@test.com", Role: model.RoleSupport},
+		{ID: 3, Email: "admin@test.com", Role: model.RoleAdmin},
+	} {
+		mr.On("GetByID", mock.Anything, actor.ID).
+			Run(func(args mock.Arguments) {
+				*args.Get(0).(*model.User) = actor
+			}).
+			Return(nil)
+	}
+}
+
+func newTestAdminUsecase(mr *MockUserRepository, tr *MockTaskRepository, ar *MockAuditRepository, rr *MockRefreshTokenRepository) IAdminUsecase {
+	uu := NewUserUsecase(mr, newMockUserValidator(), rr, repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+	return NewAdminUsecase(mr, tr, ar, uu, newMockAdminValidator())
+}
+
+func TestGetAllUsers_Support_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mockActors(mr)
+	mr.On("GetAll", mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]model.User) = []model.User{{ID: 1, Email: "user@test.com", Password: "hash", Role: model.RoleUser}}
+		}).
+		Return(nil)
+	ar := newMockAuditRepository()
+
+	au := newTestAdminUsecase(mr, newMockTaskRepository(), ar, newMockRefreshTokenRepository())
+
+	res, err := au.GetAllUsers(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.AdminUserResponse{{ID: 1, Email: "user@test.com", Role: model.RoleUser}}, res)
+	entry := &model.AuditEntry{ActorId: 2, Action: model.AuditActionListUsers, Outcome: model.AuditOutcomeSucceeded}
+	ar.AssertCalled(t, "Create", entry)
+	ar.AssertCalled(t, "SetOutcome", entry)
+}
+
+func TestGetAllUsers_User_Failure(t *testing.T) {
+	mr := newMockUserRepository()
+	mockActors(mr)
+	ar := newMockAuditRepository()
+
+	au := newTestAdminUsecase(mr, newMockTaskRepository(), ar, newMockRefreshTokenRepository())
+
+	_, err := au.GetAllUsers(1)
+	assert.ErrorIs(t, err, model.ErrPermissionDenied)
+	mr.AssertNotCalled(t, "GetAll", mock.Anything)
+	ar.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestGetUserTasks_Support_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mockActors(mr)
+	tr := newMockTaskRepository()
+	tr.On("GetAllByOwner", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]model.Task) = []model.Task{{ID: 5, Title: "Task", UserId: 1, WorkspaceId: 7}}
+		}).
+		Return(nil)
+	ar := newMockAuditRepository()
+
+	au := newTestAdminUsecase(mr, tr, ar, newMockRefreshTokenRepository())
+
+	res, err := au.GetUserTasks(2, 1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, uint(5), res[0].ID)
+	target := uint(1)
+	ar.AssertCalled(t, "SetOutcome", &model.AuditEntry{ActorId: 2, Action: model.AuditActionViewTasks, TargetUserId: &target, Outcome: model.AuditOutcomeSucceeded})
+}
+
+func TestDisableUser_Admin_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mockActors(mr)
+	mr.On("SetDisabled", uint(1), mock.Anything).Return(nil)
+	rr := newMockRefreshTokenRepository()
+	rr.On("RevokeUser", uint(1)).Return(nil)
+	ar := newMockAuditRepository()
+
+	au := newTestAdminUsecase(mr, newMockTaskRepository(), ar, rr)
+
+	err := au.DisableUser(3, 1)
+	assert.NoError(t, err)
+	mr.AssertCalled(t, "SetDisabled", uint(1), mock.MatchedBy(func(disabledAt *time.Time) bool {
+		return disabledAt != nil
+	}))
+	rr.AssertCalled(t, "RevokeUser", uint(1))
+	target := uint(1)
+	ar.AssertCalled(t, "SetOutcome", &model.AuditEntry{ActorId: 3, Action: model.AuditActionDisableUser, TargetUserId: &target, Outcome: model.AuditOutcomeSucceeded})
+}
+
+func TestDisableUser_Admin_ActionFails(t *testing.T) {
+	mr := newMockUserRepository()
+	mockActors(mr)
+	mr.On("SetDisabled", uint(1), mock.Anything).Return(errors.New("database is down"))
+	rr := newMockRefreshTokenRepository()
+	ar := &MockAuditRepository{}
+	ar.On("Create", mock.Anything).
+		Run(func(args mock.Arguments) {
+			assert.Equal(t, model.AuditOutcomePending, args.Get(0).(*model.AuditEntry).Outcome, "the entry should be written before the action")
+		}).
+		Return(nil)
+	ar.On("SetOutcome", mock.Anything).Return(nil)
+
+	au := newTestAdminUsecase(mr, newMockTaskRepository(), ar, rr)
+
+	err := au.DisableUser(3, 1)
+	assert.EqualError(t, err, "database is down")
+	rr.AssertNotCalled(t, "RevokeUser", mock.Anything)
+	target := uint(1)
+	ar.AssertCalled(t, "SetOutcome", &model.AuditEntry{ActorId: 3, Action: model.AuditActionDisableUser, TargetUserId: &target, Outcome: model.AuditOutcomeFailed, Error: "database is down"})
+}
+
+func TestDisableUser_Support_Failure(t *testing.T) {
+	mr := newMockUserRepository()
+	mockActors(mr)
+
+	au := newTestAdminUsecase(mr, newMockTaskRepository(), newMockAuditRepository(), newMockRefreshTokenRepository())
+
+	err := au.DisableUser(2, 1)
+	assert.ErrorIs(t, err, model.ErrPermissionDenied)
+	mr.AssertNotCalled(t, "SetDisabled", mock.Anything, mock.Anything)
+}
+
+func TestDisableUser_Self_Failure(t *testing.T) {
+	mr := newMockUserRepository()
+	mockActors(mr)
+
+	au := newTestAdminUsecase(mr, newMockTaskRepository(), newMockAuditRepository(), newMockRefreshTokenRepository())
+
+	err := au.DisableUser(3, 3)
+	assert.ErrorIs(t, err, model.ErrOwnAccount)
+	mr.AssertNotCalled(t, "SetDisabled", mock.Anything, mock.Anything)
+}
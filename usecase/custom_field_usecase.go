@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+)
+
+type ICustomFieldUsecase interface {
+	GetCustomFields(userId uint, workspaceId uint, projectId uint) ([]model.CustomFieldResponse, error)
+	CreateCustomField(userId uint, workspaceId uint, projectId uint, field model.CustomField) (model.CustomFieldResponse, error)
+	DeleteCustomField(userId uint, workspaceId uint, projectId uint, fieldId uint) error
+}
+
+type customFieldUsecase struct {
+	pr repository.IProjectRepository
+	fr repository.ICustomFieldRepository
+	fv validator.ICustomFieldValidator
+}
+
+func NewCustomFieldUsecase(pr repository.IProjectRepository, fr repository.ICustomFieldRepository, fv validator.ICustomFieldValidator) ICustomFieldUsecase {
+	return &customFieldUsecase{pr, fr, fv}
+}
+
+// GetCustomFields lists the fields of a project the user is a member of.
+func (cu *customFieldUsecase) GetCustomFields(userId uint, workspaceId uint, projectId uint) ([]model.CustomFieldResponse, error) {
+	project := model.Project{}
+	if err := cu.pr.GetByID(&project, userId, workspaceId, projectId); err != nil {
+		return nil, err
+	}
+	var fields []model.CustomField
+	if err := cu.fr.GetByProject(&fields, projectId); err != nil {
+		return nil, err
+	}
+
+	fieldResponses := []model.CustomFieldResponse{}
+	for _, field := range fields {
+		fieldResponses = append(fieldResponses, newCustomFieldResponse(field))
+	}
+	return fieldResponses, nil
+}
+
+// CreateCustomField lets the owner of the project define a field.
+func (cu *customFieldUsecase) CreateCustomField(userId uint, workspaceId uint, projectId uint, field model.CustomField) (model.CustomFieldResponse, error) {
+	if err := cu.fv.CustomFieldValidate(field); err != nil {
+		return model.CustomFieldResponse{}, err
+	}
+	if err := cu.checkOwner(userId, workspaceId, projectId); err != nil {
+		return model.CustomFieldResponse{}, err
+	}
+	field.ProjectId = projectId
+	if err := cu.fr.Create(&field); err != nil {
+		return model.CustomFieldResponse{}, err
+	}
+	return newCustomFieldResponse(field), nil
+}
+
+// DeleteCustomField lets the owner of the project remove a field together
+// with its values.
+func (cu *customFieldUsecase) DeleteCustomField(userId uint, workspaceId uint, projectId uint, fieldId uint) error {
+	if err := cu.checkOwner(userId, workspaceId, projectId); err != nil {
+		return err
+	}
+	return cu.fr.Delete(projectId, fieldId)
+}
+
+func (cu *customFieldUsecase) checkOwner(userId uint, workspaceId uint, projectId uint) error {
+	project := model.Project{}
+	if err := cu.pr.GetByID(&project, userId, workspaceId, projectId); err != nil {
+		return err
+	}
+	if project.UserId != userId {
+		return model.ErrNotProjectOwner
+	}
+	return nil
+}
+
+func newCustomFieldResponse(field model.CustomField) model.CustomFieldResponse {
+	return model.CustomFieldResponse{
+		ID:        field.ID,
+		Name:      field.Name,
+		Type:      field.Type,
+		Required:  field.Required,
+		Options:   field.Options,
+		Min:       field.Min,
+		Max:       field.Max,
+		MaxLength: field.MaxLength,
+		Pattern:   field.Pattern,
+		ProjectId: field.ProjectId,
+		CreatedAt: field.CreatedAt,
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateCustomField_Success(t *testing.T) {
+	mp := newMockProjectRepository()
+	mf := newMockCustomFieldRepository()
+	mv := newMockCustomFieldValidator()
+	mockProject(mp, 1)
+	mv.On("CustomFieldValidate", mock.Anything).Return(nil)
+	mf.On("Create", mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.CustomField).ID = 7
+		}).
+		Return(nil)
+
+	cu := NewCustomFieldUsecase(mp, mf, mv)
+
+	res, err := cu.CreateCustomField(1, 7, 3, model.CustomField{Name: "Stage", Type: model.CustomFieldSelect, Options: []string{"alpha", "beta"}})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), res.ID)
+	assert.Equal(t, uint(3), res.ProjectId)
+}
+
+func TestCreateCustomField_NotOwner_Failure(t *testing.T) {
+	mp := newMockProjectRepository()
+	mf := newMockCustomFieldRepository()
+	mv := newMockCustomFieldValidator()
+	mockProject(mp, 1)
+	mv.On("CustomFieldValidate", mock.Anything).Return(nil)
+
+	cu := NewCustomFieldUsecase(mp, mf, mv)
+
+	_, err := cu.CreateCustomField(2, 7, 3, model.CustomField{Name: "Stage", Type: model.CustomFieldText})
+	assert.ErrorIs(t, err, model.ErrNotProjectOwner)
+	mf.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestGetCustomFields_NotMember_Failure(t *testing.T) {
+	mp := newMockProjectRepository()
+	mf := newMockCustomFieldRepository()
+	mp.On("GetByID", mock.Anything, uint(2), uint(7), uint(3)).Return(gorm.ErrRecordNotFound)
+
+	cu := NewCustomFieldUsecase(mp, mf, newMockCustomFieldValidator())
+
+	_, err := cu.GetCustomFields(2, 7, 3)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	mf.AssertNotCalled(t, "GetByProject", mock.Anything, mock.Anything)
+}
+
+func TestDeleteCustomField_Success(t *testing.T) {
+	mp := newMockProjectRepository()
+	mf := newMockCustomFieldRepository()
+	mockProject(mp, 1)
+	mf.On("Delete", uint(3), uint(7)).Return(nil)
+
+	cu := NewCustomFieldUsecase(mp, mf, newMockCustomFieldValidator())
+
+	err := cu.DeleteCustomField(1, 7, 3, 7)
+	assert.NoError(t, err)
+	mf.AssertCalled(t, "Delete", uint(3), uint(7))
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"go-rest-api/mention"
+	"go-rest-api/model"
+	"go-rest-api/notifier"
+	"go-rest-api/repository"
+	"log"
+)
+
+const maxMentionsFetch = 100
+
+type IMentionUsecase interface {
+	RecordMentions(actorId uint, workspaceId uint, taskId uint, source string, sourceId uint, text string) error
+	GetMentions(userId uint, workspaceId uint) ([]model.MentionResponse, error)
+}
+
+type mentionUsecase struct {
+	ur repository.IUserRepository
+	mr repository.IMentionRepository
+	n  notifier.Notifier
+}
+
+func NewMentionUsecase(ur repository.IUserRepository, mr repository.IMentionRepository, n notifier.Notifier) IMentionUsecase {
+	return &mentionUsecase{ur, mr, n}
+}
+
+// RecordMentions stores the users mentioned in text and notifies the ones
+// who were not mentioned there before. Mentions of the actor and of users
+// outside the workspace are ignored.
+func (mu *mentionUsecase) RecordMentions(actorId uint, workspaceId uint, taskId uint, source string, sourceId uint, text string) error {
+	found := mention.Parse(text)
+	if len(found.Emails) == 0 && len(found.Handles) == 0 {
+		return nil
+	}
+	var users []model.User
+	if err := mu.ur.GetByMentions(&users, workspaceId, found.Emails, found.Handles); err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.ID == actorId {
+			continue
+		}
+		record := model.Mention{TaskId: taskId, Source: source, SourceId: sourceId, UserId: user.ID, ActorId: actorId}
+		added, err := mu.mr.Add(&record)
+		if err != nil {
+			return err
+		}
+		if !added {
+			continue
+		}
+		change := model.TaskChange{Type: model.TaskChangeMention, TaskId: taskId, ActorId: actorId, To: newMentionResponse(record), At: record.CreatedAt}
+		if err := mu.n.Notify(user.ID, change); err != nil {
+			log.Printf("notify user %d of task %d: %v", user.ID, taskId, err)
+		}
+	}
+	return nil
+}
+
+func (mu *mentionUsecase) GetMentions(userId uint, workspaceId uint) ([]model.MentionResponse, error) {
+	var mentions []model.Mention
+	if err := mu.mr.GetInbox(&mentions, userId, workspaceId, maxMentionsFetch); err != nil {
+		return nil, err
+	}
+
+	mentionResponses := []model.MentionResponse{}
+	for _, record := range mentions {
+		mentionResponses = append(mentionResponses, newMentionResponse(record))
+	}
+	return mentionResponses, nil
+}
+
+func newMentionResponse(record model.Mention) model.MentionResponse {
+	return model.MentionResponse{
+		ID:        record.ID,
+		TaskId:    record.TaskId,
+		Source:    record.Source,
+		SourceId:  record.SourceId,
+		ActorId:   record.ActorId,
+		CreatedAt: record.CreatedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/notifier"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMentionRepository struct {
+	mock.Mock
+}
+
+func newMockMentionRepository() *MockMentionRepository {
+	return &MockMentionRepository{}
+}
+
+func (mr *MockMentionRepository) Add(mention *model.Mention) (bool, error) {
+	args := mr.Called(mention)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mr *MockMentionRepository) GetInbox(mentions *[]model.Mention, userId uint, workspaceId uint, limit int) error {
+	args := mr.Called(mentions, userId, workspaceId, limit)
+	return args.Error(0)
+}
+
+func mockMentionedUsers(mu *MockUserRepository, users ...model.User) {
+	mu.On("GetByMentions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]model.User) = users
+		}).
+		Return(nil)
+}
+
+func TestRecordMentions_Success(t *testing.T) {
+	mu := newMockUserRepository()
+	mr := newMockMentionRepository()
+	sink := notifier.NewMemorySink()
+	mockMentionedUsers(mu, model.User{ID: 1}, model.User{ID: 2}, model.User{ID: 3})
+	mr.On("Add", mock.MatchedBy(func(m *model.Mention) bool { return m.UserId == 2 })).Return(true, nil)
+	mr.On("Add", mock.MatchedBy(func(m *model.Mention) bool { return m.UserId == 3 })).Return(false, nil)
+
+	mentionUsecase := NewMentionUsecase(mu, mr, sink)
+
+	err := mentionUsecase.RecordMentions(1, 7, 5, model.MentionSourceComment, 9, "@ann @bob @carol@test.com")
+	assert.NoError(t, err)
+	mu.AssertCalled(t, "GetByMentions", mock.Anything, uint(7), []string{"carol@test.com"}, []string{"ann", "bob"})
+	mr.AssertNumberOfCalls(t, "Add", 2)
+
+	notifications := sink.Notifications()
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, uint(2), notifications[0].UserId)
+	assert.Equal(t, model.TaskChangeMention, notifications[0].Change.Type)
+	assert.Equal(t, uint(5), notifications[0].Change.TaskId)
+	assert.Equal(t, uint(1), notifications[0].Change.ActorId)
+}
+
+func TestRecordMentions_NoMentions(t *testing.T) {
+	mu := newMockUserRepository()
+	mr := newMockMentionRepository()
+
+	mentionUsecase := NewMentionUsecase(mu, mr, notifier.NewMemorySink())
+
+	err := mentionUsecase.RecordMentions(1, 7, 5, model.MentionSourceDescription, 0, "write to alice@test.com")
+	assert.NoError(t, err)
+	mu.AssertNotCalled(t, "GetByMentions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecordMentions_Repository_Failure(t *testing.T) {
+	mu := newMockUserRepository()
+	mr := newMockMentionRepository()
+	sink := notifier.NewMemorySink()
+	mockMentionedUsers(mu, model.User{ID: 2})
+	mr.On("Add", mock.Anything).Return(false, errors.New("error"))
+
+	mentionUsecase := NewMentionUsecase(mu, mr, sink)
+
+	err := mentionUsecase.RecordMentions(1, 7, 5, model.MentionSourceComment, 9, "@bob")
+	assert.Error(t, err)
+	assert.Empty(t, sink.Notifications())
+}
+
+func TestGetMentions_Success(t *testing.T) {
+	mu := newMockUserRepository()
+	mr := newMockMentionRepository()
+	mr.On("GetInbox", mock.Anything, uint(2), uint(7), maxMentionsFetch).
+		Run(func(args mock.Arguments) {
+			mentions := args.Get(0).(*[]model.Mention)
+			*mentions = []model.Mention{{ID: 4, TaskId: 5, Source: model.MentionSourceComment, SourceId: 9, UserId: 2, ActorId: 1}}
+		}).
+		Return(nil)
+
+	mentionUsecase := NewMentionUsecase(mu, mr, notifier.NewMemorySink())
+
+	res, err := mentionUsecase.GetMentions(2, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.MentionResponse{{ID: 4, TaskId: 5, Source: model.MentionSourceComment, SourceId: 9, ActorId: 1}}, res)
+}
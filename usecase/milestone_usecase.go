@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+)
+
+// velocityWindow is how many past milestones the velocity is averaged over.
+const velocityWindow = 3
+
+type IMilestoneUsecase interface {
+	GetAllMilestones(workspaceId uint) ([]model.MilestoneResponse, error)
+	GetMilestoneSummary(workspaceId uint, milestoneId uint) (model.MilestoneSummary, error)
+	CreateMilestone(milestone model.Milestone) (model.MilestoneResponse, error)
+	AddTask(userId uint, workspaceId uint, milestoneId uint, taskId uint) error
+	RemoveTask(userId uint, workspaceId uint, milestoneId uint, taskId uint) error
+	CloseMilestone(workspaceId uint, milestoneId uint, req model.MilestoneCloseRequest) (model.MilestoneSummary, error)
+}
+
+type milestoneUsecase struct {
+	mr repository.IMilestoneRepository
+	tr repository.ITaskRepository
+	mv validator.IMilestoneValidator
+}
+
+func NewMilestoneUsecase(mr repository.IMilestoneRepository, tr repository.ITaskRepository, mv validator.IMilestoneValidator) IMilestoneUsecase {
+	return &milestoneUsecase{mr, tr, mv}
+}
+
+func (mu *milestoneUsecase) GetAllMilestones(workspaceId uint) ([]model.MilestoneResponse, error) {
+	var milestones []model.Milestone
+	if err := mu.mr.GetAll(&milestones, workspaceId); err != nil {
+		return nil, err
+	}
+
+	milestoneResponses := []model.MilestoneResponse{}
+	for _, milestone := range milestones {
+		milestoneResponses = append(milestoneResponses, newMilestoneResponse(milestone))
+	}
+	return milestoneResponses, nil
+}
+
+// GetMilestoneSummary counts the tasks of an open milestone as they are now.
+// A closed milestone reports the counts recorded when it closed, so later
+// edits to its tasks do not rewrite its history.
+func (mu *milestoneUsecase) GetMilestoneSummary(workspaceId uint, milestoneId uint) (model.MilestoneSummary, error) {
+	milestone := model.Milestone{}
+	if err := mu.mr.GetByID(&milestone, workspaceId, milestoneId); err != nil {
+		return model.MilestoneSummary{}, err
+	}
+	return mu.summarize(milestone)
+}
+
+func (mu *milestoneUsecase) CreateMilestone(milestone model.Milestone) (model.MilestoneResponse, error) {
+	if err := mu.mv.MilestoneValidate(milestone); err != nil {
+		return model.MilestoneResponse{}, err
+	}
+	if err := mu.mr.Create(&milestone); err != nil {
+		return model.MilestoneResponse{}, err
+	}
+	return newMilestoneResponse(milestone), nil
+}
+
+// AddTask adds a task the user can access to an open milestone.
+func (mu *milestoneUsecase) AddTask(userId uint, workspaceId uint, milestoneId uint, taskId uint) error {
+	if err := mu.checkOpenTask(userId, workspaceId, milestoneId, taskId); err != nil {
+		return err
+	}
+	return mu.mr.AddTask(&model.MilestoneTask{MilestoneId: milestoneId, TaskId: taskId})
+}
+
+func (mu *milestoneUsecase) RemoveTask(userId uint, workspaceId uint, milestoneId uint, taskId uint) error {
+	if err := mu.checkOpenTask(userId, workspaceId, milestoneId, taskId); err != nil {
+		return err
+	}
+	return mu.mr.RemoveTask(milestoneId, taskId)
+}
+
+// CloseMilestone closes an open milestone and carries its unfinished tasks
+// over to req.CarryOverTo, which must be another open milestone of the
+// workspace.
+func (mu *milestoneUsecase) CloseMilestone(workspaceId uint, milestoneId uint, req model.MilestoneCloseRequest) (model.MilestoneSummary, error) {
+	milestone := model.Milestone{}
+	if err := mu.mr.GetByID(&milestone, workspaceId, milestoneId); err != nil {
+		return model.MilestoneSummary{}, err
+	}
+	if milestone.ClosedAt != nil {
+		return model.MilestoneSummary{}, model.ErrMilestoneClosed
+	}
+	if req.CarryOverTo != nil {
+		// The milestone itself is about to close, so it cannot take the tasks.
+		if *req.CarryOverTo == milestoneId {
+			return model.MilestoneSummary{}, model.ErrMilestoneClosed
+		}
+		target := model.Milestone{}
+		if err := mu.mr.GetByID(&target, workspaceId, *req.CarryOverTo); err != nil {
+			return model.MilestoneSummary{}, err
+		}
+		if target.ClosedAt != nil {
+			return model.MilestoneSummary{}, model.ErrMilestoneClosed
+		}
+	}
+	if err := mu.mr.Close(&milestone, req.CarryOverTo); err != nil {
+		return model.MilestoneSummary{}, err
+	}
+	return mu.summarize(milestone)
+}
+
+func (mu *milestoneUsecase) checkOpenTask(userId uint, workspaceId uint, milestoneId uint, taskId uint) error {
+	milestone := model.Milestone{}
+	if err := mu.mr.GetByID(&milestone, workspaceId, milestoneId); err != nil {
+		return err
+	}
+	if milestone.ClosedAt != nil {
+		return model.ErrMilestoneClosed
+	}
+	task := model.Task{}
+	return mu.tr.GetAccessible(&task, userId, workspaceId, taskId)
+}
+
+func (mu *milestoneUsecase) summarize(milestone model.Milestone) (model.MilestoneSummary, error) {
+	counts := model.MilestoneCounts{}
+	if err := mu.mr.CountTasks(&counts, milestone.ID); err != nil {
+		return model.MilestoneSummary{}, err
+	}
+	summary := model.MilestoneSummary{
+		Milestone: newMilestoneResponse(milestone),
+		Committed: counts.Committed,
+		Completed: counts.Completed,
+		Remaining: counts.Committed - counts.Completed,
+		CarriedIn: counts.CarriedIn,
+	}
+	if milestone.ClosedAt != nil {
+		summary.Committed = milestone.Committed
+		summary.Completed = milestone.Completed
+		summary.Remaining = 0
+		summary.CarriedOut = milestone.Committed - milestone.Completed
+	}
+
+	var completed []int
+	if err := mu.mr.GetVelocity(&completed, milestone, velocityWindow); err != nil {
+		return model.MilestoneSummary{}, err
+	}
+	summary.VelocityMilestones = len(completed)
+	if len(completed) > 0 {
+		total := 0
+		for _, n := range completed {
+			total += n
+		}
+		summary.Velocity = float64(total) / float64(len(completed))
+	}
+	return summary, nil
+}
+
+func newMilestoneResponse(milestone model.Milestone) model.MilestoneResponse {
+	return model.MilestoneResponse{
+		ID:          milestone.ID,
+		Name:        milestone.Name,
+		StartDate:   milestone.StartDate,
+		EndDate:     milestone.EndDate,
+		ClosedAt:    milestone.ClosedAt,
+		UserId:      milestone.UserId,
+		WorkspaceId: milestone.WorkspaceId,
+		CreatedAt:   milestone.CreatedAt,
+	}
+}
@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockMilestoneRepository struct {
+	mock.Mock
+}
+
+func newMockMilestoneRepository() *MockMilestoneRepository {
+	return &MockMilestoneRepository{}
+}
+
+func (mr *MockMilestoneRepository) Create(milestone *model.Milestone) error {
+	args := mr.Called(milestone)
+	return args.Error(0)
+}
+
+func (mr *MockMilestoneRepository) GetAll(milestones *[]model.Milestone, workspaceId uint) error {
+	args := mr.Called(milestones, workspaceId)
+	return args.Error(0)
+}
+
+func (mr *MockMilestoneRepository) GetByID(milestone *model.Milestone, workspaceId uint, milestoneId uint) error {
+	args := mr.Called(milestone, workspaceId, milestoneId)
+	return args.Error(0)
+}
+
+func (mr *MockMilestoneRepository) AddTask(milestoneTask *model.MilestoneTask) error {
+	args := mr.Called(milestoneTask)
+	return args.Error(0)
+}
+
+func (mr *MockMilestoneRepository) RemoveTask(milestoneId uint, taskId uint) error {
+	args := mr.Called(milestoneId, taskId)
+	return args.Error(0)
+}
+
+func (mr *MockMilestoneRepository) CountTasks(counts *model.MilestoneCounts, milestoneId uint) error {
+	args := mr.Called(counts, milestoneId)
+	return args.Error(0)
+}
+
+func (mr *MockMilestoneRepository) GetVelocity(completed *[]int, milestone model.Milestone, limit int) error {
+	args := mr.Called(completed, milestone, limit)
+	return args.Error(0)
+}
+
+func (mr *MockMilestoneRepository) Close(milestone *model.Milestone, carryOverTo *uint) error {
+	args := mr.Called(milestone, carryOverTo)
+	return args.Error(0)
+}
+
+type MockMilestoneValidator struct {
+	mock.Mock
+}
+
+func newMockMilestoneValidator() *MockMilestoneValidator {
+	return &MockMilestoneValidator{}
+}
+
+func (mv *MockMilestoneValidator) MilestoneValidate(milestone model.Milestone) error {
+	args := mv.Called(milestone)
+	return args.Error(0)
+}
+
+// mockMilestone answers GetByID for milestone in workspace 7.
+func mockMilestone(mr *MockMilestoneRepository, milestone model.Milestone) {
+	mr.On("GetByID", mock.Anything, uint(7), milestone.ID).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.Milestone) = milestone
+		}).
+		Return(nil)
+}
+
+func mockMilestoneCounts(mr *MockMilestoneRepository, counts model.MilestoneCounts, completed []int) {
+	mr.On("CountTasks", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.MilestoneCounts) = counts
+		}).
+		Return(nil)
+	mr.On("GetVelocity", mock.Anything, mock.Anything, velocityWindow).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]int) = completed
+		}).
+		Return(nil)
+}
+
+func TestGetMilestoneSummary_Open_Success(t *testing.T) {
+	mr := newMockMilestoneRepository()
+	mockMilestone(mr, model.Milestone{ID: 3, Name: "Sprint 3", WorkspaceId: 7})
+	mockMilestoneCounts(mr, model.MilestoneCounts{Committed: 8, Completed: 5, CarriedIn: 2}, []int{6, 3})
+
+	mu := NewMilestoneUsecase(mr, newMockTaskRepository(), newMockMilestoneValidator())
+
+	res, err := mu.GetMilestoneSummary(7, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, res.Committed)
+	assert.Equal(t, 5, res.Completed)
+	assert.Equal(t, 3, res.Remaining)
+	assert.Equal(t, 2, res.CarriedIn)
+	assert.Equal(t, 4.5, res.Velocity)
+	assert.Equal(t, 2, res.VelocityMilestones)
+}
+
+func TestGetMilestoneSummary_Closed_Success(t *testing.T) {
+	closedAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	mr := newMockMilestoneRepository()
+	mockMilestone(mr, model.Milestone{ID: 3, WorkspaceId: 7, ClosedAt: &closedAt, Committed: 8, Completed: 6})
+	mockMilestoneCounts(mr, model.MilestoneCounts{Committed: 8, Completed: 8}, nil)
+
+	mu := NewMilestoneUsecase(mr, newMockTaskRepository(), newMockMilestoneValidator())
+
+	res, err := mu.GetMilestoneSummary(7, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, res.Completed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 2, res.CarriedOut)
+	assert.Equal(t, 0.0, res.Velocity)
+}
+
+func TestCreateMilestone_Success(t *testing.T) {
+	mr := newMockMilestoneRepository()
+	mv := newMockMilestoneValidator()
+	mv.On("MilestoneValidate", mock.Anything).Return(nil)
+	mr.On("Create", mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.Milestone).ID = 3
+		}).
+		Return(nil)
+
+	mu := NewMilestoneUsecase(mr, newMockTaskRepository(), mv)
+
+	res, err := mu.CreateMilestone(model.Milestone{Name: "Sprint 3", UserId: 1, WorkspaceId: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), res.ID)
+	assert.Equal(t, uint(7), res.WorkspaceId)
+}
+
+func TestAddMilestoneTask_Success(t *testing.T) {
+	mr := newMockMilestoneRepository()
+	tr := newMockTaskRepository()
+	mockMilestone(mr, model.Milestone{ID: 3, WorkspaceId: 7})
+	tr.On("GetAccessible", mock.Anything, uint(1), uint(7), uint(5)).Return(nil)
+	mr.On("AddTask", mock.Anything).Return(nil)
+
+	mu := NewMilestoneUsecase(mr, tr, newMockMilestoneValidator())
+
+	err := mu.AddTask(1, 7, 3, 5)
+	assert.NoError(t, err)
+	mr.AssertCalled(t, "AddTask", &model.MilestoneTask{MilestoneId: 3, TaskId: 5})
+}
+
+func TestAddMilestoneTask_NoAccess_Failure(t *testing.T) {
+	mr := newMockMilestoneRepository()
+	tr := newMockTaskRepository()
+	mockMilestone(mr, model.Milestone{ID: 3, WorkspaceId: 7})
+	tr.On("GetAccessible", mock.Anything, uint(1), uint(7), uint(5)).Return(gorm.ErrRecordNotFound)
+
+	mu := NewMilestoneUsecase(mr, tr, newMockMilestoneValidator())
+
+	err := mu.AddTask(1, 7, 3, 5)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	mr.AssertNotCalled(t, "AddTask", mock.Anything)
+}
+
+func TestRemoveMilestoneTask_Closed_Failure(t *testing.T) {
+	closedAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	mr := newMockMilestoneRepository()
+	mockMilestone(mr, model.Milestone{ID: 3, WorkspaceId: 7, ClosedAt: &closedAt})
+
+	mu := NewMilestoneUsecase(mr, newMockTaskRepository(), newMockMilestoneValidator())
+
+	err := mu.RemoveTask(1, 7, 3, 5)
+	assert.ErrorIs(t, err, model.ErrMilestoneClosed)
+	mr.AssertNotCalled(t, "RemoveTask", mock.Anything, mock.Anything)
+}
+
+func TestCloseMilestone_CarryOver_Success(t *testing.T) {
+	mr := newMockMilestoneRepository()
+	mockMilestone(mr, model.Milestone{ID: 3, WorkspaceId: 7})
+	mockMilestone(mr, model.Milestone{ID: 4, WorkspaceId: 7})
+	next := uint(4)
+	closedAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	mr.On("Close", mock.Anything, &next).
+		Run(func(args mock.Arguments) {
+			milestone := args.Get(0).(*model.Milestone)
+			milestone.ClosedAt = &closedAt
+			milestone.Committed = 8
+			milestone.Completed = 6
+		}).
+		Return(nil)
+	mockMilestoneCounts(mr, model.MilestoneCounts{Committed: 8, Completed: 6}, []int{4})
+
+	mu := NewMilestoneUsecase(mr, newMockTaskRepository(), newMockMilestoneValidator())
+
+	res, err := mu.CloseMilestone(7, 3, model.MilestoneCloseRequest{CarryOverTo: &next})
+	assert.NoError(t, err)
+	assert.Equal(t, &closedAt, res.Milestone.ClosedAt)
+	assert.Equal(t, 2, res.CarriedOut)
+	assert.Equal(t, 4.0, res.Velocity)
+}
+
+func TestCloseMilestone_ClosedTarget_Failure(t *testing.T) {
+	closedAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	mr := newMockMilestoneRepository()
+	mockMilestone(mr, model.Milestone{ID: 3, WorkspaceId: 7})
+	mockMilestone(mr, model.Milestone{ID: 2, WorkspaceId: 7, ClosedAt: &closedAt})
+	previous := uint(2)
+
+	mu := NewMilestoneUsecase(mr, newMockTaskRepository(), newMockMilestoneValidator())
+
+	_, err := mu.CloseMilestone(7, 3, model.MilestoneCloseRequest{CarryOverTo: &previous})
+	assert.ErrorIs(t, err, model.ErrMilestoneClosed)
+	mr.AssertNotCalled(t, "Close", mock.Anything, mock.Anything)
+}
+
+func TestCloseMilestone_AlreadyClosed_Failure(t *testing.T) {
+	closedAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	mr := newMockMilestoneRepository()
+	mockMilestone(mr, model.Milestone{ID: 3, WorkspaceId: 7, ClosedAt: &closedAt})
+
+	mu := NewMilestoneUsecase(mr, newMockTaskRepository(), newMockMilestoneValidator())
+
+	_, err := mu.CloseMilestone(7, 3, model.MilestoneCloseRequest{})
+	assert.ErrorIs(t, err, model.ErrMilestoneClosed)
+}
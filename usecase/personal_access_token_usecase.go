@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+)
+
+type IPersonalAccessTokenUsecase interface {
+	GetAllTokens(userId uint) ([]model.PersonalAccessTokenResponse, error)
+	CreateToken(userId uint, req model.PersonalAccessTokenRequest) (model.PersonalAccessTokenResponse, error)
+	RevokeToken(userId uint, tokenId uint) error
+}
+
+type personalAccessTokenUsecase struct {
+	pr repository.IPersonalAccessTokenRepository
+	pv validator.IPersonalAccessTokenValidator
+}
+
+func NewPersonalAccessTokenUsecase(pr repository.IPersonalAccessTokenRepository, pv validator.IPersonalAccessTokenValidator) IPersonalAccessTokenUsecase {
+	return &personalAccessTokenUsecase{pr, pv}
+}
+
+func (pu *personalAccessTokenUsecase) GetAllTokens(userId uint) ([]model.PersonalAccessTokenResponse, error) {
+	var tokens []model.PersonalAccessToken
+	if err := pu.pr.GetAll(&tokens, userId); err != nil {
+		return nil, err
+	}
+
+	tokenResponses := []model.PersonalAccessTokenResponse{}
+	for _, token := range tokens {
+		tokenResponses = append(tokenResponses, newPersonalAccessTokenResponse(token))
+	}
+	return tokenResponses, nil
+}
+
+// CreateToken returns the token itself only this once; afterwards only its
+// hash is kept.
+func (pu *personalAccessTokenUsecase) CreateToken(userId uint, req model.PersonalAccessTokenRequest) (model.PersonalAccessTokenResponse, error) {
+	if err := pu.pv.PersonalAccessTokenValidate(req); err != nil {
+		return model.PersonalAccessTokenResponse{}, err
+	}
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return model.PersonalAccessTokenResponse{}, err
+	}
+	tokenString := model.PersonalAccessTokenPrefix + secret
+	token := model.PersonalAccessToken{
+		Name:      req.Name,
+		TokenHash: hashToken(tokenString),
+		Scopes:    req.Scopes,
+		UserId:    userId,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := pu.pr.Create(&token); err != nil {
+		return model.PersonalAccessTokenResponse{}, err
+	}
+	res := newPersonalAccessTokenResponse(token)
+	res.Token = tokenString
+	return res, nil
+}
+
+func (pu *personalAccessTokenUsecase) RevokeToken(userId uint, tokenId uint) error {
+	return pu.pr.Delete(userId, tokenId)
+}
+
+func newPersonalAccessTokenResponse(token model.PersonalAccessToken) model.PersonalAccessTokenResponse {
+	return model.PersonalAccessTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Scopes:     token.Scopes,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
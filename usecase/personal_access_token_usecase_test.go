@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"strings"
+	"testing"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockPersonalAccessTokenRepository struct {
+	mock.Mock
+}
+
+func newMockPersonalAccessTokenRepository() *MockPersonalAccessTokenRepository {
+	return &MockPersonalAccessTokenRepository{}
+}
+
+func (pr *MockPersonalAccessTokenRepository) Create(token *model.PersonalAccessToken) error {
+	args := pr.Called(token)
+	return args.Error(0)
+}
+
+func (pr *MockPersonalAccessTokenRepository) GetAll(tokens *[]model.PersonalAccessToken, userId uint) error {
+	args := pr.Called(tokens, userId)
+	return args.Error(0)
+}
+
+func (pr *MockPersonalAccessTokenRepository) GetByHash(token *model.PersonalAccessToken, tokenHash string) error {
+	args := pr.Called(token, tokenHash)
+	return args.Error(0)
+}
+
+func (pr *MockPersonalAccessTokenRepository) MarkUsed(tokenId uint, usedAt time.Time) error {
+	args := pr.Called(tokenId, usedAt)
+	return args.Error(0)
+}
+
+func (pr *MockPersonalAccessTokenRepository) Delete(userId uint, tokenId uint) error {
+	args := pr.Called(userId, tokenId)
+	return args.Error(0)
+}
+
+type MockPersonalAccessTokenValidator struct {
+	mock.Mock
+}
+
+func newMockPersonalAccessTokenValidator() *MockPersonalAccessTokenValidator {
+	return &MockPersonalAccessTokenValidator{}
+}
+
+func (pv *MockPersonalAccessTokenValidator) PersonalAccessTokenValidate(req model.PersonalAccessTokenRequest) error {
+	args := pv.Called(req)
+	return args.Error(0)
+}
+
+func TestCreatePersonalAccessToken_Success(t *testing.T) {
+	pr := newMockPersonalAccessTokenRepository()
+	pv := newMockPersonalAccessTokenValidator()
+	pv.On("PersonalAccessTokenValidate", mock.Anything).Return(nil)
+	pr.On("Create", mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.PersonalAccessToken).ID = 3
+		}).
+		Return(nil)
+
+	pu := NewPersonalAccessTokenUsecase(pr, pv)
+
+	res, err := pu.CreateToken(1, model.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{model.ScopeTasksRead}})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), res.ID)
+	assert.True(t, strings.HasPrefix(res.Token, model.PersonalAccessTokenPrefix))
+	pr.AssertCalled(t, "Create", mock.MatchedBy(func(token *model.PersonalAccessToken) bool {
+		return token.TokenHash == hashToken(res.Token) && token.UserId == 1
+	}))
+}
+
+func TestCreatePersonalAccessToken_Invalid_Failure(t *testing.T) {
+	pr := newMockPersonalAccessTokenRepository()
+	pv := newMockPersonalAccessTokenValidator()
+	pv.On("PersonalAccessTokenValidate", mock.Anything).Return(validation.Errors{"scopes": errors.New("scopes are required")})
+
+	pu := NewPersonalAccessTokenUsecase(pr, pv)
+
+	_, err := pu.CreateToken(1, model.PersonalAccessTokenRequest{Name: "ci"})
+	assert.Error(t, err)
+	pr.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestGetAllPersonalAccessTokens_HidesToken(t *testing.T) {
+	pr := newMockPersonalAccessTokenRepository()
+	pr.On("GetAll", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]model.PersonalAccessToken) = []model.PersonalAccessToken{
+				{ID: 3, Name: "ci", TokenHash: "hash", Scopes: []string{model.ScopeTasksRead}, UserId: 1},
+			}
+		}).
+		Return(nil)
+
+	pu := NewPersonalAccessTokenUsecase(pr, newMockPersonalAccessTokenValidator())
+
+	res, err := pu.GetAllTokens(1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "ci", res[0].Name)
+	assert.Empty(t, res[0].Token)
+}
+
+func TestRevokePersonalAccessToken_NotFound_Failure(t *testing.T) {
+	pr := newMockPersonalAccessTokenRepository()
+	pr.On("Delete", uint(1), uint(3)).Return(gorm.ErrRecordNotFound)
+
+	pu := NewPersonalAccessTokenUsecase(pr, newMockPersonalAccessTokenValidator())
+
+	err := pu.RevokeToken(1, 3)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+)
+
+type IProjectUsecase interface {
+	GetAllProjects(userId uint, workspaceId uint) ([]model.ProjectResponse, error)
+	CreateProject(project model.Project) (model.ProjectResponse, error)
+	AddMember(userId uint, workspaceId uint, projectId uint, req model.ProjectMemberRequest) error
+	RemoveMember(userId uint, workspaceId uint, projectId uint, memberId uint) error
+}
+
+type projectUsecase struct {
+	pr repository.IProjectRepository
+	ur repository.IUserRepository
+	pv validator.IProjectValidator
+}
+
+func NewProjectUsecase(pr repository.IProjectRepository, ur repository.IUserRepository, pv validator.IProjectValidator) IProjectUsecase {
+	return &projectUsecase{pr, ur, pv}
+}
+
+func (pu *projectUsecase) GetAllProjects(userId uint, workspaceId uint) ([]model.ProjectResponse, error) {
+	var projects []model.Project
+	if err := pu.pr.GetAll(&projects, userId, workspaceId); err != nil {
+		return nil, err
+	}
+
+	projectResponses := []model.ProjectResponse{}
+	for _, project := range projects {
+		projectResponses = append(projectResponses, newProjectResponse(project))
+	}
+	return projectResponses, nil
+}
+
+func (pu *projectUsecase) CreateProject(project model.Project) (model.ProjectResponse, error) {
+	if err := pu.pv.ProjectValidate(project); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	if err := pu.pr.Create(&project); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	return newProjectResponse(project), nil
+}
+
+// AddMember lets the owner of the project add a member of its workspace by
+// email.
+func (pu *projectUsecase) AddMember(userId uint, workspaceId uint, projectId uint, req model.ProjectMemberRequest) error {
+	if err := pu.pv.ProjectMemberValidate(req); err != nil {
+		return err
+	}
+	project := model.Project{}
+	if err := pu.pr.GetByID(&project, userId, workspaceId, projectId); err != nil {
+		return err
+	}
+	if project.UserId != userId {
+		return model.ErrNotProjectOwner
+	}
+	member := model.User{}
+	if err := pu.ur.GetByEmail(&member, req.Email); err != nil {
+		return err
+	}
+	return pu.pr.AddMember(projectId, member.ID)
+}
+
+// RemoveMember lets the owner remove any other member and a member leave the
+// project. The owner cannot leave their own project.
+func (pu *projectUsecase) RemoveMember(userId uint, workspaceId uint, projectId uint, memberId uint) error {
+	project := model.Project{}
+	if err := pu.pr.GetByID(&project, userId, workspaceId, projectId); err != nil {
+		return err
+	}
+	if project.UserId != userId && memberId != userId {
+		return model.ErrNotProjectOwner
+	}
+	if memberId == project.UserId {
+		return model.ErrProjectOwnerMember
+	}
+	return pu.pr.RemoveMember(projectId, memberId)
+}
+
+func newProjectResponse(project model.Project) model.ProjectResponse {
+	return model.ProjectResponse{
+		ID:          project.ID,
+		Name:        project.Name,
+		UserId:      project.UserId,
+		WorkspaceId: project.WorkspaceId,
+		CreatedAt:   project.CreatedAt,
+	}
+}
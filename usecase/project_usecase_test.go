@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+func newMockProjectRepository() *MockProjectRepository {
+	return &MockProjectRepository{}
+}
+
+func (mr *MockProjectRepository) Create(project *model.Project) error {
+	args := mr.Called(project)
+	return args.Error(0)
+}
+
+func (mr *MockProjectRepository) GetAll(projects *[]model.Project, userId uint, workspaceId uint) error {
+	args := mr.Called(projects, userId, workspaceId)
+	return args.Error(0)
+}
+
+func (mr *MockProjectRepository) GetByID(project *model.Project, userId uint, workspaceId uint, projectId uint) error {
+	args := mr.Called(project, userId, workspaceId, projectId)
+	return args.Error(0)
+}
+
+func (mr *MockProjectRepository) AddMember(projectId uint, userId uint) error {
+	args := mr.Called(projectId, userId)
+	return args.Error(0)
+}
+
+func (mr *MockProjectRepository) RemoveMember(projectId uint, userId uint) error {
+	args := mr.Called(projectId, userId)
+	return args.Error(0)
+}
+
+type MockProjectValidator struct {
+	mock.Mock
+}
+
+func newMockProjectValidator() *MockProjectValidator {
+	return &MockProjectValidator{}
+}
+
+func (mv *MockProjectValidator) ProjectValidate(project model.Project) error {
+	args := mv.Called(project)
+	return args.Error(0)
+}
+
+func (mv *MockProjectValidator) ProjectMemberValidate(req model.ProjectMemberRequest) error {
+	args := mv.Called(req)
+	return args.Error(0)
+}
+
+// mockProject answers GetByID with project 3 of workspace 7 owned by
+// ownerId.
+func mockProject(mr *MockProjectRepository, ownerId uint) {
+	mr.On("GetByID", mock.Anything, mock.Anything, uint(7), uint(3)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.Project) = model.Project{ID: 3, Name: "Launch", UserId: ownerId, WorkspaceId: 7}
+		}).
+		Return(nil)
+}
+
+func TestCreateProject_Success(t *testing.T) {
+	mr := newMockProjectRepository()
+	mv := newMockProjectValidator()
+	mv.On("ProjectValidate", mock.Anything).Return(nil)
+	mr.On("Create", mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.Project).ID = 3
+		}).
+		Return(nil)
+
+	pu := NewProjectUsecase(mr, newMockUserRepository(), mv)
+
+	res, err := pu.CreateProject(model.Project{Name: "Launch", UserId: 1, WorkspaceId: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), res.ID)
+	assert.Equal(t, uint(1), res.UserId)
+	assert.Equal(t, uint(7), res.WorkspaceId)
+}
+
+func TestAddMember_Success(t *testing.T) {
+	mr := newMockProjectRepository()
+	mu := newMockUserRepository()
+	mv := newMockProjectValidator()
+	mv.On("ProjectMemberValidate", mock.Anything).Return(nil)
+	mockProject(mr, 1)
+	mu.On("GetByEmail", mock.Anything, "bob@test.com").
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.User).ID = 2
+		}).
+		Return(nil)
+	mr.On("AddMember", uint(3), uint(2)).Return(nil)
+
+	pu := NewProjectUsecase(mr, mu, mv)
+
+	err := pu.AddMember(1, 7, 3, model.ProjectMemberRequest{Email: "bob@test.com"})
+	assert.NoError(t, err)
+	mr.AssertCalled(t, "AddMember", uint(3), uint(2))
+}
+
+func TestAddMember_NotOwner_Failure(t *testing.T) {
+	mr := newMockProjectRepository()
+	mu := newMockUserRepository()
+	mv := newMockProjectValidator()
+	mv.On("ProjectMemberValidate", mock.Anything).Return(nil)
+	mockProject(mr, 1)
+
+	pu := NewProjectUsecase(mr, mu, mv)
+
+	err := pu.AddMember(2, 7, 3, model.ProjectMemberRequest{Email: "carol@test.com"})
+	assert.ErrorIs(t, err, model.ErrNotProjectOwner)
+	mr.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything)
+}
+
+func TestAddMember_UnknownUser_Failure(t *testing.T) {
+	mr := newMockProjectRepository()
+	mu := newMockUserRepository()
+	mv := newMockProjectValidator()
+	mv.On("ProjectMemberValidate", mock.Anything).Return(nil)
+	mockProject(mr, 1)
+	mu.On("GetByEmail", mock.Anything, "nobody@test.com").Return(gorm.ErrRecordNotFound)
+
+	pu := NewProjectUsecase(mr, mu, mv)
+
+	err := pu.AddMember(1, 7, 3, model.ProjectMemberRequest{Email: "nobody@test.com"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRemoveMember_Leave_Success(t *testing.T) {
+	mr := newMockProjectRepository()
+	mockProject(mr, 1)
+	mr.On("RemoveMember", uint(3), uint(2)).Return(nil)
+
+	pu := NewProjectUsecase(mr, newMockUserRepository(), newMockProjectValidator())
+
+	err := pu.RemoveMember(2, 7, 3, 2)
+	assert.NoError(t, err)
+}
+
+func TestRemoveMember_NotOwner_Failure(t *testing.T) {
+	mr := newMockProjectRepository()
+	mockProject(mr, 1)
+
+	pu := NewProjectUsecase(mr, newMockUserRepository(), newMockProjectValidator())
+
+	err := pu.RemoveMember(2, 7, 3, 4)
+	assert.ErrorIs(t, err, model.ErrNotProjectOwner)
+	mr.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything)
+}
+
+func TestRemoveMember_Owner_Failure(t *testing.T) {
+	mr := newMockProjectRepository()
+	mockProject(mr, 1)
+
+	pu := NewProjectUsecase(mr, newMockUserRepository(), newMockProjectValidator())
+
+	err := pu.RemoveMember(1, 7, 3, 1)
+	assert.ErrorIs(t, err, model.ErrProjectOwnerMember)
+}
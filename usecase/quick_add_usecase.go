@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/quickadd"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"time"
+)
+
+type IQuickAddUsecase interface {
+	QuickAddTask(userId uint, workspaceId uint, req model.QuickAddRequest) (model.QuickAddResponse, error)
+}
+
+type quickAddUsecase struct {
+	ur  repository.IUserRepository
+	tu  ITaskUsecase
+	qv  validator.IQuickAddValidator
+	now func() time.Time
+}
+
+// NewQuickAddUsecase creates tasks through tu so that parsed fields are
+// validated like any other new task.
+func NewQuickAddUsecase(ur repository.IUserRepository, tu ITaskUsecase, qv validator.IQuickAddValidator) IQuickAddUsecase {
+	return &quickAddUsecase{ur, tu, qv, time.Now}
+}
+
+// QuickAddTask parses req.Text in the user's time zone and, unless
+// req.Preview is set, creates the task it describes.
+func (qu *quickAddUsecase) QuickAddTask(userId uint, workspaceId uint, req model.QuickAddRequest) (model.QuickAddResponse, error) {
+	if err := qu.qv.QuickAddValidate(req); err != nil {
+		return model.QuickAddResponse{}, err
+	}
+	loc, err := qu.location(userId, req.Timezone)
+	if err != nil {
+		return model.QuickAddResponse{}, err
+	}
+	result := quickadd.Parse(req.Text, qu.now().In(loc))
+	res := model.QuickAddResponse{Parsed: newQuickAddParsed(result, loc)}
+	if req.Preview {
+		return res, nil
+	}
+
+	labels := make([]model.Label, 0, len(result.Labels))
+	for _, name := range result.Labels {
+		labels = append(labels, model.Label{Name: name})
+	}
+	taskRes, err := qu.tu.CreateTask(model.Task{
+		Title:       result.Title,
+		Priority:    result.Priority,
+		DueDate:     result.DueDate,
+		Labels:      labels,
+		Recurrence:  result.Recurrence,
+		UserId:      userId,
+		WorkspaceId: workspaceId,
+	})
+	if err != nil {
+		return model.QuickAddResponse{}, err
+	}
+	res.Task = &taskRes
+	return res, nil
+}
+
+// location is timezone when given, otherwise the time zone stored for the
+// user.
+func (qu *quickAddUsecase) location(userId uint, timezone string) (*time.Location, error) {
+	if timezone == "" {
+		user := model.User{}
+		if err := qu.ur.GetByID(&user, userId); err != nil {
+			return nil, err
+		}
+		timezone = user.Timezone
+	}
+	return time.LoadLocation(timezone)
+}
+
+func newQuickAddParsed(result quickadd.Result, loc *time.Location) model.QuickAddParsed {
+	labels := result.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	return model.QuickAddParsed{
+		Title:      result.Title,
+		DueDate:    result.DueDate,
+		AllDay:     result.AllDay,
+		Labels:     labels,
+		Priority:   result.Priority,
+		Recurrence: result.Recurrence,
+		Timezone:   loc.String(),
+	}
+}
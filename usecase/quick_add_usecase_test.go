@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQuickAddValidator struct {
+	mock.Mock
+}
+
+func newMockQuickAddValidator() *MockQuickAddValidator {
+	return &MockQuickAddValidator{}
+}
+
+func (mv *MockQuickAddValidator) QuickAddValidate(req model.QuickAddRequest) error {
+	args := mv.Called(req)
+	return args.Error(0)
+}
+
+// newFixedQuickAddUsecase pins the clock to Wednesday 2024-05-15 10:00 UTC.
+func newFixedQuickAddUsecase(mr *MockUserRepository, mu *MockTaskUsecase, mv *MockQuickAddValidator) IQuickAddUsecase {
+	qu := NewQuickAddUsecase(mr, mu, mv).(*quickAddUsecase)
+	qu.now = func() time.Time { return time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC) }
+	return qu
+}
+
+func TestQuickAddTask_Preview_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mu := newMockTaskUsecase()
+	mv := newMockQuickAddValidator()
+	mv.On("QuickAddValidate", mock.Anything).Return(nil)
+	mr.On("GetByID", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			user := args.Get(0).(*model.User)
+			*user = model.User{ID: 1, Timezone: "UTC"}
+		}).
+		Return(nil)
+
+	qu := newFixedQuickAddUsecase(mr, mu, mv)
+
+	res, err := qu.QuickAddTask(1, 7, model.QuickAddRequest{Text: "Call dentist tomorrow 3pm #personal !high", Preview: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "Call dentist", res.Parsed.Title)
+	assert.Equal(t, time.Date(2024, 5, 16, 15, 0, 0, 0, time.UTC), *res.Parsed.DueDate)
+	assert.Equal(t, []string{"personal"}, res.Parsed.Labels)
+	assert.Equal(t, model.TaskPriorityHigh, res.Parsed.Priority)
+	assert.Equal(t, "UTC", res.Parsed.Timezone)
+	assert.Nil(t, res.Task)
+	mu.AssertNotCalled(t, "CreateTask", mock.Anything)
+}
+
+func TestQuickAddTask_Create_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mu := newMockTaskUsecase()
+	mv := newMockQuickAddValidator()
+	mv.On("QuickAddValidate", mock.Anything).Return(nil)
+	mu.On("CreateTask", mock.Anything).Return(model.TaskResponse{ID: 7, Title: "Water plants"}, nil)
+
+	qu := newFixedQuickAddUsecase(mr, mu, mv)
+
+	res, err := qu.QuickAddTask(1, 7, model.QuickAddRequest{Text: "Water plants every monday #home", Timezone: "UTC"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), res.Task.ID)
+	mr.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+
+	task := mu.Calls[0].Arguments.Get(0).(model.Task)
+	assert.Equal(t, "Water plants", task.Title)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", task.Recurrence)
+	assert.Equal(t, []model.Label{{Name: "home"}}, task.Labels)
+	assert.Equal(t, time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC), *task.DueDate)
+	assert.Equal(t, uint(1), task.UserId)
+	assert.Equal(t, uint(7), task.WorkspaceId)
+}
+
+func TestQuickAddTask_Validator_Failure(t *testing.T) {
+	mr := newMockUserRepository()
+	mu := newMockTaskUsecase()
+	mv := newMockQuickAddValidator()
+	mv.On("QuickAddValidate", mock.Anything).Return(errors.New("error"))
+
+	qu := newFixedQuickAddUsecase(mr, mu, mv)
+
+	_, err := qu.QuickAddTask(1, 7, model.QuickAddRequest{})
+	assert.Error(t, err)
+	mr.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestQuickAddTask_Repository_Failure(t *testing.T) {
+	mr := newMockUserRepository()
+	mu := newMockTaskUsecase()
+	mv := newMockQuickAddValidator()
+	mv.On("QuickAddValidate", mock.Anything).Return(nil)
+	mr.On("GetByID", mock.Anything, uint(1)).Return(errors.New("error"))
+
+	qu := newFixedQuickAddUsecase(mr, mu, mv)
+
+	_, err := qu.QuickAddTask(1, 7, model.QuickAddRequest{Text: "Call dentist"})
+	assert.Error(t, err)
+}
+
+func TestQuickAddTask_CreateTask_Failure(t *testing.T) {
+	mr := newMockUserRepository()
+	mu := newMockTaskUsecase()
+	mv := newMockQuickAddValidator()
+	mv.On("QuickAddValidate", mock.Anything).Return(nil)
+	mu.On("CreateTask", mock.Anything).Return(model.TaskResponse{}, errors.New("error"))
+
+	qu := newFixedQuickAddUsecase(mr, mu, mv)
+
+	_, err := qu.QuickAddTask(1, 7, model.QuickAddRequest{Text: "#onlylabel", Timezone: "UTC"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"time"
+)
+
+type ISmartListUsecase interface {
+	GetAllSmartLists(userId uint) ([]model.SmartListResponse, error)
+	GetSmartListByID(userId uint, listId uint) (model.SmartListResponse, error)
+	GetSmartListTasks(userId uint, workspaceId uint, listId uint) ([]model.TaskResponse, error)
+	CreateSmartList(list model.SmartList) (model.SmartListResponse, error)
+	UpdateSmartList(userId uint, listId uint, list model.SmartList) (model.SmartListResponse, error)
+	DeleteSmartList(userId uint, listId uint) error
+}
+
+type smartListUsecase struct {
+	sr repository.ISmartListRepository
+	tr repository.ITaskRepository
+	sv validator.ISmartListValidator
+}
+
+func NewSmartListUsecase(sr repository.ISmartListRepository, tr repository.ITaskRepository, sv validator.ISmartListValidator) ISmartListUsecase {
+	return &smartListUsecase{sr, tr, sv}
+}
+
+func (su *smartListUsecase) GetAllSmartLists(userId uint) ([]model.SmartListResponse, error) {
+	var lists []model.SmartList
+	if err := su.sr.GetAll(&lists, userId); err != nil {
+		return nil, err
+	}
+
+	listResponses := []model.SmartListResponse{}
+	for _, list := range lists {
+		listResponses = append(listResponses, newSmartListResponse(list))
+	}
+	return listResponses, nil
+}
+
+func (su *smartListUsecase) GetSmartListByID(userId uint, listId uint) (model.SmartListResponse, error) {
+	list := model.SmartList{}
+	if err := su.sr.GetByID(&list, userId, listId); err != nil {
+		return model.SmartListResponse{}, err
+	}
+	return newSmartListResponse(list), nil
+}
+
+// GetSmartListTasks evaluates the saved filter against the tasks as they are
+// now, so relative due windows move with the current date. Lists belong to
+// the user and are evaluated against the tasks of the given workspace.
+func (su *smartListUsecase) GetSmartListTasks(userId uint, workspaceId uint, listId uint) ([]model.TaskResponse, error) {
+	list := model.SmartList{}
+	if err := su.sr.GetByID(&list, userId, listId); err != nil {
+		return nil, err
+	}
+
+	var tasks []model.Task
+	if err := su.tr.GetFiltered(&tasks, userId, workspaceId, resolveDueWindow(list.Filter, time.Now())); err != nil {
+		return nil, err
+	}
+
+	taskResponses := []model.TaskResponse{}
+	for _, task := range tasks {
+		taskResponses = append(taskResponses, newTaskResponse(task))
+	}
+	return taskResponses, nil
+}
+
+func (su *smartListUsecase) CreateSmartList(list model.SmartList) (model.SmartListResponse, error) {
+	if err := su.sv.SmartListValidate(list); err != nil {
+		return model.SmartListResponse{}, err
+	}
+	if err := su.sr.Create(&list); err != nil {
+		return model.SmartListResponse{}, err
+	}
+	return newSmartListResponse(list), nil
+}
+
+func (su *smartListUsecase) UpdateSmartList(userId uint, listId uint, list model.SmartList) (model.SmartListResponse, error) {
+	if err := su.sv.SmartListValidate(list); err != nil {
+		return model.SmartListResponse{}, err
+	}
+	if err := su.sr.Update(&list, userId, listId); err != nil {
+		return model.SmartListResponse{}, err
+	}
+	return newSmartListResponse(list), nil
+}
+
+func (su *smartListUsecase) DeleteSmartList(userId uint, listId uint) error {
+	return su.sr.Delete(userId, listId)
+}
+
+// resolveDueWindow turns the relative Due of filter into absolute DueFrom and
+// DueTo bounds around now. Weeks start on Monday.
+func resolveDueWindow(filter model.TaskFilter, now time.Time) model.TaskFilter {
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var from, to time.Time
+	switch filter.Due {
+	case model.DueOverdue:
+		to = now
+	case model.DueToday:
+		from, to = startOfDay, startOfDay.AddDate(0, 0, 1)
+	case model.DueThisWeek:
+		from = startOfDay.AddDate(0, 0, -((int(now.Weekday()) + 6) % 7))
+		to = from.AddDate(0, 0, 7)
+	case model.DueNext7Days:
+		from, to = now, now.AddDate(0, 0, 7)
+	default:
+		return filter
+	}
+	if !from.IsZero() {
+		filter.DueFrom = &from
+	}
+	filter.DueTo = &to
+	return filter
+}
+
+func newSmartListResponse(list model.SmartList) model.SmartListResponse {
+	return model.SmartListResponse{
+		ID:        list.ID,
+		Name:      list.Name,
+		Filter:    list.Filter,
+		CreatedAt: list.CreatedAt,
+		UpdatedAt: list.UpdatedAt,
+	}
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSmartListRepository struct {
+	mock.Mock
+}
+
+func newMockSmartListRepository() *MockSmartListRepository {
+	return &MockSmartListRepository{}
+}
+
+func (mr *MockSmartListRepository) Create(list *model.SmartList) error {
+	args := mr.Called(list)
+	return args.Error(0)
+}
+
+func (mr *MockSmartListRepository) GetAll(lists *[]model.SmartList, userId uint) error {
+	args := mr.Called(lists, userId)
+	return args.Error(0)
+}
+
+func (mr *MockSmartListRepository) GetByID(list *model.SmartList, userId uint, listId uint) error {
+	args := mr.Called(list, userId, listId)
+	return args.Error(0)
+}
+
+func (mr *MockSmartListRepository) Update(list *model.SmartList, userId uint, listId uint) error {
+	args := mr.Called(list, userId, listId)
+	return args.Error(0)
+}
+
+func (mr *MockSmartListRepository) Delete(userId uint, listId uint) error {
+	args := mr.Called(userId, listId)
+	return args.Error(0)
+}
+
+type MockSmartListValidator struct {
+	mock.Mock
+}
+
+func newMockSmartListValidator() *MockSmartListValidator {
+	return &MockSmartListValidator{}
+}
+
+func (mv *MockSmartListValidator) SmartListValidate(list model.SmartList) error {
+	args := mv.Called(list)
+	return args.Error(0)
+}
+
+func TestCreateSmartList_Success(t *testing.T) {
+	mr := newMockSmartListRepository()
+	mt := newMockTaskRepository()
+	mv := newMockSmartListValidator()
+	mr.On("Create", mock.Anything).Return(nil)
+	mv.On("SmartListValidate", mock.Anything).Return(nil)
+
+	su := NewSmartListUsecase(mr, mt, mv)
+
+	res, err := su.CreateSmartList(model.SmartList{Name: "list"})
+	assert.NoError(t, err)
+	assert.Equal(t, "list", res.Name)
+	mr.AssertCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateSmartList_Validator_Failure(t *testing.T) {
+	mr := newMockSmartListRepository()
+	mt := newMockTaskRepository()
+	mv := newMockSmartListValidator()
+	mv.On("SmartListValidate", mock.Anything).Return(errors.New("error"))
+
+	su := NewSmartListUsecase(mr, mt, mv)
+
+	_, err := su.CreateSmartList(model.SmartList{Name: "list"})
+	assert.Error(t, err)
+	mr.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestUpdateSmartList_Validator_Failure(t *testing.T) {
+	mr := newMockSmartListRepository()
+	mt := newMockTaskRepository()
+	mv := newMockSmartListValidator()
+	mv.On("SmartListValidate", mock.Anything).Return(errors.New("error"))
+
+	su := NewSmartListUsecase(mr, mt, mv)
+
+	_, err := su.UpdateSmartList(1, 1, model.SmartList{Name: "list"})
+	assert.Error(t, err)
+	mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetSmartListTasks_Success(t *testing.T) {
+	mr := newMockSmartListRepository()
+	mt := newMockTaskRepository()
+	mv := newMockSmartListValidator()
+	mr.On("GetByID", mock.Anything, uint(1), uint(2)).
+		Run(func(args mock.Arguments) {
+			list := args.Get(0).(*model.SmartList)
+			*list = model.SmartList{ID: 2, Filter: model.TaskFilter{Due: model.DueToday}}
+		}).
+		Return(nil)
+	mt.On("GetFiltered", mock.Anything, uint(1), uint(7), mock.MatchedBy(func(filter model.TaskFilter) bool {
+		return filter.DueFrom != nil && filter.DueTo != nil
+	})).
+		Run(func(args mock.Arguments) {
+			tasks := args.Get(0).(*[]model.Task)
+			*tasks = []model.Task{{ID: 1, Title: "task"}}
+		}).
+		Return(nil)
+
+	su := NewSmartListUsecase(mr, mt, mv)
+
+	res, err := su.GetSmartListTasks(1, 7, 2)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+}
+
+func TestGetSmartListTasks_Repository_Failure(t *testing.T) {
+	mr := newMockSmartListRepository()
+	mt := newMockTaskRepository()
+	mv := newMockSmartListValidator()
+	mr.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error"))
+
+	su := NewSmartListUsecase(mr, mt, mv)
+
+	_, err := su.GetSmartListTasks(1, 7, 2)
+	assert.Error(t, err)
+	mt.AssertNotCalled(t, "GetFiltered", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResolveDueWindow_ThisWeek(t *testing.T) {
+	// 2024-01-03 is a Wednesday
+	now := time.Date(2024, 1, 3, 15, 0, 0, 0, time.UTC)
+
+	filter := resolveDueWindow(model.TaskFilter{Due: model.DueThisWeek}, now)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *filter.DueFrom)
+	assert.Equal(t, time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), *filter.DueTo)
+}
+
+func TestResolveDueWindow_Overdue(t *testing.T) {
+	now := time.Date(2024, 1, 3, 15, 0, 0, 0, time.UTC)
+
+	filter := resolveDueWindow(model.TaskFilter{Due: model.DueOverdue}, now)
+	assert.Nil(t, filter.DueFrom)
+	assert.Equal(t, now, *filter.DueTo)
+}
+
+func TestResolveDueWindow_NoDueDate(t *testing.T) {
+	now := time.Date(2024, 1, 3, 15, 0, 0, 0, time.UTC)
+
+	filter := resolveDueWindow(model.TaskFilter{Due: model.DueNoDueDate}, now)
+	assert.Nil(t, filter.DueFrom)
+	assert.Nil(t, filter.DueTo)
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+	"time"
+)
+
+const (
+	wakeBatchSize  = 100
+	maxEventsFetch = 100
+)
+
+type ISnoozeUsecase interface {
+	SnoozeTask(userId uint, workspaceId uint, taskId uint, version uint, req model.SnoozeRequest) (model.TaskResponse, error)
+	UnsnoozeTask(userId uint, workspaceId uint, taskId uint, version uint) (model.TaskResponse, error)
+	WakeDueTasks() (int, error)
+	GetEvents(userId uint, workspaceId uint, after uint) ([]model.TaskEventResponse, error)
+}
+
+type snoozeUsecase struct {
+	tr repository.ITaskRepository
+	er repository.ITaskEventRepository
+	tu ITaskUsecase
+	sv validator.ISnoozeValidator
+}
+
+// NewSnoozeUsecase writes snoozes through tu so that they are versioned and
+// counted like any other edit.
+func NewSnoozeUsecase(tr repository.ITaskRepository, er repository.ITaskEventRepository, tu ITaskUsecase, sv validator.ISnoozeValidator) ISnoozeUsecase {
+	return &snoozeUsecase{tr, er, tu, sv}
+}
+
+func (su *snoozeUsecase) SnoozeTask(userId uint, workspaceId uint, taskId uint, version uint, req model.SnoozeRequest) (model.TaskResponse, error) {
+	if err := su.sv.SnoozeValidate(req); err != nil {
+		return model.TaskResponse{}, err
+	}
+	patch := model.TaskPatch{ScheduledAt: model.PatchField[time.Time]{Set: true, Value: req.Until}}
+	return su.tu.PatchTask(userId, workspaceId, taskId, version, patch)
+}
+
+// UnsnoozeTask brings the task back right away. Unlike waking up on its own,
+// this records no event since the user did it.
+func (su *snoozeUsecase) UnsnoozeTask(userId uint, workspaceId uint, taskId uint, version uint) (model.TaskResponse, error) {
+	patch := model.TaskPatch{ScheduledAt: model.PatchField[time.Time]{Set: true, Null: true}}
+	return su.tu.PatchTask(userId, workspaceId, taskId, version, patch)
+}
+
+// WakeDueTasks wakes every task whose snooze has run out, in batches, and
+// reports how many woke up.
+func (su *snoozeUsecase) WakeDueTasks() (int, error) {
+	now := time.Now()
+	woken := 0
+	for {
+		var tasks []model.Task
+		if err := su.tr.WakeSnoozed(&tasks, now, wakeBatchSize); err != nil {
+			return woken, err
+		}
+		woken += len(tasks)
+		if len(tasks) < wakeBatchSize {
+			return woken, nil
+		}
+	}
+}
+
+func (su *snoozeUsecase) GetEvents(userId uint, workspaceId uint, after uint) ([]model.TaskEventResponse, error) {
+	var events []model.TaskEvent
+	if err := su.er.GetAfter(&events, userId, workspaceId, after, maxEventsFetch); err != nil {
+		return nil, err
+	}
+
+	eventResponses := []model.TaskEventResponse{}
+	for _, event := range events {
+		eventResponses = append(eventResponses, model.TaskEventResponse{
+			ID:        event.ID,
+			Type:      event.Type,
+			TaskId:    event.TaskId,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	return eventResponses, nil
+}
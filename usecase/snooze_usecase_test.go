@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaskEventRepository struct {
+	mock.Mock
+}
+
+func newMockTaskEventRepository() *MockTaskEventRepository {
+	return &MockTaskEventRepository{}
+}
+
+func (mr *MockTaskEventRepository) GetAfter(events *[]model.TaskEvent, userId uint, workspaceId uint, after uint, limit int) error {
+	args := mr.Called(events, userId, workspaceId, after, limit)
+	return args.Error(0)
+}
+
+type MockSnoozeValidator struct {
+	mock.Mock
+}
+
+func newMockSnoozeValidator() *MockSnoozeValidator {
+	return &MockSnoozeValidator{}
+}
+
+func (mv *MockSnoozeValidator) SnoozeValidate(req model.SnoozeRequest) error {
+	args := mv.Called(req)
+	return args.Error(0)
+}
+
+func TestSnoozeTask_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	me := newMockTaskEventRepository()
+	mu := newMockTaskUsecase()
+	mv := newMockSnoozeValidator()
+	until := time.Now().Add(time.Hour)
+	mv.On("SnoozeValidate", mock.Anything).Return(nil)
+	mu.On("PatchTask", uint(1), uint(7), uint(2), uint(3), model.TaskPatch{ScheduledAt: model.PatchField[time.Time]{Set: true, Value: until}}).
+		Return(model.TaskResponse{ID: 2, ScheduledAt: &until}, nil)
+
+	su := NewSnoozeUsecase(mr, me, mu, mv)
+
+	res, err := su.SnoozeTask(1, 7, 2, 3, model.SnoozeRequest{Until: until})
+	assert.NoError(t, err)
+	assert.Equal(t, &until, res.ScheduledAt)
+}
+
+func TestSnoozeTask_Validator_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	me := newMockTaskEventRepository()
+	mu := newMockTaskUsecase()
+	mv := newMockSnoozeValidator()
+	mv.On("SnoozeValidate", mock.Anything).Return(errors.New("error"))
+
+	su := NewSnoozeUsecase(mr, me, mu, mv)
+
+	_, err := su.SnoozeTask(1, 7, 2, 0, model.SnoozeRequest{})
+	assert.Error(t, err)
+	mu.AssertNotCalled(t, "PatchTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUnsnoozeTask_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	me := newMockTaskEventRepository()
+	mu := newMockTaskUsecase()
+	mv := newMockSnoozeValidator()
+	mu.On("PatchTask", uint(1), uint(7), uint(2), uint(0), model.TaskPatch{ScheduledAt: model.PatchField[time.Time]{Set: true, Null: true}}).
+		Return(model.TaskResponse{ID: 2}, nil)
+
+	su := NewSnoozeUsecase(mr, me, mu, mv)
+
+	res, err := su.UnsnoozeTask(1, 7, 2, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, res.ScheduledAt)
+}
+
+func TestWakeDueTasks_Batches(t *testing.T) {
+	mr := newMockTaskRepository()
+	me := newMockTaskEventRepository()
+	mu := newMockTaskUsecase()
+	mv := newMockSnoozeValidator()
+	mr.On("WakeSnoozed", mock.Anything, mock.Anything, wakeBatchSize).
+		Run(func(args mock.Arguments) {
+			tasks := args.Get(0).(*[]model.Task)
+			*tasks = make([]model.Task, wakeBatchSize)
+		}).
+		Return(nil).Once()
+	mr.On("WakeSnoozed", mock.Anything, mock.Anything, wakeBatchSize).
+		Run(func(args mock.Arguments) {
+			tasks := args.Get(0).(*[]model.Task)
+			*tasks = make([]model.Task, 2)
+		}).
+		Return(nil).Once()
+
+	su := NewSnoozeUsecase(mr, me, mu, mv)
+
+	woken, err := su.WakeDueTasks()
+	assert.NoError(t, err)
+	assert.Equal(t, wakeBatchSize+2, woken)
+	mr.AssertNumberOfCalls(t, "WakeSnoozed", 2)
+}
+
+func TestGetEvents_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	me := newMockTaskEventRepository()
+	mu := newMockTaskUsecase()
+	mv := newMockSnoozeValidator()
+	me.On("GetAfter", mock.Anything, uint(1), uint(7), uint(5), maxEventsFetch).
+		Run(func(args mock.Arguments) {
+			events := args.Get(0).(*[]model.TaskEvent)
+			*events = []model.TaskEvent{{ID: 6, Type: model.TaskEventWokeUp, TaskId: 2, UserId: 1}}
+		}).
+		Return(nil)
+
+	su := NewSnoozeUsecase(mr, me, mu, mv)
+
+	res, err := su.GetEvents(1, 7, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TaskEventResponse{{ID: 6, Type: model.TaskEventWokeUp, TaskId: 2}}, res)
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+)
+
+type IStatsUsecase interface {
+	GetStats(userId uint, workspaceId uint, query model.StatsQuery) (model.StatsResponse, error)
+}
+
+type statsUsecase struct {
+	sr repository.IStatsRepository
+	sv validator.IStatsValidator
+}
+
+func NewStatsUsecase(sr repository.IStatsRepository, sv validator.IStatsValidator) IStatsUsecase {
+	return &statsUsecase{sr, sv}
+}
+
+func (su *statsUsecase) GetStats(userId uint, workspaceId uint, query model.StatsQuery) (model.StatsResponse, error) {
+	if err := su.sv.StatsQueryValidate(query); err != nil {
+		return model.StatsResponse{}, err
+	}
+	res := model.StatsResponse{
+		From:         query.From,
+		To:           query.To,
+		Interval:     query.Interval,
+		Throughput:   []model.ThroughputPoint{},
+		OpenByStatus: []model.StatusCount{},
+		OpenByLabel:  []model.LabelCount{},
+		Burndown:     []model.BurndownPoint{},
+	}
+	if err := su.sr.GetThroughput(&res.Throughput, userId, workspaceId, query); err != nil {
+		return model.StatsResponse{}, err
+	}
+	if err := su.sr.GetAverageLeadTime(&res.AverageLeadTimeHours, userId, workspaceId, query); err != nil {
+		return model.StatsResponse{}, err
+	}
+	if err := su.sr.CountOpenByStatus(&res.OpenByStatus, userId, workspaceId); err != nil {
+		return model.StatsResponse{}, err
+	}
+	if err := su.sr.CountOpenByLabel(&res.OpenByLabel, userId, workspaceId); err != nil {
+		return model.StatsResponse{}, err
+	}
+	if err := su.sr.GetBurndown(&res.Burndown, userId, workspaceId, query); err != nil {
+		return model.StatsResponse{}, err
+	}
+	return res, nil
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStatsRepository struct {
+	mock.Mock
+}
+
+func newMockStatsRepository() *MockStatsRepository {
+	return &MockStatsRepository{}
+}
+
+func (mr *MockStatsRepository) GetThroughput(points *[]model.ThroughputPoint, userId uint, workspaceId uint, query model.StatsQuery) error {
+	args := mr.Called(points, userId, workspaceId, query)
+	return args.Error(0)
+}
+
+func (mr *MockStatsRepository) GetBurndown(points *[]model.BurndownPoint, userId uint, workspaceId uint, query model.StatsQuery) error {
+	args := mr.Called(points, userId, workspaceId, query)
+	return args.Error(0)
+}
+
+func (mr *MockStatsRepository) GetAverageLeadTime(hours *float64, userId uint, workspaceId uint, query model.StatsQuery) error {
+	args := mr.Called(hours, userId, workspaceId, query)
+	return args.Error(0)
+}
+
+func (mr *MockStatsRepository) CountOpenByStatus(counts *[]model.StatusCount, userId uint, workspaceId uint) error {
+	args := mr.Called(counts, userId, workspaceId)
+	return args.Error(0)
+}
+
+func (mr *MockStatsRepository) CountOpenByLabel(counts *[]model.LabelCount, userId uint, workspaceId uint) error {
+	args := mr.Called(counts, userId, workspaceId)
+	return args.Error(0)
+}
+
+type MockStatsValidator struct {
+	mock.Mock
+}
+
+func newMockStatsValidator() *MockStatsValidator {
+	return &MockStatsValidator{}
+}
+
+func (mv *MockStatsValidator) StatsQueryValidate(query model.StatsQuery) error {
+	args := mv.Called(query)
+	return args.Error(0)
+}
+
+func newStatsQuery() model.StatsQuery {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return model.StatsQuery{From: from, To: from.AddDate(0, 0, 7), Interval: model.StatsIntervalDay}
+}
+
+func TestGetStats_Success(t *testing.T) {
+	mr := newMockStatsRepository()
+	mv := newMockStatsValidator()
+	mv.On("StatsQueryValidate", mock.Anything).Return(nil)
+	mr.On("GetThroughput", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mr.On("GetAverageLeadTime", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*float64) = 12.5
+		}).
+		Return(nil)
+	mr.On("CountOpenByStatus", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]model.StatusCount) = []model.StatusCount{{Status: model.TaskStatusTodo, Count: 3}}
+		}).
+		Return(nil)
+	mr.On("CountOpenByLabel", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mr.On("GetBurndown", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	su := NewStatsUsecase(mr, mv)
+
+	res, err := su.GetStats(1, 7, newStatsQuery())
+	assert.NoError(t, err)
+	assert.Equal(t, 12.5, res.AverageLeadTimeHours)
+	assert.Equal(t, int64(3), res.OpenByStatus[0].Count)
+	assert.NotNil(t, res.OpenByLabel)
+	mv.AssertCalled(t, "StatsQueryValidate", mock.Anything)
+}
+
+func TestGetStats_Validator_Failure(t *testing.T) {
+	mr := newMockStatsRepository()
+	mv := newMockStatsValidator()
+	mv.On("StatsQueryValidate", mock.Anything).Return(errors.New("error"))
+
+	su := NewStatsUsecase(mr, mv)
+
+	_, err := su.GetStats(1, 7, newStatsQuery())
+	assert.Error(t, err)
+	mr.AssertNotCalled(t, "GetThroughput", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetStats_Repository_Failure(t *testing.T) {
+	mr := newMockStatsRepository()
+	mv := newMockStatsValidator()
+	mv.On("StatsQueryValidate", mock.Anything).Return(nil)
+	mr.On("GetThroughput", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error"))
+
+	su := NewStatsUsecase(mr, mv)
+
+	_, err := su.GetStats(1, 7, newStatsQuery())
+	assert.Error(t, err)
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// mergeField reads one editable task field from a patch and from a stored
+// task in a comparable form, and copies it between patches.
+type mergeField struct {
+	name    string
+	inPatch func(patch model.TaskPatch) (bool, interface{})
+	inTask  func(task model.TaskResponse) interface{}
+	copy    func(dst *model.TaskPatch, src model.TaskPatch)
+}
+
+var mergeFields = []mergeField{
+	{
+		name:    "title",
+		inPatch: func(p model.TaskPatch) (bool, interface{}) { return p.Title.Set, p.Title.Value },
+		inTask:  func(t model.TaskResponse) interface{} { return t.Title },
+		copy:    func(dst *model.TaskPatch, src model.TaskPatch) { dst.Title = src.Title },
+	},
+	{
+		name:    "description",
+		inPatch: func(p model.TaskPatch) (bool, interface{}) { return p.Description.Set, p.Description.Value },
+		inTask:  func(t model.TaskResponse) interface{} { return t.Description },
+		copy:    func(dst *model.TaskPatch, src model.TaskPatch) { dst.Description = src.Description },
+	},
+	{
+		name:    "status",
+		inPatch: func(p model.TaskPatch) (bool, interface{}) { return p.Status.Set, p.Status.Value },
+		inTask:  func(t model.TaskResponse) interface{} { return t.Status },
+		copy:    func(dst *model.TaskPatch, src model.TaskPatch) { dst.Status = src.Status },
+	},
+	{
+		name:    "priority",
+		inPatch: func(p model.TaskPatch) (bool, interface{}) { return p.Priority.Set, p.Priority.Value },
+		inTask:  func(t model.TaskResponse) interface{} { return t.Priority },
+		copy:    func(dst *model.TaskPatch, src model.TaskPatch) { dst.Priority = src.Priority },
+	},
+	{
+		name: "due_date",
+		inPatch: func(p model.TaskPatch) (bool, interface{}) {
+			if p.DueDate.Null {
+				return p.DueDate.Set, comparableTime(nil)
+			}
+			return p.DueDate.Set, comparableTime(&p.DueDate.Value)
+		},
+		inTask: func(t model.TaskResponse) interface{} { return comparableTime(t.DueDate) },
+		copy:   func(dst *model.TaskPatch, src model.TaskPatch) { dst.DueDate = src.DueDate },
+	},
+	{
+		name: "labels",
+		inPatch: func(p model.TaskPatch) (bool, interface{}) {
+			names := make([]string, 0, len(p.Labels.Value))
+			for _, label := range p.Labels.Value {
+				names = append(names, label.Name)
+			}
+			return p.Labels.Set, sortedNames(names)
+		},
+		inTask: func(t model.TaskResponse) interface{} {
+			names := make([]string, 0, len(t.Labels))
+			for _, label := range t.Labels {
+				names = append(names, label.Name)
+			}
+			return sortedNames(names)
+		},
+		copy: func(dst *model.TaskPatch, src model.TaskPatch) { dst.Labels = src.Labels },
+	},
+	{
+		name:    "recurrence",
+		inPatch: func(p model.TaskPatch) (bool, interface{}) { return p.Recurrence.Set, p.Recurrence.Value },
+		inTask:  func(t model.TaskResponse) interface{} { return t.Recurrence },
+		copy:    func(dst *model.TaskPatch, src model.TaskPatch) { dst.Recurrence = src.Recurrence },
+	},
+	{
+		name: "assignee_id",
+		inPatch: func(p model.TaskPatch) (bool, interface{}) {
+			if p.AssigneeId.Null {
+				return p.AssigneeId.Set, comparableId(nil)
+			}
+			return p.AssigneeId.Set, comparableId(&p.AssigneeId.Value)
+		},
+		inTask: func(t model.TaskResponse) interface{} { return comparableId(t.AssigneeId) },
+		copy:   func(dst *model.TaskPatch, src model.TaskPatch) { dst.AssigneeId = src.AssigneeId },
+	},
+	{
+		name: "project_id",
+		inPatch: func(p model.TaskPatch) (bool, interface{}) {
+			if p.ProjectId.Null {
+				return p.ProjectId.Set, comparableId(nil)
+			}
+			return p.ProjectId.Set, comparableId(&p.ProjectId.Value)
+		},
+		inTask: func(t model.TaskResponse) interface{} { return comparableId(t.ProjectId) },
+		copy:   func(dst *model.TaskPatch, src model.TaskPatch) { dst.ProjectId = src.ProjectId },
+	},
+	{
+		name: "custom_fields",
+		inPatch: func(p model.TaskPatch) (bool, interface{}) {
+			return p.CustomFields.Set, comparableValues(p.CustomFields.Value)
+		},
+		inTask: func(t model.TaskResponse) interface{} { return comparableValues(t.CustomFields) },
+		copy:   func(dst *model.TaskPatch, src model.TaskPatch) { dst.CustomFields = src.CustomFields },
+	},
+}
+
+// mergeTaskChanges three-way merges an offline edit into the current task.
+// A field the client changed is taken when the server still has the base
+// value, skipped when both sides made the same change, and reported as a
+// conflict when the server changed it differently. A field whose base value
+// the client did not send is only taken if the server is still at the base
+// version.
+func mergeTaskChanges(base model.TaskPatch, changes model.TaskPatch, baseVersion uint, current model.TaskResponse) (model.TaskPatch, []model.SyncConflict) {
+	merged := model.TaskPatch{}
+	conflicts := []model.SyncConflict{}
+	for _, field := range mergeFields {
+		changed, yours := field.inPatch(changes)
+		if !changed {
+			continue
+		}
+		theirs := field.inTask(current)
+		if reflect.DeepEqual(yours, theirs) {
+			continue
+		}
+		hasBase, original := field.inPatch(base)
+		if current.Version == baseVersion || (hasBase && reflect.DeepEqual(original, theirs)) {
+			field.copy(&merged, changes)
+			continue
+		}
+		if !hasBase {
+			original = nil
+		}
+		conflicts = append(conflicts, model.SyncConflict{Field: field.name, Base: original, Yours: yours, Theirs: theirs})
+	}
+	return merged, conflicts
+}
+
+// deleteConflicts reports the fields the server changed after baseVersion,
+// which a delete made against baseVersion would throw away. Without base
+// values only the version change itself can be reported.
+func deleteConflicts(base model.TaskPatch, baseVersion uint, current model.TaskResponse) []model.SyncConflict {
+	conflicts := []model.SyncConflict{}
+	if current.Version == baseVersion {
+		return conflicts
+	}
+	if isEmptyPatch(base) {
+		return append(conflicts, model.SyncConflict{Field: "version", Base: baseVersion, Yours: nil, Theirs: current.Version})
+	}
+	for _, field := range mergeFields {
+		hasBase, original := field.inPatch(base)
+		theirs := field.inTask(current)
+		if !hasBase || reflect.DeepEqual(original, theirs) {
+			continue
+		}
+		conflicts = append(conflicts, model.SyncConflict{Field: field.name, Base: original, Yours: nil, Theirs: theirs})
+	}
+	return conflicts
+}
+
+func comparableTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	normalized := t.UTC().Truncate(time.Microsecond)
+	return &normalized
+}
+
+// comparableId dereferences an optional ID so that equal IDs compare equal.
+func comparableId(id *uint) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+func sortedNames(names []string) []string {
+	sort.Strings(names)
+	return names
+}
+
+// isEmptyPatch reports whether the patch sets none of mergeFields.
+func isEmptyPatch(patch model.TaskPatch) bool {
+	for _, field := range mergeFields {
+		if set, _ := field.inPatch(patch); set {
+			return false
+		}
+	}
+	return true
+}
+
+// comparableValues treats missing custom field values like empty ones.
+func comparableValues(values model.CustomFieldValues) model.CustomFieldValues {
+	if values == nil {
+		return model.CustomFieldValues{}
+	}
+	return values
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeTaskChanges(t *testing.T) {
+	due := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	otherDue := time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)
+	current := model.TaskResponse{
+		ID:       1,
+		Title:    "server title",
+		Status:   model.TaskStatusDoing,
+		Priority: model.TaskPriorityHigh,
+		DueDate:  &due,
+		Labels:   []model.LabelResponse{{ID: 1, Name: "work"}, {ID: 2, Name: "home"}},
+		Version:  5,
+	}
+	assignee := uint(2)
+	project := uint(3)
+
+	tests := []struct {
+		name          string
+		base          model.TaskPatch
+		changes       model.TaskPatch
+		baseVersion   uint
+		wantFields    []string
+		wantConflicts []model.SyncConflict
+	}{
+		{
+			name:        "same version applies everything",
+			changes:     model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "client title"}},
+			baseVersion: 5,
+			wantFields:  []string{"title"},
+		},
+		{
+			name:        "field unchanged on server is merged",
+			base:        model.TaskPatch{Priority: model.PatchField[string]{Set: true, Value: model.TaskPriorityHigh}},
+			changes:     model.TaskPatch{Priority: model.PatchField[string]{Set: true, Value: model.TaskPriorityLow}},
+			baseVersion: 3,
+			wantFields:  []string{"priority"},
+		},
+		{
+			name:        "same change on both sides is skipped",
+			base:        model.TaskPatch{Status: model.PatchField[string]{Set: true, Value: model.TaskStatusTodo}},
+			changes:     model.TaskPatch{Status: model.PatchField[string]{Set: true, Value: model.TaskStatusDoing}},
+			baseVersion: 3,
+		},
+		{
+			name:        "different change on both sides conflicts",
+			base:        model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "base title"}},
+			changes:     model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "client title"}},
+			baseVersion: 3,
+			wantConflicts: []model.SyncConflict{
+				{Field: "title", Base: "base title", Yours: "client title", Theirs: "server title"},
+			},
+		},
+		{
+			name:        "missing base value conflicts",
+			changes:     model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "client title"}},
+			baseVersion: 3,
+			wantConflicts: []model.SyncConflict{
+				{Field: "title", Base: nil, Yours: "client title", Theirs: "server title"},
+			},
+		},
+		{
+			name: "cleared due date is merged",
+			base: model.TaskPatch{
+				DueDate: model.PatchField[time.Time]{Set: true, Value: due.In(time.FixedZone("JST", 9*60*60))},
+			},
+			changes:     model.TaskPatch{DueDate: model.PatchField[time.Time]{Set: true, Null: true}},
+			baseVersion: 3,
+			wantFields:  []string{"due_date"},
+		},
+		{
+			name:        "due date changed on both sides conflicts",
+			base:        model.TaskPatch{DueDate: model.PatchField[time.Time]{Set: true, Null: true}},
+			changes:     model.TaskPatch{DueDate: model.PatchField[time.Time]{Set: true, Value: otherDue}},
+			baseVersion: 3,
+			wantConflicts: []model.SyncConflict{
+				{Field: "due_date", Base: (*time.Time)(nil), Yours: &otherDue, Theirs: &due},
+			},
+		},
+		{
+			name: "labels compare regardless of order",
+			base: model.TaskPatch{Labels: model.PatchField[[]model.Label]{Set: true, Value: []model.Label{{Name: "work"}, {Name: "home"}}}},
+			changes: model.TaskPatch{
+				Labels: model.PatchField[[]model.Label]{Set: true, Value: []model.Label{{Name: "home"}}},
+			},
+			baseVersion: 3,
+			wantFields:  []string{"labels"},
+		},
+		{
+			name:        "description is merged",
+			base:        model.TaskPatch{Description: model.PatchField[string]{Set: true, Value: ""}},
+			changes:     model.TaskPatch{Description: model.PatchField[string]{Set: true, Value: "client notes"}},
+			baseVersion: 3,
+			wantFields:  []string{"description"},
+		},
+		{
+			name:        "recurrence is merged",
+			base:        model.TaskPatch{Recurrence: model.PatchField[string]{Set: true, Value: ""}},
+			changes:     model.TaskPatch{Recurrence: model.PatchField[string]{Set: true, Value: "FREQ=WEEKLY"}},
+			baseVersion: 3,
+			wantFields:  []string{"recurrence"},
+		},
+		{
+			name:        "assignee is merged",
+			base:        model.TaskPatch{AssigneeId: model.PatchField[uint]{Set: true, Null: true}},
+			changes:     model.TaskPatch{AssigneeId: model.PatchField[uint]{Set: true, Value: assignee}},
+			baseVersion: 3,
+			wantFields:  []string{"assignee_id"},
+		},
+		{
+			name:        "project is merged",
+			base:        model.TaskPatch{ProjectId: model.PatchField[uint]{Set: true, Null: true}},
+			changes:     model.TaskPatch{ProjectId: model.PatchField[uint]{Set: true, Value: project}},
+			baseVersion: 3,
+			wantFields:  []string{"project_id"},
+		},
+		{
+			name:        "custom fields are merged",
+			base:        model.TaskPatch{CustomFields: model.PatchField[model.CustomFieldValues]{Set: true}},
+			changes:     model.TaskPatch{CustomFields: model.PatchField[model.CustomFieldValues]{Set: true, Value: model.CustomFieldValues{"1": 5.0}}},
+			baseVersion: 3,
+			wantFields:  []string{"custom_fields"},
+		},
+		{
+			name: "non-conflicting fields merge next to a conflict",
+			base: model.TaskPatch{
+				Title:    model.PatchField[string]{Set: true, Value: "base title"},
+				Priority: model.PatchField[string]{Set: true, Value: model.TaskPriorityHigh},
+			},
+			changes: model.TaskPatch{
+				Title:    model.PatchField[string]{Set: true, Value: "client title"},
+				Priority: model.PatchField[string]{Set: true, Value: model.TaskPriorityLow},
+			},
+			baseVersion: 3,
+			wantFields:  []string{"priority"},
+			wantConflicts: []model.SyncConflict{
+				{Field: "title", Base: "base title", Yours: "client title", Theirs: "server title"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := mergeTaskChanges(tt.base, tt.changes, tt.baseVersion, current)
+
+			var fields []string
+			for _, field := range mergeFields {
+				if set, _ := field.inPatch(merged); set {
+					fields = append(fields, field.name)
+				}
+			}
+			assert.Equal(t, tt.wantFields, fields)
+			if tt.wantConflicts == nil {
+				assert.Empty(t, conflicts)
+			} else {
+				assert.Equal(t, tt.wantConflicts, conflicts)
+			}
+		})
+	}
+}
+
+func TestDeleteConflicts(t *testing.T) {
+	current := model.TaskResponse{ID: 1, Title: "server title", Version: 5}
+
+	assert.Empty(t, deleteConflicts(model.TaskPatch{}, 5, current))
+	assert.Empty(t, deleteConflicts(model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "server title"}}, 3, current))
+	assert.Equal(t,
+		[]model.SyncConflict{{Field: "title", Base: "base title", Yours: nil, Theirs: "server title"}},
+		deleteConflicts(model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "base title"}}, 3, current),
+	)
+	assert.Equal(t,
+		[]model.SyncConflict{{Field: "version", Base: uint(3), Yours: nil, Theirs: uint(5)}},
+		deleteConflicts(model.TaskPatch{}, 3, current),
+	)
+	assert.Equal(t,
+		[]model.SyncConflict{{Field: "description", Base: "base notes", Yours: nil, Theirs: ""}},
+		deleteConflicts(model.TaskPatch{Description: model.PatchField[string]{Set: true, Value: "base notes"}}, 3, current),
+	)
+}
+
+func TestIsEmptyPatch(t *testing.T) {
+	assignee := uint(2)
+	project := uint(3)
+	tests := []struct {
+		name  string
+		patch model.TaskPatch
+	}{
+		{name: "title", patch: model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "title"}}},
+		{name: "description", patch: model.TaskPatch{Description: model.PatchField[string]{Set: true, Value: "notes"}}},
+		{name: "status", patch: model.TaskPatch{Status: model.PatchField[string]{Set: true, Value: model.TaskStatusDone}}},
+		{name: "priority", patch: model.TaskPatch{Priority: model.PatchField[string]{Set: true, Value: model.TaskPriorityLow}}},
+		{name: "due_date", patch: model.TaskPatch{DueDate: model.PatchField[time.Time]{Set: true, Null: true}}},
+		{name: "labels", patch: model.TaskPatch{Labels: model.PatchField[[]model.Label]{Set: true}}},
+		{name: "recurrence", patch: model.TaskPatch{Recurrence: model.PatchField[string]{Set: true, Value: "FREQ=DAILY"}}},
+		{name: "assignee_id", patch: model.TaskPatch{AssigneeId: model.PatchField[uint]{Set: true, Value: assignee}}},
+		{name: "project_id", patch: model.TaskPatch{ProjectId: model.PatchField[uint]{Set: true, Value: project}}},
+		{name: "custom_fields", patch: model.TaskPatch{CustomFields: model.PatchField[model.CustomFieldValues]{Set: true, Value: model.CustomFieldValues{"1": "a"}}}},
+	}
+
+	assert.True(t, isEmptyPatch(model.TaskPatch{}))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.False(t, isEmptyPatch(tt.patch))
+		})
+	}
+	assert.Len(t, tests, len(mergeFields), "every merged field needs a case")
+}
@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	syncTokenPrefix  = "v2:"
+	maxMergeAttempts = 3
+)
+
+type ISyncUsecase interface {
+	Sync(userId uint, workspaceId uint, token string) (model.SyncResponse, error)
+	Push(userId uint, workspaceId uint, items []model.SyncPushItem) []model.SyncPushResult
+}
+
+type syncUsecase struct {
+	sr           repository.ISyncRepository
+	tu           ITaskUsecase
+	tombstoneTTL time.Duration
+}
+
+// NewSyncUsecase keeps tombstones of deleted tasks for tombstoneTTL. Clients
+// that have not synced for longer than that must start over with a full sync.
+// Pushed edits are written through tu so that they are validated like any
+// other task change.
+func NewSyncUsecase(sr repository.ISyncRepository, tu ITaskUsecase, tombstoneTTL time.Duration) ISyncUsecase {
+	return &syncUsecase{sr, tu, tombstoneTTL}
+}
+
+// Sync returns everything that changed after token, or every task when token
+// is empty, together with the token for the next call. Tokens are bound to
+// the workspace they were issued for.
+func (su *syncUsecase) Sync(userId uint, workspaceId uint, token string) (model.SyncResponse, error) {
+	since := int64(0)
+	if token != "" {
+		seq, err := decodeSyncToken(token, workspaceId)
+		if err != nil {
+			return model.SyncResponse{}, err
+		}
+		since = seq
+	}
+
+	if err := su.sr.PurgeTombstones(userId, time.Now().Add(-su.tombstoneTTL)); err != nil {
+		return model.SyncResponse{}, err
+	}
+	counter := model.SyncCounter{}
+	if err := su.sr.GetCounter(&counter, userId); err != nil {
+		return model.SyncResponse{}, err
+	}
+	if since > counter.Seq {
+		return model.SyncResponse{}, model.ErrInvalidSyncToken
+	}
+	if token != "" && since < counter.PurgedSeq {
+		return model.SyncResponse{}, model.ErrSyncTokenExpired
+	}
+
+	var tasks []model.Task
+	if err := su.sr.GetChangedTasks(&tasks, userId, workspaceId, since, counter.Seq); err != nil {
+		return model.SyncResponse{}, err
+	}
+	res := model.SyncResponse{
+		Tasks:   []model.TaskResponse{},
+		Deleted: []model.TombstoneResponse{},
+		Token:   encodeSyncToken(workspaceId, counter.Seq),
+		Full:    token == "",
+	}
+	for _, task := range tasks {
+		res.Tasks = append(res.Tasks, newTaskResponse(task))
+	}
+	if res.Full {
+		return res, nil
+	}
+
+	var tombstones []model.TaskTombstone
+	if err := su.sr.GetTombstones(&tombstones, userId, workspaceId, since, counter.Seq); err != nil {
+		return model.SyncResponse{}, err
+	}
+	for _, tombstone := range tombstones {
+		res.Deleted = append(res.Deleted, model.TombstoneResponse{ID: tombstone.TaskId, DeletedAt: tombstone.DeletedAt})
+	}
+	return res, nil
+}
+
+// Push applies a batch of offline edits in order. Each item gets its own
+// result, so one rejected or conflicting edit does not hold back the rest.
+func (su *syncUsecase) Push(userId uint, workspaceId uint, items []model.SyncPushItem) []model.SyncPushResult {
+	results := make([]model.SyncPushResult, 0, len(items))
+	for _, item := range items {
+		result := su.pushItem(userId, workspaceId, item)
+		result.TaskId = item.TaskId
+		result.ClientId = item.ClientId
+		if result.Task != nil {
+			result.TaskId = result.Task.ID
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (su *syncUsecase) pushItem(userId uint, workspaceId uint, item model.SyncPushItem) model.SyncPushResult {
+	if item.TaskId == 0 {
+		return su.pushCreate(userId, workspaceId, item)
+	}
+	if item.BaseVersion == 0 {
+		return model.SyncPushResult{Status: model.SyncPushRejected, Error: "base_version is required"}
+	}
+	// The task can change between reading and writing it; merge again
+	// against the newer version when the write finds it stale.
+	for attempt := 0; attempt < maxMergeAttempts; attempt++ {
+		current, err := su.tu.GetTaskByID(userId, workspaceId, item.TaskId)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if item.Deleted {
+				return model.SyncPushResult{Status: model.SyncPushDeleted}
+			}
+			return model.SyncPushResult{
+				Status:    model.SyncPushConflict,
+				Conflicts: []model.SyncConflict{{Field: "deleted", Base: false, Yours: false, Theirs: true}},
+			}
+		}
+		if err != nil {
+			return model.SyncPushResult{Status: model.SyncPushRejected, Error: err.Error()}
+		}
+
+		var result model.SyncPushResult
+		if item.Deleted {
+			result, err = su.pushDelete(userId, workspaceId, item, current)
+		} else {
+			result, err = su.pushUpdate(userId, workspaceId, item, current)
+		}
+		if errors.Is(err, model.ErrStaleVersion) {
+			continue
+		}
+		if err != nil {
+			return model.SyncPushResult{Status: model.SyncPushRejected, Error: err.Error()}
+		}
+		return result
+	}
+	return model.SyncPushResult{Status: model.SyncPushRejected, Error: "task kept changing while merging, push again"}
+}
+
+func (su *syncUsecase) pushCreate(userId uint, workspaceId uint, item model.SyncPushItem) model.SyncPushResult {
+	if item.Deleted {
+		return model.SyncPushResult{Status: model.SyncPushDeleted}
+	}
+	taskResp, err := su.tu.CreateTask(newTaskFromPatch(userId, workspaceId, item.Changes))
+	if err != nil {
+		return model.SyncPushResult{Status: model.SyncPushRejected, Error: err.Error()}
+	}
+	return model.SyncPushResult{Status: model.SyncPushCreated, Task: &taskResp}
+}
+
+// newTaskFromPatch builds a task created offline from every field of its
+// change set. CreateTask validates it like any other new task.
+func newTaskFromPatch(userId uint, workspaceId uint, changes model.TaskPatch) model.Task {
+	return model.Task{
+		Title:        changes.Title.Value,
+		Description:  changes.Description.Value,
+		Status:       changes.Status.Value,
+		Priority:     changes.Priority.Value,
+		DueDate:      patchValue(changes.DueDate),
+		Labels:       changes.Labels.Value,
+		Recurrence:   changes.Recurrence.Value,
+		ScheduledAt:  patchValue(changes.ScheduledAt),
+		AssigneeId:   patchValue(changes.AssigneeId),
+		ProjectId:    patchValue(changes.ProjectId),
+		CustomFields: changes.CustomFields.Value,
+		UserId:       userId,
+		WorkspaceId:  workspaceId,
+	}
+}
+
+// patchValue is the value of an optional field, or nil if the patch leaves
+// it unset or clears it.
+func patchValue[T any](field model.PatchField[T]) *T {
+	if !field.Set || field.Null {
+		return nil
+	}
+	value := field.Value
+	return &value
+}
+
+func (su *syncUsecase) pushUpdate(userId uint, workspaceId uint, item model.SyncPushItem, current model.TaskResponse) (model.SyncPushResult, error) {
+	merged, conflicts := mergeTaskChanges(item.Base, item.Changes, item.BaseVersion, current)
+	status := model.SyncPushMerged
+	if current.Version == item.BaseVersion {
+		status = model.SyncPushApplied
+	}
+	if len(conflicts) > 0 {
+		status = model.SyncPushConflict
+	}
+	if isEmptyPatch(merged) {
+		return model.SyncPushResult{Status: status, Task: &current, Conflicts: conflicts}, nil
+	}
+	taskResp, err := su.tu.PatchTask(userId, workspaceId, item.TaskId, current.Version, merged)
+	if err != nil {
+		return model.SyncPushResult{}, err
+	}
+	return model.SyncPushResult{Status: status, Task: &taskResp, Conflicts: conflicts}, nil
+}
+
+func (su *syncUsecase) pushDelete(userId uint, workspaceId uint, item model.SyncPushItem, current model.TaskResponse) (model.SyncPushResult, error) {
+	if conflicts := deleteConflicts(item.Base, item.BaseVersion, current); len(conflicts) > 0 {
+		return model.SyncPushResult{Status: model.SyncPushConflict, Task: &current, Conflicts: conflicts}, nil
+	}
+	if err := su.tu.DeleteTask(userId, workspaceId, item.TaskId, current.Version); err != nil {
+		return model.SyncPushResult{}, err
+	}
+	return model.SyncPushResult{Status: model.SyncPushDeleted}, nil
+}
+
+func encodeSyncToken(workspaceId uint, seq int64) string {
+	raw := syncTokenPrefix + strconv.FormatUint(uint64(workspaceId), 10) + ":" + strconv.FormatInt(seq, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSyncToken rejects tokens issued for another workspace.
+func decodeSyncToken(token string, workspaceId uint) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(raw), syncTokenPrefix) {
+		return 0, model.ErrInvalidSyncToken
+	}
+	workspace, position, ok := strings.Cut(strings.TrimPrefix(string(raw), syncTokenPrefix), ":")
+	if !ok || workspace != strconv.FormatUint(uint64(workspaceId), 10) {
+		return 0, model.ErrInvalidSyncToken
+	}
+	seq, err := strconv.ParseInt(position, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, model.ErrInvalidSyncToken
+	}
+	return seq, nil
+}
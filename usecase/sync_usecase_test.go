@@ -0,0 +1,408 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"go-rest-api/notifier"
+	"testing"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockSyncRepository struct {
+	mock.Mock
+}
+
+func newMockSyncRepository() *MockSyncRepository {
+	return &MockSyncRepository{}
+}
+
+func (mr *MockSyncRepository) GetCounter(counter *model.SyncCounter, userId uint) error {
+	args := mr.Called(counter, userId)
+	return args.Error(0)
+}
+
+func (mr *MockSyncRepository) GetChangedTasks(tasks *[]model.Task, userId uint, workspaceId uint, since int64, until int64) error {
+	args := mr.Called(tasks, userId, workspaceId, since, until)
+	return args.Error(0)
+}
+
+func (mr *MockSyncRepository) GetTombstones(tombstones *[]model.TaskTombstone, userId uint, workspaceId uint, since int64, until int64) error {
+	args := mr.Called(tombstones, userId, workspaceId, since, until)
+	return args.Error(0)
+}
+
+func (mr *MockSyncRepository) PurgeTombstones(userId uint, before time.Time) error {
+	args := mr.Called(userId, before)
+	return args.Error(0)
+}
+
+type MockTaskUsecase struct {
+	mock.Mock
+}
+
+func newMockTaskUsecase() *MockTaskUsecase {
+	return &MockTaskUsecase{}
+}
+
+func (mu *MockTaskUsecase) GetAllTasks(userId uint, workspaceId uint, query model.TaskQuery) ([]model.TaskResponse, error) {
+	args := mu.Called(userId, workspaceId, query)
+	return args.Get(0).([]model.TaskResponse), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) GetTaskByID(userId uint, workspaceId uint, taskId uint) (model.TaskResponse, error) {
+	args := mu.Called(userId, workspaceId, taskId)
+	return args.Get(0).(model.TaskResponse), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) CreateTask(task model.Task) (model.TaskResponse, error) {
+	args := mu.Called(task)
+	return args.Get(0).(model.TaskResponse), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) UpdateTask(userId uint, workspaceId uint, taskId uint, version uint, task model.Task) (model.TaskResponse, error) {
+	args := mu.Called(userId, workspaceId, taskId, version, task)
+	return args.Get(0).(model.TaskResponse), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) PatchTask(userId uint, workspaceId uint, taskId uint, version uint, patch model.TaskPatch) (model.TaskResponse, error) {
+	args := mu.Called(userId, workspaceId, taskId, version, patch)
+	return args.Get(0).(model.TaskResponse), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) DeleteTask(userId uint, workspaceId uint, taskId uint, version uint) error {
+	args := mu.Called(userId, workspaceId, taskId, version)
+	return args.Error(0)
+}
+
+func (mu *MockTaskUsecase) AddComment(userId uint, workspaceId uint, taskId uint, comment model.Comment) (model.CommentResponse, error) {
+	args := mu.Called(userId, workspaceId, taskId, comment)
+	return args.Get(0).(model.CommentResponse), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) GetComments(userId uint, workspaceId uint, taskId uint) ([]model.CommentResponse, error) {
+	args := mu.Called(userId, workspaceId, taskId)
+	return args.Get(0).([]model.CommentResponse), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) AddAttachment(userId uint, workspaceId uint, taskId uint, attachment model.Attachment) (model.AttachmentResponse, error) {
+	args := mu.Called(userId, workspaceId, taskId, attachment)
+	return args.Get(0).(model.AttachmentResponse), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) GetAttachments(userId uint, workspaceId uint, taskId uint) ([]model.AttachmentResponse, error) {
+	args := mu.Called(userId, workspaceId, taskId)
+	return args.Get(0).([]model.AttachmentResponse), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) GetAttachment(userId uint, workspaceId uint, taskId uint, attachmentId uint) (model.Attachment, error) {
+	args := mu.Called(userId, workspaceId, taskId, attachmentId)
+	return args.Get(0).(model.Attachment), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) DeleteAttachment(userId uint, workspaceId uint, taskId uint, attachmentId uint) error {
+	args := mu.Called(userId, workspaceId, taskId, attachmentId)
+	return args.Error(0)
+}
+
+func (mu *MockTaskUsecase) GetUsage(userId uint) (model.UsageResponse, error) {
+	args := mu.Called(userId)
+	return args.Get(0).(model.UsageResponse), args.Error(1)
+}
+
+func (mu *MockTaskUsecase) UseRequest(userId uint) error {
+	args := mu.Called(userId)
+	return args.Error(0)
+}
+
+func mockSyncCounter(mr *MockSyncRepository, seq int64, purgedSeq int64) {
+	mr.On("PurgeTombstones", mock.Anything, mock.Anything).Return(nil)
+	mr.On("GetCounter", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.SyncCounter) = model.SyncCounter{Seq: seq, PurgedSeq: purgedSeq}
+		}).
+		Return(nil)
+}
+
+func TestSync_Full_Success(t *testing.T) {
+	mr := newMockSyncRepository()
+	mockSyncCounter(mr, 5, 0)
+	mr.On("GetChangedTasks", mock.Anything, uint(1), uint(7), int64(0), int64(5)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]model.Task) = []model.Task{{ID: 1, Title: "task"}}
+		}).
+		Return(nil)
+
+	su := NewSyncUsecase(mr, newMockTaskUsecase(), time.Hour)
+
+	res, err := su.Sync(1, 7, "")
+	assert.NoError(t, err)
+	assert.True(t, res.Full)
+	assert.Len(t, res.Tasks, 1)
+	assert.Equal(t, encodeSyncToken(7, 5), res.Token)
+	mr.AssertNotCalled(t, "GetTombstones", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSync_Delta_Success(t *testing.T) {
+	mr := newMockSyncRepository()
+	mockSyncCounter(mr, 7, 2)
+	mr.On("GetChangedTasks", mock.Anything, uint(1), uint(7), int64(5), int64(7)).Return(nil)
+	mr.On("GetTombstones", mock.Anything, uint(1), uint(7), int64(5), int64(7)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]model.TaskTombstone) = []model.TaskTombstone{{TaskId: 3, ChangeSeq: 6}}
+		}).
+		Return(nil)
+
+	su := NewSyncUsecase(mr, newMockTaskUsecase(), time.Hour)
+
+	res, err := su.Sync(1, 7, encodeSyncToken(7, 5))
+	assert.NoError(t, err)
+	assert.False(t, res.Full)
+	assert.Equal(t, uint(3), res.Deleted[0].ID)
+	assert.Equal(t, encodeSyncToken(7, 7), res.Token)
+}
+
+func TestSync_ExpiredToken_Failure(t *testing.T) {
+	mr := newMockSyncRepository()
+	mockSyncCounter(mr, 9, 6)
+
+	su := NewSyncUsecase(mr, newMockTaskUsecase(), time.Hour)
+
+	_, err := su.Sync(1, 7, encodeSyncToken(7, 5))
+	assert.ErrorIs(t, err, model.ErrSyncTokenExpired)
+}
+
+func TestSync_InvalidToken_Failure(t *testing.T) {
+	mr := newMockSyncRepository()
+
+	su := NewSyncUsecase(mr, newMockTaskUsecase(), time.Hour)
+
+	_, err := su.Sync(1, 7, "not-a-token")
+	assert.ErrorIs(t, err, model.ErrInvalidSyncToken)
+	mr.AssertNotCalled(t, "GetCounter", mock.Anything, mock.Anything)
+}
+
+func TestSync_OtherWorkspaceToken_Failure(t *testing.T) {
+	mr := newMockSyncRepository()
+
+	su := NewSyncUsecase(mr, newMockTaskUsecase(), time.Hour)
+
+	_, err := su.Sync(1, 7, encodeSyncToken(8, 5))
+	assert.ErrorIs(t, err, model.ErrInvalidSyncToken)
+	mr.AssertNotCalled(t, "GetCounter", mock.Anything, mock.Anything)
+}
+
+func TestSync_TokenAhead_Failure(t *testing.T) {
+	mr := newMockSyncRepository()
+	mockSyncCounter(mr, 3, 0)
+
+	su := NewSyncUsecase(mr, newMockTaskUsecase(), time.Hour)
+
+	_, err := su.Sync(1, 7, encodeSyncToken(7, 5))
+	assert.ErrorIs(t, err, model.ErrInvalidSyncToken)
+}
+
+func TestSync_Repository_Failure(t *testing.T) {
+	mr := newMockSyncRepository()
+	mr.On("PurgeTombstones", mock.Anything, mock.Anything).Return(errors.New("error"))
+
+	su := NewSyncUsecase(mr, newMockTaskUsecase(), time.Hour)
+
+	_, err := su.Sync(1, 7, "")
+	assert.Error(t, err)
+}
+
+func TestPush_Merged_Success(t *testing.T) {
+	mr := newMockSyncRepository()
+	mu := newMockTaskUsecase()
+	current := model.TaskResponse{ID: 1, Title: "server title", Status: model.TaskStatusTodo, Version: 3}
+	mu.On("GetTaskByID", uint(1), uint(7), uint(1)).Return(current, nil)
+	mu.On("PatchTask", uint(1), uint(7), uint(1), uint(3), mock.MatchedBy(func(patch model.TaskPatch) bool {
+		return patch.Status.Set && !patch.Title.Set
+	})).Return(model.TaskResponse{ID: 1, Title: "server title", Status: model.TaskStatusDone, Version: 4}, nil)
+
+	su := NewSyncUsecase(mr, mu, time.Hour)
+
+	results := su.Push(1, 7, []model.SyncPushItem{{
+		TaskId:      1,
+		BaseVersion: 2,
+		Base:        model.TaskPatch{Status: model.PatchField[string]{Set: true, Value: model.TaskStatusTodo}},
+		Changes:     model.TaskPatch{Status: model.PatchField[string]{Set: true, Value: model.TaskStatusDone}},
+	}})
+	assert.Equal(t, model.SyncPushMerged, results[0].Status)
+	assert.Equal(t, uint(4), results[0].Task.Version)
+}
+
+func TestPush_DescriptionOnly_Success(t *testing.T) {
+	mr := newMockSyncRepository()
+	mu := newMockTaskUsecase()
+	current := model.TaskResponse{ID: 1, Title: "server title", Version: 3}
+	mu.On("GetTaskByID", uint(1), uint(7), uint(1)).Return(current, nil)
+	mu.On("PatchTask", uint(1), uint(7), uint(1), uint(3), mock.MatchedBy(func(patch model.TaskPatch) bool {
+		return patch.Description.Set && patch.Description.Value == "offline notes"
+	})).Return(model.TaskResponse{ID: 1, Title: "server title", Description: "offline notes", Version: 4}, nil)
+
+	su := NewSyncUsecase(mr, mu, time.Hour)
+
+	results := su.Push(1, 7, []model.SyncPushItem{{
+		TaskId:      1,
+		BaseVersion: 3,
+		Changes:     model.TaskPatch{Description: model.PatchField[string]{Set: true, Value: "offline notes"}},
+	}})
+	assert.Equal(t, model.SyncPushApplied, results[0].Status)
+	assert.Equal(t, "offline notes", results[0].Task.Description)
+}
+
+func TestPush_Conflict(t *testing.T) {
+	mr := newMockSyncRepository()
+	mu := newMockTaskUsecase()
+	current := model.TaskResponse{ID: 1, Title: "server title", Version: 3}
+	mu.On("GetTaskByID", uint(1), uint(7), uint(1)).Return(current, nil)
+
+	su := NewSyncUsecase(mr, mu, time.Hour)
+
+	results := su.Push(1, 7, []model.SyncPushItem{{
+		TaskId:      1,
+		BaseVersion: 2,
+		Base:        model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "base title"}},
+		Changes:     model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "client title"}},
+	}})
+	assert.Equal(t, model.SyncPushConflict, results[0].Status)
+	assert.Equal(t, model.SyncConflict{Field: "title", Base: "base title", Yours: "client title", Theirs: "server title"}, results[0].Conflicts[0])
+	mu.AssertNotCalled(t, "PatchTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPush_StaleRetry_Success(t *testing.T) {
+	mr := newMockSyncRepository()
+	mu := newMockTaskUsecase()
+	mu.On("GetTaskByID", uint(1), uint(7), uint(1)).Return(model.TaskResponse{ID: 1, Title: "base", Version: 2}, nil).Once()
+	mu.On("GetTaskByID", uint(1), uint(7), uint(1)).Return(model.TaskResponse{ID: 1, Title: "base", Version: 3}, nil).Once()
+	mu.On("PatchTask", uint(1), uint(7), uint(1), uint(2), mock.Anything).Return(model.TaskResponse{}, model.ErrStaleVersion)
+	mu.On("PatchTask", uint(1), uint(7), uint(1), uint(3), mock.Anything).Return(model.TaskResponse{ID: 1, Title: "client", Version: 4}, nil)
+
+	su := NewSyncUsecase(mr, mu, time.Hour)
+
+	results := su.Push(1, 7, []model.SyncPushItem{{
+		TaskId:      1,
+		BaseVersion: 2,
+		Base:        model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "base"}},
+		Changes:     model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "client"}},
+	}})
+	assert.Equal(t, model.SyncPushMerged, results[0].Status)
+	mu.AssertNumberOfCalls(t, "PatchTask", 2)
+}
+
+func TestPush_Create_Success(t *testing.T) {
+	mr := newMockSyncRepository()
+	mu := newMockTaskUsecase()
+	mu.On("CreateTask", mock.MatchedBy(func(task model.Task) bool {
+		return task.Title == "offline" && task.UserId == 1 && task.WorkspaceId == 7
+	})).Return(model.TaskResponse{ID: 9, Title: "offline", Version: 1}, nil)
+
+	su := NewSyncUsecase(mr, mu, time.Hour)
+
+	results := su.Push(1, 7, []model.SyncPushItem{{
+		ClientId: "local-1",
+		Changes:  model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "offline"}},
+	}})
+	assert.Equal(t, model.SyncPushCreated, results[0].Status)
+	assert.Equal(t, uint(9), results[0].TaskId)
+	assert.Equal(t, "local-1", results[0].ClientId)
+}
+
+func TestPush_CreateAllFields_Success(t *testing.T) {
+	mr := newMockSyncRepository()
+	mu := newMockTaskUsecase()
+	project := uint(3)
+	assignee := uint(2)
+	mu.On("CreateTask", mock.MatchedBy(func(task model.Task) bool {
+		return task.Description == "notes" && task.Recurrence == "FREQ=DAILY" &&
+			*task.ProjectId == project && *task.AssigneeId == assignee &&
+			task.CustomFields["1"] == 5.0 && task.DueDate == nil
+	})).Return(model.TaskResponse{ID: 9, Title: "offline", Version: 1}, nil)
+
+	su := NewSyncUsecase(mr, mu, time.Hour)
+
+	results := su.Push(1, 7, []model.SyncPushItem{{
+		ClientId: "local-1",
+		Changes: model.TaskPatch{
+			Title:        model.PatchField[string]{Set: true, Value: "offline"},
+			Description:  model.PatchField[string]{Set: true, Value: "notes"},
+			DueDate:      model.PatchField[time.Time]{Set: true, Null: true},
+			Recurrence:   model.PatchField[string]{Set: true, Value: "FREQ=DAILY"},
+			AssigneeId:   model.PatchField[uint]{Set: true, Value: assignee},
+			ProjectId:    model.PatchField[uint]{Set: true, Value: project},
+			CustomFields: model.PatchField[model.CustomFieldValues]{Set: true, Value: model.CustomFieldValues{"1": 5.0}},
+		},
+	}})
+	assert.Equal(t, model.SyncPushCreated, results[0].Status)
+}
+
+func TestPush_CreateInvalidCustomField_Rejected(t *testing.T) {
+	mr := newMockSyncRepository()
+	tr := newMockTaskRepository()
+	tv := newMockTaskValidator()
+	mf := newMockCustomFieldRepository()
+	mfv := newMockCustomFieldValidator()
+	fields := []model.CustomField{{ID: 1, Name: "Estimate", Type: model.CustomFieldNumber, ProjectId: 3}}
+	mockCustomFields(mf, fields...)
+	tv.On("TaskValidate", mock.Anything).Return(nil)
+	mfv.On("CustomFieldValuesValidate", fields, model.CustomFieldValues{"1": "ten"}).Return(validation.Errors{"1": errors.New("must be a number")})
+	tu := NewTaskUseCase(tr, tv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), mf, mfv)
+
+	su := NewSyncUsecase(mr, tu, time.Hour)
+
+	results := su.Push(1, 7, []model.SyncPushItem{{
+		ClientId: "local-1",
+		Changes: model.TaskPatch{
+			Title:        model.PatchField[string]{Set: true, Value: "offline"},
+			ProjectId:    model.PatchField[uint]{Set: true, Value: 3},
+			CustomFields: model.PatchField[model.CustomFieldValues]{Set: true, Value: model.CustomFieldValues{"1": "ten"}},
+		},
+	}})
+	assert.Equal(t, model.SyncPushRejected, results[0].Status)
+	tr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPush_DeletedOnServer_Conflict(t *testing.T) {
+	mr := newMockSyncRepository()
+	mu := newMockTaskUsecase()
+	mu.On("GetTaskByID", uint(1), uint(7), uint(1)).Return(model.TaskResponse{}, gorm.ErrRecordNotFound)
+
+	su := NewSyncUsecase(mr, mu, time.Hour)
+
+	results := su.Push(1, 7, []model.SyncPushItem{{
+		TaskId:      1,
+		BaseVersion: 2,
+		Changes:     model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "client"}},
+	}})
+	assert.Equal(t, model.SyncPushConflict, results[0].Status)
+	assert.Equal(t, "deleted", results[0].Conflicts[0].Field)
+}
+
+func TestPush_Delete_Success(t *testing.T) {
+	mr := newMockSyncRepository()
+	mu := newMockTaskUsecase()
+	mu.On("GetTaskByID", uint(1), uint(7), uint(1)).Return(model.TaskResponse{ID: 1, Version: 2}, nil)
+	mu.On("DeleteTask", uint(1), uint(7), uint(1), uint(2)).Return(nil)
+
+	su := NewSyncUsecase(mr, mu, time.Hour)
+
+	results := su.Push(1, 7, []model.SyncPushItem{{TaskId: 1, BaseVersion: 2, Deleted: true}})
+	assert.Equal(t, model.SyncPushDeleted, results[0].Status)
+}
+
+func TestPush_MissingBaseVersion_Rejected(t *testing.T) {
+	mr := newMockSyncRepository()
+	mu := newMockTaskUsecase()
+
+	su := NewSyncUsecase(mr, mu, time.Hour)
+
+	results := su.Push(1, 7, []model.SyncPushItem{{TaskId: 1}})
+	assert.Equal(t, model.SyncPushRejected, results[0].Status)
+	mu.AssertNotCalled(t, "GetTaskByID", mock.Anything, mock.Anything, mock.Anything)
+}
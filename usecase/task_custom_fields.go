@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// validateCustomFields checks values against the fields of the project. Tasks
+// outside a project have no fields, so they can have no values.
+func (tu *taskUsecase) validateCustomFields(projectId *uint, values model.CustomFieldValues) error {
+	if projectId == nil && len(values) == 0 {
+		return nil
+	}
+	var fields []model.CustomField
+	if projectId != nil {
+		if err := tu.fr.GetByProject(&fields, *projectId); err != nil {
+			return err
+		}
+	}
+	return tu.fv.CustomFieldValuesValidate(fields, values)
+}
+
+// patchCustomFields validates the custom field values the task ends up with
+// when patch changes them or moves the task, and makes patch write them.
+// Values are dropped when the task moves to another project, since they
+// belong to the fields of the old one.
+func (tu *taskUsecase) patchCustomFields(userId uint, workspaceId uint, taskId uint, patch *model.TaskPatch) error {
+	if !patch.CustomFields.Set && !patch.ProjectId.Set {
+		return nil
+	}
+	values := patch.CustomFields.Value
+	var projectId *uint
+	if !patch.CustomFields.Set || !patch.ProjectId.Set {
+		current := model.Task{}
+		if err := tu.tr.GetByID(&current, userId, workspaceId, taskId); err != nil {
+			return err
+		}
+		projectId = current.ProjectId
+		if !patch.CustomFields.Set {
+			values = current.CustomFields
+		}
+	}
+	if patch.ProjectId.Set {
+		var target *uint
+		if !patch.ProjectId.Null {
+			target = &patch.ProjectId.Value
+		}
+		if !patch.CustomFields.Set && comparableId(projectId) != comparableId(target) {
+			values = nil
+		}
+		projectId = target
+	}
+	if err := tu.validateCustomFields(projectId, values); err != nil {
+		return err
+	}
+	patch.CustomFields = model.PatchField[model.CustomFieldValues]{Set: true, Value: values}
+	return nil
+}
+
+// resolveCustomFieldQuery fills in the field types of the filters and sort of
+// query and validates the filter values. Only fields of projects of the
+// workspace the user is a member of can be used.
+func (tu *taskUsecase) resolveCustomFieldQuery(userId uint, workspaceId uint, query *model.TaskQuery) error {
+	var fieldIds []uint
+	for _, filter := range query.Filters {
+		fieldIds = append(fieldIds, filter.FieldId)
+	}
+	if query.Sort != nil {
+		fieldIds = append(fieldIds, query.Sort.FieldId)
+	}
+	if len(fieldIds) == 0 {
+		return nil
+	}
+	var fields []model.CustomField
+	if err := tu.fr.GetByIDs(&fields, userId, workspaceId, fieldIds); err != nil {
+		return err
+	}
+	byId := map[uint]model.CustomField{}
+	for _, field := range fields {
+		byId[field.ID] = field
+	}
+	for i, filter := range query.Filters {
+		field, ok := byId[filter.FieldId]
+		if !ok {
+			return validation.Errors{fmt.Sprintf("cf.%d", filter.FieldId): errors.New("is not a custom field")}
+		}
+		if err := tu.fv.CustomFieldFilterValidate(field, filter); err != nil {
+			return err
+		}
+		query.Filters[i].Type = field.Type
+	}
+	if query.Sort != nil {
+		field, ok := byId[query.Sort.FieldId]
+		if !ok {
+			return validation.Errors{"sort": errors.New("is not a custom field")}
+		}
+		query.Sort.Type = field.Type
+	}
+	return nil
+}
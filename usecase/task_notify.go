@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"log"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// taskWatch is a task as it was before a change, kept to tell its watchers
+// what changed.
+type taskWatch struct {
+	watchers []uint
+	before   model.Task
+}
+
+// watchTask reads the watchers of the task and, if there are any, the task
+// itself. A missing task is left for the write to report.
+func (tu *taskUsecase) watchTask(userId uint, workspaceId uint, taskId uint) (taskWatch, error) {
+	watch := taskWatch{}
+	if err := tu.wr.GetWatchers(&watch.watchers, taskId); err != nil {
+		return taskWatch{}, err
+	}
+	if len(watch.watchers) == 0 {
+		return watch, nil
+	}
+	if err := tu.tr.GetByID(&watch.before, userId, workspaceId, taskId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return taskWatch{}, nil
+		}
+		return taskWatch{}, err
+	}
+	return watch, nil
+}
+
+// notifyChanges tells the watchers about the status, due date and assignee
+// changes between watch.before and after.
+func (tu *taskUsecase) notifyChanges(watch taskWatch, actorId uint, after model.Task) {
+	if len(watch.watchers) == 0 {
+		return
+	}
+	before := watch.before
+	change := model.TaskChange{TaskId: after.ID, ActorId: actorId, At: time.Now()}
+	var changes []model.TaskChange
+	if before.Status != after.Status {
+		change.Type, change.From, change.To = model.TaskChangeStatus, before.Status, after.Status
+		changes = append(changes, change)
+	}
+	if from, to := comparableTime(before.DueDate), comparableTime(after.DueDate); !reflect.DeepEqual(from, to) {
+		change.Type, change.From, change.To = model.TaskChangeDueDate, from, to
+		changes = append(changes, change)
+	}
+	if from, to := comparableId(before.AssigneeId), comparableId(after.AssigneeId); from != to {
+		change.Type, change.From, change.To = model.TaskChangeReassignment, from, to
+		changes = append(changes, change)
+	}
+	tu.notify(watch.watchers, actorId, changes...)
+}
+
+// notify delivers changes to every watcher except the user who made them.
+// A failed delivery is logged and does not undo the change.
+func (tu *taskUsecase) notify(watchers []uint, actorId uint, changes ...model.TaskChange) {
+	for _, watcher := range watchers {
+		if watcher == actorId {
+			continue
+		}
+		for _, change := range changes {
+			if err := tu.n.Notify(watcher, change); err != nil {
+				log.Printf("notify user %d of task %d: %v", watcher, change.TaskId, err)
+			}
+		}
+	}
+}
+
+// recordMentions records the mentions in text. Like notify, a failure is
+// logged and does not undo the change.
+func (tu *taskUsecase) recordMentions(actorId uint, workspaceId uint, taskId uint, source string, sourceId uint, text string) {
+	if err := tu.mu.RecordMentions(actorId, workspaceId, taskId, source, sourceId, text); err != nil {
+		log.Printf("record mentions on task %d: %v", taskId, err)
+	}
+}
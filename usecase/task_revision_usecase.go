@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"reflect"
+	"time"
+)
+
+type ITaskRevisionUsecase interface {
+	GetRevisions(userId uint, workspaceId uint, taskId uint) ([]model.TaskRevisionResponse, error)
+	DiffRevisions(userId uint, workspaceId uint, taskId uint, from uint, to uint) (model.TaskRevisionDiff, error)
+	RevertToRevision(userId uint, workspaceId uint, taskId uint, revision uint, version uint) (model.TaskResponse, error)
+}
+
+type taskRevisionUsecase struct {
+	rr repository.ITaskRevisionRepository
+	tu ITaskUsecase
+}
+
+// NewTaskRevisionUsecase writes reverts through tu so that a restored state
+// is validated like any other edit.
+func NewTaskRevisionUsecase(rr repository.ITaskRevisionRepository, tu ITaskUsecase) ITaskRevisionUsecase {
+	return &taskRevisionUsecase{rr, tu}
+}
+
+func (ru *taskRevisionUsecase) GetRevisions(userId uint, workspaceId uint, taskId uint) ([]model.TaskRevisionResponse, error) {
+	var revisions []model.TaskRevision
+	if err := ru.rr.GetAll(&revisions, userId, workspaceId, taskId); err != nil {
+		return nil, err
+	}
+
+	revisionResponses := []model.TaskRevisionResponse{}
+	for _, revision := range revisions {
+		revisionResponses = append(revisionResponses, newTaskRevisionResponse(revision))
+	}
+	return revisionResponses, nil
+}
+
+func (ru *taskRevisionUsecase) DiffRevisions(userId uint, workspaceId uint, taskId uint, from uint, to uint) (model.TaskRevisionDiff, error) {
+	fromRevision := model.TaskRevision{}
+	if err := ru.rr.GetByVersion(&fromRevision, userId, workspaceId, taskId, from); err != nil {
+		return model.TaskRevisionDiff{}, err
+	}
+	toRevision := model.TaskRevision{}
+	if err := ru.rr.GetByVersion(&toRevision, userId, workspaceId, taskId, to); err != nil {
+		return model.TaskRevisionDiff{}, err
+	}
+	return model.TaskRevisionDiff{
+		From:    from,
+		To:      to,
+		Changes: diffRevisions(fromRevision, toRevision),
+	}, nil
+}
+
+// RevertToRevision restores every editable field to its value at revision.
+// The revert is a new edit, so it gets a new version and revision of its own.
+func (ru *taskRevisionUsecase) RevertToRevision(userId uint, workspaceId uint, taskId uint, revision uint, version uint) (model.TaskResponse, error) {
+	target := model.TaskRevision{}
+	if err := ru.rr.GetByVersion(&target, userId, workspaceId, taskId, revision); err != nil {
+		return model.TaskResponse{}, err
+	}
+	labels := make([]model.Label, 0, len(target.Labels))
+	for _, name := range target.Labels {
+		labels = append(labels, model.Label{Name: name})
+	}
+	patch := model.TaskPatch{
+		Title:        model.PatchField[string]{Set: true, Value: target.Title},
+		Description:  model.PatchField[string]{Set: true, Value: target.Description},
+		Status:       model.PatchField[string]{Set: true, Value: target.Status},
+		Priority:     model.PatchField[string]{Set: true, Value: target.Priority},
+		DueDate:      model.PatchField[time.Time]{Set: true, Null: target.DueDate == nil},
+		Labels:       model.PatchField[[]model.Label]{Set: true, Value: labels},
+		Recurrence:   model.PatchField[string]{Set: true, Value: target.Recurrence},
+		AssigneeId:   model.PatchField[uint]{Set: true, Null: target.AssigneeId == nil},
+		ProjectId:    model.PatchField[uint]{Set: true, Null: target.ProjectId == nil},
+		CustomFields: model.PatchField[model.CustomFieldValues]{Set: true, Value: target.CustomFields},
+	}
+	if target.DueDate != nil {
+		patch.DueDate.Value = *target.DueDate
+	}
+	if target.AssigneeId != nil {
+		patch.AssigneeId.Value = *target.AssigneeId
+	}
+	if target.ProjectId != nil {
+		patch.ProjectId.Value = *target.ProjectId
+	}
+	return ru.tu.PatchTask(userId, workspaceId, taskId, version, patch)
+}
+
+func diffRevisions(from model.TaskRevision, to model.TaskRevision) []model.FieldChange {
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"title", from.Title, to.Title},
+		{"description", from.Description, to.Description},
+		{"status", from.Status, to.Status},
+		{"priority", from.Priority, to.Priority},
+		{"due_date", comparableTime(from.DueDate), comparableTime(to.DueDate)},
+		{"labels", sortedNames(append([]string{}, from.Labels...)), sortedNames(append([]string{}, to.Labels...))},
+		{"recurrence", from.Recurrence, to.Recurrence},
+		{"assignee_id", comparableId(from.AssigneeId), comparableId(to.AssigneeId)},
+		{"project_id", comparableId(from.ProjectId), comparableId(to.ProjectId)},
+		{"custom_fields", comparableValues(from.CustomFields), comparableValues(to.CustomFields)},
+	}
+	changes := []model.FieldChange{}
+	for _, field := range fields {
+		if !reflect.DeepEqual(field.from, field.to) {
+			changes = append(changes, model.FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+	return changes
+}
+
+func newTaskRevisionResponse(revision model.TaskRevision) model.TaskRevisionResponse {
+	return model.TaskRevisionResponse{
+		Version:      revision.Version,
+		Title:        revision.Title,
+		Description:  revision.Description,
+		Status:       revision.Status,
+		Priority:     revision.Priority,
+		DueDate:      revision.DueDate,
+		Labels:       revision.Labels,
+		Recurrence:   revision.Recurrence,
+		AssigneeId:   revision.AssigneeId,
+		ProjectId:    revision.ProjectId,
+		CustomFields: revision.CustomFields,
+		CreatedAt:    revision.CreatedAt,
+	}
+}
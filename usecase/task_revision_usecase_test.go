@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaskRevisionRepository struct {
+	mock.Mock
+}
+
+func newMockTaskRevisionRepository() *MockTaskRevisionRepository {
+	return &MockTaskRevisionRepository{}
+}
+
+func (mr *MockTaskRevisionRepository) GetAll(revisions *[]model.TaskRevision, userId uint, workspaceId uint, taskId uint) error {
+	args := mr.Called(revisions, userId, workspaceId, taskId)
+	return args.Error(0)
+}
+
+func (mr *MockTaskRevisionRepository) GetByVersion(revision *model.TaskRevision, userId uint, workspaceId uint, taskId uint, version uint) error {
+	args := mr.Called(revision, userId, workspaceId, taskId, version)
+	return args.Error(0)
+}
+
+func mockRevision(mr *MockTaskRevisionRepository, revision model.TaskRevision) {
+	mr.On("GetByVersion", mock.Anything, uint(1), uint(7), uint(1), revision.Version).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.TaskRevision) = revision
+		}).
+		Return(nil)
+}
+
+func TestGetRevisions_Success(t *testing.T) {
+	mr := newMockTaskRevisionRepository()
+	mr.On("GetAll", mock.Anything, uint(1), uint(7), uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]model.TaskRevision) = []model.TaskRevision{{Version: 1, Title: "a"}, {Version: 2, Title: "b"}}
+		}).
+		Return(nil)
+
+	ru := NewTaskRevisionUsecase(mr, newMockTaskUsecase())
+
+	res, err := ru.GetRevisions(1, 7, 1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+}
+
+func TestDiffRevisions_Success(t *testing.T) {
+	mr := newMockTaskRevisionRepository()
+	due := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	mockRevision(mr, model.TaskRevision{Version: 1, Title: "a", Status: model.TaskStatusTodo, Priority: model.TaskPriorityHigh, Labels: []string{"work", "home"}})
+	mockRevision(mr, model.TaskRevision{Version: 3, Title: "b", Status: model.TaskStatusTodo, Priority: model.TaskPriorityHigh, DueDate: &due, Labels: []string{"home", "work"}})
+
+	ru := NewTaskRevisionUsecase(mr, newMockTaskUsecase())
+
+	diff, err := ru.DiffRevisions(1, 7, 1, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.FieldChange{
+		{Field: "title", From: "a", To: "b"},
+		{Field: "due_date", From: (*time.Time)(nil), To: &due},
+	}, diff.Changes)
+}
+
+func TestDiffRevisions_Repository_Failure(t *testing.T) {
+	mr := newMockTaskRevisionRepository()
+	mr.On("GetByVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error"))
+
+	ru := NewTaskRevisionUsecase(mr, newMockTaskUsecase())
+
+	_, err := ru.DiffRevisions(1, 7, 1, 1, 3)
+	assert.Error(t, err)
+}
+
+func TestRevertToRevision_Success(t *testing.T) {
+	mr := newMockTaskRevisionRepository()
+	mu := newMockTaskUsecase()
+	mockRevision(mr, model.TaskRevision{Version: 2, Title: "old", Status: model.TaskStatusTodo, Priority: model.TaskPriorityLow, Labels: []string{"work"}})
+	mu.On("PatchTask", uint(1), uint(7), uint(1), uint(4), mock.MatchedBy(func(patch model.TaskPatch) bool {
+		return patch.Title.Value == "old" &&
+			patch.Priority.Value == model.TaskPriorityLow &&
+			patch.DueDate.Set && patch.DueDate.Null &&
+			len(patch.Labels.Value) == 1 && patch.Labels.Value[0].Name == "work"
+	})).Return(model.TaskResponse{ID: 1, Title: "old", Version: 5}, nil)
+
+	ru := NewTaskRevisionUsecase(mr, mu)
+
+	res, err := ru.RevertToRevision(1, 7, 1, 2, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), res.Version)
+}
+
+func TestRevertToRevision_Validation_Failure(t *testing.T) {
+	mr := newMockTaskRevisionRepository()
+	mu := newMockTaskUsecase()
+	mockRevision(mr, model.TaskRevision{Version: 2, Title: "old"})
+	mu.On("PatchTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(model.TaskResponse{}, errors.New("error"))
+
+	ru := NewTaskRevisionUsecase(mr, mu)
+
+	_, err := ru.RevertToRevision(1, 7, 1, 2, 0)
+	assert.Error(t, err)
+}
@@ -2,88 +2,408 @@ package usecase
 
 import (
 	"go-rest-api/model"
+	"go-rest-api/notifier"
 	"go-rest-api/repository"
 	"go-rest-api/validator"
+	"time"
 )
 
 type ITaskUsecase interface {
-	GetAllTasks(userId uint) ([]model.TaskResponse, error)
-	GetTaskByID(userId uint, taskId uint) (model.TaskResponse, error)
+	GetAllTasks(userId uint, workspaceId uint, query model.TaskQuery) ([]model.TaskResponse, error)
+	GetTaskByID(userId uint, workspaceId uint, taskId uint) (model.TaskResponse, error)
 	CreateTask(task model.Task) (model.TaskResponse, error)
-	UpdateTask(userId uint, taskId uint, task model.Task) (model.TaskResponse, error)
-	DeleteTask(userId uint, taskId uint) error
+	UpdateTask(userId uint, workspaceId uint, taskId uint, version uint, task model.Task) (model.TaskResponse, error)
+	PatchTask(userId uint, workspaceId uint, taskId uint, version uint, patch model.TaskPatch) (model.TaskResponse, error)
+	DeleteTask(userId uint, workspaceId uint, taskId uint, version uint) error
+	GetUsage(userId uint) (model.UsageResponse, error)
+	UseRequest(userId uint) error
+	AddComment(userId uint, workspaceId uint, taskId uint, comment model.Comment) (model.CommentResponse, error)
+	GetComments(userId uint, workspaceId uint, taskId uint) ([]model.CommentResponse, error)
+	AddAttachment(userId uint, workspaceId uint, taskId uint, attachment model.Attachment) (model.AttachmentResponse, error)
+	GetAttachments(userId uint, workspaceId uint, taskId uint) ([]model.AttachmentResponse, error)
+	GetAttachment(userId uint, workspaceId uint, taskId uint, attachmentId uint) (model.Attachment, error)
+	DeleteAttachment(userId uint, workspaceId uint, taskId uint, attachmentId uint) error
 }
 
 type taskUsecase struct {
-	tr repository.ITaskRepository
-	tv validator.ITaskValidator
+	tr     repository.ITaskRepository
+	tv     validator.ITaskValidator
+	qr     repository.IQuotaRepository
+	limits model.QuotaLimits
+	wr     repository.IWatcherRepository
+	cr     repository.ICommentRepository
+	ar     repository.IAttachmentRepository
+	n      notifier.Notifier
+	mu     IMentionUsecase
+	fr     repository.ICustomFieldRepository
+	fv     validator.ICustomFieldValidator
 }
 
-func NewTaskUseCase(tr repository.ITaskRepository, tv validator.ITaskValidator) ITaskUsecase {
-	return &taskUsecase{tr, tv}
+// NewTaskUseCase applies limits to every user without a model.UserQuota of
+// their own. Every call except GetUsage counts as one request against the
+// daily limit, including calls made on behalf of sync pushes and quick add.
+// Watchers of a task hear about its changes through n, and users mentioned in
+// descriptions and comments are recorded through mu. Custom field values are
+// checked against the fields of the task's project with fv.
+func NewTaskUseCase(tr repository.ITaskRepository, tv validator.ITaskValidator, qr repository.IQuotaRepository, limits model.QuotaLimits, wr repository.IWatcherRepository, cr repository.ICommentRepository, ar repository.IAttachmentRepository, n notifier.Notifier, mu IMentionUsecase, fr repository.ICustomFieldRepository, fv validator.ICustomFieldValidator) ITaskUsecase {
+	return &taskUsecase{tr, tv, qr, limits, wr, cr, ar, n, mu, fr, fv}
 }
 
-func (tu *taskUsecase) GetAllTasks(userId uint) ([]model.TaskResponse, error) {
+func (tu *taskUsecase) GetAllTasks(userId uint, workspaceId uint, query model.TaskQuery) ([]model.TaskResponse, error) {
+	if _, err := tu.useRequest(userId); err != nil {
+		return nil, err
+	}
+	if err := tu.resolveCustomFieldQuery(userId, workspaceId, &query); err != nil {
+		return nil, err
+	}
 	var tasks []model.Task
-	if err := tu.tr.GetAll(&tasks, userId); err != nil {
+	if err := tu.tr.GetAll(&tasks, userId, workspaceId, query); err != nil {
 		return nil, err
 	}
 
 	var taskResponses []model.TaskResponse
 	for _, task := range tasks {
-		taskResponses = append(taskResponses, model.TaskResponse{
-			ID:        task.ID,
-			Title:     task.Title,
-			CreatedAt: task.CreatedAt,
-			UpdatedAt: task.UpdatedAt,
-		})
+		taskResponses = append(taskResponses, newTaskResponse(task))
 	}
 	return taskResponses, nil
 }
 
-func (tu *taskUsecase) GetTaskByID(userId uint, taskId uint) (model.TaskResponse, error) {
+func (tu *taskUsecase) GetTaskByID(userId uint, workspaceId uint, taskId uint) (model.TaskResponse, error) {
+	if _, err := tu.useRequest(userId); err != nil {
+		return model.TaskResponse{}, err
+	}
 	task := model.Task{}
-	if err := tu.tr.GetByID(&task, userId, taskId); err != nil {
+	if err := tu.tr.GetAccessible(&task, userId, workspaceId, taskId); err != nil {
 		return model.TaskResponse{}, err
 	}
-	return model.TaskResponse{
-		ID:        task.ID,
-		Title:     task.Title,
-		CreatedAt: task.CreatedAt,
-		UpdatedAt: task.UpdatedAt,
-	}, nil
+	return newTaskResponse(task), nil
 }
 
 func (tu *taskUsecase) CreateTask(task model.Task) (model.TaskResponse, error) {
+	limits, err := tu.useRequest(task.UserId)
+	if err != nil {
+		return model.TaskResponse{}, err
+	}
 	if err := tu.tv.TaskValidate(task); err != nil {
 		return model.TaskResponse{}, err
 	}
-	if err := tu.tr.Create(&task); err != nil {
+	if err := tu.validateCustomFields(task.ProjectId, task.CustomFields); err != nil {
 		return model.TaskResponse{}, err
 	}
-	return model.TaskResponse{
-		ID:        task.ID,
-		Title:     task.Title,
-		CreatedAt: task.CreatedAt,
-		UpdatedAt: task.UpdatedAt,
-	}, nil
+	if task.Status == "" {
+		task.Status = model.TaskStatusTodo
+	}
+	if task.Priority == "" {
+		task.Priority = model.TaskPriorityMedium
+	}
+	if task.Status == model.TaskStatusDone {
+		now := time.Now()
+		task.CompletedAt = &now
+	}
+	if err := tu.tr.Create(&task, limits.MaxTasks); err != nil {
+		return model.TaskResponse{}, err
+	}
+	tu.recordMentions(task.UserId, task.WorkspaceId, task.ID, model.MentionSourceDescription, 0, task.Description)
+	return newTaskResponse(task), nil
 }
 
-func (tu *taskUsecase) UpdateTask(userId uint, taskId uint, task model.Task) (model.TaskResponse, error) {
+func (tu *taskUsecase) UpdateTask(userId uint, workspaceId uint, taskId uint, version uint, task model.Task) (model.TaskResponse, error) {
+	if _, err := tu.useRequest(userId); err != nil {
+		return model.TaskResponse{}, err
+	}
 	if err := tu.tv.TaskValidate(task); err != nil {
 		return model.TaskResponse{}, err
 	}
-	if err := tu.tr.Update(&task, userId, taskId); err != nil {
+	if err := tu.validateCustomFields(task.ProjectId, task.CustomFields); err != nil {
 		return model.TaskResponse{}, err
 	}
-	return model.TaskResponse{
-		ID:        task.ID,
-		Title:     task.Title,
-		CreatedAt: task.CreatedAt,
-		UpdatedAt: task.UpdatedAt,
+	watch, err := tu.watchTask(userId, workspaceId, taskId)
+	if err != nil {
+		return model.TaskResponse{}, err
+	}
+	if err := tu.tr.Update(&task, userId, workspaceId, taskId, version); err != nil {
+		return model.TaskResponse{}, err
+	}
+	tu.notifyChanges(watch, userId, task)
+	tu.recordMentions(userId, workspaceId, taskId, model.MentionSourceDescription, 0, task.Description)
+	return newTaskResponse(task), nil
+}
+
+func (tu *taskUsecase) PatchTask(userId uint, workspaceId uint, taskId uint, version uint, patch model.TaskPatch) (model.TaskResponse, error) {
+	if _, err := tu.useRequest(userId); err != nil {
+		return model.TaskResponse{}, err
+	}
+	if err := tu.tv.TaskPatchValidate(patch); err != nil {
+		return model.TaskResponse{}, err
+	}
+	if err := tu.patchCustomFields(userId, workspaceId, taskId, &patch); err != nil {
+		return model.TaskResponse{}, err
+	}
+	watch, err := tu.watchTask(userId, workspaceId, taskId)
+	if err != nil {
+		return model.TaskResponse{}, err
+	}
+	task := model.Task{}
+	if err := tu.tr.Patch(&task, userId, workspaceId, taskId, version, patch); err != nil {
+		return model.TaskResponse{}, err
+	}
+	tu.notifyChanges(watch, userId, task)
+	if patch.Description.Set {
+		tu.recordMentions(userId, workspaceId, taskId, model.MentionSourceDescription, 0, task.Description)
+	}
+	return newTaskResponse(task), nil
+}
+
+func (tu *taskUsecase) DeleteTask(userId uint, workspaceId uint, taskId uint, version uint) error {
+	if _, err := tu.useRequest(userId); err != nil {
+		return err
+	}
+	return tu.tr.Delete(userId, workspaceId, taskId, version)
+}
+
+// AddComment lets anyone who can read the task comment on it.
+func (tu *taskUsecase) AddComment(userId uint, workspaceId uint, taskId uint, comment model.Comment) (model.CommentResponse, error) {
+	if _, err := tu.useRequest(userId); err != nil {
+		return model.CommentResponse{}, err
+	}
+	if err := tu.tv.CommentValidate(comment); err != nil {
+		return model.CommentResponse{}, err
+	}
+	task := model.Task{}
+	if err := tu.tr.GetAccessible(&task, userId, workspaceId, taskId); err != nil {
+		return model.CommentResponse{}, err
+	}
+	var watchers []uint
+	if err := tu.wr.GetWatchers(&watchers, taskId); err != nil {
+		return model.CommentResponse{}, err
+	}
+	comment.TaskId = taskId
+	comment.UserId = userId
+	if err := tu.cr.Create(&comment); err != nil {
+		return model.CommentResponse{}, err
+	}
+	commentRes := newCommentResponse(comment)
+	tu.notify(watchers, userId, model.TaskChange{Type: model.TaskChangeComment, TaskId: taskId, ActorId: userId, To: commentRes, At: comment.CreatedAt})
+	tu.recordMentions(userId, workspaceId, taskId, model.MentionSourceComment, comment.ID, comment.Body)
+	return commentRes, nil
+}
+
+func (tu *taskUsecase) GetComments(userId uint, workspaceId uint, taskId uint) ([]model.CommentResponse, error) {
+	if _, err := tu.useRequest(userId); err != nil {
+		return nil, err
+	}
+	task := model.Task{}
+	if err := tu.tr.GetAccessible(&task, userId, workspaceId, taskId); err != nil {
+		return nil, err
+	}
+	var comments []model.Comment
+	if err := tu.cr.GetAll(&comments, taskId); err != nil {
+		return nil, err
+	}
+
+	commentResponses := []model.CommentResponse{}
+	for _, comment := range comments {
+		commentResponses = append(commentResponses, newCommentResponse(comment))
+	}
+	return commentResponses, nil
+}
+
+// AddAttachment lets anyone who can read the task attach a file to it. The
+// file counts against the storage quota of the uploader.
+func (tu *taskUsecase) AddAttachment(userId uint, workspaceId uint, taskId uint, attachment model.Attachment) (model.AttachmentResponse, error) {
+	limits, err := tu.useRequest(userId)
+	if err != nil {
+		return model.AttachmentResponse{}, err
+	}
+	if err := tu.tv.AttachmentValidate(attachment); err != nil {
+		return model.AttachmentResponse{}, err
+	}
+	task := model.Task{}
+	if err := tu.tr.GetAccessible(&task, userId, workspaceId, taskId); err != nil {
+		return model.AttachmentResponse{}, err
+	}
+	attachment.TaskId = taskId
+	attachment.UserId = userId
+	if err := tu.ar.Create(&attachment, limits.MaxAttachmentBytes); err != nil {
+		return model.AttachmentResponse{}, err
+	}
+	return newAttachmentResponse(attachment), nil
+}
+
+func (tu *taskUsecase) GetAttachments(userId uint, workspaceId uint, taskId uint) ([]model.AttachmentResponse, error) {
+	if _, err := tu.useRequest(userId); err != nil {
+		return nil, err
+	}
+	task := model.Task{}
+	if err := tu.tr.GetAccessible(&task, userId, workspaceId, taskId); err != nil {
+		return nil, err
+	}
+	var attachments []model.Attachment
+	if err := tu.ar.GetAll(&attachments, taskId); err != nil {
+		return nil, err
+	}
+
+	attachmentResponses := []model.AttachmentResponse{}
+	for _, attachment := range attachments {
+		attachmentResponses = append(attachmentResponses, newAttachmentResponse(attachment))
+	}
+	return attachmentResponses, nil
+}
+
+// GetAttachment loads an attachment with its contents.
+func (tu *taskUsecase) GetAttachment(userId uint, workspaceId uint, taskId uint, attachmentId uint) (model.Attachment, error) {
+	if _, err := tu.useRequest(userId); err != nil {
+		return model.Attachment{}, err
+	}
+	task := model.Task{}
+	if err := tu.tr.GetAccessible(&task, userId, workspaceId, taskId); err != nil {
+		return model.Attachment{}, err
+	}
+	attachment := model.Attachment{}
+	if err := tu.ar.GetByID(&attachment, taskId, attachmentId); err != nil {
+		return model.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// DeleteAttachment frees the storage of an attachment the user uploaded.
+func (tu *taskUsecase) DeleteAttachment(userId uint, workspaceId uint, taskId uint, attachmentId uint) error {
+	if _, err := tu.useRequest(userId); err != nil {
+		return err
+	}
+	task := model.Task{}
+	if err := tu.tr.GetAccessible(&task, userId, workspaceId, taskId); err != nil {
+		return err
+	}
+	return tu.ar.Delete(userId, taskId, attachmentId)
+}
+
+func (tu *taskUsecase) GetUsage(userId uint) (model.UsageResponse, error) {
+	quota, limits, err := tu.userLimits(userId)
+	if err != nil {
+		return model.UsageResponse{}, err
+	}
+	var count int64
+	if err := tu.qr.CountTasks(&count, userId); err != nil {
+		return model.UsageResponse{}, err
+	}
+	var attachmentBytes int64
+	if err := tu.qr.SumAttachmentSizes(&attachmentBytes, userId); err != nil {
+		return model.UsageResponse{}, err
+	}
+	day := usageDay(time.Now())
+	usage := model.APIUsage{}
+	if err := tu.qr.GetUsage(&usage, userId, day); err != nil {
+		return model.UsageResponse{}, err
+	}
+	return model.UsageResponse{
+		Plan:            quota.Plan,
+		Tasks:           model.UsageCounter{Used: count, Limit: limits.MaxTasks},
+		RequestsToday:   model.UsageCounter{Used: usage.Requests, Limit: limits.MaxRequestsPerDay},
+		RequestsResetAt: day.AddDate(0, 0, 1),
+		AttachmentBytes: model.UsageCounter{Used: attachmentBytes, Limit: limits.MaxAttachmentBytes},
 	}, nil
 }
 
-func (tu *taskUsecase) DeleteTask(userId uint, taskId uint) error {
-	return tu.tr.Delete(userId, taskId)
+// UseRequest counts one request of the user against the daily limit, for
+// routes that read or change task data without going through the task
+// usecase.
+func (tu *taskUsecase) UseRequest(userId uint) error {
+	_, err := tu.useRequest(userId)
+	return err
+}
+
+// useRequest counts one request of the user for today and returns the limits
+// that apply to the user. Requests over the daily limit are still counted.
+func (tu *taskUsecase) useRequest(userId uint) (model.QuotaLimits, error) {
+	_, limits, err := tu.userLimits(userId)
+	if err != nil {
+		return model.QuotaLimits{}, err
+	}
+	day := usageDay(time.Now())
+	usage := model.APIUsage{}
+	if err := tu.qr.AddRequest(&usage, userId, day); err != nil {
+		return model.QuotaLimits{}, err
+	}
+	if limits.MaxRequestsPerDay > 0 && usage.Requests > limits.MaxRequestsPerDay {
+		resetAt := day.AddDate(0, 0, 1)
+		return model.QuotaLimits{}, &model.QuotaError{Err: model.ErrRateLimited, Limit: model.QuotaLimitRequestsPerDay, Used: usage.Requests, Max: limits.MaxRequestsPerDay, ResetAt: &resetAt}
+	}
+	return limits, nil
+}
+
+// userLimits applies the overrides of the user to the default limits.
+func (tu *taskUsecase) userLimits(userId uint) (model.UserQuota, model.QuotaLimits, error) {
+	quota := model.UserQuota{}
+	if err := tu.qr.GetUserQuota(&quota, userId); err != nil {
+		return model.UserQuota{}, model.QuotaLimits{}, err
+	}
+	if quota.Plan == "" {
+		quota.Plan = "default"
+	}
+	limits := tu.limits
+	if quota.MaxTasks != nil {
+		limits.MaxTasks = *quota.MaxTasks
+	}
+	if quota.MaxRequestsPerDay != nil {
+		limits.MaxRequestsPerDay = *quota.MaxRequestsPerDay
+	}
+	if quota.MaxAttachmentBytes != nil {
+		limits.MaxAttachmentBytes = *quota.MaxAttachmentBytes
+	}
+	return quota, limits, nil
+}
+
+// usageDay is the UTC day that requests made at now are counted on.
+func usageDay(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func newTaskResponse(task model.Task) model.TaskResponse {
+	labels := make([]model.LabelResponse, 0, len(task.Labels))
+	for _, label := range task.Labels {
+		labels = append(labels, model.LabelResponse{ID: label.ID, Name: label.Name})
+	}
+	return model.TaskResponse{
+		ID:           task.ID,
+		Title:        task.Title,
+		Description:  task.Description,
+		Status:       task.Status,
+		Priority:     task.Priority,
+		DueDate:      task.DueDate,
+		CompletedAt:  task.CompletedAt,
+		Labels:       labels,
+		Recurrence:   task.Recurrence,
+		ScheduledAt:  task.ScheduledAt,
+		AssigneeId:   task.AssigneeId,
+		ProjectId:    task.ProjectId,
+		WorkspaceId:  task.WorkspaceId,
+		CustomFields: task.CustomFields,
+		Version:      task.Version,
+		CreatedAt:    task.CreatedAt,
+		UpdatedAt:    task.UpdatedAt,
+	}
+}
+
+func newAttachmentResponse(attachment model.Attachment) model.AttachmentResponse {
+	return model.AttachmentResponse{
+		ID:          attachment.ID,
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		TaskId:      attachment.TaskId,
+		UserId:      attachment.UserId,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
+
+func newCommentResponse(comment model.Comment) model.CommentResponse {
+	return model.CommentResponse{
+		ID:        comment.ID,
+		Body:      comment.Body,
+		TaskId:    comment.TaskId,
+		UserId:    comment.UserId,
+		CreatedAt: comment.CreatedAt,
+	}
 }
@@ -3,10 +3,14 @@ package usecase
 import (
 	"errors"
 	"go-rest-api/model"
+	"go-rest-api/notifier"
 	"testing"
+	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockTaskRepository struct {
@@ -17,28 +21,53 @@ func newMockTaskRepository() *MockTaskRepository {
 	return &MockTaskRepository{}
 }
 
-func (mr *MockTaskRepository) Create(task *model.Task) error {
-	args := mr.Called(task)
+func (mr *MockTaskRepository) Create(task *model.Task, maxTasks int64) error {
+	args := mr.Called(task, maxTasks)
 	return args.Error(0)
 }
 
-func (mr *MockTaskRepository) GetAll(tasks *[]model.Task, userId uint) error {
-	args := mr.Called(tasks, userId)
+func (mr *MockTaskRepository) GetAll(tasks *[]model.Task, userId uint, workspaceId uint, query model.TaskQuery) error {
+	args := mr.Called(tasks, userId, workspaceId, query)
+	return args.Error(0)
+}
+
+func (mr *MockTaskRepository) GetFiltered(tasks *[]model.Task, userId uint, workspaceId uint, filter model.TaskFilter) error {
+	args := mr.Called(tasks, userId, workspaceId, filter)
+	return args.Error(0)
+}
+
+func (mr *MockTaskRepository) GetByID(task *model.Task, userId uint, workspaceId uint, taskId uint) error {
+	args := mr.Called(task, userId, workspaceId, taskId)
+	return args.Error(0)
+}
+
+func (mr *MockTaskRepository) GetAccessible(task *model.Task, userId uint, workspaceId uint, taskId uint) error {
+	args := mr.Called(task, userId, workspaceId, taskId)
 	return args.Error(0)
 }
 
-func (mr *MockTaskRepository) GetByID(task *model.Task, userId uint, taskId uint) error {
-	args := mr.Called(task, userId, taskId)
+func (mr *MockTaskRepository) Update(task *model.Task, userId uint, workspaceId uint, taskId uint, version uint) error {
+	args := mr.Called(task, userId, workspaceId, taskId, version)
 	return args.Error(0)
 }
 
-func (mr *MockTaskRepository) Update(task *model.Task, userId uint, taskId uint) error {
-	args := mr.Called(task, userId, taskId)
+func (mr *MockTaskRepository) Patch(task *model.Task, userId uint, workspaceId uint, taskId uint, version uint, patch model.TaskPatch) error {
+	args := mr.Called(task, userId, workspaceId, taskId, version, patch)
 	return args.Error(0)
 }
 
-func (mr *MockTaskRepository) Delete(userId uint, taskId uint) error {
-	args := mr.Called(userId, taskId)
+func (mr *MockTaskRepository) Delete(userId uint, workspaceId uint, taskId uint, version uint) error {
+	args := mr.Called(userId, workspaceId, taskId, version)
+	return args.Error(0)
+}
+
+func (mr *MockTaskRepository) GetAllByOwner(tasks *[]model.Task, userId uint) error {
+	args := mr.Called(tasks, userId)
+	return args.Error(0)
+}
+
+func (mr *MockTaskRepository) WakeSnoozed(tasks *[]model.Task, before time.Time, limit int) error {
+	args := mr.Called(tasks, before, limit)
 	return args.Error(0)
 }
 
@@ -55,27 +84,275 @@ func (mv *MockTaskValidator) TaskValidate(task model.Task) error {
 	return args.Error(0)
 }
 
+func (mv *MockTaskValidator) TaskPatchValidate(patch model.TaskPatch) error {
+	args := mv.Called(patch)
+	return args.Error(0)
+}
+
+type MockQuotaRepository struct {
+	mock.Mock
+}
+
+func newMockQuotaRepository() *MockQuotaRepository {
+	return &MockQuotaRepository{}
+}
+
+// newAllowingMockQuotaRepository answers for a user without overrides who
+// has made no requests yet.
+func newAllowingMockQuotaRepository() *MockQuotaRepository {
+	mq := newMockQuotaRepository()
+	mq.On("GetUserQuota", mock.Anything, mock.Anything).Return(nil)
+	mq.On("AddRequest", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return mq
+}
+
+func (mq *MockQuotaRepository) GetUserQuota(quota *model.UserQuota, userId uint) error {
+	args := mq.Called(quota, userId)
+	return args.Error(0)
+}
+
+func (mq *MockQuotaRepository) CountTasks(count *int64, userId uint) error {
+	args := mq.Called(count, userId)
+	return args.Error(0)
+}
+
+func (mq *MockQuotaRepository) SumAttachmentSizes(total *int64, userId uint) error {
+	args := mq.Called(total, userId)
+	return args.Error(0)
+}
+
+func (mq *MockQuotaRepository) AddRequest(usage *model.APIUsage, userId uint, day time.Time) error {
+	args := mq.Called(usage, userId, day)
+	return args.Error(0)
+}
+
+func (mq *MockQuotaRepository) GetUsage(usage *model.APIUsage, userId uint, day time.Time) error {
+	args := mq.Called(usage, userId, day)
+	return args.Error(0)
+}
+
+func mockRequestCount(mq *MockQuotaRepository, requests int64) {
+	mq.On("AddRequest", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			usage := args.Get(0).(*model.APIUsage)
+			*usage = model.APIUsage{Requests: requests}
+		}).
+		Return(nil)
+}
+
+func mockTaskCount(mq *MockQuotaRepository, count int64) {
+	mq.On("CountTasks", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*int64) = count
+		}).
+		Return(nil)
+}
+
+func (mv *MockTaskValidator) CommentValidate(comment model.Comment) error {
+	args := mv.Called(comment)
+	return args.Error(0)
+}
+
+func (mv *MockTaskValidator) AttachmentValidate(attachment model.Attachment) error {
+	args := mv.Called(attachment)
+	return args.Error(0)
+}
+
+type MockWatcherRepository struct {
+	mock.Mock
+}
+
+func newMockWatcherRepository() *MockWatcherRepository {
+	return &MockWatcherRepository{}
+}
+
+// newUnwatchedMockWatcherRepository answers for tasks nobody watches.
+func newUnwatchedMockWatcherRepository() *MockWatcherRepository {
+	mw := newMockWatcherRepository()
+	mw.On("GetWatchers", mock.Anything, mock.Anything).Return(nil)
+	return mw
+}
+
+func (mw *MockWatcherRepository) Watch(userId uint, taskId uint) error {
+	args := mw.Called(userId, taskId)
+	return args.Error(0)
+}
+
+func (mw *MockWatcherRepository) Unwatch(userId uint, taskId uint) error {
+	args := mw.Called(userId, taskId)
+	return args.Error(0)
+}
+
+func (mw *MockWatcherRepository) GetWatchers(userIds *[]uint, taskId uint) error {
+	args := mw.Called(userIds, taskId)
+	return args.Error(0)
+}
+
+func (mw *MockWatcherRepository) GetWatchedTasks(tasks *[]model.Task, userId uint, workspaceId uint) error {
+	args := mw.Called(tasks, userId, workspaceId)
+	return args.Error(0)
+}
+
+func mockWatchers(mw *MockWatcherRepository, userIds ...uint) {
+	mw.On("GetWatchers", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]uint) = userIds
+		}).
+		Return(nil)
+}
+
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func newMockCommentRepository() *MockCommentRepository {
+	return &MockCommentRepository{}
+}
+
+func (mc *MockCommentRepository) Create(comment *model.Comment) error {
+	args := mc.Called(comment)
+	return args.Error(0)
+}
+
+func (mc *MockCommentRepository) GetAll(comments *[]model.Comment, taskId uint) error {
+	args := mc.Called(comments, taskId)
+	return args.Error(0)
+}
+
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func newMockAttachmentRepository() *MockAttachmentRepository {
+	return &MockAttachmentRepository{}
+}
+
+func (ma *MockAttachmentRepository) Create(attachment *model.Attachment, maxBytes int64) error {
+	args := ma.Called(attachment, maxBytes)
+	return args.Error(0)
+}
+
+func (ma *MockAttachmentRepository) GetAll(attachments *[]model.Attachment, taskId uint) error {
+	args := ma.Called(attachments, taskId)
+	return args.Error(0)
+}
+
+func (ma *MockAttachmentRepository) GetByID(attachment *model.Attachment, taskId uint, attachmentId uint) error {
+	args := ma.Called(attachment, taskId, attachmentId)
+	return args.Error(0)
+}
+
+func (ma *MockAttachmentRepository) Delete(userId uint, taskId uint, attachmentId uint) error {
+	args := ma.Called(userId, taskId, attachmentId)
+	return args.Error(0)
+}
+
+type MockMentionUsecase struct {
+	mock.Mock
+}
+
+func newMockMentionUsecase() *MockMentionUsecase {
+	return &MockMentionUsecase{}
+}
+
+// newSilentMockMentionUsecase records every mention without complaint.
+func newSilentMockMentionUsecase() *MockMentionUsecase {
+	mm := newMockMentionUsecase()
+	mm.On("RecordMentions", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return mm
+}
+
+func (mm *MockMentionUsecase) RecordMentions(actorId uint, workspaceId uint, taskId uint, source string, sourceId uint, text string) error {
+	args := mm.Called(actorId, workspaceId, taskId, source, sourceId, text)
+	return args.Error(0)
+}
+
+func (mm *MockMentionUsecase) GetMentions(userId uint, workspaceId uint) ([]model.MentionResponse, error) {
+	args := mm.Called(userId, workspaceId)
+	return args.Get(0).([]model.MentionResponse), args.Error(1)
+}
+
+type MockCustomFieldRepository struct {
+	mock.Mock
+}
+
+func newMockCustomFieldRepository() *MockCustomFieldRepository {
+	return &MockCustomFieldRepository{}
+}
+
+func (mr *MockCustomFieldRepository) Create(field *model.CustomField) error {
+	args := mr.Called(field)
+	return args.Error(0)
+}
+
+func (mr *MockCustomFieldRepository) GetByProject(fields *[]model.CustomField, projectId uint) error {
+	args := mr.Called(fields, projectId)
+	return args.Error(0)
+}
+
+func (mr *MockCustomFieldRepository) GetByIDs(fields *[]model.CustomField, userId uint, workspaceId uint, fieldIds []uint) error {
+	args := mr.Called(fields, userId, workspaceId, fieldIds)
+	return args.Error(0)
+}
+
+func (mr *MockCustomFieldRepository) Delete(projectId uint, fieldId uint) error {
+	args := mr.Called(projectId, fieldId)
+	return args.Error(0)
+}
+
+// mockCustomFields answers GetByProject and GetByIDs with fields.
+func mockCustomFields(mr *MockCustomFieldRepository, fields ...model.CustomField) {
+	fill := func(args mock.Arguments) {
+		*args.Get(0).(*[]model.CustomField) = fields
+	}
+	mr.On("GetByProject", mock.Anything, mock.Anything).Run(fill).Return(nil)
+	mr.On("GetByIDs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(fill).Return(nil)
+}
+
+type MockCustomFieldValidator struct {
+	mock.Mock
+}
+
+func newMockCustomFieldValidator() *MockCustomFieldValidator {
+	return &MockCustomFieldValidator{}
+}
+
+func (mv *MockCustomFieldValidator) CustomFieldValidate(field model.CustomField) error {
+	args := mv.Called(field)
+	return args.Error(0)
+}
+
+func (mv *MockCustomFieldValidator) CustomFieldValuesValidate(fields []model.CustomField, values model.CustomFieldValues) error {
+	args := mv.Called(fields, values)
+	return args.Error(0)
+}
+
+func (mv *MockCustomFieldValidator) CustomFieldFilterValidate(field model.CustomField, filter model.CustomFieldFilter) error {
+	args := mv.Called(field, filter)
+	return args.Error(0)
+}
+
 func TestCreateTask_Success(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("Create", mock.Anything).Return(nil)
+	mr.On("Create", mock.Anything, mock.Anything).Return(nil)
 	mv.On("TaskValidate", mock.Anything).Return(nil)
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
 	_, err := tu.CreateTask(model.Task{Title: "test"})
 	assert.NoError(t, err)
-	mr.AssertCalled(t, "Create", mock.Anything)
+	mr.AssertCalled(t, "Create", mock.Anything, mock.Anything)
 	mv.AssertCalled(t, "TaskValidate", mock.Anything)
 }
 
 func TestCreateTask_Repository_Failure(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("Create", mock.Anything).Return(errors.New("error"))
+	mr.On("Create", mock.Anything, mock.Anything).Return(errors.New("error"))
 	mv.On("TaskValidate", mock.Anything).Return(nil)
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
 	_, err := tu.CreateTask(model.Task{Title: "test"})
 	assert.Error(t, err)
@@ -84,10 +361,10 @@ func TestCreateTask_Repository_Failure(t *testing.T) {
 func TestCreateTask_Validator_Failure(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("Create", mock.Anything).Return(nil)
+	mr.On("Create", mock.Anything, mock.Anything).Return(nil)
 	mv.On("TaskValidate", mock.Anything).Return(errors.New("error"))
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
 	_, err := tu.CreateTask(model.Task{Title: "test"})
 	assert.Error(t, err)
@@ -96,72 +373,72 @@ func TestCreateTask_Validator_Failure(t *testing.T) {
 func TestGetAllTasks_Success(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("GetAll", mock.Anything, mock.Anything).Return(nil)
+	mr.On("GetAll", mock.Anything, mock.Anything, mock.Anything, model.TaskQuery{}).Return(nil)
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
-	_, err := tu.GetAllTasks(1)
+	_, err := tu.GetAllTasks(1, 7, model.TaskQuery{})
 	assert.NoError(t, err)
-	mr.AssertCalled(t, "GetAll", mock.Anything, mock.Anything)
+	mr.AssertCalled(t, "GetAll", mock.Anything, mock.Anything, mock.Anything, model.TaskQuery{})
 }
 
 func TestGetAllTasks_Repository_Failure(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("GetAll", mock.Anything, mock.Anything).Return(errors.New("error"))
+	mr.On("GetAll", mock.Anything, mock.Anything, mock.Anything, model.TaskQuery{}).Return(errors.New("error"))
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
-	_, err := tu.GetAllTasks(1)
+	_, err := tu.GetAllTasks(1, 7, model.TaskQuery{})
 	assert.Error(t, err)
 }
 
 func TestGetTaskByID_Success(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mr.On("GetAccessible", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
-	_, err := tu.GetTaskByID(1, 1)
+	_, err := tu.GetTaskByID(1, 7, 1)
 	assert.NoError(t, err)
-	mr.AssertCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+	mr.AssertCalled(t, "GetAccessible", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestGetTaskByID_Repository_Failure(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error"))
+	mr.On("GetAccessible", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error"))
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
-	_, err := tu.GetTaskByID(1, 1)
+	_, err := tu.GetTaskByID(1, 7, 1)
 	assert.Error(t, err)
 }
 
 func TestUpdateTask_Success(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mr.On("Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mv.On("TaskValidate", mock.Anything).Return(nil)
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
-	_, err := tu.UpdateTask(1, 1, model.Task{Title: "test"})
+	_, err := tu.UpdateTask(1, 7, 1, 0, model.Task{Title: "test"})
 	assert.NoError(t, err)
-	mr.AssertCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mr.AssertCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	mv.AssertCalled(t, "TaskValidate", mock.Anything)
 }
 
 func TestUpdateTask_Respository_Failure(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error"))
+	mr.On("Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error"))
 	mv.On("TaskValidate", mock.Anything).Return(nil)
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
-	_, err := tu.UpdateTask(1, 1, model.Task{Title: "test"})
+	_, err := tu.UpdateTask(1, 7, 1, 0, model.Task{Title: "test"})
 	assert.Error(t, err)
 }
 
@@ -171,31 +448,494 @@ func TestUpdateTask_Validator_Failure(t *testing.T) {
 	mr.On("Update", mock.Anything).Return(nil)
 	mv.On("TaskValidate", mock.Anything).Return(errors.New("error"))
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
 	_, err := tu.CreateTask(model.Task{Title: "test"})
 	assert.Error(t, err)
 }
 
+func TestPatchTask_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mr.On("Patch", mock.Anything, uint(1), uint(7), uint(2), uint(0), mock.Anything).
+		Run(func(args mock.Arguments) {
+			task := args.Get(0).(*model.Task)
+			*task = model.Task{ID: 2, Title: "unchanged", Status: model.TaskStatusDone}
+		}).
+		Return(nil)
+	mv.On("TaskPatchValidate", mock.Anything).Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	res, err := tu.PatchTask(1, 7, 2, 0, model.TaskPatch{Status: model.PatchField[string]{Set: true, Value: model.TaskStatusDone}})
+	assert.NoError(t, err)
+	assert.Equal(t, "unchanged", res.Title)
+	assert.Equal(t, model.TaskStatusDone, res.Status)
+}
+
+func TestPatchTask_Repository_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mr.On("Patch", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error"))
+	mv.On("TaskPatchValidate", mock.Anything).Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.PatchTask(1, 7, 2, 0, model.TaskPatch{})
+	assert.Error(t, err)
+}
+
+func TestPatchTask_Validator_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mv.On("TaskPatchValidate", mock.Anything).Return(errors.New("error"))
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.PatchTask(1, 7, 2, 0, model.TaskPatch{})
+	assert.Error(t, err)
+	mr.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_StaleVersion_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mr.On("Update", mock.Anything, uint(1), uint(7), uint(1), uint(3)).Return(model.ErrStaleVersion)
+	mv.On("TaskValidate", mock.Anything).Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.UpdateTask(1, 7, 1, 3, model.Task{Title: "test"})
+	assert.ErrorIs(t, err, model.ErrStaleVersion)
+}
+
 func TestDeleteTask_Success(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	mr.On("Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
-	err := tu.DeleteTask(1, 1)
+	err := tu.DeleteTask(1, 7, 1, 0)
 	assert.NoError(t, err)
-	mr.AssertCalled(t, "Delete", mock.Anything, mock.Anything)
+	mr.AssertCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestDeleteTask_Repository_Failure(t *testing.T) {
 	mr := newMockTaskRepository()
 	mv := newMockTaskValidator()
-	mr.On("Delete", mock.Anything, mock.Anything).Return(errors.New("error"))
+	mr.On("Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error"))
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	err := tu.DeleteTask(1, 7, 1, 0)
+	assert.Error(t, err)
+}
+
+func TestCreateTask_TaskQuota_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mq := newMockQuotaRepository()
+	mv.On("TaskValidate", mock.Anything).Return(nil)
+	mq.On("GetUserQuota", mock.Anything, mock.Anything).Return(nil)
+	mockRequestCount(mq, 1)
+	mr.On("Create", mock.Anything, int64(10)).Return(&model.QuotaError{Err: model.ErrQuotaExceeded, Limit: model.QuotaLimitTasks, Used: 10, Max: 10})
+
+	tu := NewTaskUseCase(mr, mv, mq, model.QuotaLimits{MaxTasks: 10}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.CreateTask(model.Task{Title: "test", UserId: 1})
+	assert.ErrorIs(t, err, model.ErrQuotaExceeded)
+	var qe *model.QuotaError
+	assert.ErrorAs(t, err, &qe)
+	assert.Equal(t, model.QuotaLimitTasks, qe.Limit)
+	assert.Equal(t, int64(10), qe.Max)
+}
+
+func TestCreateTask_UserQuotaOverride_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mq := newMockQuotaRepository()
+	mr.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mv.On("TaskValidate", mock.Anything).Return(nil)
+	maxTasks := int64(100)
+	mq.On("GetUserQuota", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			quota := args.Get(0).(*model.UserQuota)
+			*quota = model.UserQuota{UserId: 1, Plan: "partner", MaxTasks: &maxTasks}
+		}).
+		Return(nil)
+	mockRequestCount(mq, 1)
+
+	tu := NewTaskUseCase(mr, mv, mq, model.QuotaLimits{MaxTasks: 10}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.CreateTask(model.Task{Title: "test", UserId: 1})
+	assert.NoError(t, err)
+	mr.AssertCalled(t, "Create", mock.Anything, maxTasks)
+}
+
+func TestGetAllTasks_RateLimited_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mq := newMockQuotaRepository()
+	mq.On("GetUserQuota", mock.Anything, mock.Anything).Return(nil)
+	mockRequestCount(mq, 6)
+
+	tu := NewTaskUseCase(mr, mv, mq, model.QuotaLimits{MaxRequestsPerDay: 5}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.GetAllTasks(1, 7, model.TaskQuery{})
+	assert.ErrorIs(t, err, model.ErrRateLimited)
+	var qe *model.QuotaError
+	assert.ErrorAs(t, err, &qe)
+	assert.Equal(t, model.QuotaLimitRequestsPerDay, qe.Limit)
+	assert.Equal(t, usageDay(time.Now()).AddDate(0, 0, 1), *qe.ResetAt)
+	mr.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetUsage_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mq := newMockQuotaRepository()
+	mq.On("GetUserQuota", mock.Anything, mock.Anything).Return(nil)
+	mockTaskCount(mq, 3)
+	mq.On("SumAttachmentSizes", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*int64) = 2048
+		}).
+		Return(nil)
+	mq.On("GetUsage", mock.Anything, uint(1), usageDay(time.Now())).
+		Run(func(args mock.Arguments) {
+			usage := args.Get(0).(*model.APIUsage)
+			*usage = model.APIUsage{Requests: 42}
+		}).
+		Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, mq, model.QuotaLimits{MaxTasks: 10, MaxRequestsPerDay: 100, MaxAttachmentBytes: 4096}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	res, err := tu.GetUsage(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "default", res.Plan)
+	assert.Equal(t, model.UsageCounter{Used: 3, Limit: 10}, res.Tasks)
+	assert.Equal(t, model.UsageCounter{Used: 42, Limit: 100}, res.RequestsToday)
+	assert.Equal(t, model.UsageCounter{Used: 2048, Limit: 4096}, res.AttachmentBytes)
+	mq.AssertNotCalled(t, "AddRequest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatchTask_NotifiesWatchers(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mw := newMockWatcherRepository()
+	sink := notifier.NewMemorySink()
+	due := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	assignee := uint(3)
+	mv.On("TaskPatchValidate", mock.Anything).Return(nil)
+	mockWatchers(mw, 1, 2, 3)
+	mr.On("GetByID", mock.Anything, uint(1), uint(7), uint(5)).
+		Run(func(args mock.Arguments) {
+			task := args.Get(0).(*model.Task)
+			*task = model.Task{ID: 5, Title: "task", Status: model.TaskStatusTodo}
+		}).
+		Return(nil)
+	mr.On("Patch", mock.Anything, uint(1), uint(7), uint(5), uint(0), mock.Anything).
+		Run(func(args mock.Arguments) {
+			task := args.Get(0).(*model.Task)
+			*task = model.Task{ID: 5, Title: "renamed", Status: model.TaskStatusDone, DueDate: &due, AssigneeId: &assignee}
+		}).
+		Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, mw, newMockCommentRepository(), newMockAttachmentRepository(), sink, newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.PatchTask(1, 7, 5, 0, model.TaskPatch{})
+	assert.NoError(t, err)
+
+	notifications := sink.Notifications()
+	assert.Len(t, notifications, 6, "users 2 and 3 each get status, due date and reassignment, user 1 made the change")
+	types := []string{}
+	for _, n := range notifications[:3] {
+		assert.Equal(t, uint(2), n.UserId)
+		assert.Equal(t, uint(1), n.Change.ActorId)
+		types = append(types, n.Change.Type)
+	}
+	assert.Equal(t, []string{model.TaskChangeStatus, model.TaskChangeDueDate, model.TaskChangeReassignment}, types)
+	assert.Equal(t, model.TaskStatusTodo, notifications[0].Change.From)
+	assert.Equal(t, model.TaskStatusDone, notifications[0].Change.To)
+	assert.Equal(t, nil, notifications[2].Change.From)
+	assert.Equal(t, uint(3), notifications[2].Change.To)
+}
+
+func TestUpdateTask_TitleOnly_NoNotification(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mw := newMockWatcherRepository()
+	sink := notifier.NewMemorySink()
+	mv.On("TaskValidate", mock.Anything).Return(nil)
+	mockWatchers(mw, 2)
+	mr.On("GetByID", mock.Anything, uint(1), uint(7), uint(5)).
+		Run(func(args mock.Arguments) {
+			task := args.Get(0).(*model.Task)
+			*task = model.Task{ID: 5, Title: "task", Status: model.TaskStatusTodo}
+		}).
+		Return(nil)
+	mr.On("Update", mock.Anything, uint(1), uint(7), uint(5), uint(0)).
+		Run(func(args mock.Arguments) {
+			task := args.Get(0).(*model.Task)
+			*task = model.Task{ID: 5, Title: "renamed", Status: model.TaskStatusTodo}
+		}).
+		Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, mw, newMockCommentRepository(), newMockAttachmentRepository(), sink, newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.UpdateTask(1, 7, 5, 0, model.Task{Title: "renamed"})
+	assert.NoError(t, err)
+	assert.Empty(t, sink.Notifications())
+}
+
+func TestAddComment_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mw := newMockWatcherRepository()
+	mc := newMockCommentRepository()
+	sink := notifier.NewMemorySink()
+	mv.On("CommentValidate", mock.Anything).Return(nil)
+	mr.On("GetAccessible", mock.Anything, uint(2), uint(7), uint(5)).Return(nil)
+	mockWatchers(mw, 1, 2)
+	mc.On("Create", mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.Comment).ID = 9
+		}).
+		Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, mw, mc, newMockAttachmentRepository(), sink, newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	res, err := tu.AddComment(2, 7, 5, model.Comment{Body: "looks good"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.CommentResponse{ID: 9, Body: "looks good", TaskId: 5, UserId: 2}, res)
+
+	notifications := sink.Notifications()
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, uint(1), notifications[0].UserId)
+	assert.Equal(t, model.TaskChangeComment, notifications[0].Change.Type)
+	assert.Equal(t, res, notifications[0].Change.To)
+}
+
+func TestAddComment_NoAccess_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mc := newMockCommentRepository()
+	mv.On("CommentValidate", mock.Anything).Return(nil)
+	mr.On("GetAccessible", mock.Anything, uint(2), uint(7), uint(5)).Return(gorm.ErrRecordNotFound)
 
-	tu := NewTaskUseCase(mr, mv)
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), mc, newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
 
-	err := tu.DeleteTask(1, 1)
+	_, err := tu.AddComment(2, 7, 5, model.Comment{Body: "hello"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	mc.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAddComment_RecordsMentions(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mc := newMockCommentRepository()
+	mm := newMockMentionUsecase()
+	mv.On("CommentValidate", mock.Anything).Return(nil)
+	mr.On("GetAccessible", mock.Anything, uint(2), uint(7), uint(5)).Return(nil)
+	mc.On("Create", mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.Comment).ID = 9
+		}).
+		Return(nil)
+	mm.On("RecordMentions", uint(2), uint(7), uint(5), model.MentionSourceComment, uint(9), "@alice please review").Return(errors.New("error"))
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), mc, newMockAttachmentRepository(), notifier.NewMemorySink(), mm, newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.AddComment(2, 7, 5, model.Comment{Body: "@alice please review"})
+	assert.NoError(t, err)
+	mm.AssertExpectations(t)
+}
+
+func TestPatchTask_RecordsDescriptionMentions(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mm := newMockMentionUsecase()
+	mr.On("Patch", mock.Anything, uint(1), uint(7), uint(2), uint(0), mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.Task) = model.Task{ID: 2, Title: "task", Description: "ask @bob"}
+		}).
+		Return(nil)
+	mv.On("TaskPatchValidate", mock.Anything).Return(nil)
+	mm.On("RecordMentions", uint(1), uint(7), uint(2), model.MentionSourceDescription, uint(0), "ask @bob").Return(nil).Once()
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), mm, newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.PatchTask(1, 7, 2, 0, model.TaskPatch{Description: model.PatchField[string]{Set: true, Value: "ask @bob"}})
+	assert.NoError(t, err)
+	_, err = tu.PatchTask(1, 7, 2, 0, model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "task"}})
+	assert.NoError(t, err)
+	mm.AssertExpectations(t)
+}
+
+func TestGetComments_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mc := newMockCommentRepository()
+	mr.On("GetAccessible", mock.Anything, uint(1), uint(7), uint(5)).Return(nil)
+	mc.On("GetAll", mock.Anything, uint(5)).
+		Run(func(args mock.Arguments) {
+			comments := args.Get(0).(*[]model.Comment)
+			*comments = []model.Comment{{ID: 1, Body: "first", TaskId: 5, UserId: 1}}
+		}).
+		Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), mc, newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	res, err := tu.GetComments(1, 7, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CommentResponse{{ID: 1, Body: "first", TaskId: 5, UserId: 1}}, res)
+}
+
+func TestCreateTask_CustomFields_Validator_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mf := newMockCustomFieldRepository()
+	mfv := newMockCustomFieldValidator()
+	fields := []model.CustomField{{ID: 1, Name: "Estimate", Type: model.CustomFieldNumber, ProjectId: 3}}
+	mockCustomFields(mf, fields...)
+	mv.On("TaskValidate", mock.Anything).Return(nil)
+	mfv.On("CustomFieldValuesValidate", fields, model.CustomFieldValues{"1": "ten"}).Return(validation.Errors{"1": errors.New("must be a number")})
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), mf, mfv)
+
+	projectId := uint(3)
+	_, err := tu.CreateTask(model.Task{Title: "test", ProjectId: &projectId, CustomFields: model.CustomFieldValues{"1": "ten"}})
 	assert.Error(t, err)
+	mf.AssertCalled(t, "GetByProject", mock.Anything, uint(3))
+	mr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPatchTask_MovingProject_DropsCustomFields(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mf := newMockCustomFieldRepository()
+	mfv := newMockCustomFieldValidator()
+	oldProject := uint(3)
+	mr.On("GetByID", mock.Anything, uint(1), uint(7), uint(2)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.Task) = model.Task{ID: 2, ProjectId: &oldProject, CustomFields: model.CustomFieldValues{"1": "x"}}
+		}).
+		Return(nil)
+	mr.On("Patch", mock.Anything, uint(1), uint(7), uint(2), uint(0), mock.Anything).Return(nil)
+	mv.On("TaskPatchValidate", mock.Anything).Return(nil)
+	mockCustomFields(mf)
+	mfv.On("CustomFieldValuesValidate", mock.Anything, model.CustomFieldValues(nil)).Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), mf, mfv)
+
+	_, err := tu.PatchTask(1, 7, 2, 0, model.TaskPatch{ProjectId: model.PatchField[uint]{Set: true, Value: 4}})
+	assert.NoError(t, err)
+	mf.AssertCalled(t, "GetByProject", mock.Anything, uint(4))
+	mr.AssertCalled(t, "Patch", mock.Anything, uint(1), uint(7), uint(2), uint(0), mock.MatchedBy(func(patch model.TaskPatch) bool {
+		return patch.CustomFields.Set && patch.CustomFields.Value == nil
+	}))
+}
+
+func TestPatchTask_WithoutCustomFields_SkipsLookup(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	mf := newMockCustomFieldRepository()
+	mr.On("Patch", mock.Anything, uint(1), uint(7), uint(2), uint(0), mock.Anything).Return(nil)
+	mv.On("TaskPatchValidate", mock.Anything).Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), mf, newMockCustomFieldValidator())
+
+	_, err := tu.PatchTask(1, 7, 2, 0, model.TaskPatch{Title: model.PatchField[string]{Set: true, Value: "renamed"}})
+	assert.NoError(t, err)
+	mr.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mf.AssertNotCalled(t, "GetByProject", mock.Anything, mock.Anything)
+}
+
+func TestGetAllTasks_CustomFieldQuery_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	mf := newMockCustomFieldRepository()
+	mfv := newMockCustomFieldValidator()
+	mockCustomFields(mf,
+		model.CustomField{ID: 1, Type: model.CustomFieldNumber},
+		model.CustomField{ID: 2, Type: model.CustomFieldDate},
+	)
+	mfv.On("CustomFieldFilterValidate", mock.Anything, mock.Anything).Return(nil)
+	expected := model.TaskQuery{
+		Filters: []model.CustomFieldFilter{{FieldId: 1, Op: model.CustomFieldOpGte, Value: "3", Type: model.CustomFieldNumber}},
+		Sort:    &model.CustomFieldSort{FieldId: 2, Desc: true, Type: model.CustomFieldDate},
+	}
+	mr.On("GetAll", mock.Anything, uint(1), uint(7), expected).Return(nil)
+
+	tu := NewTaskUseCase(mr, newMockTaskValidator(), newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), mf, mfv)
+
+	_, err := tu.GetAllTasks(1, 7, model.TaskQuery{
+		Filters: []model.CustomFieldFilter{{FieldId: 1, Op: model.CustomFieldOpGte, Value: "3"}},
+		Sort:    &model.CustomFieldSort{FieldId: 2, Desc: true},
+	})
+	assert.NoError(t, err)
+	mf.AssertCalled(t, "GetByIDs", mock.Anything, uint(1), uint(7), []uint{1, 2})
+	mr.AssertCalled(t, "GetAll", mock.Anything, uint(1), uint(7), expected)
+}
+
+func TestGetAllTasks_UnknownCustomField_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mf := newMockCustomFieldRepository()
+	mockCustomFields(mf)
+
+	tu := NewTaskUseCase(mr, newMockTaskValidator(), newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), newMockAttachmentRepository(), notifier.NewMemorySink(), newSilentMockMentionUsecase(), mf, newMockCustomFieldValidator())
+
+	_, err := tu.GetAllTasks(1, 7, model.TaskQuery{Sort: &model.CustomFieldSort{FieldId: 9}})
+	assert.Equal(t, "sort: is not a custom field.", err.Error())
+	mr.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAddAttachment_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	ma := newMockAttachmentRepository()
+	mv.On("AttachmentValidate", mock.Anything).Return(nil)
+	mr.On("GetAccessible", mock.Anything, uint(1), uint(7), uint(5)).Return(nil)
+	ma.On("Create", mock.Anything, int64(4096)).Return(nil)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{MaxAttachmentBytes: 4096}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), ma, notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	res, err := tu.AddAttachment(1, 7, 5, model.Attachment{Name: "notes.txt", ContentType: "text/plain", Size: 5, Data: []byte("notes")})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), res.TaskId)
+	assert.Equal(t, uint(1), res.UserId)
+	ma.AssertCalled(t, "Create", mock.MatchedBy(func(attachment *model.Attachment) bool {
+		return attachment.TaskId == 5 && attachment.UserId == 1 && string(attachment.Data) == "notes"
+	}), int64(4096))
+}
+
+func TestAddAttachment_StorageQuota_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	ma := newMockAttachmentRepository()
+	mv.On("AttachmentValidate", mock.Anything).Return(nil)
+	mr.On("GetAccessible", mock.Anything, uint(1), uint(7), uint(5)).Return(nil)
+	ma.On("Create", mock.Anything, int64(4096)).Return(&model.QuotaError{Err: model.ErrQuotaExceeded, Limit: model.QuotaLimitAttachmentBytes, Used: 4000, Max: 4096})
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{MaxAttachmentBytes: 4096}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), ma, notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.AddAttachment(1, 7, 5, model.Attachment{Name: "big.bin", Size: 200, Data: make([]byte, 200)})
+	assert.ErrorIs(t, err, model.ErrQuotaExceeded)
+	var qe *model.QuotaError
+	assert.ErrorAs(t, err, &qe)
+	assert.Equal(t, model.QuotaLimitAttachmentBytes, qe.Limit)
+}
+
+func TestAddAttachment_NoAccess_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mv := newMockTaskValidator()
+	ma := newMockAttachmentRepository()
+	mv.On("AttachmentValidate", mock.Anything).Return(nil)
+	mr.On("GetAccessible", mock.Anything, uint(1), uint(7), uint(5)).Return(gorm.ErrRecordNotFound)
+
+	tu := NewTaskUseCase(mr, mv, newAllowingMockQuotaRepository(), model.QuotaLimits{}, newUnwatchedMockWatcherRepository(), newMockCommentRepository(), ma, notifier.NewMemorySink(), newSilentMockMentionUsecase(), newMockCustomFieldRepository(), newMockCustomFieldValidator())
+
+	_, err := tu.AddAttachment(1, 7, 5, model.Attachment{Name: "notes.txt", Size: 5, Data: []byte("notes")})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	ma.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
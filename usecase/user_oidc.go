@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"go-rest-api/model"
+	"go-rest-api/oidc"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	// oidcLoginTTL is how long a user has to sign in at the provider.
+	oidcLoginTTL = 10 * time.Minute
+
+	oidcLoginPurpose = "oidc-login"
+)
+
+// StartOIDCLogin sends the user to the provider. The state, nonce and PKCE
+// verifier travel in the signed state token rather than in the database, so
+// only the browser that started the login can finish it.
+func (uu *userUsecase) StartOIDCLogin(providerName string) (model.OIDCLoginStart, error) {
+	provider, ok := uu.providers[providerName]
+	if !ok {
+		return model.OIDCLoginStart{}, model.ErrUnknownProvider
+	}
+	state, err := newOpaqueToken()
+	if err != nil {
+		return model.OIDCLoginStart{}, err
+	}
+	nonce, err := newOpaqueToken()
+	if err != nil {
+		return model.OIDCLoginStart{}, err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return model.OIDCLoginStart{}, err
+	}
+	authURL, err := provider.AuthCodeURL(state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return model.OIDCLoginStart{}, fmt.Errorf("%w: %v", model.ErrOIDCLoginFailed, err)
+	}
+	expiresAt := time.Now().Add(oidcLoginTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"provider": providerName,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      expiresAt.Unix(),
+	})
+	stateToken, err := token.SignedString(purposeKey(oidcLoginPurpose))
+	if err != nil {
+		return model.OIDCLoginStart{}, err
+	}
+	return model.OIDCLoginStart{AuthURL: authURL, StateToken: stateToken, ExpiresAt: expiresAt}, nil
+}
+
+// FinishOIDCLogin signs in the user the provider vouches for. See
+// linkIdentity for how the identity is tied to a user.
+func (uu *userUsecase) FinishOIDCLogin(providerName string, stateToken string, req model.OIDCCallbackRequest) (model.LoginResult, error) {
+	provider, ok := uu.providers[providerName]
+	if !ok {
+		return model.LoginResult{}, model.ErrUnknownProvider
+	}
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}}
+	parsed, err := parser.Parse(stateToken, func(*jwt.Token) (interface{}, error) {
+		return purposeKey(oidcLoginPurpose), nil
+	})
+	if err != nil {
+		return model.LoginResult{}, fmt.Errorf("%w: login state is missing or expired", model.ErrOIDCLoginFailed)
+	}
+	claims := parsed.Claims.(jwt.MapClaims)
+	state, _ := claims["state"].(string)
+	if claims["provider"] != providerName || subtle.ConstantTimeCompare([]byte(state), []byte(req.State)) != 1 {
+		return model.LoginResult{}, fmt.Errorf("%w: state does not match", model.ErrOIDCLoginFailed)
+	}
+	if req.Error != "" {
+		return model.LoginResult{}, fmt.Errorf("%w: %s", model.ErrOIDCLoginFailed, req.Error)
+	}
+	verifier, _ := claims["verifier"].(string)
+	nonce, _ := claims["nonce"].(string)
+	identity, err := provider.Authenticate(req.Code, verifier, nonce)
+	if err != nil {
+		return model.LoginResult{}, fmt.Errorf("%w: %v", model.ErrOIDCLoginFailed, err)
+	}
+	user, err := uu.linkIdentity(identity)
+	if err != nil {
+		return model.LoginResult{}, err
+	}
+	if user.DisabledAt != nil {
+		return model.LoginResult{}, model.ErrAccountDisabled
+	}
+	return uu.login(user.ID)
+}
+
+// linkIdentity returns the user linked to the identity. An identity seen for
+// the first time is linked to the user with its email address, or to a new
+// user if there is none, but only if the provider has verified the address.
+// Otherwise anyone could claim an account by registering its address at a
+// provider. An existing user must have verified the address as well, or
+// someone who signed up with it beforehand would keep a password to the
+// account of whoever owns it.
+func (uu *userUsecase) linkIdentity(claims oidc.Claims) (model.User, error) {
+	identity := model.Identity{}
+	user := model.User{}
+	err := uu.ir.GetBySubject(&identity, claims.Issuer, claims.Subject)
+	if err == nil {
+		if err := uu.ur.GetByID(&user, identity.UserId); err != nil {
+			return model.User{}, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.User{}, err
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return model.User{}, model.ErrIdentityUnverified
+	}
+	identity = model.Identity{Issuer: claims.Issuer, Subject: claims.Subject, Email: claims.Email}
+	err = uu.ur.GetByEmail(&user, claims.Email)
+	if err == nil {
+		if user.EmailVerifiedAt == nil {
+			return model.User{}, model.ErrAccountUnverified
+		}
+		identity.UserId = user.ID
+		if err := uu.ir.Create(&identity); err != nil {
+			return model.User{}, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.User{}, err
+	}
+	// The user has no password and signs in through the provider, or sets
+	// one with a password reset.
+	now := time.Now()
+	user = model.User{Email: claims.Email, Timezone: "UTC", Role: model.RoleUser, EmailVerifiedAt: &now}
+	if err := uu.ir.CreateUser(&user, &identity); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"go-rest-api/mailer"
+	"go-rest-api/model"
+	"go-rest-api/oidc"
+	"go-rest-api/oidc/oidctest"
+	"go-rest-api/repository"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockIdentityRepository struct {
+	mock.Mock
+}
+
+func newMockIdentityRepository() *MockIdentityRepository {
+	return &MockIdentityRepository{}
+}
+
+func (ir *MockIdentityRepository) GetBySubject(identity *model.Identity, issuer string, subject string) error {
+	args := ir.Called(identity, issuer, subject)
+	return args.Error(0)
+}
+
+func (ir *MockIdentityRepository) Create(identity *model.Identity) error {
+	args := ir.Called(identity)
+	return args.Error(0)
+}
+
+func (ir *MockIdentityRepository) CreateUser(user *model.User, identity *model.Identity) error {
+	args := ir.Called(user, identity)
+	return args.Error(0)
+}
+
+// newOIDCUserUsecase signs users in with a test provider named "test" that
+// vouches for user.
+func newOIDCUserUsecase(t *testing.T, mr *MockUserRepository, ir *MockIdentityRepository, user oidctest.User) (IUserUsecase, *oidctest.Provider) {
+	idp := oidctest.NewProvider("client", "secret")
+	t.Cleanup(idp.Close)
+	idp.SetUser(user)
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:       idp.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/oidc/test/callback",
+	}, http.DefaultClient)
+	rr := newMockRefreshTokenRepository()
+	rr.On("Create", mock.Anything).Return(nil)
+	uu := NewUserUsecase(mr, newMockUserValidator(), rr, repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), ir, mailer.NewMemoryMailer(), testTokenLifetimes, map[string]oidc.Provider{"test": provider})
+	return uu, idp
+}
+
+// signInWithOIDC runs the login through the provider and returns the state
+// token and the callback.
+func signInWithOIDC(t *testing.T, uu IUserUsecase, idp *oidctest.Provider) (string, model.OIDCCallbackRequest) {
+	start, err := uu.StartOIDCLogin("test")
+	assert.NoError(t, err)
+	code, state, err := idp.SignIn(start.AuthURL)
+	assert.NoError(t, err)
+	return start.StateToken, model.OIDCCallbackRequest{Code: code, State: state}
+}
+
+func TestFinishOIDCLogin_NewUser_Success(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	mr.On("GetByEmail", mock.Anything, "alice@test.com").Return(gorm.ErrRecordNotFound)
+	ir := newMockIdentityRepository()
+	ir.On("GetBySubject", mock.Anything, mock.Anything, "alice").Return(gorm.ErrRecordNotFound)
+	ir.On("CreateUser", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.User).ID = 5
+		}).
+		Return(nil)
+
+	uu, idp := newOIDCUserUsecase(t, mr, ir, oidctest.User{Subject: "alice", Email: "alice@test.com", EmailVerified: true})
+
+	stateToken, req := signInWithOIDC(t, uu, idp)
+	res, err := uu.FinishOIDCLogin("test", stateToken, req)
+	assert.NoError(t, err)
+	assert.Nil(t, res.Challenge)
+	assert.NotEmpty(t, res.Tokens.AccessToken)
+	ir.AssertCalled(t, "CreateUser", mock.MatchedBy(func(user *model.User) bool {
+		return user.Email == "alice@test.com" && user.EmailVerifiedAt != nil && user.Password == ""
+	}), &model.Identity{Issuer: idp.Issuer(), Subject: "alice", Email: "alice@test.com"})
+}
+
+func TestFinishOIDCLogin_LinkByEmail_Success(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	mr.On("GetByEmail", mock.Anything, "user@test.com").
+		Run(func(args mock.Arguments) {
+			verifiedAt := time.Now()
+			*args.Get(0).(*model.User) = model.User{ID: 1, Email: "user@test.com", EmailVerifiedAt: &verifiedAt}
+		}).
+		Return(nil)
+	ir := newMockIdentityRepository()
+	ir.On("GetBySubject", mock.Anything, mock.Anything, "alice").Return(gorm.ErrRecordNotFound)
+	ir.On("Create", mock.Anything).Return(nil)
+
+	uu, idp := newOIDCUserUsecase(t, mr, ir, oidctest.User{Subject: "alice", Email: "user@test.com", EmailVerified: true})
+
+	stateToken, req := signInWithOIDC(t, uu, idp)
+	_, err := uu.FinishOIDCLogin("test", stateToken, req)
+	assert.NoError(t, err)
+	ir.AssertCalled(t, "Create", &model.Identity{Issuer: idp.Issuer(), Subject: "alice", Email: "user@test.com", UserId: 1})
+	ir.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestFinishOIDCLogin_LinkUnverifiedAccount_Failure(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	mr.On("GetByEmail", mock.Anything, "user@test.com").
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = model.User{ID: 1, Email: "user@test.com", Password: "hash"}
+		}).
+		Return(nil)
+	ir := newMockIdentityRepository()
+	ir.On("GetBySubject", mock.Anything, mock.Anything, "alice").Return(gorm.ErrRecordNotFound)
+
+	uu, idp := newOIDCUserUsecase(t, mr, ir, oidctest.User{Subject: "alice", Email: "user@test.com", EmailVerified: true})
+
+	stateToken, req := signInWithOIDC(t, uu, idp)
+	_, err := uu.FinishOIDCLogin("test", stateToken, req)
+	assert.ErrorIs(t, err, model.ErrAccountUnverified)
+	ir.AssertNotCalled(t, "Create", mock.Anything)
+	ir.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestFinishOIDCLogin_UnverifiedEmail_Failure(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	ir := newMockIdentityRepository()
+	ir.On("GetBySubject", mock.Anything, mock.Anything, "alice").Return(gorm.ErrRecordNotFound)
+
+	uu, idp := newOIDCUserUsecase(t, mr, ir, oidctest.User{Subject: "alice", Email: "user@test.com"})
+
+	stateToken, req := signInWithOIDC(t, uu, idp)
+	_, err := uu.FinishOIDCLogin("test", stateToken, req)
+	assert.ErrorIs(t, err, model.ErrIdentityUnverified)
+	mr.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+	ir.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestFinishOIDCLogin_StateMismatch_Failure(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	ir := newMockIdentityRepository()
+
+	uu, idp := newOIDCUserUsecase(t, newMockUserRepository(), ir, oidctest.User{Subject: "alice", Email: "alice@test.com", EmailVerified: true})
+
+	_, req := signInWithOIDC(t, uu, idp)
+	// A state token from another login in another browser.
+	otherStateToken, _ := signInWithOIDC(t, uu, idp)
+	_, err := uu.FinishOIDCLogin("test", otherStateToken, req)
+	assert.ErrorIs(t, err, model.ErrOIDCLoginFailed)
+	ir.AssertNotCalled(t, "GetBySubject", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStartOIDCLogin_UnknownProvider_Failure(t *testing.T) {
+	uu, _ := newOIDCUserUsecase(t, newMockUserRepository(), newMockIdentityRepository(), oidctest.User{})
+
+	_, err := uu.StartOIDCLogin("other")
+	assert.ErrorIs(t, err, model.ErrUnknownProvider)
+}
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"go-rest-api/mailer"
+	"go-rest-api/model"
+	"go-rest-api/totp"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// totpIssuer names the account in authenticator apps.
+	totpIssuer = "go-rest-api"
+	// loginChallengeTTL is how long a user has to enter their code after
+	// entering their password.
+	loginChallengeTTL = 5 * time.Minute
+	recoveryCodeCount = 10
+	// recoveryCodeLength is the length of a recovery code without its
+	// separator.
+	recoveryCodeLength = 8
+	// maxChallengeAttempts is how many codes can be tried with one login
+	// challenge before the password has to be entered again.
+	maxChallengeAttempts = 5
+	// twoFactorLockoutThreshold is how many wrong codes in a row, across
+	// challenges, lock the user out of two-factor logins for
+	// twoFactorLockout. Each further wrong code doubles the lockout, up to
+	// twoFactorMaxLockout.
+	twoFactorLockoutThreshold = 10
+	twoFactorLockout          = time.Minute
+	twoFactorMaxLockout       = time.Hour
+
+	loginTwoFactorPurpose = "login-2fa"
+)
+
+// EnrollTwoFactor creates a new TOTP secret for the user. It takes effect
+// once ConfirmTwoFactor accepts a code from it.
+func (uu *userUsecase) EnrollTwoFactor(userId uint) (model.TwoFactorEnrollment, error) {
+	user := model.User{}
+	if err := uu.ur.GetByID(&user, userId); err != nil {
+		return model.TwoFactorEnrollment{}, err
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return model.TwoFactorEnrollment{}, err
+	}
+	if err := uu.tfr.Enroll(&model.TwoFactor{UserId: userId, Secret: secret}); err != nil {
+		return model.TwoFactorEnrollment{}, err
+	}
+	return model.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication if the code matches the
+// enrolled secret, and returns recovery codes. They are not stored in the
+// clear, so this is the only time the user sees them.
+func (uu *userUsecase) ConfirmTwoFactor(userId uint, req model.TwoFactorCodeRequest) (model.RecoveryCodesResponse, error) {
+	twoFactor := model.TwoFactor{}
+	if err := uu.tfr.Get(&twoFactor, userId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.RecoveryCodesResponse{}, model.ErrTwoFactorNotEnrolled
+		}
+		return model.RecoveryCodesResponse{}, err
+	}
+	if twoFactor.EnabledAt != nil {
+		return model.RecoveryCodesResponse{}, model.ErrTwoFactorEnabled
+	}
+	step, ok := totp.Validate(twoFactor.Secret, normalizeCode(req.Code), time.Now())
+	if !ok {
+		return model.RecoveryCodesResponse{}, model.ErrInvalidTwoFactorCode
+	}
+	codes := []string{}
+	hashes := []string{}
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return model.RecoveryCodesResponse{}, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeCode(code)))
+	}
+	if err := uu.tfr.Enable(userId, step, hashes); err != nil {
+		return model.RecoveryCodesResponse{}, err
+	}
+	user := model.User{}
+	if err := uu.ur.GetByID(&user, userId); err == nil {
+		uu.notify(user, mailer.Message{
+			To:      user.Email,
+			Subject: "Two-factor authentication was turned on",
+			Body:    "Signing in to your account now needs a code from your authenticator app or one of your recovery codes.",
+		})
+	}
+	return model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor needs both the password and a current code, so that
+// neither a stolen session nor a stolen password is enough to turn it off.
+func (uu *userUsecase) DisableTwoFactor(userId uint, req model.TwoFactorDisableRequest) error {
+	user := model.User{}
+	if err := uu.ur.GetByID(&user, userId); err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return model.ErrWrongPassword
+	}
+	twoFactor := model.TwoFactor{}
+	if err := uu.tfr.Get(&twoFactor, userId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrTwoFactorNotEnrolled
+		}
+		return err
+	}
+	if twoFactor.EnabledAt == nil {
+		return model.ErrTwoFactorNotEnrolled
+	}
+	if err := uu.checkSecondFactor(twoFactor, req.Code); err != nil {
+		return err
+	}
+	if err := uu.tfr.Disable(userId); err != nil {
+		return err
+	}
+	uu.notify(user, mailer.Message{
+		To:      user.Email,
+		Subject: "Two-factor authentication was turned off",
+		Body:    "Signing in to your account now only needs your password.\n\nIf you did not do this, reset your password right away.",
+	})
+	return nil
+}
+
+// LoginTwoFactor completes a login that Login answered with a challenge. A
+// challenge works once and only for a few codes, and wrong codes in a row
+// lock the user out for a while.
+func (uu *userUsecase) LoginTwoFactor(req model.LoginChallengeRequest) (model.AuthTokens, error) {
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}}
+	parsed, err := parser.Parse(req.Token, func(*jwt.Token) (interface{}, error) {
+		return purposeKey(loginTwoFactorPurpose), nil
+	})
+	if err != nil {
+		return model.AuthTokens{}, model.ErrInvalidChallenge
+	}
+	claims := parsed.Claims.(jwt.MapClaims)
+	userId, _ := claims["userId"].(float64)
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return model.AuthTokens{}, model.ErrInvalidChallenge
+	}
+	// The account may have been disabled since the challenge was issued.
+	if err := uu.checkEnabled(uint(userId)); err != nil {
+		return model.AuthTokens{}, err
+	}
+	twoFactor := model.TwoFactor{}
+	if err := uu.tfr.Get(&twoFactor, uint(userId)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.AuthTokens{}, model.ErrInvalidChallenge
+		}
+		return model.AuthTokens{}, err
+	}
+	if twoFactor.EnabledAt == nil {
+		return model.AuthTokens{}, model.ErrInvalidChallenge
+	}
+	if twoFactor.LockedUntil != nil && twoFactor.LockedUntil.After(time.Now()) {
+		return model.AuthTokens{}, &model.TwoFactorLockedError{Until: *twoFactor.LockedUntil}
+	}
+	if err := uu.tfr.StartAttempt(jti, uint(userId), maxChallengeAttempts); err != nil {
+		return model.AuthTokens{}, err
+	}
+	if err := uu.checkSecondFactor(twoFactor, req.Code); err != nil {
+		if errors.Is(err, model.ErrInvalidTwoFactorCode) {
+			return model.AuthTokens{}, uu.recordTwoFactorFailure(uint(userId), err)
+		}
+		return model.AuthTokens{}, err
+	}
+	if err := uu.tfr.UseChallenge(jti, uint(userId)); err != nil {
+		return model.AuthTokens{}, err
+	}
+	return uu.startSession(uint(userId))
+}
+
+// recordTwoFactorFailure counts a wrong login code and locks the user out
+// once there have been too many in a row. It returns codeErr, or the lockout.
+func (uu *userUsecase) recordTwoFactorFailure(userId uint, codeErr error) error {
+	var failures int
+	if err := uu.tfr.RecordFailure(&failures, userId); err != nil {
+		return err
+	}
+	if failures < twoFactorLockoutThreshold {
+		return codeErr
+	}
+	lockout := twoFactorLockout
+	for i := twoFactorLockoutThreshold; i < failures && lockout < twoFactorMaxLockout; i++ {
+		lockout *= 2
+	}
+	until := time.Now().Add(min(lockout, twoFactorMaxLockout))
+	if err := uu.tfr.Lock(userId, until); err != nil {
+		return err
+	}
+	return &model.TwoFactorLockedError{Until: until}
+}
+
+// checkSecondFactor accepts a TOTP code whose time step has not been used
+// yet, or an unused recovery code. Either way the code cannot be used again.
+func (uu *userUsecase) checkSecondFactor(twoFactor model.TwoFactor, code string) error {
+	code = normalizeCode(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+		if !ok {
+			return model.ErrInvalidTwoFactorCode
+		}
+		return uu.tfr.UseStep(twoFactor.UserId, step)
+	}
+	if len(code) != recoveryCodeLength {
+		return model.ErrInvalidTwoFactorCode
+	}
+	return uu.tfr.UseRecoveryCode(twoFactor.UserId, hashToken(code))
+}
+
+// newLoginChallenge signs a challenge token and stores it by its jti, so that
+// it can be used up.
+func (uu *userUsecase) newLoginChallenge(userId uint) (model.LoginChallenge, error) {
+	jti, err := newOpaqueToken()
+	if err != nil {
+		return model.LoginChallenge{}, err
+	}
+	expiresAt := time.Now().Add(loginChallengeTTL)
+	if err := uu.tfr.CreateChallenge(&model.TwoFactorChallenge{Jti: jti, UserId: userId, ExpiresAt: expiresAt}); err != nil {
+		return model.LoginChallenge{}, err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userId": userId,
+		"jti":    jti,
+		"exp":    expiresAt.Unix(),
+	})
+	tokenString, err := token.SignedString(purposeKey(loginTwoFactorPurpose))
+	if err != nil {
+		return model.LoginChallenge{}, err
+	}
+	return model.LoginChallenge{Token: tokenString, ExpiresAt: expiresAt}, nil
+}
+
+// newRecoveryCode returns 40 random bits as a code such as "k3m9-x2pq".
+func newRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeLength*5/8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[:4] + "-" + code[4:], nil
+}
+
+// normalizeCode drops the separators users type into codes and ignores
+// case.
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"go-rest-api/mailer"
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/totp"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+// mockTwoFactor answers Get for user 1 with twoFactor. Login challenges are
+// stored and accepted, and a wrong code is the first in a row.
+func mockTwoFactor(twoFactor model.TwoFactor) *MockTwoFactorRepository {
+	tfr := &MockTwoFactorRepository{}
+	mockTwoFactorDefaults(tfr, twoFactor)
+	return tfr
+}
+
+// mockTwoFactorDefaults registers the answers of mockTwoFactor on tfr, after
+// any the test registered first.
+func mockTwoFactorDefaults(tfr *MockTwoFactorRepository, twoFactor model.TwoFactor) {
+	tfr.On("Get", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.TwoFactor) = twoFactor
+		}).
+		Return(nil)
+	tfr.On("CreateChallenge", mock.Anything).Return(nil).Maybe()
+	tfr.On("StartAttempt", mock.Anything, uint(1), maxChallengeAttempts).Return(nil).Maybe()
+	tfr.On("UseChallenge", mock.Anything, uint(1)).Return(nil).Maybe()
+	tfr.On("RecordFailure", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*int) = 1
+		}).
+		Return(nil).Maybe()
+}
+
+// challengeJti is the jti the last login challenge was stored by.
+func challengeJti(tfr *MockTwoFactorRepository) string {
+	jti := ""
+	for _, call := range tfr.Calls {
+		if call.Method == "CreateChallenge" {
+			jti = call.Arguments.Get(0).(*model.TwoFactorChallenge).Jti
+		}
+	}
+	return jti
+}
+
+func enabledTwoFactor() model.TwoFactor {
+	enabledAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	return model.TwoFactor{UserId: 1, Secret: testTOTPSecret, EnabledAt: &enabledAt}
+}
+
+// loginChallenge logs user 1 in with the password and returns the challenge.
+func loginChallenge(t *testing.T, uu IUserUsecase, mr *MockUserRepository) string {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	user := model.User{ID: 1, Email: "user@test.com", Password: string(hashedPassword)}
+	mr.On("GetByEmail", mock.Anything, "user@test.com").
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = user
+		}).
+		Return(nil)
+	mr.On("GetByID", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = user
+		}).
+		Return(nil)
+	res, err := uu.Login(model.User{Email: "user@test.com", Password: "password"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, res.Challenge) {
+		return res.Challenge.Token
+	}
+	return ""
+}
+
+func newTwoFactorUserUsecase(mr *MockUserRepository, rr *MockRefreshTokenRepository, tfr *MockTwoFactorRepository) IUserUsecase {
+	mv := newMockUserValidator()
+	mv.On("UserValidate", mock.Anything).Return(nil)
+	return NewUserUsecase(mr, mv, rr, repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), tfr, nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+}
+
+func TestLogin_TwoFactor_Challenge(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	rr := newMockRefreshTokenRepository()
+
+	uu := newTwoFactorUserUsecase(mr, rr, mockTwoFactor(enabledTwoFactor()))
+
+	challenge := loginChallenge(t, uu, mr)
+	assert.NotEmpty(t, challenge)
+	rr.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestLoginTwoFactor_Success(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	rr := newMockRefreshTokenRepository()
+	rr.On("Create", mock.Anything).Return(nil)
+	tfr := mockTwoFactor(enabledTwoFactor())
+	step := totp.Step(time.Now())
+	tfr.On("UseStep", uint(1), mock.Anything).Return(nil)
+
+	uu := newTwoFactorUserUsecase(mr, rr, tfr)
+
+	code, _ := totp.Code(testTOTPSecret, step)
+	tokens, err := uu.LoginTwoFactor(model.LoginChallengeRequest{Token: loginChallenge(t, uu, mr), Code: code})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	tfr.AssertCalled(t, "UseStep", uint(1), mock.Anything)
+	tfr.AssertCalled(t, "UseChallenge", challengeJti(tfr), uint(1))
+	rr.AssertCalled(t, "Create", mock.Anything)
+}
+
+func TestLoginTwoFactor_RecoveryCode_Success(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	rr := newMockRefreshTokenRepository()
+	rr.On("Create", mock.Anything).Return(nil)
+	tfr := mockTwoFactor(enabledTwoFactor())
+	tfr.On("UseRecoveryCode", uint(1), hashToken("abcd2345")).Return(nil)
+
+	uu := newTwoFactorUserUsecase(mr, rr, tfr)
+
+	_, err := uu.LoginTwoFactor(model.LoginChallengeRequest{Token: loginChallenge(t, uu, mr), Code: "ABCD-2345"})
+	assert.NoError(t, err)
+	tfr.AssertCalled(t, "UseRecoveryCode", uint(1), hashToken("abcd2345"))
+}
+
+func TestLoginTwoFactor_InvalidCode_Failure(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	rr := newMockRefreshTokenRepository()
+	tfr := mockTwoFactor(enabledTwoFactor())
+
+	uu := newTwoFactorUserUsecase(mr, rr, tfr)
+
+	code, _ := totp.Code(testTOTPSecret, totp.Step(time.Now())+5)
+	_, err := uu.LoginTwoFactor(model.LoginChallengeRequest{Token: loginChallenge(t, uu, mr), Code: code})
+	assert.ErrorIs(t, err, model.ErrInvalidTwoFactorCode)
+	tfr.AssertCalled(t, "RecordFailure", mock.Anything, uint(1))
+	tfr.AssertNotCalled(t, "UseChallenge", mock.Anything, mock.Anything)
+	rr.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestLoginTwoFactor_ChallengeUsedUp_Failure(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	rr := newMockRefreshTokenRepository()
+	tfr := &MockTwoFactorRepository{}
+	tfr.On("StartAttempt", mock.Anything, uint(1), maxChallengeAttempts).Return(model.ErrInvalidChallenge)
+	mockTwoFactorDefaults(tfr, enabledTwoFactor())
+
+	uu := newTwoFactorUserUsecase(mr, rr, tfr)
+
+	code, _ := totp.Code(testTOTPSecret, totp.Step(time.Now()))
+	_, err := uu.LoginTwoFactor(model.LoginChallengeRequest{Token: loginChallenge(t, uu, mr), Code: code})
+	assert.ErrorIs(t, err, model.ErrInvalidChallenge)
+	tfr.AssertNotCalled(t, "UseStep", mock.Anything, mock.Anything)
+	rr.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestLoginTwoFactor_Lockout(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	rr := newMockRefreshTokenRepository()
+	tfr := &MockTwoFactorRepository{}
+	tfr.On("RecordFailure", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*int) = twoFactorLockoutThreshold + 1
+		}).
+		Return(nil)
+	tfr.On("Lock", uint(1), mock.Anything).Return(nil)
+	mockTwoFactorDefaults(tfr, enabledTwoFactor())
+
+	uu := newTwoFactorUserUsecase(mr, rr, tfr)
+
+	code, _ := totp.Code(testTOTPSecret, totp.Step(time.Now())+5)
+	before := time.Now()
+	_, err := uu.LoginTwoFactor(model.LoginChallengeRequest{Token: loginChallenge(t, uu, mr), Code: code})
+	var lockedErr *model.TwoFactorLockedError
+	if assert.ErrorAs(t, err, &lockedErr) {
+		assert.WithinDuration(t, before.Add(2*twoFactorLockout), lockedErr.Until, time.Second, "the lockout should double after the threshold")
+	}
+	tfr.AssertCalled(t, "Lock", uint(1), lockedErr.Until)
+}
+
+func TestLoginTwoFactor_Locked_Failure(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	mr := newMockUserRepository()
+	rr := newMockRefreshTokenRepository()
+	twoFactor := enabledTwoFactor()
+	lockedUntil := time.Now().Add(time.Minute)
+	twoFactor.LockedUntil = &lockedUntil
+	tfr := mockTwoFactor(twoFactor)
+
+	uu := newTwoFactorUserUsecase(mr, rr, tfr)
+
+	code, _ := totp.Code(testTOTPSecret, totp.Step(time.Now()))
+	_, err := uu.LoginTwoFactor(model.LoginChallengeRequest{Token: loginChallenge(t, uu, mr), Code: code})
+	assert.ErrorIs(t, err, model.ErrTwoFactorLocked)
+	tfr.AssertNotCalled(t, "StartAttempt", mock.Anything, mock.Anything, mock.Anything)
+	tfr.AssertNotCalled(t, "UseStep", mock.Anything, mock.Anything)
+}
+
+func TestLoginTwoFactor_InvalidChallenge_Failure(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+	rr := newMockRefreshTokenRepository()
+	rr.On("Create", mock.Anything).Return(nil)
+
+	uu := newTwoFactorUserUsecase(newMockUserRepository(), rr, mockTwoFactor(enabledTwoFactor()))
+
+	// An access token is signed with SECRET itself and is no challenge.
+	accessToken, _ := uu.(*userUsecase).startSession(1)
+	_, err := uu.LoginTwoFactor(model.LoginChallengeRequest{Token: accessToken.AccessToken, Code: "123456"})
+	assert.ErrorIs(t, err, model.ErrInvalidChallenge)
+}
+
+func TestConfirmTwoFactor_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mockStoredUser(mr)
+	tfr := mockTwoFactor(model.TwoFactor{UserId: 1, Secret: testTOTPSecret})
+	tfr.On("Enable", uint(1), mock.Anything, mock.Anything).Return(nil)
+
+	uu := newTwoFactorUserUsecase(mr, newMockRefreshTokenRepository(), tfr)
+
+	code, _ := totp.Code(testTOTPSecret, totp.Step(time.Now()))
+	res, err := uu.ConfirmTwoFactor(1, model.TwoFactorCodeRequest{Code: code})
+	assert.NoError(t, err)
+	assert.Len(t, res.RecoveryCodes, recoveryCodeCount)
+	hashes := tfr.Calls[1].Arguments.Get(2).([]string)
+	assert.Equal(t, hashToken(normalizeCode(res.RecoveryCodes[0])), hashes[0], "only hashes of the codes should be stored")
+}
+
+func TestConfirmTwoFactor_InvalidCode_Failure(t *testing.T) {
+	tfr := mockTwoFactor(model.TwoFactor{UserId: 1, Secret: testTOTPSecret})
+
+	uu := newTwoFactorUserUsecase(newMockUserRepository(), newMockRefreshTokenRepository(), tfr)
+
+	_, err := uu.ConfirmTwoFactor(1, model.TwoFactorCodeRequest{Code: "000000x"})
+	assert.ErrorIs(t, err, model.ErrInvalidTwoFactorCode)
+	tfr.AssertNotCalled(t, "Enable", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDisableTwoFactor_WrongPassword_Failure(t *testing.T) {
+	mr := newMockUserRepository()
+	mockStoredUser(mr)
+	tfr := mockTwoFactor(enabledTwoFactor())
+
+	uu := newTwoFactorUserUsecase(mr, newMockRefreshTokenRepository(), tfr)
+
+	err := uu.DisableTwoFactor(1, model.TwoFactorDisableRequest{Password: "wrong", Code: "123456"})
+	assert.ErrorIs(t, err, model.ErrWrongPassword)
+	tfr.AssertNotCalled(t, "Disable", mock.Anything)
+}
@@ -1,30 +1,82 @@
 package usecase
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"go-rest-api/mailer"
 	"go-rest-api/model"
+	"go-rest-api/oidc"
 	"go-rest-api/repository"
 	"go-rest-api/validator"
+	"log"
+	"net/url"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// verificationTTL is how long an email verification link can be used.
+	verificationTTL = 24 * time.Hour
+	// passwordResetTTL is how long a password reset link can be used.
+	passwordResetTTL = time.Hour
+
+	verifyEmailPurpose = "verify-email"
 )
 
 type IUserUsecase interface {
 	SignUp(user model.User) (model.UserResponse, error)
-	Login(user model.User) (string, error)
+	Login(user model.User) (model.LoginResult, error)
+	LoginTwoFactor(req model.LoginChallengeRequest) (model.AuthTokens, error)
+	Refresh(refreshToken string) (model.AuthTokens, error)
+	LogOut(accessToken string) error
+	LogOutEverywhere(userId uint) error
+	VerifyEmail(token string) error
+	ResendVerification(userId uint) error
+	ForgotPassword(email string) error
+	ResetPassword(req model.PasswordResetRequest) error
+	ChangePassword(userId uint, sid string, req model.PasswordChangeRequest) error
+	ChangeEmail(userId uint, sid string, req model.EmailChangeRequest) error
+	EnrollTwoFactor(userId uint) (model.TwoFactorEnrollment, error)
+	ConfirmTwoFactor(userId uint, req model.TwoFactorCodeRequest) (model.RecoveryCodesResponse, error)
+	DisableTwoFactor(userId uint, req model.TwoFactorDisableRequest) error
+	StartOIDCLogin(provider string) (model.OIDCLoginStart, error)
+	FinishOIDCLogin(provider string, stateToken string, req model.OIDCCallbackRequest) (model.LoginResult, error)
 }
 
 type userUsecase struct {
-	ur repository.IUserRepository
-	uv validator.IUserValidator
+	ur        repository.IUserRepository
+	uv        validator.IUserValidator
+	rr        repository.IRefreshTokenRepository
+	vr        repository.IRevocationRepository
+	pr        repository.IPasswordResetRepository
+	tfr       repository.ITwoFactorRepository
+	ir        repository.IIdentityRepository
+	m         mailer.Mailer
+	lifetimes model.TokenLifetimes
+	// providers are the OpenID Connect providers users can sign in with,
+	// by the name used in their routes.
+	providers map[string]oidc.Provider
+	// resets tracks the password reset links being created and sent in
+	// the background.
+	resets sync.WaitGroup
 }
 
-func NewUserUsecase(ur repository.IUserRepository, uv validator.IUserValidator) IUserUsecase {
-	return &userUsecase{ur, uv}
+func NewUserUsecase(ur repository.IUserRepository, uv validator.IUserValidator, rr repository.IRefreshTokenRepository, vr repository.IRevocationRepository, pr repository.IPasswordResetRepository, tfr repository.ITwoFactorRepository, ir repository.IIdentityRepository, m mailer.Mailer, lifetimes model.TokenLifetimes, providers map[string]oidc.Provider) IUserUsecase {
+	return &userUsecase{ur: ur, uv: uv, rr: rr, vr: vr, pr: pr, tfr: tfr, ir: ir, m: m, lifetimes: lifetimes, providers: providers}
 }
 
+// SignUp creates an unverified account and mails a verification link to it.
+// The account exists even if the mail cannot be sent, and the user can ask
+// for the link again.
 func (uu *userUsecase) SignUp(user model.User) (model.UserResponse, error) {
 	if err := uu.uv.UserValidate(user); err != nil {
 		return model.UserResponse{}, err
@@ -33,36 +85,458 @@ func (uu *userUsecase) SignUp(user model.User) (model.UserResponse, error) {
 	if err != nil {
 		return model.UserResponse{}, err
 	}
-	newUser := model.User{Email: user.Email, Password: string(hash)}
+	newUser := model.User{Email: user.Email, Password: string(hash), Handle: user.Handle, Timezone: user.Timezone, Role: model.RoleUser}
+	if newUser.Handle != nil && *newUser.Handle == "" {
+		newUser.Handle = nil
+	}
+	if newUser.Timezone == "" {
+		newUser.Timezone = "UTC"
+	}
 	if err := uu.ur.Create(&newUser); err != nil {
 		return model.UserResponse{}, err
 	}
+	if err := uu.sendVerification(newUser); err != nil {
+		log.Printf("send verification email to user %d: %v", newUser.ID, err)
+	}
 	resUser := model.UserResponse{
-		ID:    newUser.ID,
-		Email: newUser.Email,
+		ID:            newUser.ID,
+		Email:         newUser.Email,
+		Handle:        newUser.Handle,
+		Timezone:      newUser.Timezone,
+		EmailVerified: newUser.EmailVerifiedAt != nil,
+		Role:          newUser.Role,
 	}
 	return resUser, nil
 }
 
-func (uu *userUsecase) Login(user model.User) (string, error) {
+// Login starts a session for the user. Users with two-factor
+// authentication get a challenge instead, which LoginTwoFactor turns into a
+// session.
+func (uu *userUsecase) Login(user model.User) (model.LoginResult, error) {
 	if err := uu.uv.UserValidate(user); err != nil {
-		return "", err
+		return model.LoginResult{}, err
 	}
 	storedUser := model.User{}
 	if err := uu.ur.GetByEmail(&storedUser, user.Email); err != nil {
-		return "", err
+		return model.LoginResult{}, err
 	}
 	err := bcrypt.CompareHashAndPassword([]byte(storedUser.Password), []byte(user.Password))
 	if err != nil {
-		return "", err
+		return model.LoginResult{}, err
+	}
+	if storedUser.DisabledAt != nil {
+		return model.LoginResult{}, model.ErrAccountDisabled
+	}
+	return uu.login(storedUser.ID)
+}
+
+// checkEnabled fails with model.ErrAccountDisabled if an admin has disabled
+// the user.
+func (uu *userUsecase) checkEnabled(userId uint) error {
+	user := model.User{}
+	if err := uu.ur.GetByID(&user, userId); err != nil {
+		return err
+	}
+	if user.DisabledAt != nil {
+		return model.ErrAccountDisabled
+	}
+	return nil
+}
+
+// login starts a session for a user who has proven who they are, or a
+// challenge if they have two-factor authentication.
+func (uu *userUsecase) login(userId uint) (model.LoginResult, error) {
+	twoFactor := model.TwoFactor{}
+	err := uu.tfr.Get(&twoFactor, userId)
+	if err == nil && twoFactor.EnabledAt != nil {
+		challenge, err := uu.newLoginChallenge(userId)
+		if err != nil {
+			return model.LoginResult{}, err
+		}
+		return model.LoginResult{Challenge: &challenge}, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.LoginResult{}, err
+	}
+	tokens, err := uu.startSession(userId)
+	if err != nil {
+		return model.LoginResult{}, err
+	}
+	return model.LoginResult{Tokens: tokens}, nil
+}
+
+// startSession starts a new refresh token family for the user. Like Refresh
+// it reads the generation before storing the refresh token.
+func (uu *userUsecase) startSession(userId uint) (model.AuthTokens, error) {
+	familyId, err := newOpaqueToken()
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	var generation uint
+	if err := uu.vr.GetGeneration(&generation, userId); err != nil {
+		return model.AuthTokens{}, err
+	}
+	now := time.Now()
+	stored := model.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		FamilyId:  familyId,
+		UserId:    userId,
+		ExpiresAt: now.Add(uu.lifetimes.Refresh),
+	}
+	if err := uu.rr.Create(&stored); err != nil {
+		return model.AuthTokens{}, err
+	}
+	return uu.newAuthTokens(stored, refreshToken, generation, now)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. The old refresh token cannot be used again. Presenting it a second
+// time revokes every token of its family, which logs out both the thief and
+// the user. The generation is read before rotating, so a log out everywhere
+// that revokes the new refresh token also revokes the new access token.
+func (uu *userUsecase) Refresh(refreshToken string) (model.AuthTokens, error) {
+	if refreshToken == "" {
+		return model.AuthTokens{}, model.ErrInvalidRefreshToken
 	}
+	current := model.RefreshToken{}
+	if err := uu.rr.GetByHash(&current, hashToken(refreshToken)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.AuthTokens{}, model.ErrInvalidRefreshToken
+		}
+		return model.AuthTokens{}, err
+	}
+	var generation uint
+	if err := uu.vr.GetGeneration(&generation, current.UserId); err != nil {
+		return model.AuthTokens{}, err
+	}
+	nextToken, err := newOpaqueToken()
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	now := time.Now()
+	next := model.RefreshToken{
+		TokenHash: hashToken(nextToken),
+		ExpiresAt: now.Add(uu.lifetimes.Refresh),
+	}
+	if err := uu.rr.Rotate(&next, hashToken(refreshToken)); err != nil {
+		return model.AuthTokens{}, err
+	}
+	return uu.newAuthTokens(next, nextToken, generation, now)
+}
+
+// LogOut revokes the access token and ends the session it belongs to. The
+// token may have expired already, but it must carry a valid signature. A
+// token that cannot be parsed has nothing to revoke.
+func (uu *userUsecase) LogOut(accessToken string) error {
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}, SkipClaimsValidation: true}
+	token, err := parser.Parse(accessToken, func(*jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("SECRET")), nil
+	})
+	if err != nil {
+		return nil
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if sid, ok := claims["sid"].(string); ok {
+		if err := uu.rr.RevokeFamily(sid); err != nil {
+			return err
+		}
+	}
+	jti, _ := claims["jti"].(string)
+	userId, _ := claims["userId"].(float64)
+	exp, _ := claims["exp"].(float64)
+	expiresAt := time.Unix(int64(exp), 0)
+	if jti == "" || !expiresAt.After(time.Now()) {
+		return nil
+	}
+	return uu.vr.RevokeToken(&model.RevokedToken{Jti: jti, UserId: uint(userId), ExpiresAt: expiresAt})
+}
+
+// LogOutEverywhere ends every session of the user and revokes every access
+// token issued so far by starting a new generation. Sessions are ended first
+// so that an access token signed in between carries the old generation.
+func (uu *userUsecase) LogOutEverywhere(userId uint) error {
+	if err := uu.rr.RevokeUser(userId); err != nil {
+		return err
+	}
+	return uu.vr.RevokeUser(userId)
+}
+
+// VerifyEmail verifies the address a verification link was sent to. A link
+// works once, and only while the address is still the user's.
+func (uu *userUsecase) VerifyEmail(token string) error {
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}}
+	parsed, err := parser.Parse(token, func(*jwt.Token) (interface{}, error) {
+		return purposeKey(verifyEmailPurpose), nil
+	})
+	if err != nil {
+		return model.ErrInvalidVerification
+	}
+	claims := parsed.Claims.(jwt.MapClaims)
+	userId, _ := claims["userId"].(float64)
+	email, _ := claims["email"].(string)
+	if userId == 0 || email == "" {
+		return model.ErrInvalidVerification
+	}
+	return uu.ur.VerifyEmail(uint(userId), email)
+}
+
+// ResendVerification sends a new link for the pending address of the user,
+// or else for their current address if it is unverified.
+func (uu *userUsecase) ResendVerification(userId uint) error {
+	user := model.User{}
+	if err := uu.ur.GetByID(&user, userId); err != nil {
+		return err
+	}
+	if user.PendingEmail != nil {
+		return uu.sendVerification(model.User{ID: user.ID, Email: *user.PendingEmail})
+	}
+	if user.EmailVerifiedAt != nil {
+		return model.ErrEmailAlreadyVerified
+	}
+	return uu.sendVerification(user)
+}
+
+// ForgotPassword mails a password reset link if a user has the address.
+// Whether one does is not revealed to the caller: the error is nil for
+// unknown addresses, and the link is created and sent in the background so
+// the response takes as long either way. A link that cannot be sent is only
+// logged.
+func (uu *userUsecase) ForgotPassword(email string) error {
+	user := model.User{}
+	if err := uu.ur.GetByEmail(&user, email); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	uu.resets.Add(1)
+	go func() {
+		defer uu.resets.Done()
+		uu.sendPasswordReset(user)
+	}()
+	return nil
+}
+
+// sendPasswordReset stores the hash of a new reset token for user and mails
+// them a link with the token.
+func (uu *userUsecase) sendPasswordReset(user model.User) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		log.Printf("create password reset for user %d: %v", user.ID, err)
+		return
+	}
+	stored := model.PasswordResetToken{
+		TokenHash: hashToken(token),
+		UserId:    user.ID,
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+	if err := uu.pr.Create(&stored); err != nil {
+		log.Printf("create password reset for user %d: %v", user.ID, err)
+		return
+	}
+	link := os.Getenv("FE_URL") + "/reset-password?token=" + url.QueryEscape(token)
+	err = uu.m.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    "Open this link to choose a new password:\n\n" + link + "\n\nThe link expires in 1 hour. If you did not ask for it, you can ignore this email.",
+	})
+	if err != nil {
+		log.Printf("send password reset email to user %d: %v", user.ID, err)
+	}
+}
+
+// ResetPassword sets the password of the user a reset link was mailed to and
+// logs them out everywhere. The password is validated before the link is
+// used up, so a rejected password can be retried with the same link.
+func (uu *userUsecase) ResetPassword(req model.PasswordResetRequest) error {
+	if err := uu.uv.PasswordValidate(req.Password); err != nil {
+		return err
+	}
+	if req.Token == "" {
+		return model.ErrInvalidPasswordReset
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	token := model.PasswordResetToken{}
+	if err := uu.pr.Consume(&token, hashToken(req.Token)); err != nil {
+		return err
+	}
+	if err := uu.ur.UpdatePassword(token.UserId, string(hash)); err != nil {
+		return err
+	}
+	return uu.LogOutEverywhere(token.UserId)
+}
+
+// ChangePassword replaces the password of the user, who must know the
+// current one, and ends every session but sid.
+func (uu *userUsecase) ChangePassword(userId uint, sid string, req model.PasswordChangeRequest) error {
+	user := model.User{}
+	if err := uu.ur.GetByID(&user, userId); err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return model.ErrWrongPassword
+	}
+	if err := uu.uv.PasswordValidate(req.NewPassword); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := uu.ur.UpdatePassword(userId, string(hash)); err != nil {
+		return err
+	}
+	if err := uu.revokeOtherSessions(userId, sid); err != nil {
+		return err
+	}
+	uu.notify(user, mailer.Message{
+		To:      user.Email,
+		Subject: "Your password was changed",
+		Body:    "The password of your account was just changed, and your other sessions were logged out.\n\nIf you did not do this, reset your password right away.",
+	})
+	return nil
+}
+
+// ChangeEmail mails a verification link to the new address, which replaces
+// the current one once it is verified. The current address is told about the
+// change, and every session but sid ends.
+func (uu *userUsecase) ChangeEmail(userId uint, sid string, req model.EmailChangeRequest) error {
+	user := model.User{}
+	if err := uu.ur.GetByID(&user, userId); err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return model.ErrWrongPassword
+	}
+	if err := uu.uv.EmailValidate(req.Email); err != nil {
+		return err
+	}
+	if req.Email == user.Email {
+		return model.ErrEmailTaken
+	}
+	if err := uu.ur.GetByEmail(&model.User{}, req.Email); err == nil {
+		return model.ErrEmailTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err := uu.ur.SetPendingEmail(userId, req.Email); err != nil {
+		return err
+	}
+	if err := uu.sendVerification(model.User{ID: userId, Email: req.Email}); err != nil {
+		return err
+	}
+	if err := uu.revokeOtherSessions(userId, sid); err != nil {
+		return err
+	}
+	uu.notify(user, mailer.Message{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body:    "A change of your account's email address to " + req.Email + " was just requested, and your other sessions were logged out. The change takes effect once the new address is verified.\n\nIf you did not do this, reset your password right away.",
+	})
+	return nil
+}
+
+// revokeOtherSessions ends every session of the user but sid, along with the
+// access tokens issued to them.
+func (uu *userUsecase) revokeOtherSessions(userId uint, sid string) error {
+	var familyIds []string
+	if err := uu.rr.RevokeOtherFamilies(&familyIds, userId, sid); err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(uu.lifetimes.Access)
+	sessions := []model.RevokedSession{}
+	for _, familyId := range familyIds {
+		sessions = append(sessions, model.RevokedSession{Sid: familyId, UserId: userId, ExpiresAt: expiresAt})
+	}
+	return uu.vr.RevokeSessions(sessions)
+}
+
+// notify mails a security notice. The change it reports has already been
+// made, so a notice that cannot be sent is only logged.
+func (uu *userUsecase) notify(user model.User, msg mailer.Message) {
+	if err := uu.m.Send(msg); err != nil {
+		log.Printf("send security notice to user %d: %v", user.ID, err)
+	}
+}
+
+// sendVerification mails the user a link to the frontend carrying a signed
+// token for their current address.
+func (uu *userUsecase) sendVerification(user model.User) error {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"userId": storedUser.ID,
-		"exp":    time.Now().Add(time.Hour * 12).Unix(),
+		"userId": user.ID,
+		"email":  user.Email,
+		"exp":    time.Now().Add(verificationTTL).Unix(),
+	})
+	tokenString, err := token.SignedString(purposeKey(verifyEmailPurpose))
+	if err != nil {
+		return err
+	}
+	link := os.Getenv("FE_URL") + "/verify-email?token=" + url.QueryEscape(tokenString)
+	return uu.m.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    "Open this link to verify your email address:\n\n" + link + "\n\nThe link expires in 24 hours.",
+	})
+}
+
+// purposeKey signs tokens that are not access tokens, such as verification
+// links. It is derived from SECRET and purpose so that such a token is never
+// accepted as an access token or for another purpose.
+func purposeKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("SECRET")))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// newAuthTokens signs an access token for the session of refresh. The sid
+// claim ties the access token to the refresh token family so that logging
+// out with it ends the session, and the gen claim to the generation of the
+// user so that logging out everywhere revokes it.
+func (uu *userUsecase) newAuthTokens(refresh model.RefreshToken, refreshToken string, generation uint, now time.Time) (model.AuthTokens, error) {
+	jti, err := newOpaqueToken()
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	accessExpiresAt := now.Add(uu.lifetimes.Access)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userId": refresh.UserId,
+		"jti":    jti,
+		"sid":    refresh.FamilyId,
+		"gen":    generation,
+		"iat":    now.Unix(),
+		"exp":    accessExpiresAt.Unix(),
 	})
 	tokenString, err := token.SignedString([]byte(os.Getenv("SECRET")))
 	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	return model.AuthTokens{
+		AccessToken:      tokenString,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
+}
+
+// newOpaqueToken returns 256 random bits encoded for use in a cookie.
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
 		return "", err
 	}
-	return tokenString, nil
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is how refresh, password reset and personal access tokens are
+// stored. The tokens are random, so an unsalted fast hash is enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
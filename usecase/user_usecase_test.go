@@ -2,13 +2,20 @@ package usecase
 
 import (
 	"errors"
+	"go-rest-api/mailer"
 	"go-rest-api/model"
+	"go-rest-api/repository"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type MockUserRepository struct {
@@ -20,11 +27,51 @@ func (mr *MockUserRepository) GetByEmail(user *model.User, email string) error {
 	return args.Error(0)
 }
 
+func (mr *MockUserRepository) GetByID(user *model.User, userId uint) error {
+	args := mr.Called(user, userId)
+	return args.Error(0)
+}
+
+func (mr *MockUserRepository) GetByMentions(users *[]model.User, workspaceId uint, emails []string, handles []string) error {
+	args := mr.Called(users, workspaceId, emails, handles)
+	return args.Error(0)
+}
+
 func (mr *MockUserRepository) Create(user *model.User) error {
 	args := mr.Called(user)
 	return args.Error(0)
 }
 
+func (mr *MockUserRepository) VerifyEmail(userId uint, email string) error {
+	args := mr.Called(userId, email)
+	return args.Error(0)
+}
+
+func (mr *MockUserRepository) UpdatePassword(userId uint, password string) error {
+	args := mr.Called(userId, password)
+	return args.Error(0)
+}
+
+func (mr *MockUserRepository) SetPendingEmail(userId uint, email string) error {
+	args := mr.Called(userId, email)
+	return args.Error(0)
+}
+
+func (mr *MockUserRepository) GetAll(users *[]model.User) error {
+	args := mr.Called(users)
+	return args.Error(0)
+}
+
+func (mr *MockUserRepository) SetDisabled(userId uint, disabledAt *time.Time) error {
+	args := mr.Called(userId, disabledAt)
+	return args.Error(0)
+}
+
+func (mr *MockUserRepository) SetRole(userId uint, role string) error {
+	args := mr.Called(userId, role)
+	return args.Error(0)
+}
+
 func newMockUserRepository() *MockUserRepository {
 	return &MockUserRepository{}
 }
@@ -38,24 +85,164 @@ func (mv *MockUserValidator) UserValidate(user model.User) error {
 	return args.Error(0)
 }
 
+func (mv *MockUserValidator) PasswordValidate(password string) error {
+	args := mv.Called(password)
+	return args.Error(0)
+}
+
+func (mv *MockUserValidator) EmailValidate(email string) error {
+	args := mv.Called(email)
+	return args.Error(0)
+}
+
 func newMockUserValidator() *MockUserValidator {
 	return &MockUserValidator{}
 }
 
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (mr *MockRefreshTokenRepository) RevokeOtherFamilies(familyIds *[]string, userId uint, keepFamilyId string) error {
+	args := mr.Called(familyIds, userId, keepFamilyId)
+	return args.Error(0)
+}
+
+func newMockRefreshTokenRepository() *MockRefreshTokenRepository {
+	return &MockRefreshTokenRepository{}
+}
+
+func (mr *MockRefreshTokenRepository) Create(token *model.RefreshToken) error {
+	args := mr.Called(token)
+	return args.Error(0)
+}
+
+func (mr *MockRefreshTokenRepository) GetByHash(token *model.RefreshToken, tokenHash string) error {
+	args := mr.Called(token, tokenHash)
+	return args.Error(0)
+}
+
+func (mr *MockRefreshTokenRepository) Rotate(next *model.RefreshToken, tokenHash string) error {
+	args := mr.Called(next, tokenHash)
+	return args.Error(0)
+}
+
+func (mr *MockRefreshTokenRepository) RevokeFamily(familyId string) error {
+	args := mr.Called(familyId)
+	return args.Error(0)
+}
+
+func (mr *MockRefreshTokenRepository) RevokeUser(userId uint) error {
+	args := mr.Called(userId)
+	return args.Error(0)
+}
+
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func newMockPasswordResetRepository() *MockPasswordResetRepository {
+	return &MockPasswordResetRepository{}
+}
+
+func (mr *MockPasswordResetRepository) Create(token *model.PasswordResetToken) error {
+	args := mr.Called(token)
+	return args.Error(0)
+}
+
+func (mr *MockPasswordResetRepository) Consume(token *model.PasswordResetToken, tokenHash string) error {
+	args := mr.Called(token, tokenHash)
+	return args.Error(0)
+}
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+// newMockTwoFactorRepository starts out with no user enrolled.
+func newMockTwoFactorRepository() *MockTwoFactorRepository {
+	tfr := &MockTwoFactorRepository{}
+	tfr.On("Get", mock.Anything, mock.Anything).Return(gorm.ErrRecordNotFound).Maybe()
+	return tfr
+}
+
+func (mr *MockTwoFactorRepository) Get(twoFactor *model.TwoFactor, userId uint) error {
+	args := mr.Called(twoFactor, userId)
+	return args.Error(0)
+}
+
+func (mr *MockTwoFactorRepository) Enroll(twoFactor *model.TwoFactor) error {
+	args := mr.Called(twoFactor)
+	return args.Error(0)
+}
+
+func (mr *MockTwoFactorRepository) Enable(userId uint, step int64, codeHashes []string) error {
+	args := mr.Called(userId, step, codeHashes)
+	return args.Error(0)
+}
+
+func (mr *MockTwoFactorRepository) Disable(userId uint) error {
+	args := mr.Called(userId)
+	return args.Error(0)
+}
+
+func (mr *MockTwoFactorRepository) UseStep(userId uint, step int64) error {
+	args := mr.Called(userId, step)
+	return args.Error(0)
+}
+
+func (mr *MockTwoFactorRepository) UseRecoveryCode(userId uint, codeHash string) error {
+	args := mr.Called(userId, codeHash)
+	return args.Error(0)
+}
+
+func (mr *MockTwoFactorRepository) CreateChallenge(challenge *model.TwoFactorChallenge) error {
+	args := mr.Called(challenge)
+	return args.Error(0)
+}
+
+func (mr *MockTwoFactorRepository) StartAttempt(jti string, userId uint, maxAttempts int) error {
+	args := mr.Called(jti, userId, maxAttempts)
+	return args.Error(0)
+}
+
+func (mr *MockTwoFactorRepository) UseChallenge(jti string, userId uint) error {
+	args := mr.Called(jti, userId)
+	return args.Error(0)
+}
+
+func (mr *MockTwoFactorRepository) RecordFailure(failures *int, userId uint) error {
+	args := mr.Called(failures, userId)
+	return args.Error(0)
+}
+
+func (mr *MockTwoFactorRepository) Lock(userId uint, until time.Time) error {
+	args := mr.Called(userId, until)
+	return args.Error(0)
+}
+
+var testTokenLifetimes = model.TokenLifetimes{Access: 15 * time.Minute, Refresh: 24 * time.Hour}
+
 func TestSignUp_Success(t *testing.T) {
 	mr := newMockUserRepository()
 	mv := newMockUserValidator()
 	mr.On("Create", mock.Anything).Return(nil)
 	mv.On("UserValidate", mock.Anything).Return(nil)
+	m := mailer.NewMemoryMailer()
 
-	uu := NewUserUsecase(mr, mv)
+	uu := NewUserUsecase(mr, mv, newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, m, testTokenLifetimes, nil)
 
 	res, err := uu.SignUp(model.User{Email: "user@test.com", Password: "password"})
 
 	assert.NoError(t, err)
 	assert.Equal(t, "user@test.com", res.Email, "Email should match the input email")
+	assert.False(t, res.EmailVerified)
 	mv.AssertCalled(t, "UserValidate", mock.Anything)
 	mr.AssertCalled(t, "Create", mock.Anything)
+	if assert.Len(t, m.Messages(), 1) {
+		assert.Equal(t, "user@test.com", m.Messages()[0].To)
+		assert.Contains(t, m.Messages()[0].Body, "/verify-email?token=")
+	}
 }
 
 func TestSignUp_Respository_Failed(t *testing.T) {
@@ -64,7 +251,7 @@ func TestSignUp_Respository_Failed(t *testing.T) {
 	mr.On("Create", mock.Anything).Return(nil)
 	mv.On("UserValidate", mock.Anything).Return(errors.New("error"))
 
-	uu := NewUserUsecase(mr, mv)
+	uu := NewUserUsecase(mr, mv, newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
 
 	_, err := uu.SignUp(model.User{Email: "user@test.com", Password: "password"})
 	assert.Error(t, err)
@@ -76,7 +263,7 @@ func TestSignUp_Validator_Failed(t *testing.T) {
 	mr.On("Create", mock.Anything).Return(errors.New("error"))
 	mv.On("UserValidate", mock.Anything).Return(nil)
 
-	uu := NewUserUsecase(mr, mv)
+	uu := NewUserUsecase(mr, mv, newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
 
 	_, err := uu.SignUp(model.User{Email: "user@test.com", Password: "password"})
 	assert.Error(t, err)
@@ -100,17 +287,26 @@ func TestLogin_Success(t *testing.T) {
 		}).
 		Return(nil)
 
-	uu := NewUserUsecase(mr, mv)
+	rr := newMockRefreshTokenRepository()
+	rr.On("Create", mock.Anything).Return(nil)
+
+	uu := NewUserUsecase(mr, mv, rr, repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
 
 	os.Setenv("SECRET", "testsecret")
 	defer os.Unsetenv("SECRET")
 
-	token, err := uu.Login(model.User{Email: "user@test.com", Password: "password"})
+	res, err := uu.Login(model.User{Email: "user@test.com", Password: "password"})
+	tokens := res.Tokens
 
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token, "Token should not be empty")
+	assert.NotEmpty(t, tokens.AccessToken, "Token should not be empty")
+	assert.NotEmpty(t, tokens.RefreshToken, "Refresh token should not be empty")
 	mv.AssertCalled(t, "UserValidate", mock.Anything)
 	mr.AssertCalled(t, "GetByEmail", mock.AnythingOfType("*model.User"), "user@test.com")
+	stored := rr.Calls[0].Arguments.Get(0).(*model.RefreshToken)
+	assert.Equal(t, hashToken(tokens.RefreshToken), stored.TokenHash)
+	assert.Equal(t, uint(1), stored.UserId)
+	assert.NotEmpty(t, stored.FamilyId)
 }
 
 func TestLogin_Fail_UserValidateError(t *testing.T) {
@@ -118,7 +314,7 @@ func TestLogin_Fail_UserValidateError(t *testing.T) {
 	mv := newMockUserValidator()
 	mv.On("UserValidate", mock.Anything).Return(errors.New("validation error"))
 
-	uu := NewUserUsecase(mr, mv)
+	uu := NewUserUsecase(mr, mv, newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
 
 	_, err := uu.Login(model.User{Email: "user@test.com", Password: "password"})
 
@@ -135,7 +331,7 @@ func TestLogin_Fail_UserNotFound(t *testing.T) {
 	mv.On("UserValidate", mock.Anything).Return(nil)
 	mr.On("GetByEmail", mock.Anything, "user@test.com").Return(errors.New("user not found"))
 
-	uu := NewUserUsecase(mr, mv)
+	uu := NewUserUsecase(mr, mv, newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
 
 	_, err := uu.Login(model.User{Email: "user@test.com", Password: "password"})
 
@@ -164,7 +360,7 @@ func TestLogin_PassMisMatch_Failure(t *testing.T) {
 		}).
 		Return(nil)
 
-	uu := NewUserUsecase(mr, mv)
+	uu := NewUserUsecase(mr, mv, newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
 
 	os.Setenv("SECRET", "testsecret")
 	defer os.Unsetenv("SECRET")
@@ -173,3 +369,427 @@ func TestLogin_PassMisMatch_Failure(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestRefresh_Success(t *testing.T) {
+	rr := newMockRefreshTokenRepository()
+	rr.On("GetByHash", mock.Anything, hashToken("old-token")).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.RefreshToken).UserId = 1
+		}).
+		Return(nil)
+	rr.On("Rotate", mock.Anything, hashToken("old-token")).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.RefreshToken).UserId = 1
+		}).
+		Return(nil)
+
+	uu := NewUserUsecase(newMockUserRepository(), newMockUserValidator(), rr, repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+
+	tokens, err := uu.Refresh("old-token")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEqual(t, "old-token", tokens.RefreshToken)
+	next := rr.Calls[1].Arguments.Get(0).(*model.RefreshToken)
+	assert.Equal(t, hashToken(tokens.RefreshToken), next.TokenHash)
+	assert.Equal(t, next.ExpiresAt, tokens.RefreshExpiresAt)
+}
+
+func TestRefresh_Reused_Failure(t *testing.T) {
+	rr := newMockRefreshTokenRepository()
+	rr.On("GetByHash", mock.Anything, hashToken("used-token")).Return(nil)
+	rr.On("Rotate", mock.Anything, hashToken("used-token")).Return(model.ErrRefreshTokenReused)
+
+	uu := NewUserUsecase(newMockUserRepository(), newMockUserValidator(), rr, repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	_, err := uu.Refresh("used-token")
+	assert.ErrorIs(t, err, model.ErrRefreshTokenReused)
+}
+
+func TestRefresh_Empty_Failure(t *testing.T) {
+	rr := newMockRefreshTokenRepository()
+
+	uu := NewUserUsecase(newMockUserRepository(), newMockUserValidator(), rr, repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	_, err := uu.Refresh("")
+	assert.ErrorIs(t, err, model.ErrInvalidRefreshToken)
+	rr.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything)
+}
+
+func TestLogOut_Success(t *testing.T) {
+	rr := newMockRefreshTokenRepository()
+	rr.On("Create", mock.Anything).Return(nil)
+	rr.On("RevokeFamily", mock.Anything).Return(nil)
+	vr := repository.NewMemoryRevocationRepository()
+	mr := newMockUserRepository()
+	mv := newMockUserValidator()
+	mv.On("UserValidate", mock.Anything).Return(nil)
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	mr.On("GetByEmail", mock.Anything, "user@test.com").
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = model.User{ID: 1, Email: "user@test.com", Password: string(hashedPassword)}
+		}).
+		Return(nil)
+
+	uu := NewUserUsecase(mr, mv, rr, vr, newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+
+	res, _ := uu.Login(model.User{Email: "user@test.com", Password: "password"})
+	tokens := res.Tokens
+	stored := rr.Calls[0].Arguments.Get(0).(*model.RefreshToken)
+
+	err := uu.LogOut(tokens.AccessToken)
+	assert.NoError(t, err)
+	rr.AssertCalled(t, "RevokeFamily", stored.FamilyId)
+	token, _ := jwt.Parse(tokens.AccessToken, func(*jwt.Token) (interface{}, error) { return []byte("testsecret"), nil })
+	revoked, _ := vr.IsRevoked(token.Claims.(jwt.MapClaims)["jti"].(string), "", 1, 0)
+	assert.True(t, revoked)
+}
+
+func TestLogOut_InvalidToken_Success(t *testing.T) {
+	rr := newMockRefreshTokenRepository()
+
+	uu := NewUserUsecase(newMockUserRepository(), newMockUserValidator(), rr, repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	err := uu.LogOut("not-a-token")
+	assert.NoError(t, err)
+	rr.AssertNotCalled(t, "RevokeFamily", mock.Anything)
+}
+
+func TestLogOutEverywhere_Success(t *testing.T) {
+	rr := newMockRefreshTokenRepository()
+	rr.On("RevokeUser", uint(1)).Return(nil)
+	vr := repository.NewMemoryRevocationRepository()
+
+	uu := NewUserUsecase(newMockUserRepository(), newMockUserValidator(), rr, vr, newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	err := uu.LogOutEverywhere(1)
+	assert.NoError(t, err)
+	revoked, _ := vr.IsRevoked("", "", 1, 0)
+	assert.True(t, revoked)
+	revoked, _ = vr.IsRevoked("", "", 2, 0)
+	assert.False(t, revoked)
+}
+
+func TestLogOutEverywhere_NewSessionValid(t *testing.T) {
+	rr := newMockRefreshTokenRepository()
+	rr.On("RevokeUser", uint(1)).Return(nil)
+	rr.On("Create", mock.Anything).Return(nil)
+	vr := repository.NewMemoryRevocationRepository()
+	mr := newMockUserRepository()
+	mv := newMockUserValidator()
+	mv.On("UserValidate", mock.Anything).Return(nil)
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	mr.On("GetByEmail", mock.Anything, "user@test.com").
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = model.User{ID: 1, Email: "user@test.com", Password: string(hashedPassword)}
+		}).
+		Return(nil)
+
+	uu := NewUserUsecase(mr, mv, rr, vr, newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+
+	// A login in the same second as the log out must not be revoked.
+	assert.NoError(t, uu.LogOutEverywhere(1))
+	res, err := uu.Login(model.User{Email: "user@test.com", Password: "password"})
+	assert.NoError(t, err)
+	token, _ := jwt.Parse(res.Tokens.AccessToken, func(*jwt.Token) (interface{}, error) { return []byte("testsecret"), nil })
+	claims := token.Claims.(jwt.MapClaims)
+	assert.Equal(t, 1.0, claims["gen"])
+	revoked, _ := vr.IsRevoked(claims["jti"].(string), claims["sid"].(string), 1, uint(claims["gen"].(float64)))
+	assert.False(t, revoked)
+}
+
+// verificationToken pulls the token out of the link in a verification mail.
+func verificationToken(t *testing.T, msg mailer.Message) string {
+	_, rest, found := strings.Cut(msg.Body, "/verify-email?token=")
+	assert.True(t, found)
+	token, _, _ := strings.Cut(rest, "\n")
+	token, err := url.QueryUnescape(token)
+	assert.NoError(t, err)
+	return token
+}
+
+func TestVerifyEmail_Success(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+
+	mr := newMockUserRepository()
+	mr.On("GetByID", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = model.User{ID: 1, Email: "user@test.com"}
+		}).
+		Return(nil)
+	mr.On("VerifyEmail", uint(1), "user@test.com").Return(nil)
+	m := mailer.NewMemoryMailer()
+
+	uu := NewUserUsecase(mr, newMockUserValidator(), newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, m, testTokenLifetimes, nil)
+
+	assert.NoError(t, uu.ResendVerification(1))
+	err := uu.VerifyEmail(verificationToken(t, m.Messages()[0]))
+	assert.NoError(t, err)
+	mr.AssertCalled(t, "VerifyEmail", uint(1), "user@test.com")
+}
+
+func TestVerifyEmail_AccessToken_Failure(t *testing.T) {
+	os.Setenv("SECRET", "testsecret")
+	defer os.Unsetenv("SECRET")
+
+	mr := newMockUserRepository()
+	accessToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userId": 1,
+		"email":  "user@test.com",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("testsecret"))
+
+	uu := NewUserUsecase(mr, newMockUserValidator(), newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	err := uu.VerifyEmail(accessToken)
+	assert.ErrorIs(t, err, model.ErrInvalidVerification)
+	mr.AssertNotCalled(t, "VerifyEmail", mock.Anything, mock.Anything)
+}
+
+func TestResendVerification_AlreadyVerified_Failure(t *testing.T) {
+	verifiedAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	mr := newMockUserRepository()
+	mr.On("GetByID", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = model.User{ID: 1, Email: "user@test.com", EmailVerifiedAt: &verifiedAt}
+		}).
+		Return(nil)
+	m := mailer.NewMemoryMailer()
+
+	uu := NewUserUsecase(mr, newMockUserValidator(), newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, m, testTokenLifetimes, nil)
+
+	err := uu.ResendVerification(1)
+	assert.ErrorIs(t, err, model.ErrEmailAlreadyVerified)
+	assert.Empty(t, m.Messages())
+}
+
+func TestForgotPassword_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mr.On("GetByEmail", mock.Anything, "user@test.com").
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = model.User{ID: 1, Email: "user@test.com"}
+		}).
+		Return(nil)
+	pr := newMockPasswordResetRepository()
+	pr.On("Create", mock.Anything).Return(nil)
+	m := mailer.NewMemoryMailer()
+
+	uu := NewUserUsecase(mr, newMockUserValidator(), newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), pr, newMockTwoFactorRepository(), nil, m, testTokenLifetimes, nil)
+
+	err := uu.ForgotPassword("user@test.com")
+	assert.NoError(t, err)
+	uu.(*userUsecase).resets.Wait()
+	stored := pr.Calls[0].Arguments.Get(0).(*model.PasswordResetToken)
+	assert.Equal(t, uint(1), stored.UserId)
+	if assert.Len(t, m.Messages(), 1) {
+		_, rest, _ := strings.Cut(m.Messages()[0].Body, "/reset-password?token=")
+		token, _, _ := strings.Cut(rest, "\n")
+		assert.Equal(t, hashToken(token), stored.TokenHash, "only the hash of the mailed token should be stored")
+	}
+}
+
+func TestForgotPassword_DoesNotWaitForMail_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mr.On("GetByEmail", mock.Anything, "user@test.com").
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = model.User{ID: 1, Email: "user@test.com"}
+		}).
+		Return(nil)
+	release := make(chan time.Time)
+	pr := newMockPasswordResetRepository()
+	pr.On("Create", mock.Anything).WaitUntil(release).Return(nil)
+	m := mailer.NewMemoryMailer()
+
+	uu := NewUserUsecase(mr, newMockUserValidator(), newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), pr, newMockTwoFactorRepository(), nil, m, testTokenLifetimes, nil)
+
+	err := uu.ForgotPassword("user@test.com")
+	assert.NoError(t, err, "the response should not wait for the link to be stored and sent")
+	close(release)
+	uu.(*userUsecase).resets.Wait()
+	assert.Len(t, m.Messages(), 1)
+}
+
+func TestForgotPassword_UnknownEmail_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mr.On("GetByEmail", mock.Anything, "nobody@test.com").Return(gorm.ErrRecordNotFound)
+	pr := newMockPasswordResetRepository()
+	m := mailer.NewMemoryMailer()
+
+	uu := NewUserUsecase(mr, newMockUserValidator(), newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), pr, newMockTwoFactorRepository(), nil, m, testTokenLifetimes, nil)
+
+	err := uu.ForgotPassword("nobody@test.com")
+	assert.NoError(t, err)
+	uu.(*userUsecase).resets.Wait()
+	pr.AssertNotCalled(t, "Create", mock.Anything)
+	assert.Empty(t, m.Messages())
+}
+
+func TestResetPassword_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mr.On("UpdatePassword", uint(1), mock.Anything).Return(nil)
+	mv := newMockUserValidator()
+	mv.On("PasswordValidate", "newpassword").Return(nil)
+	pr := newMockPasswordResetRepository()
+	pr.On("Consume", mock.Anything, hashToken("reset-token")).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.PasswordResetToken).UserId = 1
+		}).
+		Return(nil)
+	rr := newMockRefreshTokenRepository()
+	rr.On("RevokeUser", uint(1)).Return(nil)
+	vr := repository.NewMemoryRevocationRepository()
+
+	uu := NewUserUsecase(mr, mv, rr, vr, pr, newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	err := uu.ResetPassword(model.PasswordResetRequest{Token: "reset-token", Password: "newpassword"})
+	assert.NoError(t, err)
+	hash := mr.Calls[0].Arguments.Get(1).(string)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("newpassword")))
+	rr.AssertCalled(t, "RevokeUser", uint(1))
+	revoked, _ := vr.IsRevoked("", "", 1, 0)
+	assert.True(t, revoked)
+	var generation uint
+	vr.GetGeneration(&generation, 1)
+	revoked, _ = vr.IsRevoked("", "", 1, generation)
+	assert.False(t, revoked, "sessions started right after the reset must stay valid")
+}
+
+func TestResetPassword_InvalidPassword_Failure(t *testing.T) {
+	mv := newMockUserValidator()
+	mv.On("PasswordValidate", "pass").Return(errors.New("password: limited min 6 max 30 char."))
+	pr := newMockPasswordResetRepository()
+
+	uu := NewUserUsecase(newMockUserRepository(), mv, newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), pr, newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	err := uu.ResetPassword(model.PasswordResetRequest{Token: "reset-token", Password: "pass"})
+	assert.Error(t, err)
+	pr.AssertNotCalled(t, "Consume", mock.Anything, mock.Anything)
+}
+
+// mockStoredUser answers GetByID for user 1 with the password "password".
+func mockStoredUser(mr *MockUserRepository) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	mr.On("GetByID", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = model.User{ID: 1, Email: "user@test.com", Password: string(hashedPassword)}
+		}).
+		Return(nil)
+}
+
+// mockOtherSessions makes phone the only other session of user 1.
+func mockOtherSessions(rr *MockRefreshTokenRepository) {
+	rr.On("RevokeOtherFamilies", mock.Anything, uint(1), "laptop").
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]string) = []string{"phone"}
+		}).
+		Return(nil)
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mockStoredUser(mr)
+	mr.On("UpdatePassword", uint(1), mock.Anything).Return(nil)
+	mv := newMockUserValidator()
+	mv.On("PasswordValidate", "newpassword").Return(nil)
+	rr := newMockRefreshTokenRepository()
+	mockOtherSessions(rr)
+	vr := repository.NewMemoryRevocationRepository()
+	m := mailer.NewMemoryMailer()
+
+	uu := NewUserUsecase(mr, mv, rr, vr, newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, m, testTokenLifetimes, nil)
+
+	err := uu.ChangePassword(1, "laptop", model.PasswordChangeRequest{CurrentPassword: "password", NewPassword: "newpassword"})
+	assert.NoError(t, err)
+	mr.AssertCalled(t, "UpdatePassword", uint(1), mock.Anything)
+	revoked, _ := vr.IsRevoked("", "phone", 1, 0)
+	assert.True(t, revoked)
+	revoked, _ = vr.IsRevoked("", "laptop", 1, 0)
+	assert.False(t, revoked)
+	if assert.Len(t, m.Messages(), 1) {
+		assert.Equal(t, "user@test.com", m.Messages()[0].To)
+	}
+}
+
+func TestChangePassword_WrongPassword_Failure(t *testing.T) {
+	mr := newMockUserRepository()
+	mockStoredUser(mr)
+	rr := newMockRefreshTokenRepository()
+
+	uu := NewUserUsecase(mr, newMockUserValidator(), rr, repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	err := uu.ChangePassword(1, "laptop", model.PasswordChangeRequest{CurrentPassword: "wrong", NewPassword: "newpassword"})
+	assert.ErrorIs(t, err, model.ErrWrongPassword)
+	mr.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	rr.AssertNotCalled(t, "RevokeOtherFamilies", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangeEmail_Success(t *testing.T) {
+	mr := newMockUserRepository()
+	mockStoredUser(mr)
+	mr.On("GetByEmail", mock.Anything, "new@test.com").Return(gorm.ErrRecordNotFound)
+	mr.On("SetPendingEmail", uint(1), "new@test.com").Return(nil)
+	mv := newMockUserValidator()
+	mv.On("EmailValidate", "new@test.com").Return(nil)
+	rr := newMockRefreshTokenRepository()
+	mockOtherSessions(rr)
+	vr := repository.NewMemoryRevocationRepository()
+	m := mailer.NewMemoryMailer()
+
+	uu := NewUserUsecase(mr, mv, rr, vr, newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, m, testTokenLifetimes, nil)
+
+	err := uu.ChangeEmail(1, "laptop", model.EmailChangeRequest{Email: "new@test.com", Password: "password"})
+	assert.NoError(t, err)
+	mr.AssertCalled(t, "SetPendingEmail", uint(1), "new@test.com")
+	revoked, _ := vr.IsRevoked("", "phone", 1, 0)
+	assert.True(t, revoked)
+	if assert.Len(t, m.Messages(), 2) {
+		assert.Equal(t, "new@test.com", m.Messages()[0].To)
+		assert.Contains(t, m.Messages()[0].Body, "/verify-email?token=")
+		assert.Equal(t, "user@test.com", m.Messages()[1].To)
+	}
+}
+
+func TestChangeEmail_Taken_Failure(t *testing.T) {
+	mr := newMockUserRepository()
+	mockStoredUser(mr)
+	mr.On("GetByEmail", mock.Anything, "other@test.com").Return(nil)
+	mv := newMockUserValidator()
+	mv.On("EmailValidate", "other@test.com").Return(nil)
+	m := mailer.NewMemoryMailer()
+
+	uu := NewUserUsecase(mr, mv, newMockRefreshTokenRepository(), repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, m, testTokenLifetimes, nil)
+
+	err := uu.ChangeEmail(1, "laptop", model.EmailChangeRequest{Email: "other@test.com", Password: "password"})
+	assert.ErrorIs(t, err, model.ErrEmailTaken)
+	mr.AssertNotCalled(t, "SetPendingEmail", mock.Anything, mock.Anything)
+	assert.Empty(t, m.Messages())
+}
+
+func TestLogin_Disabled_Failure(t *testing.T) {
+	mr := newMockUserRepository()
+	mv := newMockUserValidator()
+	mv.On("UserValidate", mock.Anything).Return(nil)
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	disabledAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	mr.On("GetByEmail", mock.Anything, "user@test.com").
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.User) = model.User{ID: 1, Email: "user@test.com", Password: string(hashedPassword), DisabledAt: &disabledAt}
+		}).
+		Return(nil)
+	rr := newMockRefreshTokenRepository()
+
+	uu := NewUserUsecase(mr, mv, rr, repository.NewMemoryRevocationRepository(), newMockPasswordResetRepository(), newMockTwoFactorRepository(), nil, mailer.NewMemoryMailer(), testTokenLifetimes, nil)
+
+	_, err := uu.Login(model.User{Email: "user@test.com", Password: "password"})
+	assert.ErrorIs(t, err, model.ErrAccountDisabled)
+	rr.AssertNotCalled(t, "Create", mock.Anything)
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+)
+
+type IWatcherUsecase interface {
+	WatchTask(userId uint, workspaceId uint, taskId uint) error
+	UnwatchTask(userId uint, taskId uint) error
+	GetWatchedTasks(userId uint, workspaceId uint) ([]model.TaskResponse, error)
+}
+
+type watcherUsecase struct {
+	tr repository.ITaskRepository
+	wr repository.IWatcherRepository
+}
+
+func NewWatcherUsecase(tr repository.ITaskRepository, wr repository.IWatcherRepository) IWatcherUsecase {
+	return &watcherUsecase{tr, wr}
+}
+
+// WatchTask subscribes the user to a task they can read.
+func (wu *watcherUsecase) WatchTask(userId uint, workspaceId uint, taskId uint) error {
+	task := model.Task{}
+	if err := wu.tr.GetAccessible(&task, userId, workspaceId, taskId); err != nil {
+		return err
+	}
+	return wu.wr.Watch(userId, taskId)
+}
+
+func (wu *watcherUsecase) UnwatchTask(userId uint, taskId uint) error {
+	return wu.wr.Unwatch(userId, taskId)
+}
+
+func (wu *watcherUsecase) GetWatchedTasks(userId uint, workspaceId uint) ([]model.TaskResponse, error) {
+	var tasks []model.Task
+	if err := wu.wr.GetWatchedTasks(&tasks, userId, workspaceId); err != nil {
+		return nil, err
+	}
+
+	taskResponses := []model.TaskResponse{}
+	for _, task := range tasks {
+		taskResponses = append(taskResponses, newTaskResponse(task))
+	}
+	return taskResponses, nil
+}
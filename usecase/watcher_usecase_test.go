@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestWatchTask_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	mw := newMockWatcherRepository()
+	mr.On("GetAccessible", mock.Anything, uint(1), uint(7), uint(5)).Return(nil)
+	mw.On("Watch", uint(1), uint(5)).Return(nil)
+
+	wu := NewWatcherUsecase(mr, mw)
+
+	err := wu.WatchTask(1, 7, 5)
+	assert.NoError(t, err)
+	mw.AssertCalled(t, "Watch", uint(1), uint(5))
+}
+
+func TestWatchTask_NoAccess_Failure(t *testing.T) {
+	mr := newMockTaskRepository()
+	mw := newMockWatcherRepository()
+	mr.On("GetAccessible", mock.Anything, uint(1), uint(7), uint(5)).Return(gorm.ErrRecordNotFound)
+
+	wu := NewWatcherUsecase(mr, mw)
+
+	err := wu.WatchTask(1, 7, 5)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	mw.AssertNotCalled(t, "Watch", mock.Anything, mock.Anything)
+}
+
+func TestGetWatchedTasks_Success(t *testing.T) {
+	mr := newMockTaskRepository()
+	mw := newMockWatcherRepository()
+	mw.On("GetWatchedTasks", mock.Anything, uint(1), uint(7)).
+		Run(func(args mock.Arguments) {
+			tasks := args.Get(0).(*[]model.Task)
+			*tasks = []model.Task{{ID: 5, Title: "watched"}}
+		}).
+		Return(nil)
+
+	wu := NewWatcherUsecase(mr, mw)
+
+	res, err := wu.GetWatchedTasks(1, 7)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "watched", res[0].Title)
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"go-rest-api/repository"
+	"go-rest-api/validator"
+)
+
+type IWorkspaceUsecase interface {
+	GetAllWorkspaces(userId uint) ([]model.WorkspaceResponse, error)
+	CreateWorkspace(userId uint, workspace model.Workspace) (model.WorkspaceResponse, error)
+	AddMember(userId uint, workspaceId uint, req model.WorkspaceMemberRequest) error
+	RemoveMember(userId uint, workspaceId uint, memberId uint) error
+}
+
+type workspaceUsecase struct {
+	wr repository.IWorkspaceRepository
+	ur repository.IUserRepository
+	wv validator.IWorkspaceValidator
+}
+
+func NewWorkspaceUsecase(wr repository.IWorkspaceRepository, ur repository.IUserRepository, wv validator.IWorkspaceValidator) IWorkspaceUsecase {
+	return &workspaceUsecase{wr, ur, wv}
+}
+
+func (wu *workspaceUsecase) GetAllWorkspaces(userId uint) ([]model.WorkspaceResponse, error) {
+	var members []model.WorkspaceMember
+	if err := wu.wr.GetAll(&members, userId); err != nil {
+		return nil, err
+	}
+
+	workspaceResponses := []model.WorkspaceResponse{}
+	for _, member := range members {
+		workspaceResponses = append(workspaceResponses, newWorkspaceResponse(member.Workspace, member.Role))
+	}
+	return workspaceResponses, nil
+}
+
+// CreateWorkspace makes the user the owner of the new workspace.
+func (wu *workspaceUsecase) CreateWorkspace(userId uint, workspace model.Workspace) (model.WorkspaceResponse, error) {
+	if err := wu.wv.WorkspaceValidate(workspace); err != nil {
+		return model.WorkspaceResponse{}, err
+	}
+	if err := wu.wr.Create(&workspace, userId); err != nil {
+		return model.WorkspaceResponse{}, err
+	}
+	return newWorkspaceResponse(workspace, model.WorkspaceRoleOwner), nil
+}
+
+// AddMember lets owners and admins add a user by email. Adding someone who
+// is already a member leaves their role alone.
+func (wu *workspaceUsecase) AddMember(userId uint, workspaceId uint, req model.WorkspaceMemberRequest) error {
+	if err := wu.wv.WorkspaceMemberValidate(req); err != nil {
+		return err
+	}
+	actor := model.WorkspaceMember{}
+	if err := wu.wr.GetMember(&actor, workspaceId, userId); err != nil {
+		return err
+	}
+	if !isWorkspaceAdmin(actor) {
+		return model.ErrNotWorkspaceAdmin
+	}
+	user := model.User{}
+	if err := wu.ur.GetByEmail(&user, req.Email); err != nil {
+		return err
+	}
+	role := req.Role
+	if role == "" {
+		role = model.WorkspaceRoleMember
+	}
+	return wu.wr.AddMember(&model.WorkspaceMember{WorkspaceId: workspaceId, UserId: user.ID, Role: role})
+}
+
+// RemoveMember lets owners and admins remove any other member and a member
+// leave the workspace. The owner cannot be removed.
+func (wu *workspaceUsecase) RemoveMember(userId uint, workspaceId uint, memberId uint) error {
+	actor := model.WorkspaceMember{}
+	if err := wu.wr.GetMember(&actor, workspaceId, userId); err != nil {
+		return err
+	}
+	if memberId != userId && !isWorkspaceAdmin(actor) {
+		return model.ErrNotWorkspaceAdmin
+	}
+	member := model.WorkspaceMember{}
+	if err := wu.wr.GetMember(&member, workspaceId, memberId); err != nil {
+		return err
+	}
+	if member.Role == model.WorkspaceRoleOwner {
+		return model.ErrWorkspaceOwnerMember
+	}
+	return wu.wr.RemoveMember(workspaceId, memberId)
+}
+
+func isWorkspaceAdmin(member model.WorkspaceMember) bool {
+	return member.Role == model.WorkspaceRoleOwner || member.Role == model.WorkspaceRoleAdmin
+}
+
+func newWorkspaceResponse(workspace model.Workspace, role string) model.WorkspaceResponse {
+	return model.WorkspaceResponse{
+		ID:        workspace.ID,
+		Name:      workspace.Name,
+		Role:      role,
+		CreatedAt: workspace.CreatedAt,
+	}
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"go-rest-api/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockWorkspaceRepository struct {
+	mock.Mock
+}
+
+func newMockWorkspaceRepository() *MockWorkspaceRepository {
+	return &MockWorkspaceRepository{}
+}
+
+func (mr *MockWorkspaceRepository) Create(workspace *model.Workspace, userId uint) error {
+	args := mr.Called(workspace, userId)
+	return args.Error(0)
+}
+
+func (mr *MockWorkspaceRepository) GetAll(members *[]model.WorkspaceMember, userId uint) error {
+	args := mr.Called(members, userId)
+	return args.Error(0)
+}
+
+func (mr *MockWorkspaceRepository) GetMember(member *model.WorkspaceMember, workspaceId uint, userId uint) error {
+	args := mr.Called(member, workspaceId, userId)
+	return args.Error(0)
+}
+
+func (mr *MockWorkspaceRepository) GetDefault(member *model.WorkspaceMember, userId uint) error {
+	args := mr.Called(member, userId)
+	return args.Error(0)
+}
+
+func (mr *MockWorkspaceRepository) AddMember(member *model.WorkspaceMember) error {
+	args := mr.Called(member)
+	return args.Error(0)
+}
+
+func (mr *MockWorkspaceRepository) RemoveMember(workspaceId uint, userId uint) error {
+	args := mr.Called(workspaceId, userId)
+	return args.Error(0)
+}
+
+type MockWorkspaceValidator struct {
+	mock.Mock
+}
+
+func newMockWorkspaceValidator() *MockWorkspaceValidator {
+	return &MockWorkspaceValidator{}
+}
+
+func (mv *MockWorkspaceValidator) WorkspaceValidate(workspace model.Workspace) error {
+	args := mv.Called(workspace)
+	return args.Error(0)
+}
+
+func (mv *MockWorkspaceValidator) WorkspaceMemberValidate(req model.WorkspaceMemberRequest) error {
+	args := mv.Called(req)
+	return args.Error(0)
+}
+
+// mockWorkspaceMember answers GetMember for userId in workspace 7 with role.
+func mockWorkspaceMember(mr *MockWorkspaceRepository, userId uint, role string) {
+	mr.On("GetMember", mock.Anything, uint(7), userId).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*model.WorkspaceMember) = model.WorkspaceMember{WorkspaceId: 7, UserId: userId, Role: role}
+		}).
+		Return(nil)
+}
+
+func TestGetAllWorkspaces_Success(t *testing.T) {
+	mr := newMockWorkspaceRepository()
+	mr.On("GetAll", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]model.WorkspaceMember) = []model.WorkspaceMember{
+				{Workspace: model.Workspace{ID: 7, Name: "Personal"}, WorkspaceId: 7, UserId: 1, Role: model.WorkspaceRoleOwner},
+			}
+		}).
+		Return(nil)
+
+	wu := NewWorkspaceUsecase(mr, newMockUserRepository(), newMockWorkspaceValidator())
+
+	res, err := wu.GetAllWorkspaces(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.WorkspaceResponse{{ID: 7, Name: "Personal", Role: model.WorkspaceRoleOwner}}, res)
+}
+
+func TestCreateWorkspace_Success(t *testing.T) {
+	mr := newMockWorkspaceRepository()
+	mv := newMockWorkspaceValidator()
+	mv.On("WorkspaceValidate", mock.Anything).Return(nil)
+	mr.On("Create", mock.Anything, uint(1)).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.Workspace).ID = 8
+		}).
+		Return(nil)
+
+	wu := NewWorkspaceUsecase(mr, newMockUserRepository(), mv)
+
+	res, err := wu.CreateWorkspace(1, model.Workspace{Name: "Team"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(8), res.ID)
+	assert.Equal(t, model.WorkspaceRoleOwner, res.Role)
+}
+
+func TestAddWorkspaceMember_Success(t *testing.T) {
+	mr := newMockWorkspaceRepository()
+	mu := newMockUserRepository()
+	mv := newMockWorkspaceValidator()
+	mv.On("WorkspaceMemberValidate", mock.Anything).Return(nil)
+	mockWorkspaceMember(mr, 1, model.WorkspaceRoleAdmin)
+	mu.On("GetByEmail", mock.Anything, "bob@test.com").
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.User).ID = 2
+		}).
+		Return(nil)
+	mr.On("AddMember", mock.Anything).Return(nil)
+
+	wu := NewWorkspaceUsecase(mr, mu, mv)
+
+	err := wu.AddMember(1, 7, model.WorkspaceMemberRequest{Email: "bob@test.com"})
+	assert.NoError(t, err)
+	mr.AssertCalled(t, "AddMember", &model.WorkspaceMember{WorkspaceId: 7, UserId: 2, Role: model.WorkspaceRoleMember})
+}
+
+func TestAddWorkspaceMember_NotAdmin_Failure(t *testing.T) {
+	mr := newMockWorkspaceRepository()
+	mv := newMockWorkspaceValidator()
+	mv.On("WorkspaceMemberValidate", mock.Anything).Return(nil)
+	mockWorkspaceMember(mr, 2, model.WorkspaceRoleMember)
+
+	wu := NewWorkspaceUsecase(mr, newMockUserRepository(), mv)
+
+	err := wu.AddMember(2, 7, model.WorkspaceMemberRequest{Email: "carol@test.com"})
+	assert.ErrorIs(t, err, model.ErrNotWorkspaceAdmin)
+	mr.AssertNotCalled(t, "AddMember", mock.Anything)
+}
+
+func TestAddWorkspaceMember_NotMember_Failure(t *testing.T) {
+	mr := newMockWorkspaceRepository()
+	mv := newMockWorkspaceValidator()
+	mv.On("WorkspaceMemberValidate", mock.Anything).Return(nil)
+	mr.On("GetMember", mock.Anything, uint(7), uint(3)).Return(gorm.ErrRecordNotFound)
+
+	wu := NewWorkspaceUsecase(mr, newMockUserRepository(), mv)
+
+	err := wu.AddMember(3, 7, model.WorkspaceMemberRequest{Email: "carol@test.com"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRemoveWorkspaceMember_Leave_Success(t *testing.T) {
+	mr := newMockWorkspaceRepository()
+	mockWorkspaceMember(mr, 2, model.WorkspaceRoleMember)
+	mr.On("RemoveMember", uint(7), uint(2)).Return(nil)
+
+	wu := NewWorkspaceUsecase(mr, newMockUserRepository(), newMockWorkspaceValidator())
+
+	err := wu.RemoveMember(2, 7, 2)
+	assert.NoError(t, err)
+}
+
+func TestRemoveWorkspaceMember_NotAdmin_Failure(t *testing.T) {
+	mr := newMockWorkspaceRepository()
+	mockWorkspaceMember(mr, 2, model.WorkspaceRoleMember)
+
+	wu := NewWorkspaceUsecase(mr, newMockUserRepository(), newMockWorkspaceValidator())
+
+	err := wu.RemoveMember(2, 7, 4)
+	assert.ErrorIs(t, err, model.ErrNotWorkspaceAdmin)
+	mr.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything)
+}
+
+func TestRemoveWorkspaceMember_Owner_Failure(t *testing.T) {
+	mr := newMockWorkspaceRepository()
+	mockWorkspaceMember(mr, 1, model.WorkspaceRoleOwner)
+	mockWorkspaceMember(mr, 2, model.WorkspaceRoleAdmin)
+
+	wu := NewWorkspaceUsecase(mr, newMockUserRepository(), newMockWorkspaceValidator())
+
+	err := wu.RemoveMember(2, 7, 1)
+	assert.ErrorIs(t, err, model.ErrWorkspaceOwnerMember)
+	mr.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything)
+}
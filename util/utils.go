@@ -15,7 +15,7 @@ func PrepareTestDB() {
 	conn := NewTestDB()
 	defer fmt.Println("Test database migration succeded.")
 	defer CloseTestDB(conn)
-	conn.AutoMigrate(&model.User{}, &model.Task{})
+	conn.AutoMigrate(&model.User{}, &model.Workspace{}, &model.WorkspaceMember{}, &model.Project{}, &model.ProjectMember{}, &model.CustomField{}, &model.Label{}, &model.Task{}, &model.SmartList{}, &model.IdempotencyRecord{}, &model.SyncCounter{}, &model.TaskTombstone{}, &model.TaskRevision{}, &model.UserQuota{}, &model.APIUsage{}, &model.TaskEvent{}, &model.Comment{}, &model.TaskWatcher{}, &model.Mention{}, &model.Milestone{}, &model.MilestoneTask{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.RevokedSession{}, &model.SessionRevocation{}, &model.PasswordResetToken{}, &model.TwoFactor{}, &model.TwoFactorChallenge{}, &model.RecoveryCode{}, &model.Identity{})
 }
 
 func NewTestDB() *gorm.DB {
@@ -35,7 +35,7 @@ func CloseTestDB(db *gorm.DB) {
 }
 
 func CleanupTestDB(db *gorm.DB) {
-	tables := []string{"tasks", "users"}
+	tables := []string{"milestone_tasks", "milestones", "mentions", "task_watchers", "attachments", "comments", "task_events", "api_usages", "user_quotas", "task_revisions", "task_tombstones", "sync_counters", "idempotency_records", "smart_lists", "task_labels", "tasks", "labels", "custom_fields", "project_members", "projects", "workspace_members", "workspaces", "refresh_tokens", "revoked_tokens", "revoked_sessions", "session_revocations", "password_reset_tokens", "recovery_codes", "two_factor_challenges", "two_factors", "identities", "personal_access_tokens", "audit_entries", "users"}
 
 	for _, table := range tables {
 		db.Exec("TRUNCATE TABLE " + table + " CASCADE")
@@ -46,6 +46,86 @@ func CleanupTaskTable(db *gorm.DB) {
 	db.Exec("TRUNCATE TABLE tasks CASCADE")
 }
 
+func CleanupSyncTables(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE task_tombstones, sync_counters CASCADE")
+}
+
+func CleanupWatcherTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE task_watchers CASCADE")
+}
+
+func CleanupCommentTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE comments CASCADE")
+}
+
+func CleanupAttachmentTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE attachments CASCADE")
+}
+
+func CleanupTaskEventTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE task_events CASCADE")
+}
+
+func CleanupQuotaTables(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE api_usages, user_quotas CASCADE")
+}
+
+func CleanupMentionTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE mentions CASCADE")
+}
+
+func CleanupProjectTables(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE custom_fields, project_members, projects CASCADE")
+}
+
+func CleanupWorkspaceTables(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE workspace_members, workspaces CASCADE")
+}
+
+func CleanupMilestoneTables(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE milestone_tasks, milestones CASCADE")
+}
+
+func CleanupRefreshTokenTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE refresh_tokens CASCADE")
+}
+
+func CleanupRevocationTables(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE revoked_tokens, revoked_sessions, session_revocations CASCADE")
+}
+
+func CleanupPasswordResetTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE password_reset_tokens CASCADE")
+}
+
+func CleanupTwoFactorTables(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE recovery_codes, two_factor_challenges, two_factors CASCADE")
+}
+
+func CleanupIdentityTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE identities CASCADE")
+}
+
+func CleanupPersonalAccessTokenTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE personal_access_tokens CASCADE")
+}
+
+func CleanupAuditTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE audit_entries CASCADE")
+}
+
+func CleanupIdempotencyTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE idempotency_records CASCADE")
+}
+
+func CleanupSmartListTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE smart_lists CASCADE")
+}
+
+func CleanupLabelTable(db *gorm.DB) {
+	db.Exec("TRUNCATE TABLE labels CASCADE")
+}
+
 func CleanupUserTabls(db *gorm.DB) {
 	db.Exec("TRUNCATE TABLE users CASCADE")
 }
@@ -0,0 +1,27 @@
+package validator
+
+import (
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type IAdminValidator interface {
+	RoleValidate(req model.RoleRequest) error
+}
+
+type adminValidator struct{}
+
+func NewAdminValidator() IAdminValidator {
+	return &adminValidator{}
+}
+
+func (av *adminValidator) RoleValidate(req model.RoleRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Role,
+			validation.Required.Error("role is required"),
+			validation.In(model.RoleUser, model.RoleSupport, model.RoleAdmin).Error("must be one of user, support, admin"),
+		),
+	)
+}
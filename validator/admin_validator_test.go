@@ -0,0 +1,28 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleValidator_Success(t *testing.T) {
+	av := NewAdminValidator()
+	err := av.RoleValidate(model.RoleRequest{Role: model.RoleSupport})
+	assert.Nil(t, err)
+}
+
+func TestRoleValidator_RoleNil_Failure(t *testing.T) {
+	av := NewAdminValidator()
+	err := av.RoleValidate(model.RoleRequest{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "role: role is required.", err.Error())
+}
+
+func TestRoleValidator_UnknownRole_Failure(t *testing.T) {
+	av := NewAdminValidator()
+	err := av.RoleValidate(model.RoleRequest{Role: "owner"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "role: must be one of user, support, admin.", err.Error())
+}
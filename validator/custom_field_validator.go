@@ -0,0 +1,257 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"go-rest-api/model"
+	"regexp"
+	"strconv"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type ICustomFieldValidator interface {
+	CustomFieldValidate(field model.CustomField) error
+	CustomFieldValuesValidate(fields []model.CustomField, values model.CustomFieldValues) error
+	CustomFieldFilterValidate(field model.CustomField, filter model.CustomFieldFilter) error
+}
+
+type customFieldValidator struct{}
+
+func NewCustomFieldValidator() ICustomFieldValidator {
+	return &customFieldValidator{}
+}
+
+// CustomFieldValidate checks a field definition, including that it only sets
+// the rules its type supports.
+func (cv *customFieldValidator) CustomFieldValidate(field model.CustomField) error {
+	isNumber := field.Type == model.CustomFieldNumber
+	isText := field.Type == model.CustomFieldText
+	isSelect := field.Type == model.CustomFieldSelect || field.Type == model.CustomFieldMultiSelect
+	return validation.ValidateStruct(&field,
+		validation.Field(
+			&field.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 50).Error("limited max 50 char"),
+		),
+		validation.Field(
+			&field.Type,
+			validation.Required.Error("type is required"),
+			validation.In(model.CustomFieldText, model.CustomFieldNumber, model.CustomFieldDate, model.CustomFieldSelect, model.CustomFieldMultiSelect).Error("must be one of text, number, date, select, multi_select"),
+		),
+		validation.Field(
+			&field.Options,
+			validation.When(isSelect, validation.Required.Error("options are required"), validation.By(optionsRule)),
+			validation.When(!isSelect, validation.Empty.Error("only allowed for select and multi_select")),
+		),
+		validation.Field(
+			&field.Min,
+			validation.When(!isNumber, validation.Nil.Error("only allowed for number")),
+		),
+		validation.Field(
+			&field.Max,
+			validation.When(!isNumber, validation.Nil.Error("only allowed for number")),
+			validation.When(isNumber && field.Min != nil && field.Max != nil, validation.By(func(interface{}) error {
+				if *field.Max < *field.Min {
+					return errors.New("must not be less than min")
+				}
+				return nil
+			})),
+		),
+		validation.Field(
+			&field.MaxLength,
+			validation.When(!isText, validation.Nil.Error("only allowed for text")),
+			validation.When(isText && field.MaxLength != nil, validation.By(func(interface{}) error {
+				if *field.MaxLength < 1 {
+					return errors.New("must be at least 1")
+				}
+				return nil
+			})),
+		),
+		validation.Field(
+			&field.Pattern,
+			validation.When(!isText, validation.Empty.Error("only allowed for text")),
+			validation.By(func(interface{}) error {
+				if _, err := regexp.Compile(field.Pattern); err != nil {
+					return errors.New("is not a valid regular expression")
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+// CustomFieldValuesValidate validates the values of a task against the
+// fields of its project. The rules are generated from each field definition
+// and values for fields the project does not have are rejected.
+func (cv *customFieldValidator) CustomFieldValuesValidate(fields []model.CustomField, values model.CustomFieldValues) error {
+	if values == nil {
+		values = model.CustomFieldValues{}
+	}
+	keys := make([]*validation.KeyRules, 0, len(fields))
+	for _, field := range fields {
+		key := validation.Key(strconv.FormatUint(uint64(field.ID), 10), customFieldRules(field)...)
+		if !field.Required {
+			key = key.Optional()
+		}
+		keys = append(keys, key)
+	}
+	return validation.Validate(map[string]interface{}(values), validation.Map(keys...))
+}
+
+// CustomFieldFilterValidate checks that the filter value has the type of the
+// field and that ranges are only used on numbers and dates.
+func (cv *customFieldValidator) CustomFieldFilterValidate(field model.CustomField, filter model.CustomFieldFilter) error {
+	var err error
+	switch {
+	case filter.Op != model.CustomFieldOpEq && filter.Op != model.CustomFieldOpGte && filter.Op != model.CustomFieldOpLte:
+		err = errors.New("must be one of eq, gte, lte")
+	case filter.Op != model.CustomFieldOpEq && field.Type != model.CustomFieldNumber && field.Type != model.CustomFieldDate:
+		err = errors.New("ranges are only allowed for number and date")
+	case field.Type == model.CustomFieldNumber:
+		if _, parseErr := strconv.ParseFloat(filter.Value, 64); parseErr != nil {
+			err = errors.New("must be a number")
+		}
+	case field.Type == model.CustomFieldDate:
+		err = dateRule(filter.Value)
+	case field.Type == model.CustomFieldSelect || field.Type == model.CustomFieldMultiSelect:
+		err = validation.Validate(filter.Value, validation.In(options(field)...).Error("must be one of the field options"))
+	}
+	if err != nil {
+		return validation.Errors{fmt.Sprintf("cf.%d", field.ID): err}
+	}
+	return nil
+}
+
+// customFieldRules generates the rules for the values of field from its
+// definition.
+func customFieldRules(field model.CustomField) []validation.Rule {
+	rules := []validation.Rule{}
+	if field.Required {
+		rules = append(rules, validation.By(requiredValueRule))
+	}
+	switch field.Type {
+	case model.CustomFieldText:
+		rules = append(rules, validation.By(stringRule))
+		if field.MaxLength != nil {
+			rules = append(rules, validation.RuneLength(0, *field.MaxLength).Error(fmt.Sprintf("limited max %d char", *field.MaxLength)))
+		}
+		if pattern, err := regexp.Compile(field.Pattern); field.Pattern != "" && err == nil {
+			rules = append(rules, validation.Match(pattern).Error("must match "+field.Pattern))
+		}
+	case model.CustomFieldNumber:
+		rules = append(rules, validation.By(numberRule(field.Min, field.Max)))
+	case model.CustomFieldDate:
+		rules = append(rules, validation.By(stringRule), validation.By(dateRule))
+	case model.CustomFieldSelect:
+		rules = append(rules, validation.By(stringRule), validation.In(options(field)...).Error("must be one of the field options"))
+	case model.CustomFieldMultiSelect:
+		rules = append(rules, validation.By(multiSelectRule(options(field))))
+	}
+	return rules
+}
+
+// requiredValueRule rejects missing and empty values but, unlike
+// validation.Required, accepts the number 0.
+func requiredValueRule(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return errors.New("is required")
+	case string:
+		if v == "" {
+			return errors.New("is required")
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return errors.New("is required")
+		}
+	}
+	return nil
+}
+
+func stringRule(value interface{}) error {
+	if _, ok := value.(string); !ok && value != nil {
+		return errors.New("must be a string")
+	}
+	return nil
+}
+
+func dateRule(value interface{}) error {
+	date, _ := value.(string)
+	if date == "" {
+		return nil
+	}
+	if _, err := time.Parse(model.CustomFieldDateLayout, date); err != nil {
+		return errors.New("must be a date such as 2024-05-31")
+	}
+	return nil
+}
+
+func numberRule(min *float64, max *float64) validation.RuleFunc {
+	return func(value interface{}) error {
+		if value == nil {
+			return nil
+		}
+		number, ok := value.(float64)
+		if !ok {
+			return errors.New("must be a number")
+		}
+		if min != nil && number < *min {
+			return fmt.Errorf("must be no less than %v", *min)
+		}
+		if max != nil && number > *max {
+			return fmt.Errorf("must be no greater than %v", *max)
+		}
+		return nil
+	}
+}
+
+func multiSelectRule(choices []interface{}) validation.RuleFunc {
+	return func(value interface{}) error {
+		if value == nil {
+			return nil
+		}
+		selected, ok := value.([]interface{})
+		if !ok {
+			return errors.New("must be a list of options")
+		}
+		seen := map[interface{}]bool{}
+		for _, option := range selected {
+			if err := validation.Validate(option, validation.By(stringRule), validation.Required.Error("must be one of the field options"), validation.In(choices...).Error("must be one of the field options")); err != nil {
+				return err
+			}
+			if seen[option] {
+				return errors.New("must not repeat an option")
+			}
+			seen[option] = true
+		}
+		return nil
+	}
+}
+
+func optionsRule(value interface{}) error {
+	list, _ := value.([]string)
+	seen := map[string]bool{}
+	for _, option := range list {
+		if option == "" {
+			return errors.New("option is required")
+		}
+		if len([]rune(option)) > 50 {
+			return errors.New("option limited max 50 char")
+		}
+		if seen[option] {
+			return errors.New("option " + option + " is repeated")
+		}
+		seen[option] = true
+	}
+	return nil
+}
+
+func options(field model.CustomField) []interface{} {
+	choices := make([]interface{}, 0, len(field.Options))
+	for _, option := range field.Options {
+		choices = append(choices, option)
+	}
+	return choices
+}
@@ -0,0 +1,122 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+var testCustomFields = []model.CustomField{
+	{ID: 1, Name: "Estimate", Type: model.CustomFieldNumber, Required: true, Min: floatPtr(0), Max: floatPtr(100)},
+	{ID: 2, Name: "Code", Type: model.CustomFieldText, MaxLength: intPtr(5), Pattern: "^[A-Z]+$"},
+	{ID: 3, Name: "Launch", Type: model.CustomFieldDate},
+	{ID: 4, Name: "Stage", Type: model.CustomFieldSelect, Options: []string{"alpha", "beta"}},
+	{ID: 5, Name: "Platforms", Type: model.CustomFieldMultiSelect, Options: []string{"ios", "android", "web"}},
+}
+
+func TestCustomFieldValidator_Success(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	for _, field := range testCustomFields {
+		assert.Nil(t, cv.CustomFieldValidate(field), field.Name)
+	}
+}
+
+func TestCustomFieldValidator_InvalidType_Failure(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	err := cv.CustomFieldValidate(model.CustomField{Name: "Size", Type: "shirt"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "type: must be one of text, number, date, select, multi_select.", err.Error())
+}
+
+func TestCustomFieldValidator_OptionsNil_Failure(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	err := cv.CustomFieldValidate(model.CustomField{Name: "Stage", Type: model.CustomFieldSelect})
+	assert.NotNil(t, err)
+	assert.Equal(t, "options: options are required.", err.Error())
+}
+
+func TestCustomFieldValidator_RuleForOtherType_Failure(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	err := cv.CustomFieldValidate(model.CustomField{Name: "Code", Type: model.CustomFieldText, Min: floatPtr(1)})
+	assert.NotNil(t, err)
+	assert.Equal(t, "min: only allowed for number.", err.Error())
+}
+
+func TestCustomFieldValidator_InvalidPattern_Failure(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	err := cv.CustomFieldValidate(model.CustomField{Name: "Code", Type: model.CustomFieldText, Pattern: "("})
+	assert.NotNil(t, err)
+	assert.Equal(t, "pattern: is not a valid regular expression.", err.Error())
+}
+
+func TestCustomFieldValuesValidator_Success(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	values := model.CustomFieldValues{
+		"1": float64(0),
+		"2": "ABC",
+		"3": "2024-05-31",
+		"4": "beta",
+		"5": []interface{}{"ios", "web"},
+	}
+	err := cv.CustomFieldValuesValidate(testCustomFields, values)
+	assert.Nil(t, err)
+}
+
+func TestCustomFieldValuesValidator_RequiredMissing_Failure(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	err := cv.CustomFieldValuesValidate(testCustomFields, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "1: required key is missing.", err.Error())
+}
+
+func TestCustomFieldValuesValidator_Failure(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	values := model.CustomFieldValues{
+		"1": float64(101),
+		"2": "abc",
+		"3": "31/05/2024",
+		"4": "gamma",
+		"5": []interface{}{"ios", "ios"},
+		"6": "unknown",
+	}
+	err := cv.CustomFieldValuesValidate(testCustomFields, values)
+	assert.NotNil(t, err)
+	assert.Equal(t, "1: must be no greater than 100; 2: must match ^[A-Z]+$; 3: must be a date such as 2024-05-31; 4: must be one of the field options; 5: must not repeat an option; 6: key not expected.", err.Error())
+}
+
+func TestCustomFieldValuesValidator_WrongType_Failure(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	values := model.CustomFieldValues{"1": "ten", "5": "ios"}
+	err := cv.CustomFieldValuesValidate(testCustomFields, values)
+	assert.NotNil(t, err)
+	assert.Equal(t, "1: must be a number; 5: must be a list of options.", err.Error())
+}
+
+func TestCustomFieldFilterValidator_Success(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	err := cv.CustomFieldFilterValidate(testCustomFields[0], model.CustomFieldFilter{FieldId: 1, Op: model.CustomFieldOpGte, Value: "2.5"})
+	assert.Nil(t, err)
+}
+
+func TestCustomFieldFilterValidator_RangeOnSelect_Failure(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	err := cv.CustomFieldFilterValidate(testCustomFields[3], model.CustomFieldFilter{FieldId: 4, Op: model.CustomFieldOpLte, Value: "beta"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "cf.4: ranges are only allowed for number and date.", err.Error())
+}
+
+func TestCustomFieldFilterValidator_InvalidOption_Failure(t *testing.T) {
+	cv := NewCustomFieldValidator()
+	err := cv.CustomFieldFilterValidate(testCustomFields[4], model.CustomFieldFilter{FieldId: 5, Op: model.CustomFieldOpEq, Value: "linux"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "cf.5: must be one of the field options.", err.Error())
+}
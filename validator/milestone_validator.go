@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"errors"
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type IMilestoneValidator interface {
+	MilestoneValidate(milestone model.Milestone) error
+}
+
+type milestoneValidator struct{}
+
+func NewMilestoneValidator() IMilestoneValidator {
+	return &milestoneValidator{}
+}
+
+func (mv *milestoneValidator) MilestoneValidate(milestone model.Milestone) error {
+	return validation.ValidateStruct(&milestone,
+		validation.Field(
+			&milestone.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 100).Error("limited max 100 char"),
+		),
+		validation.Field(
+			&milestone.StartDate,
+			validation.Required.Error("start_date is required"),
+		),
+		validation.Field(
+			&milestone.EndDate,
+			validation.Required.Error("end_date is required"),
+			validation.By(func(interface{}) error {
+				if !milestone.EndDate.After(milestone.StartDate) {
+					return errors.New("must be after start_date")
+				}
+				return nil
+			}),
+		),
+	)
+}
@@ -0,0 +1,43 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMilestoneValidator_Success(t *testing.T) {
+	mv := NewMilestoneValidator()
+	start := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	milestone := model.Milestone{Name: "Sprint 1", StartDate: start, EndDate: start.AddDate(0, 0, 14)}
+	err := mv.MilestoneValidate(milestone)
+	assert.Nil(t, err)
+}
+
+func TestMilestoneValidator_NameNil_Failure(t *testing.T) {
+	mv := NewMilestoneValidator()
+	start := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	milestone := model.Milestone{StartDate: start, EndDate: start.AddDate(0, 0, 14)}
+	err := mv.MilestoneValidate(milestone)
+	assert.NotNil(t, err)
+	assert.Equal(t, "name: name is required.", err.Error())
+}
+
+func TestMilestoneValidator_EndBeforeStart_Failure(t *testing.T) {
+	mv := NewMilestoneValidator()
+	start := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	milestone := model.Milestone{Name: "Sprint 1", StartDate: start, EndDate: start}
+	err := mv.MilestoneValidate(milestone)
+	assert.NotNil(t, err)
+	assert.Equal(t, "end_date: must be after start_date.", err.Error())
+}
+
+func TestMilestoneValidator_DatesNil_Failure(t *testing.T) {
+	mv := NewMilestoneValidator()
+	milestone := model.Milestone{Name: "Sprint 1"}
+	err := mv.MilestoneValidate(milestone)
+	assert.NotNil(t, err)
+	assert.Equal(t, "end_date: end_date is required; start_date: start_date is required.", err.Error())
+}
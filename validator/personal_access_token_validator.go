@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type IPersonalAccessTokenValidator interface {
+	PersonalAccessTokenValidate(req model.PersonalAccessTokenRequest) error
+}
+
+type personalAccessTokenValidator struct{}
+
+func NewPersonalAccessTokenValidator() IPersonalAccessTokenValidator {
+	return &personalAccessTokenValidator{}
+}
+
+func (pv *personalAccessTokenValidator) PersonalAccessTokenValidate(req model.PersonalAccessTokenRequest) error {
+	scopes := make([]interface{}, len(model.PersonalAccessTokenScopes))
+	for i, scope := range model.PersonalAccessTokenScopes {
+		scopes[i] = scope
+	}
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 100).Error("limited max 100 char"),
+		),
+		validation.Field(
+			&req.Scopes,
+			validation.Required.Error("scopes are required"),
+			validation.Each(validation.In(scopes...).Error("must be one of "+strings.Join(model.PersonalAccessTokenScopes, ", "))),
+		),
+		validation.Field(
+			&req.ExpiresAt,
+			validation.Min(time.Now()).Exclusive().Error("must be in the future"),
+		),
+	)
+}
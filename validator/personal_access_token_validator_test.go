@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersonalAccessTokenValidator_Success(t *testing.T) {
+	pv := NewPersonalAccessTokenValidator()
+	expiresAt := time.Now().Add(24 * time.Hour)
+	req := model.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{model.ScopeTasksRead, model.ScopeProjectsWrite}, ExpiresAt: &expiresAt}
+	err := pv.PersonalAccessTokenValidate(req)
+	assert.Nil(t, err)
+}
+
+func TestPersonalAccessTokenValidator_NoExpiry_Success(t *testing.T) {
+	pv := NewPersonalAccessTokenValidator()
+	req := model.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{model.ScopeTasksRead}}
+	err := pv.PersonalAccessTokenValidate(req)
+	assert.Nil(t, err)
+}
+
+func TestPersonalAccessTokenValidator_ScopesNil_Failure(t *testing.T) {
+	pv := NewPersonalAccessTokenValidator()
+	req := model.PersonalAccessTokenRequest{Name: "ci"}
+	err := pv.PersonalAccessTokenValidate(req)
+	assert.NotNil(t, err)
+	assert.Equal(t, "scopes: scopes are required.", err.Error())
+}
+
+func TestPersonalAccessTokenValidator_UnknownScope_Failure(t *testing.T) {
+	pv := NewPersonalAccessTokenValidator()
+	req := model.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{model.ScopeTasksRead, "admin"}}
+	err := pv.PersonalAccessTokenValidate(req)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "scopes: (1: must be one of tasks:read")
+}
+
+func TestPersonalAccessTokenValidator_ExpiresPast_Failure(t *testing.T) {
+	pv := NewPersonalAccessTokenValidator()
+	expiresAt := time.Now().Add(-time.Hour)
+	req := model.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{model.ScopeTasksRead}, ExpiresAt: &expiresAt}
+	err := pv.PersonalAccessTokenValidate(req)
+	assert.NotNil(t, err)
+	assert.Equal(t, "expires_at: must be in the future.", err.Error())
+}
@@ -0,0 +1,39 @@
+package validator
+
+import (
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type IProjectValidator interface {
+	ProjectValidate(project model.Project) error
+	ProjectMemberValidate(req model.ProjectMemberRequest) error
+}
+
+type projectValidator struct{}
+
+func NewProjectValidator() IProjectValidator {
+	return &projectValidator{}
+}
+
+func (pv *projectValidator) ProjectValidate(project model.Project) error {
+	return validation.ValidateStruct(&project,
+		validation.Field(
+			&project.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 100).Error("limited max 100 char"),
+		),
+	)
+}
+
+func (pv *projectValidator) ProjectMemberValidate(req model.ProjectMemberRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Email,
+			validation.Required.Error("email is required"),
+			is.EmailFormat.Error("is not valid email format"),
+		),
+	)
+}
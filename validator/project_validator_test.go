@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectValidator_Success(t *testing.T) {
+	pv := NewProjectValidator()
+	err := pv.ProjectValidate(model.Project{Name: "Launch"})
+	assert.Nil(t, err)
+}
+
+func TestProjectValidator_NameNil_Failure(t *testing.T) {
+	pv := NewProjectValidator()
+	err := pv.ProjectValidate(model.Project{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "name: name is required.", err.Error())
+}
+
+func TestProjectValidator_NameMax_Failure(t *testing.T) {
+	pv := NewProjectValidator()
+	err := pv.ProjectValidate(model.Project{Name: strings.Repeat("a", 101)})
+	assert.NotNil(t, err)
+	assert.Equal(t, "name: limited max 100 char.", err.Error())
+}
+
+func TestProjectMemberValidator_Success(t *testing.T) {
+	pv := NewProjectValidator()
+	err := pv.ProjectMemberValidate(model.ProjectMemberRequest{Email: "member@test.com"})
+	assert.Nil(t, err)
+}
+
+func TestProjectMemberValidator_InvalidEmail_Failure(t *testing.T) {
+	pv := NewProjectValidator()
+	err := pv.ProjectMemberValidate(model.ProjectMemberRequest{Email: "member"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "email: is not valid email format.", err.Error())
+}
@@ -0,0 +1,31 @@
+package validator
+
+import (
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type IQuickAddValidator interface {
+	QuickAddValidate(req model.QuickAddRequest) error
+}
+
+type quickAddValidator struct{}
+
+func NewQuickAddValidator() IQuickAddValidator {
+	return &quickAddValidator{}
+}
+
+func (qv *quickAddValidator) QuickAddValidate(req model.QuickAddRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Text,
+			validation.Required.Error("text is required"),
+			validation.RuneLength(1, 500).Error("limited max 500 char"),
+		),
+		validation.Field(
+			&req.Timezone,
+			validation.By(timezoneRule),
+		),
+	)
+}
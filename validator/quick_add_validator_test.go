@@ -0,0 +1,48 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuickAddValidator_Success(t *testing.T) {
+	qv := NewQuickAddValidator()
+	req := model.QuickAddRequest{
+		Text:     "Call dentist tomorrow 3pm",
+		Timezone: "UTC",
+	}
+	err := qv.QuickAddValidate(req)
+	assert.Nil(t, err)
+}
+
+func TestQuickAddValidator_TextNil_Failure(t *testing.T) {
+	qv := NewQuickAddValidator()
+	req := model.QuickAddRequest{}
+	err := qv.QuickAddValidate(req)
+	assert.NotNil(t, err)
+	assert.Equal(t, "text: text is required.", err.Error())
+}
+
+func TestQuickAddValidator_TextMax_Failure(t *testing.T) {
+	qv := NewQuickAddValidator()
+	req := model.QuickAddRequest{
+		Text: strings.Repeat("a", 501),
+	}
+	err := qv.QuickAddValidate(req)
+	assert.NotNil(t, err)
+	assert.Equal(t, "text: limited max 500 char.", err.Error())
+}
+
+func TestQuickAddValidator_InvalidTimezone_Failure(t *testing.T) {
+	qv := NewQuickAddValidator()
+	req := model.QuickAddRequest{
+		Text:     "Call dentist",
+		Timezone: "Mars/Olympus",
+	}
+	err := qv.QuickAddValidate(req)
+	assert.NotNil(t, err)
+	assert.Equal(t, "timezone: is not a valid time zone.", err.Error())
+}
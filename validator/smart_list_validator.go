@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"errors"
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type ISmartListValidator interface {
+	SmartListValidate(list model.SmartList) error
+}
+
+type smartListValidator struct{}
+
+func NewSmartListValidator() ISmartListValidator {
+	return &smartListValidator{}
+}
+
+func (sv *smartListValidator) SmartListValidate(list model.SmartList) error {
+	return validation.ValidateStruct(&list,
+		validation.Field(
+			&list.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 50).Error("limited max 50 char"),
+		),
+		validation.Field(
+			&list.Filter,
+			validation.By(func(interface{}) error {
+				return taskFilterValidate(list.Filter)
+			}),
+		),
+	)
+}
+
+func taskFilterValidate(filter model.TaskFilter) error {
+	return validation.ValidateStruct(&filter,
+		validation.Field(
+			&filter.Statuses,
+			validation.Each(validation.In(model.TaskStatusTodo, model.TaskStatusDoing, model.TaskStatusDone).Error("must be one of todo, doing, done")),
+		),
+		validation.Field(
+			&filter.Priorities,
+			validation.Each(validation.In(model.TaskPriorityLow, model.TaskPriorityMedium, model.TaskPriorityHigh).Error("must be one of low, medium, high")),
+		),
+		validation.Field(
+			&filter.Labels,
+			validation.Each(validation.Required.Error("label name is required"), validation.RuneLength(1, 30).Error("limited max 30 char")),
+		),
+		validation.Field(
+			&filter.ExcludeLabels,
+			validation.Each(validation.Required.Error("label name is required"), validation.RuneLength(1, 30).Error("limited max 30 char")),
+			validation.By(func(interface{}) error {
+				for _, excluded := range filter.ExcludeLabels {
+					for _, label := range filter.Labels {
+						if excluded == label {
+							return errors.New("label " + label + " is both required and excluded")
+						}
+					}
+				}
+				return nil
+			}),
+		),
+		validation.Field(
+			&filter.Due,
+			validation.In(model.DueOverdue, model.DueToday, model.DueThisWeek, model.DueNext7Days, model.DueNoDueDate).Error("must be one of overdue, today, this_week, next_7_days, none"),
+			validation.When(filter.DueFrom != nil || filter.DueTo != nil, validation.Empty.Error("cannot be combined with due_from or due_to")),
+		),
+		validation.Field(
+			&filter.DueTo,
+			validation.By(func(interface{}) error {
+				if filter.DueFrom != nil && filter.DueTo != nil && !filter.DueTo.After(*filter.DueFrom) {
+					return errors.New("must be after due_from")
+				}
+				return nil
+			}),
+		),
+		validation.Field(
+			&filter.TitleContains,
+			validation.RuneLength(0, 100).Error("limited max 100 char"),
+		),
+	)
+}
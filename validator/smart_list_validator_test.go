@@ -0,0 +1,78 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmartListValidator_Success(t *testing.T) {
+	sv := NewSmartListValidator()
+	list := model.SmartList{
+		Name: "high this week",
+		Filter: model.TaskFilter{
+			Priorities:    []string{model.TaskPriorityHigh},
+			Due:           model.DueThisWeek,
+			ExcludeLabels: []string{"waiting"},
+		},
+	}
+	err := sv.SmartListValidate(list)
+	assert.Nil(t, err)
+}
+
+func TestSmartListValidator_NameNil_Failure(t *testing.T) {
+	sv := NewSmartListValidator()
+	list := model.SmartList{
+		Name: "",
+	}
+	err := sv.SmartListValidate(list)
+	assert.NotNil(t, err)
+	assert.Equal(t, "name: name is required.", err.Error())
+}
+
+func TestSmartListValidator_InvalidPriority_Failure(t *testing.T) {
+	sv := NewSmartListValidator()
+	list := model.SmartList{
+		Name:   "list",
+		Filter: model.TaskFilter{Priorities: []string{"urgent"}},
+	}
+	err := sv.SmartListValidate(list)
+	assert.NotNil(t, err)
+	assert.Equal(t, "filter: (priorities: (0: must be one of low, medium, high.).).", err.Error())
+}
+
+func TestSmartListValidator_InvalidDue_Failure(t *testing.T) {
+	sv := NewSmartListValidator()
+	list := model.SmartList{
+		Name:   "list",
+		Filter: model.TaskFilter{Due: "someday"},
+	}
+	err := sv.SmartListValidate(list)
+	assert.NotNil(t, err)
+	assert.Equal(t, "filter: (due: must be one of overdue, today, this_week, next_7_days, none.).", err.Error())
+}
+
+func TestSmartListValidator_DueWithRange_Failure(t *testing.T) {
+	sv := NewSmartListValidator()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := model.SmartList{
+		Name:   "list",
+		Filter: model.TaskFilter{Due: model.DueToday, DueFrom: &from},
+	}
+	err := sv.SmartListValidate(list)
+	assert.NotNil(t, err)
+	assert.Equal(t, "filter: (due: cannot be combined with due_from or due_to.).", err.Error())
+}
+
+func TestSmartListValidator_LabelConflict_Failure(t *testing.T) {
+	sv := NewSmartListValidator()
+	list := model.SmartList{
+		Name:   "list",
+		Filter: model.TaskFilter{Labels: []string{"waiting"}, ExcludeLabels: []string{"waiting"}},
+	}
+	err := sv.SmartListValidate(list)
+	assert.NotNil(t, err)
+	assert.Equal(t, "filter: (exclude_labels: label waiting is both required and excluded.).", err.Error())
+}
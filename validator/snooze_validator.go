@@ -0,0 +1,28 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type ISnoozeValidator interface {
+	SnoozeValidate(req model.SnoozeRequest) error
+}
+
+type snoozeValidator struct{}
+
+func NewSnoozeValidator() ISnoozeValidator {
+	return &snoozeValidator{}
+}
+
+func (sv *snoozeValidator) SnoozeValidate(req model.SnoozeRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Until,
+			validation.Required.Error("until is required"),
+			validation.Min(time.Now()).Exclusive().Error("must be in the future"),
+		),
+	)
+}
@@ -0,0 +1,36 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnoozeValidator_Success(t *testing.T) {
+	sv := NewSnoozeValidator()
+	req := model.SnoozeRequest{
+		Until: time.Now().Add(time.Hour),
+	}
+	err := sv.SnoozeValidate(req)
+	assert.Nil(t, err)
+}
+
+func TestSnoozeValidator_UntilNil_Failure(t *testing.T) {
+	sv := NewSnoozeValidator()
+	req := model.SnoozeRequest{}
+	err := sv.SnoozeValidate(req)
+	assert.NotNil(t, err)
+	assert.Equal(t, "until: until is required.", err.Error())
+}
+
+func TestSnoozeValidator_UntilPast_Failure(t *testing.T) {
+	sv := NewSnoozeValidator()
+	req := model.SnoozeRequest{
+		Until: time.Now().Add(-time.Minute),
+	}
+	err := sv.SnoozeValidate(req)
+	assert.NotNil(t, err)
+	assert.Equal(t, "until: must be in the future.", err.Error())
+}
@@ -0,0 +1,48 @@
+package validator
+
+import (
+	"errors"
+	"go-rest-api/model"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const maxStatsRange = 366 * 24 * time.Hour
+
+type IStatsValidator interface {
+	StatsQueryValidate(query model.StatsQuery) error
+}
+
+type statsValidator struct{}
+
+func NewStatsValidator() IStatsValidator {
+	return &statsValidator{}
+}
+
+func (sv *statsValidator) StatsQueryValidate(query model.StatsQuery) error {
+	return validation.ValidateStruct(&query,
+		validation.Field(
+			&query.Interval,
+			validation.Required.Error("interval is required"),
+			validation.In(model.StatsIntervalDay, model.StatsIntervalWeek).Error("must be one of day, week"),
+		),
+		validation.Field(
+			&query.From,
+			validation.Required.Error("from is required"),
+		),
+		validation.Field(
+			&query.To,
+			validation.Required.Error("to is required"),
+			validation.By(func(interface{}) error {
+				if !query.To.After(query.From) {
+					return errors.New("must be after from")
+				}
+				if query.To.Sub(query.From) > maxStatsRange {
+					return errors.New("range limited max 366 days")
+				}
+				return nil
+			}),
+		),
+	)
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsValidator_Success(t *testing.T) {
+	sv := NewStatsValidator()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := model.StatsQuery{
+		From:     from,
+		To:       from.AddDate(0, 1, 0),
+		Interval: model.StatsIntervalWeek,
+	}
+	err := sv.StatsQueryValidate(query)
+	assert.Nil(t, err)
+}
+
+func TestStatsValidator_InvalidInterval_Failure(t *testing.T) {
+	sv := NewStatsValidator()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := model.StatsQuery{
+		From:     from,
+		To:       from.AddDate(0, 1, 0),
+		Interval: "month",
+	}
+	err := sv.StatsQueryValidate(query)
+	assert.NotNil(t, err)
+	assert.Equal(t, "interval: must be one of day, week.", err.Error())
+}
+
+func TestStatsValidator_ToBeforeFrom_Failure(t *testing.T) {
+	sv := NewStatsValidator()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := model.StatsQuery{
+		From:     from,
+		To:       from.AddDate(0, 0, -1),
+		Interval: model.StatsIntervalDay,
+	}
+	err := sv.StatsQueryValidate(query)
+	assert.NotNil(t, err)
+	assert.Equal(t, "to: must be after from.", err.Error())
+}
+
+func TestStatsValidator_RangeMax_Failure(t *testing.T) {
+	sv := NewStatsValidator()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := model.StatsQuery{
+		From:     from,
+		To:       from.AddDate(2, 0, 0),
+		Interval: model.StatsIntervalDay,
+	}
+	err := sv.StatsQueryValidate(query)
+	assert.NotNil(t, err)
+	assert.Equal(t, "to: range limited max 366 days.", err.Error())
+}
@@ -1,13 +1,22 @@
 package validator
 
 import (
+	"errors"
 	"go-rest-api/model"
+	"regexp"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
+// recurrencePattern accepts the subset of RFC 5545 RRULE values that tasks
+// support.
+var recurrencePattern = regexp.MustCompile(`^FREQ=(DAILY|WEEKLY|MONTHLY|YEARLY)(;INTERVAL=[1-9][0-9]*)?(;BYDAY=(MO|TU|WE|TH|FR|SA|SU)(,(MO|TU|WE|TH|FR|SA|SU))*)?$`)
+
 type ITaskValidator interface {
 	TaskValidate(task model.Task) error
+	TaskPatchValidate(patch model.TaskPatch) error
+	CommentValidate(comment model.Comment) error
+	AttachmentValidate(attachment model.Attachment) error
 }
 
 type taskValidator struct{}
@@ -23,5 +32,122 @@ func (tv *taskValidator) TaskValidate(task model.Task) error {
 			validation.Required.Error("title is requred"),
 			validation.RuneLength(1, 100).Error("limited max 100 char"),
 		),
+		validation.Field(
+			&task.Description,
+			validation.RuneLength(0, 5000).Error("limited max 5000 char"),
+		),
+		validation.Field(
+			&task.Status,
+			validation.In(model.TaskStatusTodo, model.TaskStatusDoing, model.TaskStatusDone).Error("must be one of todo, doing, done"),
+		),
+		validation.Field(
+			&task.Priority,
+			validation.In(model.TaskPriorityLow, model.TaskPriorityMedium, model.TaskPriorityHigh).Error("must be one of low, medium, high"),
+		),
+		validation.Field(
+			&task.Labels,
+			validation.By(labelsRule),
+		),
+		validation.Field(
+			&task.Recurrence,
+			validation.Match(recurrencePattern).Error("must be an RRULE such as FREQ=WEEKLY;BYDAY=MO"),
+		),
+	)
+}
+
+// TaskPatchValidate applies the TaskValidate rules to the members present in
+// patch only.
+func (tv *taskValidator) TaskPatchValidate(patch model.TaskPatch) error {
+	return validation.ValidateStruct(&patch,
+		validation.Field(
+			&patch.Title,
+			validation.When(patch.Title.Set, validation.By(patchRule(patch.Title.Null, "title", patch.Title.Value,
+				validation.Required.Error("title is requred"),
+				validation.RuneLength(1, 100).Error("limited max 100 char"),
+			))),
+		),
+		validation.Field(
+			&patch.Description,
+			validation.When(patch.Description.Set, validation.By(func(interface{}) error {
+				return validation.Validate(patch.Description.Value,
+					validation.RuneLength(0, 5000).Error("limited max 5000 char"),
+				)
+			})),
+		),
+		validation.Field(
+			&patch.Status,
+			validation.When(patch.Status.Set, validation.By(patchRule(patch.Status.Null, "status", patch.Status.Value,
+				validation.In(model.TaskStatusTodo, model.TaskStatusDoing, model.TaskStatusDone).Error("must be one of todo, doing, done"),
+			))),
+		),
+		validation.Field(
+			&patch.Priority,
+			validation.When(patch.Priority.Set, validation.By(patchRule(patch.Priority.Null, "priority", patch.Priority.Value,
+				validation.In(model.TaskPriorityLow, model.TaskPriorityMedium, model.TaskPriorityHigh).Error("must be one of low, medium, high"),
+			))),
+		),
+		validation.Field(
+			&patch.Labels,
+			validation.When(patch.Labels.Set && !patch.Labels.Null, validation.By(func(interface{}) error {
+				return labelsRule(patch.Labels.Value)
+			})),
+		),
+		validation.Field(
+			&patch.Recurrence,
+			validation.When(patch.Recurrence.Set, validation.By(func(interface{}) error {
+				return validation.Validate(patch.Recurrence.Value,
+					validation.Match(recurrencePattern).Error("must be an RRULE such as FREQ=WEEKLY;BYDAY=MO"),
+				)
+			})),
+		),
 	)
 }
+
+func (tv *taskValidator) CommentValidate(comment model.Comment) error {
+	return validation.ValidateStruct(&comment,
+		validation.Field(
+			&comment.Body,
+			validation.Required.Error("body is required"),
+			validation.RuneLength(1, 2000).Error("limited max 2000 char"),
+		),
+	)
+}
+
+func (tv *taskValidator) AttachmentValidate(attachment model.Attachment) error {
+	return validation.ValidateStruct(&attachment,
+		validation.Field(
+			&attachment.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 255).Error("limited max 255 char"),
+		),
+		validation.Field(
+			&attachment.Size,
+			validation.Required.Error("file is empty"),
+			validation.Max(int64(model.MaxAttachmentSize)).Error("limited max 10 MiB"),
+		),
+	)
+}
+
+// patchRule validates a required member of a merge patch, which may be
+// changed but not cleared.
+func patchRule(null bool, name string, value interface{}, rules ...validation.Rule) validation.RuleFunc {
+	return func(interface{}) error {
+		if null {
+			return errors.New(name + " cannot be null")
+		}
+		return validation.Validate(value, rules...)
+	}
+}
+
+func labelsRule(value interface{}) error {
+	labels, _ := value.([]model.Label)
+	for _, label := range labels {
+		if label.Name == "" {
+			return errors.New("label name is required")
+		}
+		if len([]rune(label.Name)) > 30 {
+			return errors.New("label name limited max 30 char")
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"go-rest-api/model"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,3 +37,167 @@ func TestTaskValidator_TitleMax_Failure(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "title: limited max 100 char.", err.Error())
 }
+
+func TestTaskValidator_InvalidStatus_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	task := model.Task{
+		Title:  "title",
+		Status: "archived",
+	}
+	err := tv.TaskValidate(task)
+	assert.NotNil(t, err)
+	assert.Equal(t, "status: must be one of todo, doing, done.", err.Error())
+}
+
+func TestTaskValidator_LabelNameNil_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	task := model.Task{
+		Title:  "title",
+		Labels: []model.Label{{Name: ""}},
+	}
+	err := tv.TaskValidate(task)
+	assert.NotNil(t, err)
+	assert.Equal(t, "labels: label name is required.", err.Error())
+}
+
+func TestTaskValidator_InvalidPriority_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	task := model.Task{
+		Title:    "title",
+		Priority: "urgent",
+	}
+	err := tv.TaskValidate(task)
+	assert.NotNil(t, err)
+	assert.Equal(t, "priority: must be one of low, medium, high.", err.Error())
+}
+
+func TestTaskPatchValidator_Success(t *testing.T) {
+	tv := NewTaskValidator()
+	patch := model.TaskPatch{
+		Status:  model.PatchField[string]{Set: true, Value: model.TaskStatusDone},
+		DueDate: model.PatchField[time.Time]{Set: true, Null: true},
+	}
+	err := tv.TaskPatchValidate(patch)
+	assert.Nil(t, err)
+}
+
+func TestTaskPatchValidator_AbsentTitle_Success(t *testing.T) {
+	tv := NewTaskValidator()
+	patch := model.TaskPatch{
+		Priority: model.PatchField[string]{Set: true, Value: model.TaskPriorityHigh},
+	}
+	err := tv.TaskPatchValidate(patch)
+	assert.Nil(t, err)
+}
+
+func TestTaskPatchValidator_TitleNull_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	patch := model.TaskPatch{
+		Title: model.PatchField[string]{Set: true, Null: true},
+	}
+	err := tv.TaskPatchValidate(patch)
+	assert.NotNil(t, err)
+	assert.Equal(t, "title: title cannot be null.", err.Error())
+}
+
+func TestTaskPatchValidator_TitleMax_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	patch := model.TaskPatch{
+		Title: model.PatchField[string]{Set: true, Value: strings.Repeat("a", 101)},
+	}
+	err := tv.TaskPatchValidate(patch)
+	assert.NotNil(t, err)
+	assert.Equal(t, "title: limited max 100 char.", err.Error())
+}
+
+func TestTaskPatchValidator_InvalidStatus_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	patch := model.TaskPatch{
+		Status: model.PatchField[string]{Set: true, Value: "archived"},
+	}
+	err := tv.TaskPatchValidate(patch)
+	assert.NotNil(t, err)
+	assert.Equal(t, "status: must be one of todo, doing, done.", err.Error())
+}
+
+func TestTaskValidator_Recurrence_Success(t *testing.T) {
+	tv := NewTaskValidator()
+	task := model.Task{
+		Title:      "title",
+		Recurrence: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+	}
+	err := tv.TaskValidate(task)
+	assert.Nil(t, err)
+}
+
+func TestTaskValidator_InvalidRecurrence_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	task := model.Task{
+		Title:      "title",
+		Recurrence: "every monday",
+	}
+	err := tv.TaskValidate(task)
+	assert.NotNil(t, err)
+	assert.Equal(t, "recurrence: must be an RRULE such as FREQ=WEEKLY;BYDAY=MO.", err.Error())
+}
+
+func TestTaskPatchValidator_RecurrenceNull_Success(t *testing.T) {
+	tv := NewTaskValidator()
+	patch := model.TaskPatch{
+		Recurrence: model.PatchField[string]{Set: true, Null: true},
+	}
+	err := tv.TaskPatchValidate(patch)
+	assert.Nil(t, err)
+}
+
+func TestTaskValidator_DescriptionMax_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	task := model.Task{
+		Title:       "title",
+		Description: strings.Repeat("a", 5001),
+	}
+	err := tv.TaskValidate(task)
+	assert.NotNil(t, err)
+	assert.Equal(t, "description: limited max 5000 char.", err.Error())
+}
+
+func TestTaskPatchValidator_DescriptionMax_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	patch := model.TaskPatch{
+		Description: model.PatchField[string]{Set: true, Value: strings.Repeat("a", 5001)},
+	}
+	err := tv.TaskPatchValidate(patch)
+	assert.NotNil(t, err)
+	assert.Equal(t, "description: limited max 5000 char.", err.Error())
+}
+
+func TestCommentValidator_BodyNil_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	comment := model.Comment{}
+	err := tv.CommentValidate(comment)
+	assert.NotNil(t, err)
+	assert.Equal(t, "body: body is required.", err.Error())
+}
+
+func TestCommentValidator_BodyMax_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	comment := model.Comment{Body: strings.Repeat("a", 2001)}
+	err := tv.CommentValidate(comment)
+	assert.NotNil(t, err)
+	assert.Equal(t, "body: limited max 2000 char.", err.Error())
+}
+
+func TestAttachmentValidator_Success(t *testing.T) {
+	tv := NewTaskValidator()
+	attachment := model.Attachment{Name: "notes.txt", Size: 12}
+	err := tv.AttachmentValidate(attachment)
+	assert.Nil(t, err)
+}
+
+func TestAttachmentValidator_SizeMax_Failure(t *testing.T) {
+	tv := NewTaskValidator()
+	attachment := model.Attachment{Name: "video.mp4", Size: model.MaxAttachmentSize + 1}
+	err := tv.AttachmentValidate(attachment)
+	assert.NotNil(t, err)
+	assert.Equal(t, "size: limited max 10 MiB.", err.Error())
+}
@@ -1,14 +1,35 @@
 package validator
 
 import (
+	"errors"
 	"go-rest-api/model"
+	"regexp"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 )
 
+// handlePattern matches the handles that can be @mentioned.
+var handlePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,30}$`)
+
+// emailRules apply wherever an email address is chosen.
+var emailRules = []validation.Rule{
+	validation.Required.Error("email is required"),
+	validation.RuneLength(1, 30).Error("limited max 30 char"),
+	is.Email.Error("is not valid email format"),
+}
+
+// passwordRules apply wherever a password is chosen.
+var passwordRules = []validation.Rule{
+	validation.Required.Error("password is required"),
+	validation.RuneLength(6, 30).Error("limited min 6 max 30 char"),
+}
+
 type IUserValidator interface {
 	UserValidate(user model.User) error
+	PasswordValidate(password string) error
+	EmailValidate(email string) error
 }
 
 type userValidator struct{}
@@ -19,16 +40,40 @@ func NewUserValidator() IUserValidator {
 
 func (uv *userValidator) UserValidate(user model.User) error {
 	return validation.ValidateStruct(&user,
+		validation.Field(&user.Email, emailRules...),
+		validation.Field(&user.Password, passwordRules...),
 		validation.Field(
-			&user.Email,
-			validation.Required.Error("email is required"),
-			validation.RuneLength(1, 30).Error("limited max 30 char"),
-			is.Email.Error("is not valid email format"),
+			&user.Handle,
+			validation.Match(handlePattern).Error("must be 3-30 letters, digits or underscores"),
 		),
 		validation.Field(
-			&user.Password,
-			validation.Required.Error("password is required"),
-			validation.RuneLength(6, 30).Error("limited min 6 max 30 char"),
+			&user.Timezone,
+			validation.By(timezoneRule),
 		),
 	)
 }
+
+func (uv *userValidator) PasswordValidate(password string) error {
+	return validation.Errors{
+		"password": validation.Validate(password, passwordRules...),
+	}.Filter()
+}
+
+func (uv *userValidator) EmailValidate(email string) error {
+	return validation.Errors{
+		"email": validation.Validate(email, emailRules...),
+	}.Filter()
+}
+
+// timezoneRule accepts an IANA time zone name such as Asia/Tokyo. An empty
+// value is left to the caller's default.
+func timezoneRule(value interface{}) error {
+	name, _ := value.(string)
+	if name == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return errors.New("is not a valid time zone")
+	}
+	return nil
+}
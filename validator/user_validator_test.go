@@ -95,3 +95,40 @@ func TestUserValidator_PasswordMax_Failure(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "password: limited min 6 max 30 char.", err.Error())
 }
+
+func TestUserValidator_InvalidHandle_Failure(t *testing.T) {
+	uv := NewUserValidator()
+	handle := "no spaces"
+	user := model.User{
+		Email:    "user@test.com",
+		Password: "password",
+		Handle:   &handle,
+	}
+	err := uv.UserValidate(user)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "handle: must be 3-30 letters, digits or underscores.", err.Error())
+}
+
+func TestPasswordValidator_Success(t *testing.T) {
+	uv := NewUserValidator()
+	err := uv.PasswordValidate("password")
+
+	assert.Nil(t, err)
+}
+
+func TestPasswordValidator_PasswordMin_Failure(t *testing.T) {
+	uv := NewUserValidator()
+	err := uv.PasswordValidate("pass")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "password: limited min 6 max 30 char.", err.Error())
+}
+
+func TestEmailValidator_EmailNil_Failure(t *testing.T) {
+	uv := NewUserValidator()
+	err := uv.EmailValidate("")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "email: email is required.", err.Error())
+}
@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"go-rest-api/model"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type IWorkspaceValidator interface {
+	WorkspaceValidate(workspace model.Workspace) error
+	WorkspaceMemberValidate(req model.WorkspaceMemberRequest) error
+}
+
+type workspaceValidator struct{}
+
+func NewWorkspaceValidator() IWorkspaceValidator {
+	return &workspaceValidator{}
+}
+
+func (wv *workspaceValidator) WorkspaceValidate(workspace model.Workspace) error {
+	return validation.ValidateStruct(&workspace,
+		validation.Field(
+			&workspace.Name,
+			validation.Required.Error("name is required"),
+			validation.RuneLength(1, 100).Error("limited max 100 char"),
+		),
+	)
+}
+
+// WorkspaceMemberValidate leaves out the owner role, since a workspace has
+// exactly one owner. An empty role means member.
+func (wv *workspaceValidator) WorkspaceMemberValidate(req model.WorkspaceMemberRequest) error {
+	return validation.ValidateStruct(&req,
+		validation.Field(
+			&req.Email,
+			validation.Required.Error("email is required"),
+			is.EmailFormat.Error("is not valid email format"),
+		),
+		validation.Field(
+			&req.Role,
+			validation.In(model.WorkspaceRoleAdmin, model.WorkspaceRoleMember).Error("must be one of admin, member"),
+		),
+	)
+}
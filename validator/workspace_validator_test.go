@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"go-rest-api/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceValidator_Success(t *testing.T) {
+	wv := NewWorkspaceValidator()
+	err := wv.WorkspaceValidate(model.Workspace{Name: "Engineering"})
+	assert.Nil(t, err)
+}
+
+func TestWorkspaceValidator_NameNil_Failure(t *testing.T) {
+	wv := NewWorkspaceValidator()
+	err := wv.WorkspaceValidate(model.Workspace{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "name: name is required.", err.Error())
+}
+
+func TestWorkspaceValidator_NameMax_Failure(t *testing.T) {
+	wv := NewWorkspaceValidator()
+	err := wv.WorkspaceValidate(model.Workspace{Name: strings.Repeat("a", 101)})
+	assert.NotNil(t, err)
+	assert.Equal(t, "name: limited max 100 char.", err.Error())
+}
+
+func TestWorkspaceMemberValidator_Success(t *testing.T) {
+	wv := NewWorkspaceValidator()
+	err := wv.WorkspaceMemberValidate(model.WorkspaceMemberRequest{Email: "member@test.com"})
+	assert.Nil(t, err)
+	err = wv.WorkspaceMemberValidate(model.WorkspaceMemberRequest{Email: "admin@test.com", Role: model.WorkspaceRoleAdmin})
+	assert.Nil(t, err)
+}
+
+func TestWorkspaceMemberValidator_OwnerRole_Failure(t *testing.T) {
+	wv := NewWorkspaceValidator()
+	err := wv.WorkspaceMemberValidate(model.WorkspaceMemberRequest{Email: "member@test.com", Role: model.WorkspaceRoleOwner})
+	assert.NotNil(t, err)
+	assert.Equal(t, "role: must be one of admin, member.", err.Error())
+}